@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
 	"reflect"
 	"runtime"
 	"strconv"
@@ -129,6 +130,23 @@ processing will proceed even if an individual RLP-file import failure occurs.`,
 Checks if the chain config isMPS parameter value.
 If false, it upgrades the DB to be MPS enabled (builds the trie of private states) and if successful sets isMPS to true.
 If true, exits displaying an error message that the DB is already MPS.`,
+	}
+	mpsdbSplitCommand = &cli.Command{
+		Action:    mpsdbSplit,
+		Name:      "mpsdbsplit",
+		Usage:     "Split the private state of a PSI out of an MPS DB into a standalone DB",
+		ArgsUsage: "<datadir>",
+		Flags: []cli.Flag{
+			utils.DataDirFlag,
+			utils.AncientFlag,
+			privateStatePSIFlag,
+		},
+		Description: `
+geth mpsdbsplit --psi <psi> <datadir>
+writes a new database in the given data directory, which contains the public chain of the
+MPS DB and the history of the private state of the given PSI only, for the PSI to be run
+by a standalone node without multiple private states. The node key, the keystore and the
+configuration of the private transaction manager of the new node are not written.`,
 	}
 	exportCommand = &cli.Command{
 		Action:    exportChain,
@@ -452,6 +470,32 @@ func mpsdbUpgrade(ctx *cli.Context) error {
 	return mps.UpgradeDB(db, chain)
 }
 
+func mpsdbSplit(ctx *cli.Context) error {
+	if ctx.NArg() != 1 {
+		utils.Fatalf("This command requires an argument.")
+	}
+	if !ctx.IsSet(privateStatePSIFlag.Name) {
+		utils.Fatalf("The --%s flag is required", privateStatePSIFlag.Name)
+	}
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	db := utils.MakeChainDatabase(ctx, stack, true)
+	defer db.Close()
+
+	chaindata := filepath.Join(ctx.Args().First(), "geth", "chaindata")
+	if _, err := os.Stat(chaindata); err == nil {
+		utils.Fatalf("The database %s already exists", chaindata)
+	}
+	splitDb, err := rawdb.NewLevelDBDatabaseWithFreezer(chaindata, 256, 256, filepath.Join(chaindata, "ancient"), "", false)
+	if err != nil {
+		utils.Fatalf("Failed to open the database %s: %v", chaindata, err)
+	}
+	defer splitDb.Close()
+
+	return mps.SplitDB(db, splitDb, types.PrivateStateIdentifier(ctx.String(privateStatePSIFlag.Name)))
+}
+
 func exportChain(ctx *cli.Context) error {
 	if ctx.NArg() < 1 {
 		utils.Fatalf("This command requires an argument.")
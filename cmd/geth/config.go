@@ -24,6 +24,7 @@ import (
 	"math/big"
 	"os"
 	"reflect"
+	"strconv"
 	"strings"
 	"unicode"
 
@@ -148,6 +149,8 @@ func makeConfigNode(ctx *cli.Context) (*node.Node, gethConfig) {
 	if err != nil {
 		utils.Fatalf("Failed to create the protocol stack: %v", err)
 	}
+	// Quorum: release the private payload cache once the services using it have stopped
+	stack.RegisterLifecycle(&private.ConnectionLifecycle{})
 	utils.SetEthConfig(ctx, stack, &cfg.Eth)
 	if ctx.IsSet(utils.EthStatsURLFlag.Name) {
 		cfg.Ethstats.URL = ctx.String(utils.EthStatsURLFlag.Name)
@@ -454,6 +457,62 @@ func QuorumSetupPrivacyConfiguration(ctx *cli.Context) (http.Config, error) {
 	if ctx.IsSet(utils.QuorumPTMTlsInsecureSkipVerify.Name) {
 		cfg.SetTlsInsecureSkipVerify(ctx.Bool(utils.QuorumPTMTlsInsecureSkipVerify.Name))
 	}
+	if ctx.IsSet(utils.QuorumPTMHttpFailoverUrlsFlag.Name) {
+		cfg.SetHttpFailoverUrls(utils.SplitAndTrim(ctx.String(utils.QuorumPTMHttpFailoverUrlsFlag.Name)))
+	}
+	if ctx.IsSet(utils.QuorumPTMUpcheckIntervalFlag.Name) {
+		cfg.SetUpcheckInterval(ctx.Uint(utils.QuorumPTMUpcheckIntervalFlag.Name))
+	}
+	if ctx.IsSet(utils.QuorumPTMRetryMaxAttemptsFlag.Name) {
+		cfg.SetRetryMaxAttempts(ctx.Uint(utils.QuorumPTMRetryMaxAttemptsFlag.Name))
+	}
+	if ctx.IsSet(utils.QuorumPTMRetryInitialBackoffFlag.Name) {
+		cfg.SetRetryInitialBackoff(ctx.Uint(utils.QuorumPTMRetryInitialBackoffFlag.Name))
+	}
+	if ctx.IsSet(utils.QuorumPTMRetryMaxBackoffFlag.Name) {
+		cfg.SetRetryMaxBackoff(ctx.Uint(utils.QuorumPTMRetryMaxBackoffFlag.Name))
+	}
+	if ctx.IsSet(utils.QuorumPTMRetryStatusCodesFlag.Name) {
+		var statusCodes []int
+		for _, s := range utils.SplitAndTrim(ctx.String(utils.QuorumPTMRetryStatusCodesFlag.Name)) {
+			statusCode, err := strconv.Atoi(s)
+			if err != nil {
+				return cfg, fmt.Errorf("invalid value for flag %s: %v", utils.QuorumPTMRetryStatusCodesFlag.Name, err)
+			}
+			statusCodes = append(statusCodes, statusCode)
+		}
+		cfg.SetRetryStatusCodes(statusCodes)
+	}
+	if ctx.IsSet(utils.QuorumPTMCircuitBreakerThresholdFlag.Name) {
+		cfg.SetCircuitBreakerThreshold(ctx.Uint(utils.QuorumPTMCircuitBreakerThresholdFlag.Name))
+	}
+	if ctx.IsSet(utils.QuorumPTMCircuitBreakerCooldownFlag.Name) {
+		cfg.SetCircuitBreakerCooldown(ctx.Uint(utils.QuorumPTMCircuitBreakerCooldownFlag.Name))
+	}
+	if ctx.IsSet(utils.QuorumPTMCacheSizeFlag.Name) {
+		cfg.SetCacheSize(ctx.Int(utils.QuorumPTMCacheSizeFlag.Name))
+	}
+	if ctx.IsSet(utils.QuorumPTMCacheExpirationFlag.Name) {
+		cfg.SetCacheExpiration(ctx.Uint(utils.QuorumPTMCacheExpirationFlag.Name))
+	}
+	if ctx.IsSet(utils.QuorumPTMCacheDirFlag.Name) {
+		cfg.SetCacheDir(ctx.String(utils.QuorumPTMCacheDirFlag.Name))
+	}
+	if ctx.IsSet(utils.QuorumPTMCacheDiskExpirationFlag.Name) {
+		cfg.SetCacheDiskExpiration(ctx.Uint(utils.QuorumPTMCacheDiskExpirationFlag.Name))
+	}
+	if ctx.IsSet(utils.QuorumPTMEmbeddedKeysFlag.Name) {
+		cfg.SetEmbeddedKeys(ctx.String(utils.QuorumPTMEmbeddedKeysFlag.Name))
+	}
+	if ctx.IsSet(utils.QuorumPTMEmbeddedDirFlag.Name) {
+		cfg.SetEmbeddedDir(ctx.String(utils.QuorumPTMEmbeddedDirFlag.Name))
+	}
+	if ctx.IsSet(utils.QuorumPTMEmbeddedListenFlag.Name) {
+		cfg.SetEmbeddedListen(ctx.String(utils.QuorumPTMEmbeddedListenFlag.Name))
+	}
+	if ctx.IsSet(utils.QuorumPTMEmbeddedPeersFlag.Name) {
+		cfg.SetEmbeddedPeers(utils.SplitAndTrim(ctx.String(utils.QuorumPTMEmbeddedPeersFlag.Name)))
+	}
 
 	if err = cfg.Validate(); err != nil {
 		return cfg, err
@@ -162,9 +162,13 @@ var (
 		utils.AllowedFutureBlockTimeFlag,
 		utils.EVMCallTimeOutFlag,
 		utils.MultitenancyFlag,
+		utils.PrivateStatesRefreshFlag,
 		utils.RevertReasonFlag,
 		utils.QuorumEnablePrivateTrieCache,
 		utils.QuorumEnablePrivacyMarker,
+		utils.QuorumPrivacyMarkerBatchAccount,
+		utils.QuorumPrivacyMarkerBatchSize,
+		utils.QuorumPrivacyMarkerBatchInterval,
 		utils.QuorumPTMUnixSocketFlag,
 		utils.QuorumPTMUrlFlag,
 		utils.QuorumPTMTimeoutFlag,
@@ -177,6 +181,22 @@ var (
 		utils.QuorumPTMTlsClientCertFlag,
 		utils.QuorumPTMTlsClientKeyFlag,
 		utils.QuorumPTMTlsInsecureSkipVerify,
+		utils.QuorumPTMHttpFailoverUrlsFlag,
+		utils.QuorumPTMUpcheckIntervalFlag,
+		utils.QuorumPTMRetryMaxAttemptsFlag,
+		utils.QuorumPTMRetryInitialBackoffFlag,
+		utils.QuorumPTMRetryMaxBackoffFlag,
+		utils.QuorumPTMRetryStatusCodesFlag,
+		utils.QuorumPTMCircuitBreakerThresholdFlag,
+		utils.QuorumPTMCircuitBreakerCooldownFlag,
+		utils.QuorumPTMCacheSizeFlag,
+		utils.QuorumPTMCacheExpirationFlag,
+		utils.QuorumPTMCacheDirFlag,
+		utils.QuorumPTMCacheDiskExpirationFlag,
+		utils.QuorumPTMEmbeddedKeysFlag,
+		utils.QuorumPTMEmbeddedDirFlag,
+		utils.QuorumPTMEmbeddedListenFlag,
+		utils.QuorumPTMEmbeddedPeersFlag,
 		utils.QuorumLightServerFlag,
 		utils.QuorumLightServerP2PListenPortFlag,
 		utils.QuorumLightServerP2PMaxPeersFlag,
@@ -259,6 +279,7 @@ func init() {
 		initCommand,
 		updateCommand,
 		mpsdbUpgradeCommand,
+		mpsdbSplitCommand,
 		importCommand,
 		exportCommand,
 		importPreimagesCommand,
@@ -285,6 +306,8 @@ func init() {
 		utils.ShowDeprecated,
 		// See snapshot.go
 		snapshotCommand,
+		// See privatestatecmd.go
+		privateStateCommand,
 	}
 	sort.Sort(cli.CommandsByName(app.Commands))
 
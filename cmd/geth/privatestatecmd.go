@@ -0,0 +1,545 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/cmd/utils"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/private"
+	cli "github.com/urfave/cli/v2"
+)
+
+var (
+	privateStatePeersFlag = &cli.StringFlag{
+		Name:  "peers",
+		Usage: "Comma separated URLs of the P2P servers of the Tessera nodes to request the payloads from",
+	}
+	privateStateWaitFlag = &cli.DurationFlag{
+		Name:  "wait",
+		Usage: "How long to wait for the requested payloads to be resent",
+		Value: time.Minute,
+	}
+	privateStatePSIFlag = &cli.StringFlag{
+		Name:  "psi",
+		Usage: "Private state identifier, 'private' if multiple private states are not enabled",
+	}
+	privateStateBlockFlag = &cli.Uint64Flag{
+		Name:  "block",
+		Usage: "Number of the block to export the private state at (default the head block)",
+	}
+	privateStateCommand = &cli.Command{
+		Name:        "privatestate",
+		Usage:       "A set of commands to inspect, recover and migrate the private state",
+		Description: "",
+		Subcommands: []*cli.Command{
+			{
+				Name:      "verify",
+				Usage:     "Re-execute the chain to verify the integrity of the private state",
+				ArgsUsage: "[<blockNumFirst> [<blockNumLast>]]",
+				Action:    verifyPrivateState,
+				Flags: []cli.Flag{
+					utils.DataDirFlag,
+					utils.AncientFlag,
+					configFileFlag,
+					utils.QuorumPTMUnixSocketFlag,
+					utils.QuorumPTMUrlFlag,
+					utils.QuorumPTMEmbeddedKeysFlag,
+					utils.QuorumPTMEmbeddedDirFlag,
+				},
+				Description: `
+geth privatestate verify [<blockNumFirst> [<blockNumLast>]]
+will re-execute the blocks from blockNumFirst (default 1) to blockNumLast
+(default the head block), starting from the stored state of the parent of
+blockNumFirst and carrying the recomputed state forward, and compare the
+recomputed private state root - the root of each private state if multiple
+private states are enabled - with the one stored for the block.
+
+The first divergent block is reported together with the first private
+transaction whose receipt differs and the private transactions of the block
+the divergent private state took part in whose payload the private
+transaction manager does not hold.
+
+The private transaction manager must be running. Only the state of the parent
+of blockNumFirst must be available, so the node does not need to have run in
+archive mode when verifying from the genesis block or a recent block.
+`,
+			},
+			{
+				Name:      "scan-missing",
+				Usage:     "List the private transactions whose payload is missing from the private transaction manager",
+				ArgsUsage: "[<blockNumFirst> [<blockNumLast>]]",
+				Action:    scanMissingPrivatePayloads,
+				Flags: []cli.Flag{
+					utils.DataDirFlag,
+					utils.AncientFlag,
+					configFileFlag,
+					utils.QuorumPTMUnixSocketFlag,
+					utils.QuorumPTMUrlFlag,
+					utils.QuorumPTMEmbeddedKeysFlag,
+					utils.QuorumPTMEmbeddedDirFlag,
+				},
+				Description: `
+geth privatestate scan-missing [<blockNumFirst> [<blockNumLast>]]
+will list the private transactions from blockNumFirst (default 1) to
+blockNumLast (default the head block) whose payload the private transaction
+manager cannot return, with the parties managed by this node which take part
+in each of them as far as the private transaction manager knows.
+
+The private transaction manager does not hold the payloads of the transactions
+this node is not a party to, so the list also contains those. The same list is
+returned by the admin_missingPrivatePayloads RPC method.
+`,
+			},
+			{
+				Name:      "recover",
+				Usage:     "Recover missing private payloads from other Tessera nodes and rebuild the private state",
+				ArgsUsage: "[<blockNumFirst> [<blockNumLast>]]",
+				Action:    recoverPrivateState,
+				Flags: []cli.Flag{
+					utils.DataDirFlag,
+					utils.AncientFlag,
+					configFileFlag,
+					utils.QuorumPTMUnixSocketFlag,
+					utils.QuorumPTMUrlFlag,
+					utils.QuorumPTMEmbeddedKeysFlag,
+					utils.QuorumPTMEmbeddedDirFlag,
+					privateStatePeersFlag,
+					privateStateWaitFlag,
+				},
+				Description: `
+geth privatestate recover --peers <url>[,<url>...] [<blockNumFirst> [<blockNumLast>]]
+will scan the blocks from blockNumFirst (default 1) to blockNumLast (default
+the head block) for private transactions whose payload is missing, ask the
+Tessera node of each peer to resend all the payloads it holds for the parties
+managed by this node and wait for the missing payloads to arrive, i.e. for the
+payloads of the transactions the receipts show this node took part in, or for
+all of them if there are none, until --wait is over.
+
+The private state is then rebuilt from the earliest block with a recovered
+payload to the head block. The payloads which are still missing afterwards
+belong to transactions this node is not a party to, unless no peer holds them.
+
+The node must not be running. The states of all rebuilt blocks must be
+available, so unless only recent blocks are affected the node must have run in
+archive mode (--gcmode=archive).
+`,
+			},
+			{
+				Name:      "export",
+				Usage:     "Export a private state, including the privacy metadata of its accounts, into a file",
+				ArgsUsage: "<filename>",
+				Action:    exportPrivateState,
+				Flags: []cli.Flag{
+					utils.DataDirFlag,
+					utils.AncientFlag,
+					configFileFlag,
+					privateStatePSIFlag,
+					privateStateBlockFlag,
+				},
+				Description: `
+geth privatestate export --psi <psi> [--block <blockNum>] <filename>
+will write the accounts, storage, code, privacy metadata and managed parties
+of the private state with the given identifier at the given block (default the
+head block) to the file, one JSON object per line.
+
+The preimages of the trie keys are needed to export the state, so the node
+must have recorded them from genesis (--cache.preimages).
+`,
+			},
+			{
+				Name:      "import",
+				Usage:     "Import a private state exported by 'geth privatestate export'",
+				ArgsUsage: "<filename>",
+				Action:    importPrivateState,
+				Flags: []cli.Flag{
+					utils.DataDirFlag,
+					utils.AncientFlag,
+					configFileFlag,
+					utils.QuorumPTMUnixSocketFlag,
+					utils.QuorumPTMUrlFlag,
+					utils.QuorumPTMEmbeddedKeysFlag,
+					utils.QuorumPTMEmbeddedDirFlag,
+					privateStatePSIFlag,
+				},
+				Description: `
+geth privatestate import [--psi <psi>] <filename>
+will seed the private state with the given identifier (default the one the
+file was exported from) with the accounts of the file, replacing the accounts
+which already exist. A private state which does not exist yet is created, so
+that a tenant can be migrated from another node with multiple private states.
+
+The imported accounts are applied on top of the private state of the head
+block, which is re-executed for the purpose, so the state of its parent must
+be available. The private transaction manager must be running and the node
+must not be running.
+`,
+			},
+			{
+				Name:      "index-contracts",
+				Usage:     "Record the private contracts created by blocks imported before the private contracts index",
+				ArgsUsage: "[<blockNumFirst> [<blockNumLast>]]",
+				Action:    indexPrivateContracts,
+				Flags: []cli.Flag{
+					utils.DataDirFlag,
+					utils.AncientFlag,
+					configFileFlag,
+					utils.QuorumPTMUnixSocketFlag,
+					utils.QuorumPTMUrlFlag,
+					utils.QuorumPTMEmbeddedKeysFlag,
+					utils.QuorumPTMEmbeddedDirFlag,
+				},
+				Description: `
+geth privatestate index-contracts [<blockNumFirst> [<blockNumLast>]]
+will record the private contracts created by the blocks from blockNumFirst
+(default 1) to blockNumLast (default the head block), as returned by the
+eth_privateContracts RPC method. The contracts are recorded as blocks are
+imported, so this is only needed for the blocks imported before the index
+was introduced.
+
+The private state of each block must be available, i.e. the node must have
+run in archive mode unless only recent blocks are indexed. The private
+transaction manager must be running and the node must not be running.
+`,
+			},
+		},
+	}
+)
+
+func verifyPrivateState(ctx *cli.Context) error {
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	chain, _ := utils.MakeChain(ctx, stack, true)
+	defer chain.Stop()
+
+	first, last, err := parseBlockRange(ctx, chain.CurrentBlock().NumberU64())
+	if err != nil {
+		return err
+	}
+
+	var (
+		start  = time.Now()
+		logged = time.Now()
+	)
+	log.Info("Verifying private state", "first", first, "last", last, "mps", chain.Config().IsMPS)
+	divergence, err := chain.VerifyPrivateState(first, last, func(block *types.Block) {
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Verifying private state", "number", block.NumberU64(), "hash", block.Hash(), "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	})
+	if err != nil {
+		log.Error("Failed to verify private state", "err", err)
+		return err
+	}
+	if divergence == nil {
+		log.Info("Verified private state", "first", first, "last", last, "elapsed", common.PrettyDuration(time.Since(start)))
+		return nil
+	}
+
+	fmt.Printf("Private state diverges at block %d with hash %v\n", divergence.Block.NumberU64(), divergence.Block.Hash().Hex())
+	fmt.Printf("  private state:   %s\n", divergence.PSI)
+	fmt.Printf("  stored root:     %v\n", divergence.Expected.Hex())
+	fmt.Printf("  recomputed root: %v\n", divergence.Actual.Hex())
+	if divergence.Tx != nil {
+		fmt.Printf("  first divergent transaction: %v\n", divergence.Tx.Hash().Hex())
+	} else {
+		fmt.Printf("  first divergent transaction: unknown\n")
+	}
+	if len(divergence.MissingPayloads) == 0 {
+		fmt.Printf("  no private payloads are missing from the private transaction manager\n")
+	} else {
+		fmt.Printf("  transactions whose private payload is missing from the private transaction manager:\n")
+		for _, hash := range divergence.MissingPayloads {
+			fmt.Printf("    %v\n", hash.Hex())
+		}
+	}
+	return fmt.Errorf("private state diverges at block #%d", divergence.Block.NumberU64())
+}
+
+func indexPrivateContracts(ctx *cli.Context) error {
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	chain, _ := utils.MakeChain(ctx, stack, true)
+	defer chain.Stop()
+
+	first, last, err := parseBlockRange(ctx, chain.CurrentBlock().NumberU64())
+	if err != nil {
+		return err
+	}
+
+	var (
+		start  = time.Now()
+		logged = time.Now()
+	)
+	log.Info("Indexing private contracts", "first", first, "last", last)
+	err = chain.IndexPrivateContracts(first, last, func(block *types.Block) {
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Indexing private contracts", "number", block.NumberU64(), "hash", block.Hash(), "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	})
+	if err != nil {
+		log.Error("Failed to index private contracts", "err", err)
+		return err
+	}
+	log.Info("Indexed private contracts", "first", first, "last", last, "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
+
+func scanMissingPrivatePayloads(ctx *cli.Context) error {
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	chain, _ := utils.MakeChain(ctx, stack, true)
+	defer chain.Stop()
+
+	first, last, err := parseBlockRange(ctx, chain.CurrentBlock().NumberU64())
+	if err != nil {
+		return err
+	}
+	managedParties, err := private.ManagedParties(private.P)
+	if err != nil {
+		log.Warn("Failed to retrieve the parties managed by the private transaction manager", "err", err)
+	}
+	missing, err := chain.ScanMissingPrivatePayloads(first, last, managedParties)
+	if err != nil {
+		log.Error("Failed to scan for missing private payloads", "err", err)
+		return err
+	}
+	printMissingPrivatePayloads(missing)
+	return nil
+}
+
+func recoverPrivateState(ctx *cli.Context) error {
+	if !ctx.IsSet(privateStatePeersFlag.Name) {
+		utils.Fatalf("The --%s flag is required", privateStatePeersFlag.Name)
+	}
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	chain, _ := utils.MakeChain(ctx, stack, true)
+	defer chain.Stop()
+
+	first, last, err := parseBlockRange(ctx, chain.CurrentBlock().NumberU64())
+	if err != nil {
+		return err
+	}
+	managedParties, err := private.ManagedParties(private.P)
+	if err != nil {
+		return err
+	}
+	missing, err := chain.ScanMissingPrivatePayloads(first, last, managedParties)
+	if err != nil {
+		log.Error("Failed to scan for missing private payloads", "err", err)
+		return err
+	}
+	if len(missing) == 0 {
+		log.Info("No private payloads are missing", "first", first, "last", last)
+		return nil
+	}
+	log.Info("Found missing private payloads", "count", len(missing))
+	if err := private.RequestResendFromPeers(private.P, strings.Split(ctx.String(privateStatePeersFlag.Name), ",")); err != nil {
+		log.Error("Failed to request resend of private payloads", "err", err)
+		return err
+	}
+
+	// the payloads are resent asynchronously, so wait for them until the awaited ones arrived or
+	// the wait is over
+	var (
+		recovered []*core.MissingPrivatePayload
+		deadline  = time.Now().Add(ctx.Duration(privateStateWaitFlag.Name))
+		partyOnly = awaitedPrivatePayloads(missing, true) > 0
+	)
+	for {
+		remaining := missing[:0]
+		for _, m := range missing {
+			if m.Available() {
+				recovered = append(recovered, m)
+			} else {
+				remaining = append(remaining, m)
+			}
+		}
+		missing = remaining
+		if awaitedPrivatePayloads(missing, partyOnly) == 0 || !time.Now().Before(deadline) {
+			break
+		}
+		log.Info("Waiting for private payloads to be resent", "recovered", len(recovered), "missing", len(missing))
+		wait := time.Until(deadline)
+		if wait > time.Second {
+			wait = time.Second
+		}
+		time.Sleep(wait)
+	}
+	if len(recovered) == 0 {
+		log.Warn("No missing private payloads have been recovered")
+		printMissingPrivatePayloads(missing)
+		return nil
+	}
+
+	rebuildFrom := recovered[0].BlockNumber
+	for _, m := range recovered {
+		if m.BlockNumber < rebuildFrom {
+			rebuildFrom = m.BlockNumber
+		}
+	}
+	head := chain.CurrentBlock().NumberU64()
+	var (
+		start  = time.Now()
+		logged = time.Now()
+	)
+	log.Info("Rebuilding private state", "first", rebuildFrom, "last", head)
+	err = chain.RebuildPrivateState(rebuildFrom, head, func(block *types.Block) {
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Rebuilding private state", "number", block.NumberU64(), "hash", block.Hash(), "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	})
+	if err != nil {
+		log.Error("Failed to rebuild private state", "err", err)
+		return err
+	}
+	log.Info("Rebuilt private state", "first", rebuildFrom, "last", head, "recovered", len(recovered), "elapsed", common.PrettyDuration(time.Since(start)))
+	if len(missing) > 0 {
+		fmt.Printf("Private payloads which are still missing:\n")
+		printMissingPrivatePayloads(missing)
+	}
+	return nil
+}
+
+func exportPrivateState(ctx *cli.Context) error {
+	if ctx.NArg() != 1 {
+		utils.Fatalf("This command requires an argument.")
+	}
+	if !ctx.IsSet(privateStatePSIFlag.Name) {
+		utils.Fatalf("The --%s flag is required", privateStatePSIFlag.Name)
+	}
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	chain, _ := utils.MakeChain(ctx, stack, true)
+	defer chain.Stop()
+
+	number := chain.CurrentBlock().NumberU64()
+	if ctx.IsSet(privateStateBlockFlag.Name) {
+		number = ctx.Uint64(privateStateBlockFlag.Name)
+	}
+	fh, err := os.OpenFile(ctx.Args().First(), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, os.ModePerm)
+	if err != nil {
+		return err
+	}
+	defer fh.Close()
+
+	start := time.Now()
+	psi := types.PrivateStateIdentifier(ctx.String(privateStatePSIFlag.Name))
+	count, err := chain.ExportPrivateState(psi, number, fh)
+	if err != nil {
+		log.Error("Failed to export private state", "psi", psi, "number", number, "err", err)
+		fh.Close()
+		os.Remove(ctx.Args().First())
+		return err
+	}
+	log.Info("Exported private state", "psi", psi, "number", number, "accounts", count, "file", ctx.Args().First(), "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
+
+func importPrivateState(ctx *cli.Context) error {
+	if ctx.NArg() != 1 {
+		utils.Fatalf("This command requires an argument.")
+	}
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	chain, _ := utils.MakeChain(ctx, stack, true)
+	defer chain.Stop()
+
+	fh, err := os.Open(ctx.Args().First())
+	if err != nil {
+		return err
+	}
+	defer fh.Close()
+	imp, err := state.NewPrivateStateImport(fh)
+	if err != nil {
+		return err
+	}
+
+	start := time.Now()
+	psi := types.PrivateStateIdentifier(ctx.String(privateStatePSIFlag.Name))
+	log.Info("Importing private state", "psi", psi, "exportedPSI", imp.Header.PSI, "exportedNumber", imp.Header.BlockNumber, "exportedRoot", imp.Header.Root)
+	count, err := chain.ImportPrivateState(psi, imp)
+	if err != nil {
+		log.Error("Failed to import private state", "err", err)
+		return err
+	}
+	log.Info("Imported private state", "accounts", count, "number", chain.CurrentBlock().NumberU64(), "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
+
+// awaitedPrivatePayloads returns the number of missing payloads the recovery waits for: the
+// payloads of the transactions the node is known to have taken part in if there are any, as the
+// payloads of the other transactions may never arrive, or else all of them
+func awaitedPrivatePayloads(missing []*core.MissingPrivatePayload, partyOnly bool) int {
+	if !partyOnly {
+		return len(missing)
+	}
+	awaited := 0
+	for _, m := range missing {
+		if m.IsParty {
+			awaited++
+		}
+	}
+	return awaited
+}
+
+func printMissingPrivatePayloads(missing []*core.MissingPrivatePayload) {
+	if len(missing) == 0 {
+		fmt.Printf("No private payloads are missing\n")
+		return
+	}
+	for _, m := range missing {
+		parties := "unknown"
+		if len(m.ManagedParties) > 0 {
+			parties = strings.Join(m.ManagedParties, ",")
+		}
+		sender := "unknown"
+		if m.IsSender != nil {
+			sender = strconv.FormatBool(*m.IsSender)
+		}
+		fmt.Printf("block %d tx %v party: %v managed parties: %s sender: %s\n", m.BlockNumber, m.TxHash.Hex(), m.IsParty, parties, sender)
+	}
+}
+
+// parseBlockRange returns the block range given by the optional first and last block
+// number arguments, which default to 1 and head
+func parseBlockRange(ctx *cli.Context, head uint64) (uint64, uint64, error) {
+	if ctx.NArg() > 2 {
+		log.Error("Too many arguments given")
+		return 0, 0, errors.New("too many arguments")
+	}
+	first, last := uint64(1), head
+	var err error
+	if ctx.NArg() > 0 {
+		if first, err = strconv.ParseUint(ctx.Args().Get(0), 10, 64); err != nil {
+			return 0, 0, fmt.Errorf("invalid first block number: %v", err)
+		}
+	}
+	if ctx.NArg() > 1 {
+		if last, err = strconv.ParseUint(ctx.Args().Get(1), 10, 64); err != nil {
+			return 0, 0, fmt.Errorf("invalid last block number: %v", err)
+		}
+	}
+	if first > last {
+		return 0, 0, fmt.Errorf("first block #%d is after last block #%d", first, last)
+	}
+	return first, last, nil
+}
@@ -69,6 +69,11 @@ All trie nodes and contract codes that do not belong to the specified
 version state will be deleted from the database. After pruning, only
 two version states are available: genesis and the specific one.
 
+The private states of both versions, the trie of private states if
+multiple private states are enabled, and the account extra data of all
+of them are kept as well. The private states of all other versions are
+deleted together with their root mappings.
+
 The default pruning target is the HEAD-127 state.
 
 WARNING: It's necessary to delete the trie clean cache after the pruning.
@@ -965,6 +965,12 @@ var (
 		Category: flags.GoQuorumOptionCategory,
 	}
 
+	PrivateStatesRefreshFlag = &cli.DurationFlag{
+		Name:     "privatestates.refresh",
+		Usage:    "Interval to add the new private states of the private transaction manager to a running MPS node (0 = disabled, use admin_refreshPrivateStates)",
+		Category: flags.GoQuorumOptionCategory,
+	}
+
 	QuorumEnablePrivateTrieCache = &cli.BoolFlag{
 		Name:     "privatetriecache.enable",
 		Usage:    "Enable use of private trie cache for this node.",
@@ -976,6 +982,23 @@ var (
 		Usage:    "Enable use of privacy marker transactions (PMT) for this node.",
 		Category: flags.GoQuorumOptionCategory,
 	}
+	QuorumPrivacyMarkerBatchAccount = &cli.StringFlag{
+		Name:     "privacymarker.batch.account",
+		Usage:    "Unlocked account signing the privacy marker transactions carrying batches of the private transactions submitted to this node (empty = batching disabled)",
+		Category: flags.GoQuorumOptionCategory,
+	}
+	QuorumPrivacyMarkerBatchSize = &cli.IntFlag{
+		Name:     "privacymarker.batch.size",
+		Usage:    "Maximum number of private transactions carried by a privacy marker transaction",
+		Value:    16,
+		Category: flags.GoQuorumOptionCategory,
+	}
+	QuorumPrivacyMarkerBatchInterval = &cli.DurationFlag{
+		Name:     "privacymarker.batch.interval",
+		Usage:    "Interval to submit the privacy marker transactions carrying incomplete batches of private transactions",
+		Value:    time.Second,
+		Category: flags.GoQuorumOptionCategory,
+	}
 
 	// Quorum Private Transaction Manager connection options
 	QuorumPTMUnixSocketFlag = &flags.DirectoryFlag{
@@ -1043,6 +1066,95 @@ var (
 		Usage:    "Disable verification of server's TLS certificate on connection to private transaction manager",
 		Category: flags.GoQuorumOptionCategory,
 	}
+	QuorumPTMHttpFailoverUrlsFlag = &cli.StringFlag{
+		Name:     "ptm.failover.urls",
+		Usage:    "Comma separated list of URLs of additional private transaction managers, sharing the same keys as --ptm.url, to fail over to",
+		Category: flags.GoQuorumOptionCategory,
+	}
+	QuorumPTMUpcheckIntervalFlag = &cli.UintFlag{
+		Name:     "ptm.failover.upcheckinterval",
+		Usage:    "Interval (seconds) between health checks of the private transaction manager endpoints when failover urls are configured",
+		Value:    http2.DefaultConfig.UpcheckInterval,
+		Category: flags.GoQuorumOptionCategory,
+	}
+	QuorumPTMRetryMaxAttemptsFlag = &cli.UintFlag{
+		Name:     "ptm.retry.attempts",
+		Usage:    "Maximum number of attempts of a call to the private transaction manager, including the first one (calls are not retried when failover URLs are configured)",
+		Value:    http2.DefaultConfig.RetryMaxAttempts,
+		Category: flags.GoQuorumOptionCategory,
+	}
+	QuorumPTMRetryInitialBackoffFlag = &cli.UintFlag{
+		Name:     "ptm.retry.backoff",
+		Usage:    "Wait (milliseconds) before retrying a failed call to the private transaction manager, doubled for each further retry",
+		Value:    http2.DefaultConfig.RetryInitialBackoff,
+		Category: flags.GoQuorumOptionCategory,
+	}
+	QuorumPTMRetryMaxBackoffFlag = &cli.UintFlag{
+		Name:     "ptm.retry.maxbackoff",
+		Usage:    "Maximum wait (milliseconds) between retries of a call to the private transaction manager",
+		Value:    http2.DefaultConfig.RetryMaxBackoff,
+		Category: flags.GoQuorumOptionCategory,
+	}
+	QuorumPTMRetryStatusCodesFlag = &cli.StringFlag{
+		Name:     "ptm.retry.statuscodes",
+		Usage:    "Comma separated list of HTTP status codes returned by the private transaction manager which are retried",
+		Value:    "500,502,503,504",
+		Category: flags.GoQuorumOptionCategory,
+	}
+	QuorumPTMCircuitBreakerThresholdFlag = &cli.UintFlag{
+		Name:     "ptm.circuitbreaker.threshold",
+		Usage:    "Number of consecutive failed calls to the private transaction manager after which calls fail fast (0 = disabled)",
+		Category: flags.GoQuorumOptionCategory,
+	}
+	QuorumPTMCircuitBreakerCooldownFlag = &cli.UintFlag{
+		Name:     "ptm.circuitbreaker.cooldown",
+		Usage:    "Time (seconds) calls fail fast before the private transaction manager is tried again",
+		Value:    http2.DefaultConfig.CircuitBreakerCooldown,
+		Category: flags.GoQuorumOptionCategory,
+	}
+	QuorumPTMCacheSizeFlag = &cli.IntFlag{
+		Name:     "ptm.cache.size",
+		Usage:    "Maximum number of private payloads retrieved from the private transaction manager to keep in memory",
+		Value:    http2.DefaultConfig.CacheSize,
+		Category: flags.GoQuorumOptionCategory,
+	}
+	QuorumPTMCacheExpirationFlag = &cli.UintFlag{
+		Name:     "ptm.cache.expiration",
+		Usage:    "Time (seconds) after which a private payload retrieved from the private transaction manager is dropped from memory",
+		Value:    http2.DefaultConfig.CacheExpiration,
+		Category: flags.GoQuorumOptionCategory,
+	}
+	QuorumPTMCacheDirFlag = &cli.StringFlag{
+		Name:     "ptm.cache.dir",
+		Usage:    "Directory of the database keeping private payloads retrieved from the private transaction manager across restarts, unencrypted (disabled if not set)",
+		Category: flags.GoQuorumOptionCategory,
+	}
+	QuorumPTMCacheDiskExpirationFlag = &cli.UintFlag{
+		Name:     "ptm.cache.disk.expiration",
+		Usage:    "Time (seconds) after which a private payload is dropped from the database of --ptm.cache.dir",
+		Value:    http2.DefaultConfig.CacheDiskExpiration,
+		Category: flags.GoQuorumOptionCategory,
+	}
+	QuorumPTMEmbeddedKeysFlag = &cli.StringFlag{
+		Name:     "ptm.embedded.keys",
+		Usage:    "JSON file of the keys of an embedded in-process private transaction manager, used instead of --ptm.socket or --ptm.url (development only)",
+		Category: flags.GoQuorumOptionCategory,
+	}
+	QuorumPTMEmbeddedDirFlag = &flags.DirectoryFlag{
+		Name:     "ptm.embedded.dir",
+		Usage:    "Directory of the database holding the private payloads of the embedded private transaction manager (in memory if not set)",
+		Category: flags.GoQuorumOptionCategory,
+	}
+	QuorumPTMEmbeddedListenFlag = &cli.StringFlag{
+		Name:     "ptm.embedded.listen",
+		Usage:    "Loopback address (e.g. 127.0.0.1:9101) on which the embedded private transaction manager accepts private payloads from the ones of other nodes",
+		Category: flags.GoQuorumOptionCategory,
+	}
+	QuorumPTMEmbeddedPeersFlag = &cli.StringFlag{
+		Name:     "ptm.embedded.peers",
+		Usage:    "Comma separated list of URLs of the embedded private transaction managers of other nodes on the same host",
+		Category: flags.GoQuorumOptionCategory,
+	}
 	QuorumLightServerFlag = &cli.BoolFlag{
 		Name:     "qlight.server",
 		Usage:    "If enabled, the quorum light P2P protocol is started in addition to the other P2P protocols",
@@ -1911,6 +2023,22 @@ func setRaft(ctx *cli.Context, cfg *eth.Config) {
 
 func setQuorumConfig(ctx *cli.Context, cfg *eth.Config) error {
 	cfg.EVMCallTimeOut = time.Duration(ctx.Int(EVMCallTimeOutFlag.Name)) * time.Second
+	cfg.PrivateStatesRefresh = ctx.Duration(PrivateStatesRefreshFlag.Name)
+	if ctx.IsSet(QuorumPrivacyMarkerBatchAccount.Name) {
+		account := ctx.String(QuorumPrivacyMarkerBatchAccount.Name)
+		if !common.IsHexAddress(account) {
+			return fmt.Errorf("invalid privacy marker batch account %q", account)
+		}
+		cfg.PrivacyMarkerBatchAccount = common.HexToAddress(account)
+		cfg.PrivacyMarkerBatchSize = ctx.Int(QuorumPrivacyMarkerBatchSize.Name)
+		if cfg.PrivacyMarkerBatchSize < 1 {
+			return fmt.Errorf("invalid privacy marker batch size %d", cfg.PrivacyMarkerBatchSize)
+		}
+		cfg.PrivacyMarkerBatchInterval = ctx.Duration(QuorumPrivacyMarkerBatchInterval.Name)
+		if cfg.PrivacyMarkerBatchInterval <= 0 {
+			return fmt.Errorf("invalid privacy marker batch interval %v", cfg.PrivacyMarkerBatchInterval)
+		}
+	}
 	cfg.QuorumChainConfig = core.NewQuorumChainConfig(ctx.Bool(MultitenancyFlag.Name),
 		ctx.Bool(RevertReasonFlag.Name), ctx.Bool(QuorumEnablePrivacyMarker.Name),
 		ctx.Bool(QuorumEnablePrivateTrieCache.Name))
@@ -41,5 +41,37 @@ func CreateClient(cfg Config) (*engine.Client, error) {
 		}
 	}
 
+	client.Retry = retryPolicy(cfg)
+	if cfg.CircuitBreakerThreshold > 0 {
+		cooldown := cfg.CircuitBreakerCooldown
+		if cooldown == 0 {
+			cooldown = DefaultConfig.CircuitBreakerCooldown
+		}
+		client.Breaker = engine.NewCircuitBreaker(int(cfg.CircuitBreakerThreshold), time.Duration(cooldown)*time.Second)
+	}
+
 	return client, nil
 }
+
+// retryPolicy returns the retry policy of the config, using the default for any setting which is not configured
+func retryPolicy(cfg Config) *engine.RetryPolicy {
+	policy := &engine.RetryPolicy{
+		MaxAttempts:          int(cfg.RetryMaxAttempts),
+		InitialBackoff:       time.Duration(cfg.RetryInitialBackoff) * time.Millisecond,
+		MaxBackoff:           time.Duration(cfg.RetryMaxBackoff) * time.Millisecond,
+		RetryableStatusCodes: cfg.RetryStatusCodes,
+	}
+	if policy.MaxAttempts == 0 {
+		policy.MaxAttempts = int(DefaultConfig.RetryMaxAttempts)
+	}
+	if policy.InitialBackoff == 0 {
+		policy.InitialBackoff = time.Duration(DefaultConfig.RetryInitialBackoff) * time.Millisecond
+	}
+	if policy.MaxBackoff == 0 {
+		policy.MaxBackoff = time.Duration(DefaultConfig.RetryMaxBackoff) * time.Millisecond
+	}
+	if len(policy.RetryableStatusCodes) == 0 {
+		policy.RetryableStatusCodes = DefaultRetryStatusCodes
+	}
+	return policy
+}
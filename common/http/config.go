@@ -2,6 +2,7 @@ package http
 
 import (
 	"fmt"
+	"net/http"
 	"os"
 	"path/filepath"
 	"strings"
@@ -13,6 +14,7 @@ const (
 	NoConnection               string = "none"
 	UnixDomainSocketConnection string = "unix"
 	HttpConnection             string = "http"
+	EmbeddedConnection         string = "embedded"
 )
 
 const (
@@ -35,18 +37,50 @@ type Config struct {
 	TlsClientCert         string // path to file containing client certificate (or chain of certs)
 	TlsClientKey          string // path to file containing client's private key
 	TlsInsecureSkipVerify bool   // if true then does not verify that server certificate is CA signed
+
+	HttpFailoverUrls []string // additional transaction manager URLs, sharing the same keys as HttpUrl, to fail over to
+	UpcheckInterval  uint     // interval between /upcheck probes of the transaction manager endpoints (seconds), only used with HttpFailoverUrls
+
+	RetryMaxAttempts        uint  // maximum number of attempts of a call to the transaction manager, including the first one, zero means default, ignored with HttpFailoverUrls
+	RetryInitialBackoff     uint  // wait before retrying a failed call (milliseconds), doubled for each further retry, zero means default
+	RetryMaxBackoff         uint  // upper bound of the wait between retries (milliseconds), zero means default
+	RetryStatusCodes        []int // response status codes of the transaction manager which are retried, empty means default
+	CircuitBreakerThreshold uint  // consecutive failed calls after which calls fail fast, zero means the circuit breaker is disabled
+	CircuitBreakerCooldown  uint  // time calls fail fast before the transaction manager is tried again (seconds), zero means default
+
+	CacheSize       int    // maximum number of retrieved payloads held in memory
+	CacheExpiration uint   // time after which a retrieved payload is dropped from memory (seconds)
+	CacheDir        string // directory of the database holding retrieved payloads across restarts, disabled if empty
+
+	CacheDiskExpiration uint // time after which a retrieved payload is dropped from the database of CacheDir (seconds)
+
+	EmbeddedKeys   string   // JSON file of the keys of an embedded in-process transaction manager, used instead of Socket or HttpUrl
+	EmbeddedDir    string   // directory of the database holding the payloads of the embedded transaction manager, in memory if empty
+	EmbeddedListen string   // loopback address on which the embedded transaction manager accepts payloads from the ones of other nodes
+	EmbeddedPeers  []string // URLs of the embedded transaction managers of other nodes on the same host
 }
 
+// DefaultRetryStatusCodes are retried if RetryStatusCodes is not configured
+var DefaultRetryStatusCodes = []int{http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout}
+
 var NoConnectionConfig = Config{
 	ConnectionType: NoConnection,
 	TlsMode:        TlsOff,
 }
 
 var DefaultConfig = Config{
-	Timeout:             5,
-	DialTimeout:         1,
-	HttpIdleConnTimeout: 10,
-	TlsMode:             TlsOff,
+	Timeout:                5,
+	DialTimeout:            1,
+	HttpIdleConnTimeout:    10,
+	TlsMode:                TlsOff,
+	UpcheckInterval:        5,
+	RetryMaxAttempts:       5,
+	RetryInitialBackoff:    500,
+	RetryMaxBackoff:        5000,
+	CircuitBreakerCooldown: 5,
+	CacheSize:              10000,
+	CacheExpiration:        300,
+	CacheDiskExpiration:    604800,
 }
 
 func IsSocketConfigured(cfg Config) bool {
@@ -101,14 +135,21 @@ func LoadConfigFile(path string) (Config, error) {
 		cfg.ConnectionType = UnixDomainSocketConnection
 	} else if cfg.HttpUrl != "" {
 		cfg.ConnectionType = HttpConnection
+	} else if cfg.EmbeddedKeys != "" {
+		cfg.ConnectionType = EmbeddedConnection
 	} else {
-		return Config{}, fmt.Errorf("either Socket or HTTP connection must be specified in config file")
+		return Config{}, fmt.Errorf("either Socket or HTTP connection must be specified in config file, or EmbeddedKeys for an embedded private transaction manager")
 	}
 
 	return cfg, nil
 }
 
 func (cfg *Config) Validate() error {
+	for _, statusCode := range cfg.RetryStatusCodes {
+		if statusCode < 100 || statusCode > 599 {
+			return fmt.Errorf("invalid retry status code %d for private transaction manager connection", statusCode)
+		}
+	}
 	switch cfg.ConnectionType {
 	case "": // no connection type defined
 	case NoConnection:
@@ -116,9 +157,12 @@ func (cfg *Config) Validate() error {
 		if len(cfg.Socket) == 0 { //sanity check - should never occur
 			return fmt.Errorf("ipc file configuration is missing for private transaction manager connection")
 		}
-		if len(cfg.HttpUrl) != 0 {
+		if len(cfg.HttpUrl) != 0 || len(cfg.HttpFailoverUrls) != 0 {
 			return fmt.Errorf("HTTP URL and unix ipc file cannot both be specified for private transaction manager connection")
 		}
+		if len(cfg.EmbeddedKeys) != 0 {
+			return fmt.Errorf("embedded keys and unix ipc file cannot both be specified for private transaction manager connection")
+		}
 		if cfg.TlsMode != TlsOff {
 			return fmt.Errorf("TLS is not supported over unix domain socket for private transaction manager connection")
 		}
@@ -129,6 +173,9 @@ func (cfg *Config) Validate() error {
 		if len(cfg.HttpUrl) == 0 { //sanity check - should never occur
 			return fmt.Errorf("URL configuration is missing for private transaction manager HTTP connection")
 		}
+		if len(cfg.EmbeddedKeys) != 0 {
+			return fmt.Errorf("embedded keys and HTTP URL cannot both be specified for private transaction manager connection")
+		}
 		switch cfg.TlsMode {
 		case TlsOff:
 			//no action needed
@@ -136,12 +183,24 @@ func (cfg *Config) Validate() error {
 			if !strings.Contains(strings.ToLower(cfg.HttpUrl), "https") {
 				return fmt.Errorf("connection is configured with TLS but HTTPS url is not specified")
 			}
+			for _, failoverUrl := range cfg.HttpFailoverUrls {
+				if !strings.Contains(strings.ToLower(failoverUrl), "https") {
+					return fmt.Errorf("connection is configured with TLS but HTTPS failover url is not specified")
+				}
+			}
 			if (len(cfg.TlsClientCert) == 0 && len(cfg.TlsClientKey) != 0) || (len(cfg.TlsClientCert) != 0 && len(cfg.TlsClientKey) == 0) {
 				return fmt.Errorf("invalid details for HTTP connection with TLS, configuration must specify both clientCert and clientKey, or neither one")
 			}
 		default:
 			return fmt.Errorf("invalid value for TLS mode in config file, must be either OFF or STRICT")
 		}
+	case EmbeddedConnection:
+		if len(cfg.EmbeddedKeys) == 0 { //sanity check - should never occur
+			return fmt.Errorf("keys configuration is missing for embedded private transaction manager")
+		}
+		if len(cfg.Socket) != 0 || len(cfg.HttpUrl) != 0 || len(cfg.HttpFailoverUrls) != 0 {
+			return fmt.Errorf("embedded keys cannot be specified along with the unix ipc file or HTTP URL of a private transaction manager")
+		}
 	}
 
 	return nil
@@ -204,3 +263,68 @@ func (cfg *Config) SetTlsClientKey(tlsClientKey string) {
 func (cfg *Config) SetTlsInsecureSkipVerify(tlsInsecureSkipVerify bool) {
 	cfg.TlsInsecureSkipVerify = tlsInsecureSkipVerify
 }
+
+func (cfg *Config) SetHttpFailoverUrls(httpFailoverUrls []string) {
+	cfg.HttpFailoverUrls = httpFailoverUrls
+}
+
+func (cfg *Config) SetUpcheckInterval(upcheckInterval uint) {
+	cfg.UpcheckInterval = upcheckInterval
+}
+
+func (cfg *Config) SetRetryMaxAttempts(retryMaxAttempts uint) {
+	cfg.RetryMaxAttempts = retryMaxAttempts
+}
+
+func (cfg *Config) SetRetryInitialBackoff(retryInitialBackoff uint) {
+	cfg.RetryInitialBackoff = retryInitialBackoff
+}
+
+func (cfg *Config) SetRetryMaxBackoff(retryMaxBackoff uint) {
+	cfg.RetryMaxBackoff = retryMaxBackoff
+}
+
+func (cfg *Config) SetRetryStatusCodes(retryStatusCodes []int) {
+	cfg.RetryStatusCodes = retryStatusCodes
+}
+
+func (cfg *Config) SetCircuitBreakerThreshold(circuitBreakerThreshold uint) {
+	cfg.CircuitBreakerThreshold = circuitBreakerThreshold
+}
+
+func (cfg *Config) SetCircuitBreakerCooldown(circuitBreakerCooldown uint) {
+	cfg.CircuitBreakerCooldown = circuitBreakerCooldown
+}
+
+func (cfg *Config) SetCacheSize(cacheSize int) {
+	cfg.CacheSize = cacheSize
+}
+
+func (cfg *Config) SetCacheExpiration(cacheExpiration uint) {
+	cfg.CacheExpiration = cacheExpiration
+}
+
+func (cfg *Config) SetCacheDir(cacheDir string) {
+	cfg.CacheDir = cacheDir
+}
+
+func (cfg *Config) SetCacheDiskExpiration(cacheDiskExpiration uint) {
+	cfg.CacheDiskExpiration = cacheDiskExpiration
+}
+
+func (cfg *Config) SetEmbeddedKeys(embeddedKeys string) {
+	cfg.ConnectionType = EmbeddedConnection
+	cfg.EmbeddedKeys = embeddedKeys
+}
+
+func (cfg *Config) SetEmbeddedDir(embeddedDir string) {
+	cfg.EmbeddedDir = embeddedDir
+}
+
+func (cfg *Config) SetEmbeddedListen(embeddedListen string) {
+	cfg.EmbeddedListen = embeddedListen
+}
+
+func (cfg *Config) SetEmbeddedPeers(embeddedPeers []string) {
+	cfg.EmbeddedPeers = embeddedPeers
+}
@@ -82,6 +82,29 @@ tlsRootCA = "mydir/rootca.cert.pem"
 tlsClientCert = "mydir/client.cert.pem"
 tlsClientKey = "mydir/client.key.pem"
 `
+var httpConfigFileWithFailoverUrls = `
+httpUrl = "http://localhost:9101"
+httpFailoverUrls = ["http://localhost:9201", "http://localhost:9301"]
+upcheckInterval = 3
+`
+var httpConfigFileWithRetryPolicy = `
+httpUrl = "http://localhost:9101"
+retryMaxAttempts = 3
+retryInitialBackoff = 100
+retryMaxBackoff = 1000
+retryStatusCodes = [503]
+circuitBreakerThreshold = 4
+circuitBreakerCooldown = 30
+`
+var httpConfigFileWithInvalidRetryStatusCode = `
+httpUrl = "http://localhost:9101"
+retryStatusCodes = [5030]
+`
+var httpTlsConfigFileWithHTTPFailoverUrl = `
+httpUrl = "https://localhost:9101"
+httpFailoverUrls = ["http://localhost:9201"]
+tlsMode = "strict"
+`
 var invalidConfigWithSocketAndHttp = `
 socket = "tm.ipc"
 workdir = "qdata/c1"
@@ -89,6 +112,15 @@ httpUrl = "http:localhost:9101"
 `
 var invalidConfigWithNoSocketOrHttp = `
 `
+var embeddedConfigFile = `
+embeddedKeys = "qdata/c1/keys.json"
+embeddedListen = "127.0.0.1:9111"
+embeddedPeers = ["http://127.0.0.1:9112"]
+`
+var invalidConfigWithEmbeddedAndHttp = `
+httpUrl = "http://localhost:9101"
+embeddedKeys = "qdata/c1/keys.json"
+`
 
 func TestDefaultTimeoutsUsedWhenNoConfigFileSpecified(t *testing.T) {
 	if runtime.GOOS == "windows" {
@@ -311,6 +343,77 @@ func TestTlsWithHTTPUrlOnly(t *testing.T) {
 	}
 }
 
+func TestLoadHttpConfigWithFailoverUrls(t *testing.T) {
+	configFile := filepath.Join(os.TempDir(), "httpConfigFileWithFailoverUrls.toml")
+	if err := os.WriteFile(configFile, []byte(httpConfigFileWithFailoverUrls), 0600); err != nil {
+		t.Fatalf("Failed to create config file for unit test, error: %v", err)
+	}
+	defer os.Remove(configFile)
+
+	cfg, err := FetchConfig(configFile)
+	if assert.NoError(t, err, "Failed to load config file") {
+		assert.Equal(t, "http://localhost:9101", cfg.HttpUrl, "Did not get expected http url from config file")
+		assert.Equal(t, []string{"http://localhost:9201", "http://localhost:9301"}, cfg.HttpFailoverUrls, "Did not get expected http failover urls from config file")
+		assert.Equal(t, uint(3), cfg.UpcheckInterval, "Did not get expected UpcheckInterval from config file")
+	}
+
+	err = cfg.Validate()
+	assert.NoError(t, err)
+}
+
+func TestLoadHttpConfigWithRetryPolicy(t *testing.T) {
+	configFile := filepath.Join(os.TempDir(), "httpConfigFileWithRetryPolicy.toml")
+	if err := os.WriteFile(configFile, []byte(httpConfigFileWithRetryPolicy), 0600); err != nil {
+		t.Fatalf("Failed to create config file for unit test, error: %v", err)
+	}
+	defer os.Remove(configFile)
+
+	cfg, err := FetchConfig(configFile)
+	if assert.NoError(t, err, "Failed to load config file") {
+		assert.Equal(t, uint(3), cfg.RetryMaxAttempts, "Did not get expected RetryMaxAttempts from config file")
+		assert.Equal(t, uint(100), cfg.RetryInitialBackoff, "Did not get expected RetryInitialBackoff from config file")
+		assert.Equal(t, uint(1000), cfg.RetryMaxBackoff, "Did not get expected RetryMaxBackoff from config file")
+		assert.Equal(t, []int{503}, cfg.RetryStatusCodes, "Did not get expected RetryStatusCodes from config file")
+		assert.Equal(t, uint(4), cfg.CircuitBreakerThreshold, "Did not get expected CircuitBreakerThreshold from config file")
+		assert.Equal(t, uint(30), cfg.CircuitBreakerCooldown, "Did not get expected CircuitBreakerCooldown from config file")
+	}
+
+	err = cfg.Validate()
+	assert.NoError(t, err)
+}
+
+func TestInvalidRetryStatusCode(t *testing.T) {
+	configFile := filepath.Join(os.TempDir(), "httpConfigFileWithInvalidRetryStatusCode.toml")
+	if err := os.WriteFile(configFile, []byte(httpConfigFileWithInvalidRetryStatusCode), 0600); err != nil {
+		t.Fatalf("Failed to create config file for unit test, error: %v", err)
+	}
+	defer os.Remove(configFile)
+
+	cfg, err := FetchConfig(configFile)
+	assert.NoError(t, err)
+
+	err = cfg.Validate()
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "invalid retry status code 5030")
+	}
+}
+
+func TestTlsWithHTTPFailoverUrl(t *testing.T) {
+	configFile := filepath.Join(os.TempDir(), "httpTlsConfigFileWithHTTPFailoverUrl.toml")
+	if err := os.WriteFile(configFile, []byte(httpTlsConfigFileWithHTTPFailoverUrl), 0600); err != nil {
+		t.Fatalf("Failed to create config file for unit test, error: %v", err)
+	}
+	defer os.Remove(configFile)
+
+	cfg, err := FetchConfig(configFile)
+	assert.NoError(t, err)
+
+	err = cfg.Validate()
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "connection is configured with TLS but HTTPS failover url is not specified")
+	}
+}
+
 func TestSocketWithHTTPNotAllowed(t *testing.T) {
 	configFile := filepath.Join(os.TempDir(), "invalidConfigWithSocketAndHttp.toml")
 	if err := os.WriteFile(configFile, []byte(invalidConfigWithSocketAndHttp), 0600); err != nil {
@@ -339,3 +442,38 @@ func TestEitherSocketOrHTTPMustBeSpecified(t *testing.T) {
 		assert.Contains(t, err.Error(), "either Socket or HTTP connection must be specified in config file")
 	}
 }
+
+func TestLoadEmbeddedConfig(t *testing.T) {
+	configFile := filepath.Join(os.TempDir(), "embeddedConfigFile.toml")
+	if err := os.WriteFile(configFile, []byte(embeddedConfigFile), 0600); err != nil {
+		t.Fatalf("Failed to create config file for unit test, error: %v", err)
+	}
+	defer os.Remove(configFile)
+
+	cfg, err := FetchConfig(configFile)
+	if assert.NoError(t, err, "Failed to load config file") {
+		assert.Equal(t, EmbeddedConnection, cfg.ConnectionType, "Did not get expected connection type from config file")
+		assert.Equal(t, "qdata/c1/keys.json", cfg.EmbeddedKeys, "Did not get expected EmbeddedKeys from config file")
+		assert.Equal(t, "127.0.0.1:9111", cfg.EmbeddedListen, "Did not get expected EmbeddedListen from config file")
+		assert.Equal(t, []string{"http://127.0.0.1:9112"}, cfg.EmbeddedPeers, "Did not get expected EmbeddedPeers from config file")
+	}
+
+	err = cfg.Validate()
+	assert.NoError(t, err)
+}
+
+func TestEmbeddedWithHTTPNotAllowed(t *testing.T) {
+	configFile := filepath.Join(os.TempDir(), "invalidConfigWithEmbeddedAndHttp.toml")
+	if err := os.WriteFile(configFile, []byte(invalidConfigWithEmbeddedAndHttp), 0600); err != nil {
+		t.Fatalf("Failed to create config file for unit test, error: %v", err)
+	}
+	defer os.Remove(configFile)
+
+	cfg, err := FetchConfig(configFile)
+	assert.NoError(t, err)
+
+	err = cfg.Validate()
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "embedded keys and HTTP URL cannot both be specified for private transaction manager connection")
+	}
+}
@@ -219,7 +219,10 @@ type BlockChain struct {
 
 	// privateStateManager manages private state(s) for this blockchain
 	privateStateManager           mps.PrivateStateManager
+	privateSnaps                  *mps.PrivateStateSnapshots // Snapshot trees of the private states, nil if snapshots are disabled
 	privateStateRootHashValidator qlight.PrivateStateRootHashValidator
+	// privatePayloadPrefetcher retrieves the private payloads of a block ahead of processing
+	privatePayloadPrefetcher *privatePayloadPrefetcher
 	// End Quorum
 }
 
@@ -266,12 +269,16 @@ func NewBlockChain(db ethdb.Database, cacheConfig *CacheConfig, chainConfig *par
 	bc.validator = NewBlockValidator(chainConfig, bc, engine)
 	bc.prefetcher = newStatePrefetcher(chainConfig, bc, engine)
 	bc.processor = NewStateProcessor(chainConfig, bc, engine)
+	bc.privatePayloadPrefetcher = newPrivatePayloadPrefetcher(defaultPrivatePayloadPrefetchParallelism) // Quorum
 
 	var err error
 	privateStateCacheProvider := privatecache.NewPrivateCacheProvider(db, &trie.Config{Cache: cacheConfig.TrieCleanLimit,
 		Preimages: cacheConfig.Preimages}, bc.stateCache, quorumChainConfig.privateTrieCacheEnabled)
+	if cacheConfig.SnapshotLimit > 0 {
+		bc.privateSnaps = mps.NewPrivateStateSnapshots(db, privateStateCacheProvider.GetCacheWithConfig().TrieDB(), cacheConfig.SnapshotLimit, !cacheConfig.SnapshotWait)
+	}
 	// Quorum: attempt to initialize PSM
-	if bc.privateStateManager, err = newPrivateStateManager(bc.db, privateStateCacheProvider, chainConfig.IsMPS); err != nil {
+	if bc.privateStateManager, err = newPrivateStateManagerWithSnapshots(bc.db, privateStateCacheProvider, bc.privateSnaps, chainConfig.IsMPS); err != nil {
 		return nil, err
 	}
 	bc.hc, err = NewHeaderChain(db, chainConfig, engine, bc.insertStopped)
@@ -406,6 +413,8 @@ func NewBlockChain(db ethdb.Database, cacheConfig *CacheConfig, chainConfig *par
 		if err != nil {
 			log.Error("Error trying to load snapshot", "err", err)
 		}
+		// Quorum
+		bc.loadPrivateSnapshots(head)
 	}
 	// Take ownership of this particular state
 	go bc.update()
@@ -1152,6 +1161,8 @@ func (bc *BlockChain) Stop() {
 			log.Error("Failed to journal state snapshot", "err", err)
 		}
 	}
+	// Quorum
+	bc.journalPrivateSnapshots(bc.CurrentBlock())
 	// Ensure the state of a recent block is also stored to disk before exiting.
 	// We're writing three different states to catch different restart scenarios:
 	//  - HEAD:     So we don't need to reprocess any blocks in the general case
@@ -1606,6 +1617,10 @@ func (bc *BlockChain) writeBlockWithState(block *types.Block, receipts []*types.
 	rawdb.WriteBlock(blockBatch, block)
 	rawdb.WriteReceipts(blockBatch, block.Hash(), block.NumberU64(), receipts)
 	rawdb.WritePreimages(blockBatch, state.Preimages())
+	// Quorum
+	indexPrivateContracts(blockBatch, block, receipts, psManager)
+	indexPrivacyMarkerBatches(blockBatch, block, receipts)
+	// End Quorum
 	if err := blockBatch.Write(); err != nil {
 		log.Crit("Failed to write block into disk", "err", err)
 	}
@@ -2029,6 +2044,7 @@ func (bc *BlockChain) insertChain(chain types.Blocks) (int, error) {
 
 					// Quorum: add privateStateThrowaway argument
 					go func(start time.Time, followup *types.Block, throwaway *state.StateDB, privateStateThrowaway mps.PrivateStateRepository, interrupt *uint32) {
+						bc.privatePayloadPrefetcher.Prefetch(followup, interrupt)
 						bc.prefetcher.Prefetch(followup, throwaway, throwawayPrivateStateRepo, bc.vmConfig, &followupInterrupt)
 
 						blockPrefetchExecuteTimer.Update(time.Since(start))
@@ -2042,10 +2058,17 @@ func (bc *BlockChain) insertChain(chain types.Blocks) (int, error) {
 				// End Quorum
 			}
 		}
+		// Quorum: retrieve the private payloads of the block concurrently while it is processed,
+		// so that processing is mostly served from the private transaction manager's cache. This
+		// is cheap when the payloads were already retrieved while this block was the followup block.
+		var privatePayloadInterrupt uint32
+		go bc.privatePayloadPrefetcher.Prefetch(block, &privatePayloadInterrupt)
+
 		// Process block using the parent state as reference point
 		substart := time.Now()
 
 		receipts, privateReceipts, logs, usedGas, err := bc.processor.Process(block, statedb, privateStateRepo, bc.vmConfig)
+		atomic.StoreUint32(&privatePayloadInterrupt, 1) // Quorum
 		if err != nil {
 			bc.reportBlock(block, receipts, err)
 			atomic.StoreUint32(&followupInterrupt, 1)
@@ -19,6 +19,7 @@ type DefaultPrivateStateManager struct {
 	db                   ethdb.Database
 	repoCache            state.Database
 	privateCacheProvider privatecache.Provider
+	snapshots            *mps.PrivateStateSnapshots // nil if snapshots are disabled
 }
 
 func newDefaultPrivateStateManager(db ethdb.Database, privateCacheProvider privatecache.Provider) *DefaultPrivateStateManager {
@@ -30,7 +31,13 @@ func newDefaultPrivateStateManager(db ethdb.Database, privateCacheProvider priva
 }
 
 func (d *DefaultPrivateStateManager) StateRepository(blockHash common.Hash) (mps.PrivateStateRepository, error) {
-	return mps.NewDefaultPrivateStateRepository(d.db, d.repoCache, d.privateCacheProvider, blockHash)
+	return d.stateRepository(blockHash, d.snapshots)
+}
+
+// stateRepository returns the repository with the private state read from, and maintaining, its
+// snapshot tree of the given snapshots, if any
+func (d *DefaultPrivateStateManager) stateRepository(blockHash common.Hash, snapshots *mps.PrivateStateSnapshots) (mps.PrivateStateRepository, error) {
+	return mps.NewDefaultPrivateStateRepositoryWithSnapshots(d.db, d.repoCache, d.privateCacheProvider, blockHash, snapshots)
 }
 
 func (d *DefaultPrivateStateManager) ResolveForManagedParty(_ string) (*mps.PrivateStateMetadata, error) {
@@ -2,11 +2,13 @@ package core
 
 import (
 	"context"
+	"math/big"
 	"testing"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/mps"
 	"github.com/ethereum/go-ethereum/core/privatecache"
+	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/core/vm"
@@ -15,6 +17,7 @@ import (
 	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // Tests DefaultState, StatePSI, CommitAndWrite
@@ -106,3 +109,39 @@ func TestDefaultResolver(t *testing.T) {
 
 	assert.Equal(t, mpsm.PSIs(), []types.PrivateStateIdentifier{types.DefaultPrivateStateIdentifier})
 }
+
+func TestPrivateStateRepositoryWithoutSnapshots_leavesSnapshotTree(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	privateCacheProvider := privatecache.NewPrivateCacheProvider(db, nil, nil, false)
+	snapshots := mps.NewPrivateStateSnapshots(db, privateCacheProvider.GetCacheWithConfig().TrieDB(), 16, false)
+	psm, err := newPrivateStateManagerWithSnapshots(db, privateCacheProvider, snapshots, false)
+	require.NoError(t, err)
+	bc := &BlockChain{privateStateManager: psm}
+	addr := common.BytesToAddress([]byte{1})
+	writePrivateState := func(repo mps.PrivateStateRepository, block *types.Block, balance int64) common.Hash {
+		privateState, err := repo.DefaultState()
+		require.NoError(t, err)
+		privateState.SetBalance(addr, big.NewInt(balance))
+		require.NoError(t, repo.CommitAndWrite(false, block))
+		return rawdb.GetPrivateStateRoot(db, block.Root())
+	}
+
+	// two imported blocks, the second one being a diff layer of the tree
+	repo, err := psm.StateRepository(common.Hash{})
+	require.NoError(t, err)
+	block1 := types.NewBlockWithHeader(&types.Header{Root: common.Hash{1}})
+	writePrivateState(repo, block1, 1)
+	repo, err = psm.StateRepository(block1.Root())
+	require.NoError(t, err)
+	root2 := writePrivateState(repo, types.NewBlockWithHeader(&types.Header{Root: common.Hash{2}}), 2)
+	tree := snapshots.Tree(types.DefaultPrivateStateIdentifier)
+	require.NotNil(t, tree.Snapshot(root2))
+
+	// a re-execution on top of the first block does not change the tree
+	repo, err = bc.PrivateStateRepositoryWithoutSnapshots(block1.Root())
+	require.NoError(t, err)
+	reexecutedRoot := writePrivateState(repo, types.NewBlockWithHeader(&types.Header{Root: common.Hash{3}}), 3)
+
+	assert.Nil(t, tree.Snapshot(reexecutedRoot))
+	assert.NotNil(t, tree.Snapshot(root2), "the diff layer of the imported block is kept")
+}
@@ -214,3 +214,113 @@ func TestStaticCall_whenPrivateToPublic(t *testing.T) {
 
 	verifyStaticCall(t, privateState, publicState, common.Hash{10})
 }
+
+var (
+	// a function which stores 42 in the transient storage, loads it back, copies it
+	// in memory and stores it in the storage
+	//000000: PUSH1 0x2a
+	//000002: PUSH0
+	//000003: TSTORE
+	//000004: PUSH0
+	//000005: TLOAD
+	//000006: PUSH0
+	//000007: MSTORE
+	//000008: PUSH1 0x20
+	//000010: PUSH0
+	//000011: PUSH1 0x20
+	//000013: MCOPY
+	//000014: PUSH1 0x20
+	//000016: MLOAD
+	//000017: PUSH0
+	//000018: SSTORE
+	//000019: STOP
+	cancunContractCode = "602a5f5d5f5c5f5260205f60205e6020515f5500"
+	// a function which stores 42 in the transient storage
+	tstoreContractCode = "602a5f5d00"
+)
+
+// cancunTransitionConfig returns a chain config on Berlin enabling Cancun at block 5 through a transition.
+func cancunTransitionConfig() *params.ChainConfig {
+	config := *params.QuorumTestChainConfig
+	config.BerlinBlock = big.NewInt(0)
+	enabled := true
+	config.Transitions = []params.Transition{{Block: big.NewInt(5), CancunEnabled: &enabled}}
+	return &config
+}
+
+func callAtBlock(number int64, publicState, privateState *state.StateDB, to common.Address) error {
+	author := common.Address{}
+	header := dualStateTestHeader
+	header.Number = big.NewInt(number)
+
+	config := cancunTransitionConfig()
+	if err := config.CheckConfigForkOrder(); err != nil {
+		return err
+	}
+	// Set up the access lists as the state transition does for a private transaction
+	rules := config.Rules(header.Number)
+	publicState.PrepareAccessList(author, &to, vm.ActivePrecompiles(rules), nil)
+	if rules.IsShanghai {
+		privateState.PrepareAccessList(author, &to, vm.ActivePrecompiles(rules), nil)
+	}
+
+	ctx := NewEVMBlockContext(&header, nil, &author)
+	txCtx := vm.TxContext{Origin: author, GasPrice: new(big.Int)}
+	env := vm.NewEVM(ctx, txCtx, publicState, privateState, config, vm.Config{})
+	_, _, err := env.Call(vm.AccountRef(author), to, nil, 1000000, new(big.Int))
+	return err
+}
+
+func TestDualStateCancunOpcodes_whenPrivateAndPublic(t *testing.T) {
+	for _, private := range []bool{false, true} {
+		for _, test := range []struct {
+			number  int64
+			enabled bool
+		}{
+			{4, false},
+			{5, true},
+		} {
+			db := rawdb.NewMemoryDatabase()
+			publicState, _ := state.New(common.Hash{}, state.NewDatabase(db), nil)
+			privateState := publicState
+			if private {
+				privateState, _ = state.New(common.Hash{}, state.NewDatabase(db), nil)
+			}
+			privateState.SetCode(callerAddress, common.Hex2Bytes(cancunContractCode))
+
+			err := callAtBlock(test.number, publicState, privateState, callerAddress)
+			value := privateState.GetState(callerAddress, common.Hash{})
+			if test.enabled {
+				if err != nil {
+					t.Fatalf("private=%v block %d: unexpected error: %v", private, test.number, err)
+				}
+				if value != common.BigToHash(big.NewInt(42)) {
+					t.Errorf("private=%v block %d: expected 42 got %x", private, test.number, value)
+				}
+				continue
+			}
+			if _, ok := err.(*vm.ErrInvalidOpCode); !ok {
+				t.Errorf("private=%v block %d: expected invalid opcode error, got %v", private, test.number, err)
+			}
+			if value != (common.Hash{}) {
+				t.Errorf("private=%v block %d: expected 0 got %x", private, test.number, value)
+			}
+		}
+	}
+}
+
+func TestDualStateCancunOpcodes_whenPrivateToPublicTstore(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	publicState, _ := state.New(common.Hash{}, state.NewDatabase(db), nil)
+	publicState.SetCode(calleeAddress, common.Hex2Bytes(tstoreContractCode))
+
+	privateState, _ := state.New(common.Hash{}, state.NewDatabase(db), nil)
+
+	// The public state is read-only for a private transaction, including its transient storage
+	if err := callAtBlock(5, publicState, privateState, calleeAddress); err == nil {
+		t.Fatal("expected an error writing the transient storage of a public contract")
+	}
+	if value := publicState.GetTransientState(calleeAddress, common.Hash{}); value != (common.Hash{}) {
+		t.Errorf("expected 0 got %x", value)
+	}
+}
@@ -19,15 +19,22 @@ type DefaultPrivateStateRepository struct {
 	// cache of stateDB
 	stateCache           state.Database
 	privateCacheProvider privatecache.Provider
+	snapshots            *PrivateStateSnapshots
 	// stateDB gives access to the underlying state
 	stateDB *state.StateDB
 	root    common.Hash
 }
 
 func NewDefaultPrivateStateRepository(db ethdb.Database, cache state.Database, privateCacheProvider privatecache.Provider, previousBlockHash common.Hash) (*DefaultPrivateStateRepository, error) {
+	return NewDefaultPrivateStateRepositoryWithSnapshots(db, cache, privateCacheProvider, previousBlockHash, nil)
+}
+
+// NewDefaultPrivateStateRepositoryWithSnapshots creates the repository with the private state read
+// from, and maintaining, its snapshot tree of the given snapshots
+func NewDefaultPrivateStateRepositoryWithSnapshots(db ethdb.Database, cache state.Database, privateCacheProvider privatecache.Provider, previousBlockHash common.Hash, snapshots *PrivateStateSnapshots) (*DefaultPrivateStateRepository, error) {
 	root := rawdb.GetPrivateStateRoot(db, previousBlockHash)
 
-	statedb, err := state.New(root, cache, nil)
+	statedb, err := state.New(root, cache, snapshots.Tree(types.DefaultPrivateStateIdentifier))
 	if err != nil {
 		return nil, err
 	}
@@ -36,6 +43,7 @@ func NewDefaultPrivateStateRepository(db ethdb.Database, cache state.Database, p
 		db:                   db,
 		stateCache:           cache,
 		privateCacheProvider: privateCacheProvider,
+		snapshots:            snapshots,
 		stateDB:              statedb,
 		root:                 root,
 	}, nil
@@ -84,6 +92,7 @@ func (dpsr *DefaultPrivateStateRepository) CommitAndWrite(isEIP158 bool, block *
 	}
 	dpsr.privateCacheProvider.Commit(dpsr.stateCache, privateRoot)
 	dpsr.privateCacheProvider.Reference(privateRoot, block.Root())
+	dpsr.snapshots.Committed(types.DefaultPrivateStateIdentifier, dpsr.root, privateRoot)
 	return nil
 }
 
@@ -99,6 +108,7 @@ func (dpsr *DefaultPrivateStateRepository) Copy() PrivateStateRepository {
 		db:                   dpsr.db,
 		stateCache:           dpsr.stateCache,
 		privateCacheProvider: dpsr.privateCacheProvider,
+		snapshots:            dpsr.snapshots,
 		stateDB:              dpsr.stateDB.Copy(),
 		root:                 dpsr.root,
 	}
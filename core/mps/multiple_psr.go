@@ -26,6 +26,7 @@ type MultiplePrivateStateRepository struct {
 	// trie of private states cache
 	repoCache            state.Database
 	privateCacheProvider privatecache.Provider
+	snapshots            *PrivateStateSnapshots
 
 	// the trie of private states
 	// key - the private state identifier
@@ -39,6 +40,12 @@ type MultiplePrivateStateRepository struct {
 }
 
 func NewMultiplePrivateStateRepository(db ethdb.Database, cache state.Database, privateStatesTrieRoot common.Hash, privateCacheProvider privatecache.Provider) (*MultiplePrivateStateRepository, error) {
+	return NewMultiplePrivateStateRepositoryWithSnapshots(db, cache, privateStatesTrieRoot, privateCacheProvider, nil)
+}
+
+// NewMultiplePrivateStateRepositoryWithSnapshots creates the repository with each private state read
+// from, and maintaining, its snapshot tree of the given snapshots
+func NewMultiplePrivateStateRepositoryWithSnapshots(db ethdb.Database, cache state.Database, privateStatesTrieRoot common.Hash, privateCacheProvider privatecache.Provider, snapshots *PrivateStateSnapshots) (*MultiplePrivateStateRepository, error) {
 	tr, err := cache.OpenTrie(privateStatesTrieRoot)
 	if err != nil {
 		return nil, err
@@ -47,6 +54,7 @@ func NewMultiplePrivateStateRepository(db ethdb.Database, cache state.Database,
 		db:                   db,
 		repoCache:            cache,
 		privateCacheProvider: privateCacheProvider,
+		snapshots:            snapshots,
 		trie:                 tr,
 		managedStates:        make(map[types.PrivateStateIdentifier]*managedState),
 	}
@@ -56,17 +64,23 @@ func NewMultiplePrivateStateRepository(db ethdb.Database, cache state.Database,
 // A managed state is a pair of stateDb and it's corresponding stateCache objects
 // Although right now we may not need a separate stateCache it may be useful if we'll do multiple managed state commits in parallel
 type managedState struct {
+	psi                   types.PrivateStateIdentifier
+	root                  common.Hash // root of the last commit, or the one the state was opened at
 	stateDb               *state.StateDB
 	stateCache            state.Database
 	privateCacheProvider  privatecache.Provider
+	snapshots             *PrivateStateSnapshots
 	stateRootProviderFunc StateRootProviderFunc
 }
 
 func (ms *managedState) Copy() *managedState {
 	copy := &managedState{
+		psi:                  ms.psi,
+		root:                 ms.root,
 		stateDb:              ms.stateDb.Copy(),
 		stateCache:           ms.stateCache,
 		privateCacheProvider: ms.privateCacheProvider,
+		snapshots:            ms.snapshots,
 	}
 	copy.stateRootProviderFunc = copy.calPrivateStateRoot
 	return copy
@@ -129,10 +143,12 @@ func (mpsr *MultiplePrivateStateRepository) StatePSI(psi types.PrivateStateIdent
 		mpsr.mux.Unlock()
 
 		stateDB = emptyState.Copy()
+		// the branched state gets its own snapshot once committed
+		stateDB.DisableSnapshot()
 		stateCache = ms.stateCache
 	} else {
 		stateCache = mpsr.privateCacheProvider.GetCache()
-		stateDB, err = state.New(common.BytesToHash(privateStateRoot), stateCache, nil)
+		stateDB, err = state.New(common.BytesToHash(privateStateRoot), stateCache, mpsr.snapshots.Tree(psi))
 		if err != nil {
 			return nil, err
 		}
@@ -140,8 +156,11 @@ func (mpsr *MultiplePrivateStateRepository) StatePSI(psi types.PrivateStateIdent
 	mpsr.mux.Lock()
 	defer mpsr.mux.Unlock()
 	managedState := &managedState{
+		psi:                  psi,
+		root:                 common.BytesToHash(privateStateRoot),
 		stateCache:           stateCache,
 		privateCacheProvider: mpsr.privateCacheProvider,
+		snapshots:            mpsr.snapshots,
 		stateDb:              stateDB,
 	}
 	managedState.stateRootProviderFunc = managedState.calPrivateStateRoot
@@ -181,6 +200,9 @@ func (mpsr *MultiplePrivateStateRepository) CommitAndWrite(isEIP158 bool, block
 		if err != nil {
 			return err
 		}
+		// only the private states written with their block maintain their snapshot trees
+		mpsr.snapshots.Committed(psi, managedState.root, privateRoot)
+		managedState.root = privateRoot
 
 		// update the managed state root in the trie of state roots
 		if err := mpsr.trie.TryUpdate([]byte(psi), privateRoot.Bytes()); err != nil {
@@ -248,6 +270,7 @@ func (mpsr *MultiplePrivateStateRepository) Copy() PrivateStateRepository {
 		db:                   mpsr.db,
 		repoCache:            mpsr.repoCache,
 		privateCacheProvider: mpsr.privateCacheProvider,
+		snapshots:            mpsr.snapshots,
 		trie:                 mpsr.repoCache.CopyTrie(mpsr.trie),
 		managedStates:        managedStatesCopy,
 	}
@@ -0,0 +1,146 @@
+package mps
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state/snapshot"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+// PrivateStateSnapshots maintains a snapshot tree per private state, i.e. per PSI if multiple
+// private states are enabled. Each tree is stored in its own namespace of the database, so the
+// private snapshots are maintained, journaled and regenerated independently of the snapshot of
+// the public state and of each other.
+//
+// A nil PrivateStateSnapshots is valid and means that private snapshots are disabled.
+type PrivateStateSnapshots struct {
+	db     ethdb.Database
+	triedb *trie.Database // trie database of the private states, used to generate the snapshots
+	cache  int            // megabytes of memory for the disk layer cache of each tree
+	async  bool           // whether the snapshots are generated in the background
+
+	mux        sync.RWMutex
+	trees      map[types.PrivateStateIdentifier]*snapshot.Tree
+	rebuilding map[types.PrivateStateIdentifier]bool // trees being regenerated at a new root
+}
+
+// NewPrivateStateSnapshots creates the snapshot trees of the private states. No tree is opened
+// until it is loaded or a state is committed for its private state.
+func NewPrivateStateSnapshots(db ethdb.Database, triedb *trie.Database, cache int, async bool) *PrivateStateSnapshots {
+	return &PrivateStateSnapshots{
+		db:         db,
+		triedb:     triedb,
+		cache:      cache,
+		async:      async,
+		trees:      make(map[types.PrivateStateIdentifier]*snapshot.Tree),
+		rebuilding: make(map[types.PrivateStateIdentifier]bool),
+	}
+}
+
+// Load opens the snapshot tree of the private state with the given head root, regenerating it
+// if the journaled snapshot does not match the root
+func (s *PrivateStateSnapshots) Load(psi types.PrivateStateIdentifier, root common.Hash) {
+	if s == nil {
+		return
+	}
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	s.load(psi, root)
+}
+
+func (s *PrivateStateSnapshots) load(psi types.PrivateStateIdentifier, root common.Hash) *snapshot.Tree {
+	if tree, ok := s.trees[psi]; ok {
+		return tree
+	}
+	tree, err := snapshot.New(rawdb.NewPrivateSnapshotTable(s.db, psi), s.triedb, s.cache, root, s.async, true, false)
+	if err != nil {
+		log.Error("Failed to load private state snapshot", "psi", psi, "root", root, "err", err)
+		return nil
+	}
+	s.trees[psi] = tree
+	return tree
+}
+
+// Tree returns the snapshot tree of the private state, nil if it has not been opened
+func (s *PrivateStateSnapshots) Tree(psi types.PrivateStateIdentifier) *snapshot.Tree {
+	if s == nil {
+		return nil
+	}
+	s.mux.RLock()
+	defer s.mux.RUnlock()
+	return s.trees[psi]
+}
+
+// Committed makes sure that the snapshot tree of the private state covers the root committed on
+// top of parent. The commit of a state opened with the tree adds the diff layer of the root to the
+// tree, so nothing is done on block import unless the tree does not cover the parent, e.g. for a new
+// private state, which gets its tree opened, or after a rewind, which regenerates the tree in the
+// background.
+func (s *PrivateStateSnapshots) Committed(psi types.PrivateStateIdentifier, parent, root common.Hash) {
+	if s == nil || parent == root {
+		return
+	}
+	s.mux.RLock()
+	tree := s.trees[psi]
+	rebuilding := s.rebuilding[psi]
+	s.mux.RUnlock()
+	if rebuilding || (tree != nil && tree.Snapshot(root) != nil) {
+		return
+	}
+
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	if tree == nil {
+		s.load(psi, root)
+		return
+	}
+	if s.rebuilding[psi] {
+		return
+	}
+	s.rebuilding[psi] = true
+	go func() {
+		log.Debug("Regenerating private state snapshot", "psi", psi, "root", root)
+		tree.Rebuild(root)
+
+		s.mux.Lock()
+		delete(s.rebuilding, psi)
+		s.mux.Unlock()
+	}()
+}
+
+// Journal writes the snapshot tree of each private state to disk, with the root returned by the
+// given function as the head of the tree, and persists the tries of the disk layers so that the
+// journals can be loaded again.
+func (s *PrivateStateSnapshots) Journal(rootOf func(psi types.PrivateStateIdentifier) (common.Hash, error)) {
+	if s == nil {
+		return
+	}
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	psis := make([]types.PrivateStateIdentifier, 0, len(s.trees))
+	for psi := range s.trees {
+		psis = append(psis, psi)
+	}
+	sort.Slice(psis, func(i, j int) bool { return psis[i] < psis[j] })
+	for _, psi := range psis {
+		root, err := rootOf(psi)
+		if err != nil {
+			log.Error("Failed to journal private state snapshot", "psi", psi, "err", err)
+			continue
+		}
+		base, err := s.trees[psi].Journal(root)
+		if err != nil {
+			log.Error("Failed to journal private state snapshot", "psi", psi, "root", root, "err", err)
+			continue
+		}
+		if err := s.triedb.Commit(base, false, nil); err != nil {
+			log.Error("Failed to commit private state snapshot base", "psi", psi, "root", base, "err", err)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package mps
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/privatecache"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrivateStateSnapshots(t *testing.T) {
+	testdb := rawdb.NewMemoryDatabase()
+	testCache := state.NewDatabase(testdb)
+	privateCacheProvider := privatecache.NewPrivateCacheProvider(testdb, nil, testCache, false)
+	snapshots := NewPrivateStateSnapshots(testdb, testCache.TrieDB(), 16, false)
+	addr := common.BytesToAddress([]byte{1})
+
+	// the snapshot is generated when the private state is first committed
+	psr, err := NewDefaultPrivateStateRepositoryWithSnapshots(testdb, testCache, privateCacheProvider, common.Hash{}, snapshots)
+	require.NoError(t, err)
+	testState, _ := psr.DefaultState()
+	testState.SetBalance(addr, big.NewInt(1))
+	block1 := types.NewBlockWithHeader(&types.Header{Root: common.Hash{1}})
+	require.NoError(t, psr.CommitAndWrite(false, block1))
+	root1 := rawdb.GetPrivateStateRoot(testdb, block1.Root())
+
+	tree := snapshots.Tree(types.DefaultPrivateStateIdentifier)
+	require.NotNil(t, tree)
+	require.NotNil(t, tree.Snapshot(root1))
+	account, err := tree.Snapshot(root1).Account(crypto.Keccak256Hash(addr.Bytes()))
+	require.NoError(t, err)
+	assert.Equal(t, big.NewInt(1), account.Balance)
+
+	// the next commit is added to the snapshot
+	psr, err = NewDefaultPrivateStateRepositoryWithSnapshots(testdb, testCache, privateCacheProvider, block1.Root(), snapshots)
+	require.NoError(t, err)
+	testState, _ = psr.DefaultState()
+	testState.SetBalance(addr, big.NewInt(2))
+	block2 := types.NewBlockWithHeader(&types.Header{Root: common.Hash{2}})
+	require.NoError(t, psr.CommitAndWrite(false, block2))
+	root2 := rawdb.GetPrivateStateRoot(testdb, block2.Root())
+
+	require.NotNil(t, tree.Snapshot(root2))
+	account, err = tree.Snapshot(root2).Account(crypto.Keccak256Hash(addr.Bytes()))
+	require.NoError(t, err)
+	assert.Equal(t, big.NewInt(2), account.Balance)
+
+	// the journaled snapshot is loaded again
+	snapshots.Journal(func(psi types.PrivateStateIdentifier) (common.Hash, error) {
+		return root2, nil
+	})
+	reloaded := NewPrivateStateSnapshots(testdb, testCache.TrieDB(), 16, false)
+	reloaded.Load(types.DefaultPrivateStateIdentifier, root2)
+	require.NotNil(t, reloaded.Tree(types.DefaultPrivateStateIdentifier))
+	assert.NotNil(t, reloaded.Tree(types.DefaultPrivateStateIdentifier).Snapshot(root2))
+	assert.Nil(t, reloaded.Tree("other"))
+
+	// the snapshot of the public state is not affected
+	assert.Equal(t, common.Hash{}, rawdb.ReadSnapshotRoot(testdb))
+}
+
+func TestPrivateStateSnapshots_whenRootNotCovered(t *testing.T) {
+	testdb := rawdb.NewMemoryDatabase()
+	testCache := state.NewDatabase(testdb)
+	privateCacheProvider := privatecache.NewPrivateCacheProvider(testdb, nil, testCache, false)
+	snapshots := NewPrivateStateSnapshots(testdb, testCache.TrieDB(), 16, false)
+	addr := common.BytesToAddress([]byte{1})
+
+	psr, err := NewDefaultPrivateStateRepositoryWithSnapshots(testdb, testCache, privateCacheProvider, common.Hash{}, snapshots)
+	require.NoError(t, err)
+	testState, _ := psr.DefaultState()
+	testState.SetBalance(addr, big.NewInt(1))
+	block1 := types.NewBlockWithHeader(&types.Header{Root: common.Hash{1}})
+	require.NoError(t, psr.CommitAndWrite(false, block1))
+	root1 := rawdb.GetPrivateStateRoot(testdb, block1.Root())
+	tree := snapshots.Tree(types.DefaultPrivateStateIdentifier)
+	require.NotNil(t, tree)
+
+	// nothing to do if the root is unchanged or already covered by the tree
+	snapshots.Committed(types.DefaultPrivateStateIdentifier, root1, root1)
+	snapshots.Committed(types.DefaultPrivateStateIdentifier, common.Hash{}, root1)
+	assert.NotNil(t, tree.Snapshot(root1))
+
+	// a root committed without the tree, e.g. after a rewind, is regenerated in the background
+	detached, err := state.New(root1, testCache, nil)
+	require.NoError(t, err)
+	detached.SetBalance(addr, big.NewInt(3))
+	root3, err := detached.Commit(false)
+	require.NoError(t, err)
+	require.Nil(t, tree.Snapshot(root3))
+
+	snapshots.Committed(types.DefaultPrivateStateIdentifier, root1, root3)
+	require.Eventually(t, func() bool {
+		return tree.Snapshot(root3) != nil
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestPrivateStateSnapshots_whenMPSCommitOnly(t *testing.T) {
+	testdb := rawdb.NewMemoryDatabase()
+	testCache := state.NewDatabase(testdb)
+	privateCacheProvider := privatecache.NewPrivateCacheProvider(testdb, nil, testCache, false)
+	snapshots := NewPrivateStateSnapshots(testdb, testCache.TrieDB(), 16, false)
+	psi := types.PrivateStateIdentifier("psi1")
+
+	mpsr, err := NewMultiplePrivateStateRepositoryWithSnapshots(testdb, testCache, common.Hash{}, privateCacheProvider, snapshots)
+	require.NoError(t, err)
+	testState, err := mpsr.StatePSI(psi)
+	require.NoError(t, err)
+	testState.SetBalance(common.BytesToAddress([]byte{1}), big.NewInt(1))
+	block := types.NewBlockWithHeader(&types.Header{Root: common.Hash{1}})
+
+	// a commit which is not written, e.g. by a re-execution, does not maintain the tree
+	require.NoError(t, mpsr.Commit(false, block))
+	assert.Nil(t, snapshots.Tree(psi))
+
+	require.NoError(t, mpsr.CommitAndWrite(false, block))
+	assert.NotNil(t, snapshots.Tree(psi))
+}
+
+func TestPrivateStateSnapshots_whenDisabled(t *testing.T) {
+	var snapshots *PrivateStateSnapshots
+
+	snapshots.Load(types.DefaultPrivateStateIdentifier, common.Hash{1})
+	snapshots.Committed(types.DefaultPrivateStateIdentifier, common.Hash{}, common.Hash{1})
+
+	assert.Nil(t, snapshots.Tree(types.DefaultPrivateStateIdentifier))
+}
@@ -0,0 +1,274 @@
+package mps
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+var emptyCodeHash = crypto.Keccak256(nil)
+
+// SplitDB performs the following database operations to extract a standalone DB, without MPS
+// support, for the private state of the given PSI
+// 1. Copy the canonical blocks and their transaction lookup entries, replacing the receipts of
+// the private transactions by the receipts of the private state, and the private contracts of the
+// private state
+// 2. Copy the public state and the private state of the PSI of each block whose state is
+// available, and map the block header root to the root of the private state
+// 3. Set the head of the chain and write the chain config with isMPS false
+//
+// The private states of the other PSIs and the trie of private states are not copied. Up to
+// its first transaction a private state is the empty private state, as it is branched from it.
+func SplitDB(db, splitDb ethdb.Database, psi types.PrivateStateIdentifier) error {
+	if psi == types.EmptyPrivateStateIdentifier {
+		return errors.New("the empty private state cannot be split")
+	}
+	genesisHash := rawdb.ReadCanonicalHash(db, 0)
+	config := rawdb.ReadChainConfig(db, genesisHash)
+	if config == nil {
+		return errors.New("chain config not found")
+	}
+	if !config.IsMPS {
+		return errors.New("the database does not support multiple private states")
+	}
+	headHash := rawdb.ReadHeadBlockHash(db)
+	headNumber := rawdb.ReadHeaderNumber(db, headHash)
+	if headNumber == nil {
+		return errors.New("head block not found")
+	}
+	headHeader := rawdb.ReadHeader(db, headHash, *headNumber)
+	if _, found, err := privateStateRootOf(db, headHeader.Root, psi); err != nil {
+		return err
+	} else if !found {
+		log.Warn("Private state not found at the head block, splitting the empty private state", "psi", psi)
+	}
+
+	var (
+		start  = time.Now()
+		logged = time.Now()
+	)
+	for number := uint64(0); number <= *headNumber; number++ {
+		hash := rawdb.ReadCanonicalHash(db, number)
+		block := rawdb.ReadBlock(db, hash, number)
+		if block == nil {
+			return fmt.Errorf("block #%d not found", number)
+		}
+		receipts := rawdb.ReadRawReceipts(db, hash, number)
+		if receipts == nil && len(block.Transactions()) > 0 {
+			return fmt.Errorf("receipts of block #%d not found", number)
+		}
+		privateReceipts := splitReceipts(block, receipts, psi)
+
+		batch := splitDb.NewBatch()
+		rawdb.WriteBlock(batch, block)
+		rawdb.WriteCanonicalHash(batch, hash, number)
+		rawdb.WriteTd(batch, hash, number, rawdb.ReadTd(db, hash, number))
+		rawdb.WriteReceipts(batch, hash, number, receipts)
+		rawdb.WriteTxLookupEntriesByBlock(batch, block)
+		for _, entry := range rawdb.ReadPrivateContractEntries(db, psi, number, number, math.MaxInt32) {
+			if entry.BlockHash == hash {
+				rawdb.WritePrivateContractEntry(batch, types.DefaultPrivateStateIdentifier, entry)
+			}
+		}
+		if err := batch.Write(); err != nil {
+			return err
+		}
+		if err := rawdb.WritePrivateBlockBloom(splitDb, number, privateReceipts); err != nil {
+			return err
+		}
+		if err := splitStates(db, splitDb, block.Root(), psi); err != nil {
+			return fmt.Errorf("failed to split the state of block #%d: %v", number, err)
+		}
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Splitting MPS database", "psi", psi, "number", number, "head", *headNumber, "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+	if !hasState(splitDb, headHeader.Root) || rawdb.GetPrivateStateRoot(splitDb, headHeader.Root) == (common.Hash{}) {
+		return fmt.Errorf("state of the head block #%d not available", *headNumber)
+	}
+
+	rawdb.WriteHeadHeaderHash(splitDb, headHash)
+	rawdb.WriteHeadBlockHash(splitDb, headHash)
+	rawdb.WriteHeadFastBlockHash(splitDb, headHash)
+	if version := rawdb.ReadDatabaseVersion(db); version != nil {
+		rawdb.WriteDatabaseVersion(splitDb, *version)
+	}
+	if rawdb.GetIsQuorumEIP155Activated(db) {
+		if err := rawdb.WriteQuorumEIP155Activation(splitDb); err != nil {
+			return err
+		}
+	}
+	splitConfig := *config
+	splitConfig.IsMPS = false
+	rawdb.WriteChainConfig(splitDb, genesisHash, &splitConfig)
+	log.Info("MPS DB split finished successfully", "psi", psi, "head", *headNumber, "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
+
+// splitReceipts replaces in place the receipts of the private transactions by the receipts of
+// the private state, as they are stored without MPS, and returns the private receipts. The
+// private state is not a party to a private transaction without a receipt for it, in which
+// case the transaction is executed on the empty state.
+func splitReceipts(block *types.Block, receipts types.Receipts, psi types.PrivateStateIdentifier) types.Receipts {
+	var privateReceipts types.Receipts
+	for i, receipt := range receipts {
+		if receipt.PSReceipts == nil {
+			continue
+		}
+		psReceipt, found := receipt.PSReceipts[psi]
+		if !found {
+			psReceipt, found = receipt.PSReceipts[types.EmptyPrivateStateIdentifier]
+		}
+		if !found {
+			receipt.PSReceipts = nil
+			continue
+		}
+		privateReceipts = append(privateReceipts, psReceipt)
+		if block.Transactions()[i].IsPrivacyMarker() {
+			// the receipt of the privacy marker transaction holds the receipt of the private transaction
+			receipt.PSReceipts = map[types.PrivateStateIdentifier]*types.Receipt{types.DefaultPrivateStateIdentifier: psReceipt}
+		} else {
+			psReceipt.PSReceipts = nil
+			receipts[i] = psReceipt
+		}
+	}
+	return privateReceipts
+}
+
+// splitStates copies the public state with the given root and the private state of the PSI linked
+// to it, if they are available, and links the private state to the public state root
+func splitStates(db, splitDb ethdb.Database, root common.Hash, psi types.PrivateStateIdentifier) error {
+	if hasState(db, root) {
+		if err := copyState(db, splitDb, root); err != nil {
+			return err
+		}
+	}
+	if rawdb.GetPrivateStatesTrieRoot(db, root) == (common.Hash{}) {
+		return nil
+	}
+	privateRoot, found, err := privateStateRootOf(db, root, psi)
+	if err != nil {
+		return err
+	}
+	if !found {
+		if privateRoot, _, err = privateStateRootOf(db, root, types.EmptyPrivateStateIdentifier); err != nil {
+			return err
+		}
+	}
+	if !hasState(db, privateRoot) {
+		return nil
+	}
+	if err := copyState(db, splitDb, privateRoot); err != nil {
+		return err
+	}
+	return rawdb.WritePrivateStateRoot(splitDb, root, privateRoot)
+}
+
+// privateStateRootOf returns the root of the private state of the PSI in the trie of private
+// states linked to the given public state root
+func privateStateRootOf(db ethdb.Database, root common.Hash, psi types.PrivateStateIdentifier) (common.Hash, bool, error) {
+	mpsRoot := rawdb.GetPrivateStatesTrieRoot(db, root)
+	if mpsRoot == (common.Hash{}) {
+		return common.Hash{}, false, fmt.Errorf("trie of private states of state root %x not found", root)
+	}
+	tr, err := state.NewDatabase(db).OpenTrie(mpsRoot)
+	if err != nil {
+		return common.Hash{}, false, err
+	}
+	privateRoot, err := tr.TryGet([]byte(psi))
+	if err != nil {
+		return common.Hash{}, false, err
+	}
+	return common.BytesToHash(privateRoot), privateRoot != nil, nil
+}
+
+func hasState(db ethdb.KeyValueReader, root common.Hash) bool {
+	return root == emptyRoot || len(rawdb.ReadTrieNode(db, root)) > 0
+}
+
+// copyState copies the state with the given root, including the storage tries, the code, the
+// preimages of the trie keys and the state.AccountExtraData trie of the state. The subtries
+// already in the destination database, e.g. shared with the state of a previous block, are
+// not traversed.
+func copyState(db, splitDb ethdb.Database, root common.Hash) error {
+	batch := splitDb.NewBatch()
+	err := copyTrie(db, splitDb, batch, root, func(leaf []byte) error {
+		var acc state.Account
+		if err := rlp.DecodeBytes(leaf, &acc); err != nil {
+			return err
+		}
+		if err := copyTrie(db, splitDb, batch, acc.Root, nil); err != nil {
+			return err
+		}
+		if !bytes.Equal(acc.CodeHash, emptyCodeHash) {
+			codeHash := common.BytesToHash(acc.CodeHash)
+			rawdb.WriteCode(batch, codeHash, rawdb.ReadCode(db, codeHash))
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if extraDataRoot := rawdb.GetAccountExtraDataRoot(db, root); extraDataRoot != (common.Hash{}) {
+		if err := copyTrie(db, splitDb, batch, extraDataRoot, nil); err != nil {
+			return err
+		}
+		if err := rawdb.WriteRootHashMapping(batch, root, extraDataRoot); err != nil {
+			return err
+		}
+	}
+	return batch.Write()
+}
+
+// copyTrie copies the nodes of the secure trie with the given root into the batch, with the
+// preimages of its keys, and calls onLeaf, if not nil, for each leaf of the copied nodes
+func copyTrie(db, splitDb ethdb.Database, batch ethdb.Batch, root common.Hash, onLeaf func(leaf []byte) error) error {
+	if root == emptyRoot || len(rawdb.ReadTrieNode(splitDb, root)) > 0 {
+		return nil
+	}
+	t, err := trie.New(root, trie.NewDatabase(db))
+	if err != nil {
+		return err
+	}
+	iter := t.NodeIterator(nil)
+	for descend := true; iter.Next(descend); {
+		descend = true
+		if hash := iter.Hash(); hash != (common.Hash{}) {
+			if len(rawdb.ReadTrieNode(splitDb, hash)) > 0 {
+				descend = false
+				continue
+			}
+			rawdb.WriteTrieNode(batch, hash, rawdb.ReadTrieNode(db, hash))
+		}
+		if iter.Leaf() {
+			if preimage := rawdb.ReadPreimage(db, common.BytesToHash(iter.LeafKey())); preimage != nil {
+				rawdb.WritePreimages(batch, map[common.Hash][]byte{common.BytesToHash(iter.LeafKey()): preimage})
+			}
+			if onLeaf != nil {
+				if err := onLeaf(iter.LeafBlob()); err != nil {
+					return err
+				}
+			}
+		}
+		if batch.ValueSize() > ethdb.IdealBatchSize {
+			if err := batch.Write(); err != nil {
+				return err
+			}
+			batch.Reset()
+		}
+	}
+	return iter.Error()
+}
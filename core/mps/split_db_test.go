@@ -0,0 +1,142 @@
+package mps
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitDB(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	var (
+		account  = common.BytesToAddress([]byte{1})
+		contract = common.BytesToAddress([]byte{2})
+		psi1     = types.PrivateStateIdentifier("psi1")
+		psi2     = types.PrivateStateIdentifier("psi2")
+	)
+	publicRoot := commitTestState(t, db, func(s *state.StateDB) {
+		s.SetBalance(account, big.NewInt(1))
+	})
+	emptyStateRoot := commitTestState(t, db, func(s *state.StateDB) {
+		s.SetNonce(contract, 1)
+	})
+	psi1Root := commitTestState(t, db, func(s *state.StateDB) {
+		s.SetNonce(contract, 1)
+		s.SetCode(contract, []byte{1})
+		s.SetState(contract, common.HexToHash("0x01"), common.HexToHash("0x11"))
+	})
+	psi2Root := commitTestState(t, db, func(s *state.StateDB) {
+		s.SetNonce(contract, 1)
+		s.SetCode(contract, []byte{2})
+		s.SetState(contract, common.HexToHash("0x01"), common.HexToHash("0x22"))
+	})
+
+	config := *params.QuorumMPSTestChainConfig
+	genesis := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(0), Root: emptyRoot})
+	privateTx := types.NewContractCreation(0, big.NewInt(0), 100000, big.NewInt(0), nil)
+	privateTx.SetPrivate()
+	block := types.NewBlock(&types.Header{Number: big.NewInt(1), ParentHash: genesis.Hash(), Root: publicRoot}, []*types.Transaction{privateTx}, nil, nil, trie.NewStackTrie(nil))
+	psi1Receipt := &types.Receipt{Status: types.ReceiptStatusSuccessful, Logs: []*types.Log{{Address: contract, Topics: []common.Hash{{1}}}}}
+	psi2Receipt := &types.Receipt{Status: types.ReceiptStatusSuccessful, Logs: []*types.Log{{Address: contract, Topics: []common.Hash{{2}}}}}
+	writeTestBlock(db, genesis, nil)
+	writeTestBlock(db, block, types.Receipts{{
+		Status: types.ReceiptStatusSuccessful,
+		QuorumReceiptExtraData: types.QuorumReceiptExtraData{
+			PSReceipts: map[types.PrivateStateIdentifier]*types.Receipt{
+				psi1:                              psi1Receipt,
+				psi2:                              psi2Receipt,
+				types.EmptyPrivateStateIdentifier: {Status: types.ReceiptStatusSuccessful},
+			},
+		},
+	}})
+	rawdb.WriteChainConfig(db, genesis.Hash(), &config)
+	rawdb.WriteHeadBlockHash(db, block.Hash())
+	writeTestPrivateStatesTrie(t, db, emptyRoot, map[types.PrivateStateIdentifier]common.Hash{types.EmptyPrivateStateIdentifier: emptyRoot})
+	writeTestPrivateStatesTrie(t, db, publicRoot, map[types.PrivateStateIdentifier]common.Hash{
+		types.EmptyPrivateStateIdentifier: emptyStateRoot,
+		psi1:                              psi1Root,
+		psi2:                              psi2Root,
+	})
+
+	psi1Contract := &rawdb.PrivateContractEntry{Address: contract, BlockNumber: 1, BlockHash: block.Hash(), TxHash: privateTx.Hash()}
+	rawdb.WritePrivateContractEntry(db, psi1, psi1Contract)
+	rawdb.WritePrivateContractEntry(db, psi2, &rawdb.PrivateContractEntry{Address: account, BlockNumber: 1, BlockHash: block.Hash(), TxHash: privateTx.Hash()})
+
+	splitDb := rawdb.NewMemoryDatabase()
+	require.NoError(t, SplitDB(db, splitDb, psi1))
+
+	assert.False(t, rawdb.ReadChainConfig(splitDb, genesis.Hash()).IsMPS)
+	assert.Equal(t, block.Hash(), rawdb.ReadHeadBlockHash(splitDb))
+	assert.Equal(t, block.Hash(), rawdb.ReadCanonicalHash(splitDb, 1))
+	assert.Equal(t, emptyRoot, rawdb.GetPrivateStateRoot(splitDb, genesis.Root()))
+	assert.Equal(t, psi1Root, rawdb.GetPrivateStateRoot(splitDb, publicRoot))
+	assert.Equal(t, common.Hash{}, rawdb.GetPrivateStatesTrieRoot(splitDb, publicRoot))
+
+	publicState, err := state.New(publicRoot, state.NewDatabase(splitDb), nil)
+	require.NoError(t, err)
+	assert.Equal(t, big.NewInt(1), publicState.GetBalance(account))
+	privateState, err := state.New(psi1Root, state.NewDatabase(splitDb), nil)
+	require.NoError(t, err)
+	assert.Equal(t, []byte{1}, privateState.GetCode(contract))
+	assert.Equal(t, common.HexToHash("0x11"), privateState.GetState(contract, common.HexToHash("0x01")))
+	// the private state of the other PSI is not copied
+	_, err = state.New(psi2Root, state.NewDatabase(splitDb), nil)
+	assert.Error(t, err)
+
+	receipts := rawdb.ReadRawReceipts(splitDb, block.Hash(), 1)
+	require.Len(t, receipts, 1)
+	assert.Nil(t, receipts[0].PSReceipts)
+	assert.Equal(t, psi1Receipt.Logs[0].Topics, receipts[0].Logs[0].Topics)
+	assert.Equal(t, types.CreateBloom(types.Receipts{psi1Receipt}), rawdb.GetPrivateBlockBloom(splitDb, 1))
+	assert.Equal(t, []*rawdb.PrivateContractEntry{psi1Contract}, rawdb.ReadPrivateContractEntries(splitDb, types.DefaultPrivateStateIdentifier, 0, 1, 10))
+	assert.Empty(t, rawdb.ReadPrivateContractEntries(splitDb, psi2, 0, 1, 10))
+}
+
+func TestSplitDB_whenNotMPS(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	genesis := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(0), Root: emptyRoot})
+	writeTestBlock(db, genesis, nil)
+	rawdb.WriteChainConfig(db, genesis.Hash(), params.QuorumTestChainConfig)
+	rawdb.WriteHeadBlockHash(db, genesis.Hash())
+
+	assert.EqualError(t, SplitDB(db, rawdb.NewMemoryDatabase(), "psi1"), "the database does not support multiple private states")
+}
+
+func commitTestState(t *testing.T, db ethdb.Database, update func(s *state.StateDB)) common.Hash {
+	s, err := state.New(common.Hash{}, state.NewDatabase(db), nil)
+	require.NoError(t, err)
+	update(s)
+	root, err := s.Commit(false)
+	require.NoError(t, err)
+	require.NoError(t, s.Database().TrieDB().Commit(root, false, nil))
+	return root
+}
+
+func writeTestBlock(db ethdb.Database, block *types.Block, receipts types.Receipts) {
+	rawdb.WriteBlock(db, block)
+	rawdb.WriteCanonicalHash(db, block.Hash(), block.NumberU64())
+	rawdb.WriteTd(db, block.Hash(), block.NumberU64(), big.NewInt(int64(block.NumberU64()+1)))
+	rawdb.WriteReceipts(db, block.Hash(), block.NumberU64(), receipts)
+}
+
+func writeTestPrivateStatesTrie(t *testing.T, db ethdb.Database, root common.Hash, privateRoots map[types.PrivateStateIdentifier]common.Hash) {
+	cache := state.NewDatabase(db)
+	tr, err := cache.OpenTrie(common.Hash{})
+	require.NoError(t, err)
+	for psi, privateRoot := range privateRoots {
+		require.NoError(t, tr.TryUpdate([]byte(psi), privateRoot.Bytes()))
+	}
+	mpsRoot, err := tr.Commit(nil)
+	require.NoError(t, err)
+	require.NoError(t, cache.TrieDB().Commit(mpsRoot, false, nil))
+	require.NoError(t, rawdb.WritePrivateStatesTrieRoot(db, root, mpsRoot))
+}
@@ -3,6 +3,8 @@ package core
 import (
 	"context"
 	"fmt"
+	"sort"
+	"sync"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/mps"
@@ -11,6 +13,7 @@ import (
 	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/private/engine"
 	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/ethereum/go-ethereum/trie"
 )
@@ -20,27 +23,47 @@ type MultiplePrivateStateManager struct {
 	db                     ethdb.Database
 	privateStatesTrieCache state.Database
 	privateCacheProvider   privatecache.Provider
+	snapshots              *mps.PrivateStateSnapshots // nil if snapshots are disabled
 
+	// mux protects the private states, which can be refreshed while the node is running
+	mux                sync.RWMutex
 	residentGroupByKey map[string]*mps.PrivateStateMetadata
 	privacyGroupById   map[types.PrivateStateIdentifier]*mps.PrivateStateMetadata
+	// number of the first block of the private states added to a running node
+	startBlocks map[types.PrivateStateIdentifier]uint64
 }
 
 func newMultiplePrivateStateManager(db ethdb.Database, privateCacheProvider privatecache.Provider, residentGroupByKey map[string]*mps.PrivateStateMetadata, privacyGroupById map[types.PrivateStateIdentifier]*mps.PrivateStateMetadata) (*MultiplePrivateStateManager, error) {
+	startBlocks := make(map[types.PrivateStateIdentifier]uint64)
+	for psi := range privacyGroupById {
+		if number, found := rawdb.ReadPrivateStateStartBlock(db, psi); found {
+			startBlocks[psi] = number
+		}
+	}
 	return &MultiplePrivateStateManager{
 		db:                     db,
 		privateStatesTrieCache: privateCacheProvider.GetCacheWithConfig(),
 		privateCacheProvider:   privateCacheProvider,
 		residentGroupByKey:     residentGroupByKey,
 		privacyGroupById:       privacyGroupById,
+		startBlocks:            startBlocks,
 	}, nil
 }
 
 func (m *MultiplePrivateStateManager) StateRepository(blockHash common.Hash) (mps.PrivateStateRepository, error) {
+	return m.stateRepository(blockHash, m.snapshots)
+}
+
+// stateRepository returns the repository with each private state read from, and maintaining, its
+// snapshot tree of the given snapshots, if any
+func (m *MultiplePrivateStateManager) stateRepository(blockHash common.Hash, snapshots *mps.PrivateStateSnapshots) (mps.PrivateStateRepository, error) {
 	privateStatesTrieRoot := rawdb.GetPrivateStatesTrieRoot(m.db, blockHash)
-	return mps.NewMultiplePrivateStateRepository(m.db, m.privateStatesTrieCache, privateStatesTrieRoot, m.privateCacheProvider)
+	return mps.NewMultiplePrivateStateRepositoryWithSnapshots(m.db, m.privateStatesTrieCache, privateStatesTrieRoot, m.privateCacheProvider, snapshots)
 }
 
 func (m *MultiplePrivateStateManager) ResolveForManagedParty(managedParty string) (*mps.PrivateStateMetadata, error) {
+	m.mux.RLock()
+	defer m.mux.RUnlock()
 	psm, found := m.residentGroupByKey[managedParty]
 	if !found {
 		return nil, fmt.Errorf("unable to find private state metadata for managed party %s", managedParty)
@@ -53,6 +76,8 @@ func (m *MultiplePrivateStateManager) ResolveForUserContext(ctx context.Context)
 	if !ok {
 		psi = types.DefaultPrivateStateIdentifier
 	}
+	m.mux.RLock()
+	defer m.mux.RUnlock()
 	psm, found := m.privacyGroupById[psi]
 	if !found {
 		return nil, fmt.Errorf("unable to find private state for context psi %s", psi)
@@ -61,6 +86,8 @@ func (m *MultiplePrivateStateManager) ResolveForUserContext(ctx context.Context)
 }
 
 func (m *MultiplePrivateStateManager) PSIs() []types.PrivateStateIdentifier {
+	m.mux.RLock()
+	defer m.mux.RUnlock()
 	psis := make([]types.PrivateStateIdentifier, 0, len(m.privacyGroupById))
 	for psi := range m.privacyGroupById {
 		psis = append(psis, psi)
@@ -68,6 +95,59 @@ func (m *MultiplePrivateStateManager) PSIs() []types.PrivateStateIdentifier {
 	return psis
 }
 
+// PSIsAt returns the private states which exist at the given block, i.e. without the private
+// states added to the running node from a later block
+func (m *MultiplePrivateStateManager) PSIsAt(number uint64) []types.PrivateStateIdentifier {
+	m.mux.RLock()
+	defer m.mux.RUnlock()
+	psis := make([]types.PrivateStateIdentifier, 0, len(m.privacyGroupById))
+	for psi := range m.privacyGroupById {
+		if start, found := m.startBlocks[psi]; !found || number >= start {
+			psis = append(psis, psi)
+		}
+	}
+	return psis
+}
+
+// Refresh replaces the private states with the ones of the given privacy groups and returns
+// the private states which were added, whose state starts from the given block. Removing a
+// private state, or moving a resident member to another private state, is rejected as it would
+// orphan the existing state.
+func (m *MultiplePrivateStateManager) Refresh(groups []engine.PrivacyGroup, startBlock uint64) ([]types.PrivateStateIdentifier, error) {
+	residentGroupByKey, privacyGroupById, err := privateStateMetadataFromGroups(groups)
+	if err != nil {
+		return nil, err
+	}
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	for psi := range m.privacyGroupById {
+		if _, found := privacyGroupById[psi]; !found {
+			return nil, fmt.Errorf("private state %s is no longer managed by the transaction manager, removing it would orphan its state", psi)
+		}
+	}
+	for address, psm := range m.residentGroupByKey {
+		if refreshed, found := residentGroupByKey[address]; !found || refreshed.ID != psm.ID {
+			return nil, fmt.Errorf("managed party %s is no longer a member of private state %s, removing it would orphan its state", address, psm.ID)
+		}
+	}
+	var added []types.PrivateStateIdentifier
+	for psi := range privacyGroupById {
+		if _, found := m.privacyGroupById[psi]; !found {
+			added = append(added, psi)
+		}
+	}
+	sort.Slice(added, func(i, j int) bool { return added[i] < added[j] })
+	for _, psi := range added {
+		if err := rawdb.WritePrivateStateStartBlock(m.db, psi, startBlock); err != nil {
+			return nil, err
+		}
+		m.startBlocks[psi] = startBlock
+	}
+	m.residentGroupByKey = residentGroupByKey
+	m.privacyGroupById = privacyGroupById
+	return added, nil
+}
+
 func (m *MultiplePrivateStateManager) NotIncludeAny(psm *mps.PrivateStateMetadata, managedParties ...string) bool {
 	return psm.NotIncludeAny(managedParties...)
 }
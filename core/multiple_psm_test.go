@@ -271,6 +271,82 @@ func TestPrivateStateMetadataResolver(t *testing.T) {
 	assert.Contains(t, mpsm.PSIs(), types.PrivateStateIdentifier("LEGACY1"))
 }
 
+func TestRefreshPrivateStates(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockptm := private.NewMockPrivateTransactionManager(mockCtrl)
+
+	saved := private.P
+	defer func() {
+		private.P = saved
+	}()
+	private.P = mockptm
+
+	// the test chain executes a private contract creation
+	mockptm.EXPECT().Receive(gomock.Not(common.EncryptedPayloadHash{})).Return("", []string{"AAA"}, common.FromHex(testCode), nil, nil).AnyTimes()
+	mockptm.EXPECT().Receive(common.EncryptedPayloadHash{}).Return("", []string{}, common.EncryptedPayloadHash{}.Bytes(), nil, nil).AnyTimes()
+	groups := PrivacyGroups
+	mockptm.EXPECT().HasFeature(engine.MultiplePrivateStates).Return(true)
+	mockptm.EXPECT().Groups().DoAndReturn(func() ([]engine.PrivacyGroup, error) {
+		return groups, nil
+	}).AnyTimes()
+
+	_, _, blockchain := buildTestChain(1, params.QuorumMPSTestChainConfig)
+	mpsm := blockchain.privateStateManager.(*MultiplePrivateStateManager)
+	_, err := mpsm.ResolveForManagedParty("EEE")
+	assert.Error(t, err)
+
+	RG3 := engine.PrivacyGroup{
+		Type:           "RESIDENT",
+		Name:           "RG3",
+		PrivacyGroupId: base64.StdEncoding.EncodeToString([]byte("RG3")),
+		Description:    "Resident Group 3",
+		Members:        []string{"EEE"},
+	}
+	groups = append(append([]engine.PrivacyGroup{}, PrivacyGroups...), RG3)
+	startBlock := uint64(5)
+	added, err := blockchain.RefreshPrivateStates(&startBlock)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []types.PrivateStateIdentifier{"RG3"}, added)
+	psm, err := mpsm.ResolveForManagedParty("EEE")
+	assert.NoError(t, err)
+	assert.Equal(t, types.PrivateStateIdentifier("RG3"), psm.ID)
+	psm, err = mpsm.ResolveForManagedParty("AAA")
+	assert.NoError(t, err)
+	assert.Equal(t, types.PrivateStateIdentifier("RG1"), psm.ID)
+	assert.Contains(t, mpsm.PSIs(), types.PrivateStateIdentifier("RG3"))
+	assert.NotContains(t, mpsm.PSIsAt(4), types.PrivateStateIdentifier("RG3"))
+	assert.Contains(t, mpsm.PSIsAt(4), types.PrivateStateIdentifier("RG1"))
+	assert.Contains(t, mpsm.PSIsAt(5), types.PrivateStateIdentifier("RG3"))
+	number, found := rawdb.ReadPrivateStateStartBlock(blockchain.db, "RG3")
+	assert.True(t, found)
+	assert.Equal(t, uint64(5), number)
+
+	// nothing is added when refreshing again
+	added, err = blockchain.RefreshPrivateStates(nil)
+	assert.NoError(t, err)
+	assert.Empty(t, added)
+
+	// a private state cannot be removed
+	groups = PrivacyGroups
+	_, err = blockchain.RefreshPrivateStates(nil)
+	assert.EqualError(t, err, "private state RG3 is no longer managed by the transaction manager, removing it would orphan its state")
+	assert.Contains(t, mpsm.PSIs(), types.PrivateStateIdentifier("RG3"))
+
+	// a resident member cannot be removed
+	RG3.Members = []string{"FFF"}
+	groups = append(append([]engine.PrivacyGroup{}, PrivacyGroups...), RG3)
+	_, err = blockchain.RefreshPrivateStates(nil)
+	assert.EqualError(t, err, "managed party EEE is no longer a member of private state RG3, removing it would orphan its state")
+
+	// the new private states cannot start before the head block
+	startBlock = 0
+	_, err = blockchain.RefreshPrivateStates(&startBlock)
+	assert.EqualError(t, err, "start block 0 of the new private states must be after the head block 0")
+}
+
 var PSI1PSM = mps.PrivateStateMetadata{
 	ID:          "psi1",
 	Name:        "psi1",
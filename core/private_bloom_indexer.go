@@ -0,0 +1,69 @@
+package core
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethdb"
+)
+
+// PrivateBloomIndexer implements a core.ChainIndexer, building up a rotated bloom bits index
+// for the private logs of a private state. The index is stored in the namespace of the private
+// state, so that filtering the logs of a private state does not match the blocks which only
+// contain logs of other private states.
+type PrivateBloomIndexer struct {
+	BloomIndexer
+
+	chainDb ethdb.Database // database instance to read the private blooms and receipts from
+	psi     types.PrivateStateIdentifier
+	isMPS   bool
+}
+
+// NewPrivateBloomIndexer returns a chain indexer that generates bloom bits data of the private
+// logs of the given private state for the canonical chain.
+func NewPrivateBloomIndexer(db ethdb.Database, psi types.PrivateStateIdentifier, isMPS bool, size, confirms uint64) *ChainIndexer {
+	table := rawdb.NewPrivateBloomBitsTable(db, psi)
+	backend := &PrivateBloomIndexer{
+		BloomIndexer: BloomIndexer{
+			db:   table,
+			size: size,
+		},
+		chainDb: db,
+		psi:     psi,
+		isMPS:   isMPS,
+	}
+	return NewChainIndexer(db, table, backend, size, confirms, bloomThrottling, "privatebloombits")
+}
+
+// Process implements core.ChainIndexerBackend, adding the bloom of the private logs of the
+// header's block into the index.
+//
+// Without MPS the private bloom of the block only contains the logs of the private state. With
+// MPS it contains the logs of all private states, so the bloom is built from the receipts of the
+// private state instead. These include the receipts of the public transactions, whose logs are
+// matched by the public index anyway.
+func (b *PrivateBloomIndexer) Process(ctx context.Context, header *types.Header) error {
+	var bloom types.Bloom
+	if b.isMPS {
+		receipts := rawdb.ReadRawReceipts(b.chainDb, header.Hash(), header.Number.Uint64())
+		psReceipts := make(types.Receipts, len(receipts))
+		for i, receipt := range receipts {
+			// the private state is not a party to a private transaction without a receipt for it,
+			// in which case the transaction is executed on the empty state
+			if psReceipt, ok := receipt.PSReceipts[b.psi]; ok {
+				psReceipts[i] = psReceipt
+			} else if emptyReceipt, ok := receipt.PSReceipts[types.EmptyPrivateStateIdentifier]; ok {
+				psReceipts[i] = emptyReceipt
+			} else {
+				psReceipts[i] = receipt
+			}
+		}
+		bloom = types.CreateBloom(psReceipts)
+	} else {
+		bloom = rawdb.GetPrivateBlockBloom(b.chainDb, header.Number.Uint64())
+	}
+	b.gen.AddBloom(uint(header.Number.Uint64()-b.section*b.size), bloom)
+	b.head = header.Hash()
+	return nil
+}
@@ -0,0 +1,123 @@
+package core
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/bitutil"
+	"github.com/ethereum/go-ethereum/core/bloombits"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/stretchr/testify/require"
+)
+
+const testPrivateBloomSectionSize = 8
+
+var (
+	testPublicLog = &types.Log{Address: common.HexToAddress("0x1")}
+	testPSI1Log   = &types.Log{Address: common.HexToAddress("0x2"), Topics: []common.Hash{common.HexToHash("0x22")}}
+	testPSI2Log   = &types.Log{Address: common.HexToAddress("0x3"), Topics: []common.Hash{common.HexToHash("0x33")}}
+)
+
+func TestPrivateBloomIndexer_whenMPS(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	headers := testPrivateBloomHeaders()
+	// block 0: public transaction
+	rawdb.WriteReceipts(db, headers[0].Hash(), 0, types.Receipts{{Logs: []*types.Log{testPublicLog}}})
+	// block 1: private transaction for psi1 and psi2
+	rawdb.WriteReceipts(db, headers[1].Hash(), 1, types.Receipts{{
+		Logs: []*types.Log{testPSI1Log, testPSI2Log},
+		QuorumReceiptExtraData: types.QuorumReceiptExtraData{
+			PSReceipts: map[types.PrivateStateIdentifier]*types.Receipt{
+				"psi1":                            {Logs: []*types.Log{testPSI1Log}},
+				"psi2":                            {Logs: []*types.Log{testPSI2Log}},
+				types.EmptyPrivateStateIdentifier: {},
+			},
+		},
+	}})
+	// block 2: private transaction for psi2 only
+	rawdb.WriteReceipts(db, headers[2].Hash(), 2, types.Receipts{{
+		Logs: []*types.Log{testPSI2Log},
+		QuorumReceiptExtraData: types.QuorumReceiptExtraData{
+			PSReceipts: map[types.PrivateStateIdentifier]*types.Receipt{
+				"psi2":                            {Logs: []*types.Log{testPSI2Log}},
+				types.EmptyPrivateStateIdentifier: {},
+			},
+		},
+	}})
+
+	indexPrivateBlooms(t, db, "psi1", true, headers)
+	indexPrivateBlooms(t, db, "psi2", true, headers)
+
+	publicBloom := types.CreateBloom(types.Receipts{{Logs: []*types.Log{testPublicLog}}})
+	psi1Bloom := types.CreateBloom(types.Receipts{{Logs: []*types.Log{testPSI1Log}}})
+	psi2Bloom := types.CreateBloom(types.Receipts{{Logs: []*types.Log{testPSI2Log}}})
+	requirePrivateBloomBits(t, db, "psi1", headers, []types.Bloom{publicBloom, psi1Bloom, {}})
+	requirePrivateBloomBits(t, db, "psi2", headers, []types.Bloom{publicBloom, psi2Bloom, psi2Bloom})
+
+	// the public index is not written to
+	_, err := rawdb.ReadBloomBits(db, 0, 0, headers[len(headers)-1].Hash())
+	require.Error(t, err)
+}
+
+func TestPrivateBloomIndexer_whenNotMPS(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	headers := testPrivateBloomHeaders()
+	require.NoError(t, rawdb.WritePrivateBlockBloom(db, 1, types.Receipts{{Logs: []*types.Log{testPSI1Log}}}))
+
+	indexPrivateBlooms(t, db, types.DefaultPrivateStateIdentifier, false, headers)
+
+	privateBloom := types.CreateBloom(types.Receipts{{Logs: []*types.Log{testPSI1Log}}})
+	requirePrivateBloomBits(t, db, types.DefaultPrivateStateIdentifier, headers, []types.Bloom{{}, privateBloom})
+}
+
+func testPrivateBloomHeaders() []*types.Header {
+	headers := make([]*types.Header, testPrivateBloomSectionSize)
+	for i := range headers {
+		headers[i] = &types.Header{Number: big.NewInt(int64(i)), Extra: []byte("private bloom")}
+	}
+	return headers
+}
+
+// indexPrivateBlooms processes the headers as the first section of the private bloom index
+func indexPrivateBlooms(t *testing.T, db ethdb.Database, psi types.PrivateStateIdentifier, isMPS bool, headers []*types.Header) {
+	indexer := &PrivateBloomIndexer{
+		BloomIndexer: BloomIndexer{db: rawdb.NewPrivateBloomBitsTable(db, psi), size: testPrivateBloomSectionSize},
+		chainDb:      db,
+		psi:          psi,
+		isMPS:        isMPS,
+	}
+	require.NoError(t, indexer.Reset(context.Background(), 0, common.Hash{}))
+	for _, header := range headers {
+		require.NoError(t, indexer.Process(context.Background(), header))
+	}
+	require.NoError(t, indexer.Commit())
+}
+
+// requirePrivateBloomBits checks that the private bloom index of the first section contains the
+// expected blooms, the blooms of the headers not given being empty
+func requirePrivateBloomBits(t *testing.T, db ethdb.Database, psi types.PrivateStateIdentifier, headers []*types.Header, expected []types.Bloom) {
+	gen, err := bloombits.NewGenerator(testPrivateBloomSectionSize)
+	require.NoError(t, err)
+	for i := range headers {
+		var bloom types.Bloom
+		if i < len(expected) {
+			bloom = expected[i]
+		}
+		require.NoError(t, gen.AddBloom(uint(i), bloom))
+	}
+	table := rawdb.NewPrivateBloomBitsTable(db, psi)
+	head := headers[len(headers)-1].Hash()
+	for bit := uint(0); bit < types.BloomBitLength; bit++ {
+		expectedBits, err := gen.Bitset(bit)
+		require.NoError(t, err)
+		compressed, err := rawdb.ReadBloomBits(table, bit, 0, head)
+		require.NoError(t, err)
+		actualBits, err := bitutil.DecompressBytes(compressed, testPrivateBloomSectionSize/8)
+		require.NoError(t, err)
+		require.Equal(t, expectedBits, actualBits, "bit %d of psi %s", bit, psi)
+	}
+}
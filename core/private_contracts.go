@@ -0,0 +1,156 @@
+package core
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/mps"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/private"
+	"github.com/ethereum/go-ethereum/private/engine"
+)
+
+// indexPrivateContracts records the private contracts created by the transactions of the block in
+// each private state, given the receipts of the block merged with their private receipts and the
+// private states of the block. The contracts are recorded only in the private states of the parties
+// of the transactions, in which their code exists.
+//
+// The contracts are recorded as blocks are imported, so the ones created by blocks imported before
+// the index was introduced are only recorded once the blocks are indexed by IndexPrivateContracts.
+func indexPrivateContracts(db ethdb.KeyValueWriter, block *types.Block, receipts []*types.Receipt, psManager mps.PrivateStateRepository) {
+	if len(receipts) != len(block.Transactions()) {
+		return
+	}
+	for i, tx := range block.Transactions() {
+		receipt := receipts[i]
+		switch {
+		case tx.IsPrivate():
+			creationTxHash := common.BytesToEncryptedPayloadHash(tx.Data())
+			if !psManager.IsMPS() {
+				indexPrivateContract(db, block, types.DefaultPrivateStateIdentifier, receipt, tx.Hash(), common.Hash{}, creationTxHash, psManager)
+				continue
+			}
+			for psi, psReceipt := range receipt.PSReceipts {
+				if psi != types.EmptyPrivateStateIdentifier {
+					indexPrivateContract(db, block, psi, psReceipt, tx.Hash(), common.Hash{}, creationTxHash, psManager)
+				}
+			}
+		case tx.IsPrivacyMarker():
+			indexPrivacyMarkerContracts(db, block, tx, receipt, psManager)
+		}
+	}
+}
+
+// indexPrivacyMarkerContracts records the private contracts created by the private transactions
+// carried by a privacy marker transaction. The hashes of their private payloads are taken from the
+// private receipts of the executed block; they are only retrieved from the private transaction
+// manager for stored receipts, which do not hold them.
+func indexPrivacyMarkerContracts(db ethdb.KeyValueWriter, block *types.Block, pmt *types.Transaction, receipt *types.Receipt, psManager mps.PrivateStateRepository) {
+	var creationTxHashes map[common.Hash]common.EncryptedPayloadHash // by hash of the private transactions, retrieved once needed
+	for psi, psReceipt := range receipt.PSReceipts {
+		if psi == types.EmptyPrivateStateIdentifier {
+			continue
+		}
+		privateReceipts := []*types.Receipt{psReceipt}
+		if len(psReceipt.BatchReceipts) > 0 {
+			privateReceipts = psReceipt.BatchReceipts
+		}
+		for _, privateReceipt := range privateReceipts {
+			if privateReceipt.ContractAddress == (common.Address{}) || privateReceipt.Status != types.ReceiptStatusSuccessful {
+				continue
+			}
+			creationTxHash := privateReceipt.PrivatePayloadHash
+			if common.EmptyEncryptedPayloadHash(creationTxHash) {
+				if creationTxHashes == nil {
+					txs, _, _, err := private.FetchPrivateTransactions(pmt.Data())
+					if err != nil {
+						log.Warn("Unable to retrieve the private transactions to index private contracts", "pmt", pmt.Hash(), "err", err)
+						return
+					}
+					creationTxHashes = make(map[common.Hash]common.EncryptedPayloadHash, len(txs))
+					for _, tx := range txs {
+						creationTxHashes[tx.Hash()] = common.BytesToEncryptedPayloadHash(tx.Data())
+					}
+				}
+				creationTxHash = creationTxHashes[privateReceipt.TxHash]
+			}
+			indexPrivateContract(db, block, psi, privateReceipt, privateReceipt.TxHash, pmt.Hash(), creationTxHash, psManager)
+		}
+	}
+}
+
+// IndexPrivateContracts records the private contracts created by the canonical blocks from first
+// to last, e.g. to backfill the index for the blocks imported before it was introduced. The private
+// states of the blocks must be available, and the private transaction manager must be running to
+// retrieve the hashes of the private payloads carried by privacy marker transactions.
+func (bc *BlockChain) IndexPrivateContracts(first, last uint64, progress func(block *types.Block)) error {
+	for number := first; number <= last; number++ {
+		block := bc.GetBlockByNumber(number)
+		if block == nil {
+			return fmt.Errorf("block #%d not found", number)
+		}
+		receipts := bc.GetReceiptsByHash(block.Hash())
+		if receipts == nil && len(block.Transactions()) > 0 {
+			return fmt.Errorf("receipts of block #%d not found", number)
+		}
+		privateStateRepo, err := bc.privateStateManager.StateRepository(block.Root())
+		if err != nil {
+			return fmt.Errorf("private state of block #%d not available: %v", number, err)
+		}
+		indexPrivateContracts(bc.db, block, receipts, privateStateRepo)
+		if progress != nil {
+			progress(block)
+		}
+	}
+	return nil
+}
+
+func indexPrivateContract(db ethdb.KeyValueWriter, block *types.Block, psi types.PrivateStateIdentifier, receipt *types.Receipt, txHash, pmtHash common.Hash, creationTxHash common.EncryptedPayloadHash, psManager mps.PrivateStateRepository) {
+	if receipt == nil || receipt.ContractAddress == (common.Address{}) || receipt.Status != types.ReceiptStatusSuccessful {
+		return
+	}
+	privateState, err := psManager.StatePSI(psi)
+	if err != nil {
+		log.Warn("Unable to open the private state to index private contracts", "psi", psi, "err", err)
+		return
+	}
+	// the contract is created with no code in the private state of a non party
+	if len(privateState.GetCode(receipt.ContractAddress)) == 0 {
+		return
+	}
+	privacyFlag := engine.PrivacyFlagStandardPrivate
+	if privacyMetadata, _ := privateState.GetPrivacyMetadata(receipt.ContractAddress); privacyMetadata != nil {
+		privacyFlag = privacyMetadata.PrivacyFlag
+	}
+	rawdb.WritePrivateContractEntry(db, psi, &rawdb.PrivateContractEntry{
+		Address:             receipt.ContractAddress,
+		BlockNumber:         block.NumberU64(),
+		BlockHash:           block.Hash(),
+		TxHash:              txHash,
+		PrivacyMarkerTxHash: pmtHash,
+		CreationTxHash:      creationTxHash,
+		PrivacyFlag:         privacyFlag,
+	})
+}
+
+// indexPrivacyMarkerBatches records the privacy marker transaction carrying each private transaction
+// of a batch the node is a party to, given the receipts of the block merged with their private
+// receipts, so that the private transactions of the batch can be retrieved by their own hash
+func indexPrivacyMarkerBatches(db ethdb.KeyValueWriter, block *types.Block, receipts []*types.Receipt) {
+	if len(receipts) != len(block.Transactions()) {
+		return
+	}
+	for i, tx := range block.Transactions() {
+		if !tx.IsPrivacyMarkerBatch() {
+			continue
+		}
+		for _, psReceipt := range receipts[i].PSReceipts {
+			for _, batchReceipt := range psReceipt.BatchReceipts {
+				rawdb.WritePrivateBatchTxLookup(db, batchReceipt.TxHash, tx.Hash())
+			}
+		}
+	}
+}
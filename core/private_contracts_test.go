@@ -0,0 +1,94 @@
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/mps"
+	"github.com/ethereum/go-ethereum/core/privatecache"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/private"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIndexPrivateContracts_whenPrivacyMarker(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	saved := private.P
+	defer func() {
+		private.P = saved
+	}()
+	// the hash of the private payload is taken from the private receipt, the private transaction
+	// manager is not called
+	private.P = private.NewMockPrivateTransactionManager(mockCtrl)
+
+	db := rawdb.NewMemoryDatabase()
+	cache := state.NewDatabase(db)
+	psr, err := mps.NewDefaultPrivateStateRepository(db, cache, privatecache.NewPrivateCacheProvider(db, nil, cache, false), common.Hash{})
+	require.NoError(t, err)
+	contract := common.HexToAddress("0x2")
+	privateState, _ := psr.DefaultState()
+	privateState.SetCode(contract, []byte{1})
+
+	innerTx := types.NewContractCreation(0, big.NewInt(0), 1, big.NewInt(0), common.EncryptedPayloadHash{3}.Bytes())
+	pmt := types.NewTransaction(0, common.QuorumPrivacyPrecompileContractAddress(), big.NewInt(0), 1, big.NewInt(0), common.EncryptedPayloadHash{1}.Bytes())
+	block := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(1)}).WithBody([]*types.Transaction{pmt}, nil)
+	receipts := []*types.Receipt{{
+		TxHash: pmt.Hash(),
+		QuorumReceiptExtraData: types.QuorumReceiptExtraData{
+			PSReceipts: map[types.PrivateStateIdentifier]*types.Receipt{
+				types.DefaultPrivateStateIdentifier: {
+					Status:             types.ReceiptStatusSuccessful,
+					TxHash:             innerTx.Hash(),
+					ContractAddress:    contract,
+					PrivatePayloadHash: common.EncryptedPayloadHash{3},
+				},
+			},
+		},
+	}}
+
+	indexPrivateContracts(db, block, receipts, psr)
+
+	entries := rawdb.ReadPrivateContractEntries(db, types.DefaultPrivateStateIdentifier, 0, 1, 10)
+	require.Len(t, entries, 1)
+	assert.Equal(t, contract, entries[0].Address)
+	assert.Equal(t, block.Hash(), entries[0].BlockHash)
+	assert.Equal(t, innerTx.Hash(), entries[0].TxHash)
+	assert.Equal(t, pmt.Hash(), entries[0].PrivacyMarkerTxHash)
+	assert.Equal(t, common.EncryptedPayloadHash{3}, entries[0].CreationTxHash)
+}
+
+func TestIndexPrivacyMarkerBatches(t *testing.T) {
+	innerTx1 := types.NewTransaction(1, common.HexToAddress("0x1"), big.NewInt(0), 1, big.NewInt(0), nil)
+	innerTx2 := types.NewTransaction(2, common.HexToAddress("0x1"), big.NewInt(0), 1, big.NewInt(0), nil)
+	data, err := types.NewPrivacyMarkerBatchData(common.EncryptedPayloadHash{1}, []types.PrivacyMarkerBatchEntry{types.NewPrivacyMarkerBatchEntry(innerTx1, nil), types.NewPrivacyMarkerBatchEntry(innerTx2, nil)})
+	assert.NoError(t, err)
+	pmt := types.NewTransaction(0, common.QuorumPrivacyPrecompileContractAddress(), big.NewInt(0), 1, big.NewInt(0), data)
+	block := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(1)}).WithBody([]*types.Transaction{pmt}, nil)
+	receipts := []*types.Receipt{{
+		TxHash: pmt.Hash(),
+		QuorumReceiptExtraData: types.QuorumReceiptExtraData{
+			PSReceipts: map[types.PrivateStateIdentifier]*types.Receipt{
+				types.DefaultPrivateStateIdentifier: {
+					TxHash: pmt.Hash(),
+					// the node is only a party to the first private transaction of the batch
+					QuorumReceiptExtraData: types.QuorumReceiptExtraData{BatchReceipts: []*types.Receipt{{TxHash: innerTx1.Hash()}}},
+				},
+			},
+		},
+	}}
+	db := rawdb.NewMemoryDatabase()
+
+	indexPrivacyMarkerBatches(db, block, receipts)
+
+	pmtHash, ok := rawdb.ReadPrivateBatchTxLookup(db, innerTx1.Hash())
+	assert.True(t, ok)
+	assert.Equal(t, pmt.Hash(), pmtHash)
+	_, ok = rawdb.ReadPrivateBatchTxLookup(db, innerTx2.Hash())
+	assert.False(t, ok)
+}
@@ -0,0 +1,137 @@
+package core
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/private"
+	lru "github.com/hashicorp/golang-lru"
+)
+
+// defaultPrivatePayloadPrefetchParallelism is the maximum number of concurrent requests
+// made to the private transaction manager while prefetching the payloads of a block.
+const defaultPrivatePayloadPrefetchParallelism = 16
+
+// privatePayloadNotPartyLimit is the number of hashes of payloads this node is not a party to
+// which are recorded, so that they are not requested again.
+const privatePayloadNotPartyLimit = 4096
+
+var (
+	privatePayloadPrefetchHitMeter      = metrics.NewRegisteredMeter("chain/prefetch/private/hits", nil)
+	privatePayloadPrefetchNotPartyMeter = metrics.NewRegisteredMeter("chain/prefetch/private/notparty", nil)
+	privatePayloadPrefetchErrorMeter    = metrics.NewRegisteredMeter("chain/prefetch/private/errors", nil)
+)
+
+// privatePayloadPrefetcher retrieves the private payloads referenced by a block from the
+// private transaction manager before the block is executed. The state processor fetches
+// the payloads one transaction at a time, so fetching them concurrently beforehand means
+// the state processor is served from the transaction manager's cache instead.
+//
+// The transaction manager does not cache the payloads this node is not a party to, so the
+// prefetcher records their hashes and does not request them again, e.g. when a block is
+// prefetched as the followup block and then again before it is processed.
+type privatePayloadPrefetcher struct {
+	parallelism int        // Maximum number of concurrent requests to the private transaction manager
+	notParty    *lru.Cache // Hashes of the payloads this node is not a party to
+}
+
+// newPrivatePayloadPrefetcher initialises a new privatePayloadPrefetcher.
+func newPrivatePayloadPrefetcher(parallelism int) *privatePayloadPrefetcher {
+	if parallelism <= 0 {
+		parallelism = defaultPrivatePayloadPrefetchParallelism
+	}
+	notParty, _ := lru.New(privatePayloadNotPartyLimit)
+	return &privatePayloadPrefetcher{
+		parallelism: parallelism,
+		notParty:    notParty,
+	}
+}
+
+// Prefetch retrieves the payloads of all private transactions in the block, including
+// the ones wrapped by privacy marker transactions, and blocks until they have all been
+// retrieved or the prefetch is interrupted.
+//
+// A payload which is found is counted as a hit, a payload the private transaction
+// manager does not hold (because this node is not a party to the transaction) is
+// counted as not party and is not requested again.
+func (p *privatePayloadPrefetcher) Prefetch(block *types.Block, interrupt *uint32) {
+	var pending []*types.Transaction
+	for _, tx := range block.Transactions() {
+		if tx.IsPrivate() || tx.IsPrivacyMarker() {
+			pending = append(pending, tx)
+		}
+	}
+	if len(pending) == 0 {
+		return
+	}
+	workers := p.parallelism
+	if len(pending) < workers {
+		workers = len(pending)
+	}
+	txs := make(chan *types.Transaction)
+
+	var pend sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		pend.Add(1)
+		go func() {
+			defer pend.Done()
+			for tx := range txs {
+				if tx.IsPrivacyMarker() {
+					p.prefetchMarkerPayload(tx)
+				} else {
+					p.prefetchPayload(common.BytesToEncryptedPayloadHash(tx.Data()))
+				}
+			}
+		}()
+	}
+	for _, tx := range pending {
+		if interrupt != nil && atomic.LoadUint32(interrupt) == 1 {
+			break
+		}
+		txs <- tx
+	}
+	close(txs)
+	pend.Wait()
+}
+
+// prefetchPayload retrieves the payload of a private transaction.
+func (p *privatePayloadPrefetcher) prefetchPayload(hash common.EncryptedPayloadHash) {
+	if common.EmptyEncryptedPayloadHash(hash) || p.notParty.Contains(hash) {
+		return
+	}
+	_, _, data, _, err := private.P.Receive(hash)
+	p.mark(hash, data != nil, err)
+}
+
+// prefetchMarkerPayload retrieves the private transactions wrapped by a privacy marker
+// transaction, a single one or a batch, and then the payloads of those private transactions.
+func (p *privatePayloadPrefetcher) prefetchMarkerPayload(marker *types.Transaction) {
+	hash := common.BytesToEncryptedPayloadHash(marker.Data())
+	if p.notParty.Contains(hash) {
+		return
+	}
+	txs, _, _, err := private.FetchPrivateTransactions(marker.Data())
+	p.mark(hash, txs != nil, err)
+	for _, tx := range txs {
+		if tx.IsPrivate() {
+			p.prefetchPayload(common.BytesToEncryptedPayloadHash(tx.Data()))
+		}
+	}
+}
+
+func (p *privatePayloadPrefetcher) mark(hash common.EncryptedPayloadHash, found bool, err error) {
+	switch {
+	case err != nil:
+		log.Debug("Failed to prefetch private payload", "hash", hash.TerminalString(), "err", err)
+		privatePayloadPrefetchErrorMeter.Mark(1)
+	case found:
+		privatePayloadPrefetchHitMeter.Mark(1)
+	default:
+		p.notParty.Add(hash, struct{}{})
+		privatePayloadPrefetchNotPartyMeter.Mark(1)
+	}
+}
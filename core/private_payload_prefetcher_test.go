@@ -0,0 +1,93 @@
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/private"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/golang/mock/gomock"
+)
+
+func TestPrivatePayloadPrefetcher_Prefetch(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	key, _ := crypto.GenerateKey()
+	innerTx := types.NewTransaction(1, common.Address{1}, common.Big0, uint64(3000000), common.Big0, encryptedPayloadHashForSetFunction.Bytes())
+	innerTx.SetPrivate()
+	signedInnerTx, _ := types.SignTx(innerTx, types.QuorumPrivateTxSigner{}, key)
+	jsonInnerTx, _ := signedInnerTx.MarshalJSON()
+	markerHash := common.BytesToEncryptedPayloadHash([]byte("marker"))
+	notPartyHash := common.BytesToEncryptedPayloadHash([]byte("not a party"))
+
+	mockptm := private.NewMockPrivateTransactionManager(mockCtrl)
+	mockptm.EXPECT().Receive(encryptedPayloadHashForContractDeployment).Return("", []string{}, contractCreateABIPayloadBytes, nil, nil).Times(1)
+	mockptm.EXPECT().Receive(markerHash).Return("", []string{}, jsonInnerTx, nil, nil).Times(1)
+	mockptm.EXPECT().Receive(encryptedPayloadHashForSetFunction).Return("", []string{}, contractSetABIPayloadBytes, nil, nil).Times(1)
+	mockptm.EXPECT().Receive(notPartyHash).Return("", nil, nil, nil, nil).Times(1)
+	saved := private.P
+	defer func() {
+		private.P = saved
+	}()
+	private.P = mockptm
+
+	privateTx := types.NewContractCreation(0, common.Big0, uint64(3000000), common.Big0, encryptedPayloadHashForContractDeployment.Bytes())
+	privateTx.SetPrivate()
+	notPartyTx := types.NewTransaction(1, common.Address{2}, common.Big0, uint64(3000000), common.Big0, notPartyHash.Bytes())
+	notPartyTx.SetPrivate()
+	markerTx := types.NewTransaction(2, common.QuorumPrivacyPrecompileContractAddress(), common.Big0, uint64(3000000), common.Big0, markerHash.Bytes())
+	publicTx := types.NewTransaction(3, common.Address{3}, common.Big0, uint64(3000000), common.Big0, nil)
+	block := types.NewBlock(&types.Header{Number: big.NewInt(1)}, []*types.Transaction{privateTx, notPartyTx, markerTx, publicTx}, nil, nil, trie.NewStackTrie(nil))
+
+	newPrivatePayloadPrefetcher(2).Prefetch(block, nil)
+}
+
+func TestPrivatePayloadPrefetcher_Prefetch_whenInterrupted(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	// no calls are expected on the private transaction manager
+	mockptm := private.NewMockPrivateTransactionManager(mockCtrl)
+	saved := private.P
+	defer func() {
+		private.P = saved
+	}()
+	private.P = mockptm
+
+	privateTx := types.NewContractCreation(0, common.Big0, uint64(3000000), common.Big0, encryptedPayloadHashForContractDeployment.Bytes())
+	privateTx.SetPrivate()
+	block := types.NewBlock(&types.Header{Number: big.NewInt(1)}, []*types.Transaction{privateTx}, nil, nil, trie.NewStackTrie(nil))
+	interrupt := uint32(1)
+
+	newPrivatePayloadPrefetcher(2).Prefetch(block, &interrupt)
+}
+
+func TestPrivatePayloadPrefetcher_Prefetch_whenNotParty(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	notPartyHash := common.BytesToEncryptedPayloadHash([]byte("not a party"))
+	notPartyMarkerHash := common.BytesToEncryptedPayloadHash([]byte("not a party marker"))
+	mockptm := private.NewMockPrivateTransactionManager(mockCtrl)
+	// the payloads this node is not a party to are only requested once
+	mockptm.EXPECT().Receive(notPartyHash).Return("", nil, nil, nil, nil).Times(1)
+	mockptm.EXPECT().Receive(notPartyMarkerHash).Return("", nil, nil, nil, nil).Times(1)
+	saved := private.P
+	defer func() {
+		private.P = saved
+	}()
+	private.P = mockptm
+
+	notPartyTx := types.NewTransaction(0, common.Address{2}, common.Big0, uint64(3000000), common.Big0, notPartyHash.Bytes())
+	notPartyTx.SetPrivate()
+	markerTx := types.NewTransaction(1, common.QuorumPrivacyPrecompileContractAddress(), common.Big0, uint64(3000000), common.Big0, notPartyMarkerHash.Bytes())
+	block := types.NewBlock(&types.Header{Number: big.NewInt(1)}, []*types.Transaction{notPartyTx, markerTx}, nil, nil, trie.NewStackTrie(nil))
+
+	prefetcher := newPrivatePayloadPrefetcher(2)
+	prefetcher.Prefetch(block, nil)
+	prefetcher.Prefetch(block, nil)
+}
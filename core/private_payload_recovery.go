@@ -0,0 +1,241 @@
+package core
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/mps"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/private"
+)
+
+// MissingPrivatePayload is a private transaction whose payload the private transaction
+// manager does not hold. The private transaction manager does not hold the payloads of the
+// transactions this node is not a party to, so a missing payload is only known to be lost
+// if the receipts of the block show that the node took part in the transaction, or once it
+// can be retrieved again, e.g. after it has been resent by another party.
+type MissingPrivatePayload struct {
+	BlockNumber uint64      `json:"blockNumber"`
+	BlockHash   common.Hash `json:"blockHash"`
+	// TxHash is the hash of the private transaction, or of the privacy marker transaction
+	// if the payload of the privacy marker transaction itself is missing
+	TxHash common.Hash `json:"txHash"`
+	// IsParty reports whether the receipts of the block show that the node took part in
+	// the transaction, i.e. that the payload was available when the block was imported.
+	// Without multiple private states this is only known for transactions which emitted logs
+	// or failed, or which are wrapped by a privacy marker transaction.
+	IsParty bool `json:"isParty"`
+	// ManagedParties are the parties managed by this node which took part in the transaction,
+	// as recorded by the receipts of the block
+	ManagedParties []string `json:"managedParties"`
+	// IsSender is nil if the private transaction manager cannot tell whether this node sent
+	// the transaction, which is the case when it has lost the payload, with the reason in SenderError
+	IsSender    *bool  `json:"isSender"`
+	SenderError string `json:"senderError,omitempty"`
+
+	tx *types.Transaction
+}
+
+// Available reports whether the private transaction manager holds the payload by now
+func (m *MissingPrivatePayload) Available() bool {
+	return hasPrivatePayload(m.tx)
+}
+
+// ScanMissingPrivatePayloads returns the private transactions, including the ones wrapped by
+// privacy marker transactions, from block first to block last whose payload the private
+// transaction manager cannot return. See MissingPrivatePayloadsAt.
+func (bc *BlockChain) ScanMissingPrivatePayloads(first, last uint64, managedParties []string) ([]*MissingPrivatePayload, error) {
+	var missing []*MissingPrivatePayload
+	for number := first; number <= last; number++ {
+		missingAt, err := bc.MissingPrivatePayloadsAt(number, managedParties)
+		if err != nil {
+			return nil, err
+		}
+		missing = append(missing, missingAt...)
+	}
+	return missing, nil
+}
+
+// MissingPrivatePayloadsAt returns the private transactions of the given block, including the
+// ones wrapped by privacy marker transactions, whose payload the private transaction manager
+// cannot return. Whether the node took part in each transaction is taken from the receipts of
+// the block, the private transaction manager not knowing the participants of a lost payload,
+// and the given managed parties are narrowed down to the private states which took part.
+func (bc *BlockChain) MissingPrivatePayloadsAt(number uint64, managedParties []string) ([]*MissingPrivatePayload, error) {
+	block := bc.GetBlockByNumber(number)
+	if block == nil {
+		return nil, fmt.Errorf("block #%d not found", number)
+	}
+	var (
+		missing  []*MissingPrivatePayload
+		receipts types.Receipts
+		isMPS    = bc.chainConfig.IsMPS
+	)
+	for i, tx := range block.Transactions() {
+		missingTx := missingPrivateTransaction(tx)
+		if missingTx == nil {
+			continue
+		}
+		if receipts == nil {
+			if receipts = bc.GetReceiptsByHash(block.Hash()); len(receipts) != len(block.Transactions()) {
+				return nil, fmt.Errorf("receipts of block #%d not found", number)
+			}
+		}
+		entry := &MissingPrivatePayload{
+			BlockNumber: number,
+			BlockHash:   block.Hash(),
+			TxHash:      missingTx.Hash(),
+			tx:          missingTx,
+		}
+		psis := partyPrivateStates(receipts, i, tx, isMPS)
+		entry.IsParty = len(psis) > 0
+		for _, party := range managedParties {
+			if !isMPS {
+				if entry.IsParty {
+					entry.ManagedParties = append(entry.ManagedParties, party)
+				}
+				continue
+			}
+			psm, err := bc.privateStateManager.ResolveForManagedParty(party)
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := psis[psm.ID]; ok {
+				entry.ManagedParties = append(entry.ManagedParties, party)
+			}
+		}
+		if isSender, err := private.P.IsSender(common.BytesToEncryptedPayloadHash(missingTx.Data())); err != nil {
+			entry.SenderError = err.Error()
+		} else {
+			entry.IsSender = &isSender
+		}
+		missing = append(missing, entry)
+	}
+	return missing, nil
+}
+
+// RebuildPrivateState re-executes the blocks from first to last, each on top of the stored
+// public state and the rebuilt private state of its parent, and replaces the private states
+// and private receipts stored for them. It is meant to recover the private state once lost
+// private payloads have been restored and must not run while blocks are being imported.
+//
+// The public state of the parent of the first block and of every block rebuilt must be
+// available, i.e. the node must run in archive mode unless only recent blocks are rebuilt.
+func (bc *BlockChain) RebuildPrivateState(first, last uint64, progress func(block *types.Block)) error {
+	if first == 0 {
+		first = 1 // the genesis block is not executed
+	}
+	for number := first; number <= last; number++ {
+		block := bc.GetBlockByNumber(number)
+		if block == nil {
+			return fmt.Errorf("block #%d not found", number)
+		}
+		parent := bc.GetHeader(block.ParentHash(), number-1)
+		if parent == nil {
+			return fmt.Errorf("parent of block #%d not found", number)
+		}
+		statedb, err := state.New(parent.Root, bc.stateCache, nil)
+		if err != nil {
+			return fmt.Errorf("public state of block #%d not available: %v", parent.Number, err)
+		}
+		privateStateRepo, err := bc.PrivateStateRepositoryWithoutSnapshots(parent.Root)
+		if err != nil {
+			return fmt.Errorf("private state of block #%d not available: %v", parent.Number, err)
+		}
+		receipts, privateReceipts, _, _, err := bc.processor.Process(block, statedb, privateStateRepo, bc.vmConfig)
+		if err != nil {
+			return fmt.Errorf("failed to re-execute block #%d: %v", number, err)
+		}
+		if err := bc.writeReexecutedState(block, statedb, privateStateRepo); err != nil {
+			return err
+		}
+		allReceipts := privateStateRepo.MergeReceipts(receipts, privateReceipts)
+		rawdb.WriteReceipts(bc.db, block.Hash(), number, allReceipts)
+		indexPrivateContracts(bc.db, block, allReceipts, privateStateRepo)
+		if err := rawdb.WritePrivateBlockBloom(bc.db, number, privateReceipts); err != nil {
+			return err
+		}
+		bc.receiptsCache.Remove(block.Hash())
+		if progress != nil {
+			progress(block)
+		}
+	}
+	return nil
+}
+
+// writeReexecutedState writes the private states of a re-executed block to disk, replacing
+// the ones stored for it
+func (bc *BlockChain) writeReexecutedState(block *types.Block, statedb *state.StateDB, privateStateRepo mps.PrivateStateRepository) error {
+	isEIP158 := bc.chainConfig.IsEIP158(block.Number())
+	// the public state is committed again, so that the private states referenced by it
+	// are written to disk together with it if the trie cache is shared
+	root, err := statedb.Commit(isEIP158)
+	if err != nil {
+		return err
+	}
+	if root != block.Root() {
+		return fmt.Errorf("public state of block #%d diverges: have %x, want %x", block.NumberU64(), root, block.Root())
+	}
+	if err := privateStateRepo.CommitAndWrite(isEIP158, block); err != nil {
+		return err
+	}
+	return bc.stateCache.TrieDB().Commit(root, false, nil)
+}
+
+// missingPrivateTransaction returns the transaction whose private payload the private
+// transaction manager does not hold: the transaction itself if it is a private
+// transaction, or the privacy marker transaction or one of its inner private transactions.
+// It returns nil if the payload is available or the transaction is not private.
+func missingPrivateTransaction(tx *types.Transaction) *types.Transaction {
+	switch {
+	case tx.IsPrivacyMarker():
+		privateTxs, _, _, err := private.FetchPrivateTransactions(tx.Data())
+		if err != nil || privateTxs == nil {
+			return tx
+		}
+		for _, privateTx := range privateTxs {
+			if !hasPrivatePayload(privateTx) {
+				return privateTx
+			}
+		}
+	case tx.IsPrivate():
+		if !hasPrivatePayload(tx) {
+			return tx
+		}
+	}
+	return nil
+}
+
+// partyPrivateStates returns the private states which took part in the private transaction, or
+// the privacy marker transaction, at the given index as recorded by the receipts of its block.
+// The receipt of a privacy marker transaction only holds private receipts if its inner
+// transactions were executed. Without multiple private states a private transaction the node
+// is not a party to is skipped, so it succeeds without logs; a party is then only recognised
+// by the logs or the failure of the transaction.
+func partyPrivateStates(receipts types.Receipts, index int, tx *types.Transaction, isMPS bool) map[types.PrivateStateIdentifier]struct{} {
+	receipt := receipts[index]
+	psis := make(map[types.PrivateStateIdentifier]struct{})
+	if isMPS || tx.IsPrivacyMarker() {
+		for psi := range receipt.PSReceipts {
+			if psi != mps.EmptyPrivateStateMetadata.ID {
+				psis[psi] = struct{}{}
+			}
+		}
+		return psis
+	}
+	if len(receipt.Logs) > 0 || receipt.Status == types.ReceiptStatusFailed {
+		psis[types.DefaultPrivateStateIdentifier] = struct{}{}
+	}
+	return psis
+}
+
+func hasPrivatePayload(tx *types.Transaction) bool {
+	if tx.IsPrivacyMarker() {
+		privateTxs, _, _, err := private.FetchPrivateTransactions(tx.Data())
+		return err == nil && privateTxs != nil
+	}
+	_, _, payload, _, err := private.P.Receive(common.BytesToEncryptedPayloadHash(tx.Data()))
+	return err == nil && payload != nil
+}
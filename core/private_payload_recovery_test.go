@@ -0,0 +1,149 @@
+package core
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/private"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// buildLossyMockPTM returns a private transaction manager which has lost the payload of the increments
+func buildLossyMockPTM(mockCtrl *gomock.Controller) *private.MockPrivateTransactionManager {
+	mockptm := private.NewMockPrivateTransactionManager(mockCtrl)
+	deployAccumulatorContractConstructor, _ := AccumulatorParsedABI.Pack("", big.NewInt(1))
+	deployAccumulatorContract := append(common.FromHex(AccumulatorBin), deployAccumulatorContractConstructor...)
+
+	mockptm.EXPECT().Receive(deployContract).Return("", []string{"AAA"}, deployAccumulatorContract, nil, nil).AnyTimes()
+	mockptm.EXPECT().Receive(incrementByOne).Return("", nil, nil, nil, nil).AnyTimes()
+	mockptm.EXPECT().IsSender(incrementByOne).Return(false, errors.New("not found")).AnyTimes()
+	mockptm.EXPECT().GetParticipants(incrementByOne).Return(nil, errors.New("not found")).AnyTimes()
+	return mockptm
+}
+
+func TestScanMissingPrivatePayloads_whenLostBeforeImport(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	saved := private.P
+	defer func() {
+		private.P = saved
+	}()
+	private.P = buildLossyMockPTM(mockCtrl)
+
+	blocks, _, blockchain := buildCacheProviderTestChain(3, params.QuorumTestChainConfig, nil)
+	_, err := blockchain.InsertChain(blocks)
+	require.NoError(t, err)
+
+	missing, err := blockchain.ScanMissingPrivatePayloads(1, 3, []string{"AAA"})
+
+	require.NoError(t, err)
+	require.Len(t, missing, 2)
+	for i, m := range missing {
+		block := blocks[i+1]
+		assert.Equal(t, block.NumberU64(), m.BlockNumber)
+		assert.Equal(t, block.Hash(), m.BlockHash)
+		assert.Equal(t, block.Transactions()[0].Hash(), m.TxHash)
+		assert.False(t, m.IsParty, "expected the transaction to have been executed as a non-party")
+		assert.Empty(t, m.ManagedParties)
+		assert.Nil(t, m.IsSender)
+		assert.Equal(t, "not found", m.SenderError)
+		assert.False(t, m.Available())
+	}
+
+	private.P = buildMockPTM(mockCtrl)
+	assert.True(t, missing[0].Available(), "expected the payload to be available once it has been resent")
+}
+
+func TestScanMissingPrivatePayloads_whenLostAfterImport(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	saved := private.P
+	defer func() {
+		private.P = saved
+	}()
+	private.P = buildMockPTM(mockCtrl)
+
+	blocks, _, blockchain := buildCacheProviderTestChain(3, params.QuorumTestChainConfig, nil)
+	_, err := blockchain.InsertChain(blocks)
+	require.NoError(t, err)
+
+	private.P = buildLossyMockPTM(mockCtrl)
+	missing, err := blockchain.ScanMissingPrivatePayloads(1, 3, []string{"AAA"})
+
+	require.NoError(t, err)
+	require.Len(t, missing, 2)
+	for _, m := range missing {
+		assert.True(t, m.IsParty, "expected the receipts to show the node took part in the transaction")
+		assert.Equal(t, []string{"AAA"}, m.ManagedParties)
+	}
+}
+
+func TestScanMissingPrivatePayloads_whenMPS(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	saved := private.P
+	defer func() {
+		private.P = saved
+	}()
+	private.P = buildMockMPSPTM(mockCtrl)
+
+	blocks, _, blockchain := buildCacheProviderMPSTestChain(3, params.QuorumMPSTestChainConfig, nil)
+	_, err := blockchain.InsertChain(blocks)
+	require.NoError(t, err)
+
+	// the payload of the transactions of PS1 only has been lost
+	lossyptm := private.NewMockPrivateTransactionManager(mockCtrl)
+	lossyptm.EXPECT().Receive(incrementByOnePS1).Return("", nil, nil, nil, nil).AnyTimes()
+	lossyptm.EXPECT().Receive(gomock.Not(incrementByOnePS1)).Return("", []string{"AAA"}, []byte{1}, nil, nil).AnyTimes()
+	lossyptm.EXPECT().IsSender(incrementByOnePS1).Return(false, errors.New("not found")).AnyTimes()
+	private.P = lossyptm
+	missing, err := blockchain.ScanMissingPrivatePayloads(1, 3, []string{"AAA", "BBB", "CCC", "DDD"})
+
+	require.NoError(t, err)
+	require.Len(t, missing, 1)
+	assert.Equal(t, blocks[1].Transactions()[0].Hash(), missing[0].TxHash)
+	assert.True(t, missing[0].IsParty)
+	assert.Equal(t, []string{"AAA", "BBB"}, missing[0].ManagedParties, "expected the parties of PS1 only")
+}
+
+func TestRebuildPrivateState(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	saved := private.P
+	defer func() {
+		private.P = saved
+	}()
+	private.P = buildLossyMockPTM(mockCtrl)
+
+	blocks, _, blockchain := buildCacheProviderTestChain(5, params.QuorumTestChainConfig, nil)
+	_, err := blockchain.InsertChain(blocks)
+	require.NoError(t, err)
+
+	// the lost payloads have been resent
+	private.P = buildMockPTM(mockCtrl)
+	divergence, err := blockchain.VerifyPrivateState(1, uint64(len(blocks)), nil)
+	require.NoError(t, err)
+	require.NotNil(t, divergence)
+	assert.Equal(t, uint64(2), divergence.Block.NumberU64())
+
+	err = blockchain.RebuildPrivateState(divergence.Block.NumberU64(), uint64(len(blocks)), nil)
+
+	require.NoError(t, err)
+	divergence, err = blockchain.VerifyPrivateState(1, uint64(len(blocks)), nil)
+	require.NoError(t, err)
+	assert.Nil(t, divergence)
+	_, privateStateRepo, err := blockchain.StateAt(blocks[len(blocks)-1].Root())
+	require.NoError(t, err)
+	privateState, err := privateStateRepo.DefaultState()
+	require.NoError(t, err)
+	assert.Equal(t, common.BytesToHash(big.NewInt(int64(len(blocks))).Bytes()), privateState.GetState(Contract1AddressAfterDeployment, common.Hash{}))
+}
@@ -0,0 +1,89 @@
+package core
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// ExportPrivateState writes the private state of the given PSI at the given block, including
+// the privacy metadata and managed parties of its accounts, and returns the number of accounts
+// written. The preimages of the trie keys must have been recorded (--cache.preimages), nothing is
+// written otherwise.
+func (bc *BlockChain) ExportPrivateState(psi types.PrivateStateIdentifier, number uint64, w io.Writer) (int, error) {
+	if !bc.cacheConfig.Preimages {
+		return 0, state.ErrMissingPreimages
+	}
+	block := bc.GetBlockByNumber(number)
+	if block == nil {
+		return 0, fmt.Errorf("block #%d not found", number)
+	}
+	_, privateState, err := bc.StateAtPSI(block.Root(), psi)
+	if err != nil {
+		return 0, fmt.Errorf("private state of psi %s at block #%d not available: %v", psi, number, err)
+	}
+	header := state.PrivateStateExportHeader{
+		PSI:         psi,
+		BlockNumber: number,
+		BlockHash:   block.Hash(),
+	}
+	return privateState.ExportPrivateState(header, w)
+}
+
+// ImportPrivateState seeds the private state of the given PSI with the accounts of an export
+// written by ExportPrivateState and returns the number of accounts imported. The PSI defaults
+// to the one the export was taken from. A PSI which does not exist yet is branched from the
+// empty private state, as it is when a private state is first used.
+//
+// The head block is re-executed on top of the state of its parent and the imported accounts
+// are applied to the resulting private state, which replaces the one stored for the head
+// block. Nothing is written if the accounts do not match the root of the export header. The
+// node must not be running.
+func (bc *BlockChain) ImportPrivateState(psi types.PrivateStateIdentifier, imp *state.PrivateStateImport) (int, error) {
+	if psi == "" {
+		psi = imp.Header.PSI
+	}
+	block := bc.CurrentBlock()
+	if !bc.isKnownPSI(psi, block.NumberU64()) {
+		log.Warn("Importing a private state which is not managed by this node", "psi", psi)
+	}
+	if block.NumberU64() == 0 {
+		return 0, fmt.Errorf("private state cannot be imported into the genesis block")
+	}
+	parent := bc.GetHeader(block.ParentHash(), block.NumberU64()-1)
+	if parent == nil {
+		return 0, fmt.Errorf("parent of block #%d not found", block.NumberU64())
+	}
+	statedb, err := state.New(parent.Root, bc.stateCache, nil)
+	if err != nil {
+		return 0, fmt.Errorf("public state of block #%d not available: %v", parent.Number, err)
+	}
+	privateStateRepo, err := bc.PrivateStateRepositoryWithoutSnapshots(parent.Root)
+	if err != nil {
+		return 0, fmt.Errorf("private state of block #%d not available: %v", parent.Number, err)
+	}
+	if _, _, _, _, err := bc.processor.Process(block, statedb, privateStateRepo, bc.vmConfig); err != nil {
+		return 0, fmt.Errorf("failed to re-execute block #%d: %v", block.NumberU64(), err)
+	}
+	privateState, err := privateStateRepo.StatePSI(psi)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open private state of psi %s: %v", psi, err)
+	}
+	count, err := privateState.ImportPrivateState(imp)
+	if err != nil {
+		return count, err
+	}
+	return count, bc.writeReexecutedState(block, statedb, privateStateRepo)
+}
+
+func (bc *BlockChain) isKnownPSI(psi types.PrivateStateIdentifier, number uint64) bool {
+	for _, known := range bc.verifiablePSIs(number) {
+		if known == psi {
+			return true
+		}
+	}
+	return false
+}
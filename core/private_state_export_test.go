@@ -0,0 +1,100 @@
+package core
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/private"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportImportPrivateState(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	saved := private.P
+	defer func() {
+		private.P = saved
+	}()
+	private.P = buildMockPTM(mockCtrl)
+
+	blocks, source := buildPrivateStateExportTestChain(t, 3)
+	_, err := source.InsertChain(blocks)
+	require.NoError(t, err)
+
+	var export bytes.Buffer
+	count, err := source.ExportPrivateState(types.DefaultPrivateStateIdentifier, 3, &export)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	// the target node has lost the payloads of the increments, so its accumulator is still at 1
+	private.P = buildLossyMockPTM(mockCtrl)
+	blocks, target := buildPrivateStateExportTestChain(t, 3)
+	_, err = target.InsertChain(blocks)
+	require.NoError(t, err)
+	imp, err := state.NewPrivateStateImport(&export)
+	require.NoError(t, err)
+
+	count, err = target.ImportPrivateState("", imp)
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+	_, privateState, err := target.StatePSI(types.DefaultPrivateStateIdentifier)
+	require.NoError(t, err)
+	assert.Equal(t, common.BytesToHash(big.NewInt(3).Bytes()), privateState.GetState(Contract1AddressAfterDeployment, common.Hash{}))
+	assert.Equal(t, imp.Header.Root, privateState.IntermediateRoot(true))
+}
+
+func TestExportPrivateState_whenPreimagesNotRecorded(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	saved := private.P
+	defer func() {
+		private.P = saved
+	}()
+	private.P = buildMockPTM(mockCtrl)
+
+	blocks, _, chain := buildCacheProviderTestChain(1, params.QuorumTestChainConfig, nil)
+	defer chain.Stop()
+	_, err := chain.InsertChain(blocks)
+	require.NoError(t, err)
+
+	var export bytes.Buffer
+	_, err = chain.ExportPrivateState(types.DefaultPrivateStateIdentifier, 1, &export)
+
+	assert.Equal(t, state.ErrMissingPreimages, err)
+	assert.Zero(t, export.Len())
+}
+
+// buildPrivateStateExportTestChain returns the blocks of buildCacheProviderTestChain and a chain
+// recording the preimages of the trie keys, which are needed to export private states
+func buildPrivateStateExportTestChain(t *testing.T, n int) ([]*types.Block, *BlockChain) {
+	blocks, _, generator := buildCacheProviderTestChain(n, params.QuorumTestChainConfig, nil)
+	generator.Stop()
+
+	db := rawdb.NewMemoryDatabase()
+	GenesisBlockForTesting(db, testAddress, big.NewInt(1000000000))
+	cacheConfig := &CacheConfig{
+		TrieCleanLimit: 256,
+		TrieDirtyLimit: 256,
+		TrieTimeLimit:  5 * time.Minute,
+		SnapshotWait:   true,
+		Preimages:      true,
+	}
+	chain, err := NewBlockChain(db, cacheConfig, params.QuorumTestChainConfig, ethash.NewFaker(), vm.Config{}, nil, nil, nil)
+	require.NoError(t, err)
+	t.Cleanup(chain.Stop)
+	return blocks, chain
+}
@@ -2,12 +2,15 @@ package core
 
 import (
 	"encoding/base64"
+	"errors"
 	"fmt"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/mps"
 	"github.com/ethereum/go-ethereum/core/privatecache"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/private"
 	"github.com/ethereum/go-ethereum/private/engine"
 )
@@ -27,32 +30,9 @@ func newPrivateStateManager(db ethdb.Database, privateCacheProvider privatecache
 		if err != nil {
 			return nil, err
 		}
-		residentGroupByKey := make(map[string]*mps.PrivateStateMetadata)
-		privacyGroupById := make(map[types.PrivateStateIdentifier]*mps.PrivateStateMetadata)
-		for _, group := range groups {
-			if group.Type == engine.PrivacyGroupResident {
-				// Resident group IDs come in base64 encoded, so revert to original ID
-				decoded, err := base64.StdEncoding.DecodeString(group.PrivacyGroupId)
-				if err != nil {
-					return nil, err
-				}
-				group.PrivacyGroupId = string(decoded)
-			}
-			psi := types.ToPrivateStateIdentifier(group.PrivacyGroupId)
-			existing, found := privacyGroupById[psi]
-			if found {
-				return nil, fmt.Errorf("privacy groups id clash id=%s existing.Name=%s duplicate.Name=%s", existing.ID, existing.Name, group.Name)
-			}
-			privacyGroupById[psi] = privacyGroupToPrivateStateMetadata(group)
-			if group.Type == engine.PrivacyGroupResident {
-				for _, address := range group.Members {
-					existing, found := residentGroupByKey[address]
-					if found {
-						return nil, fmt.Errorf("same address is part of two different groups: address=%s existing.Name=%s duplicate.Name=%s", address, existing.Name, group.Name)
-					}
-					residentGroupByKey[address] = privacyGroupToPrivateStateMetadata(group)
-				}
-			}
+		residentGroupByKey, privacyGroupById, err := privateStateMetadataFromGroups(groups)
+		if err != nil {
+			return nil, err
 		}
 		return newMultiplePrivateStateManager(db, privateCacheProvider, residentGroupByKey, privacyGroupById)
 	} else {
@@ -60,6 +40,55 @@ func newPrivateStateManager(db ethdb.Database, privateCacheProvider privatecache
 	}
 }
 
+// newPrivateStateManagerWithSnapshots is newPrivateStateManager with the private states read
+// from, and maintaining, their snapshot trees of the given snapshots
+func newPrivateStateManagerWithSnapshots(db ethdb.Database, privateCacheProvider privatecache.Provider, snapshots *mps.PrivateStateSnapshots, isMPS bool) (mps.PrivateStateManager, error) {
+	psm, err := newPrivateStateManager(db, privateCacheProvider, isMPS)
+	if err != nil {
+		return nil, err
+	}
+	switch psm := psm.(type) {
+	case *DefaultPrivateStateManager:
+		psm.snapshots = snapshots
+	case *MultiplePrivateStateManager:
+		psm.snapshots = snapshots
+	}
+	return psm, nil
+}
+
+// privateStateMetadataFromGroups returns the private states of the privacy groups of the private
+// transaction manager, by private state identifier and by the address of their resident members
+func privateStateMetadataFromGroups(groups []engine.PrivacyGroup) (map[string]*mps.PrivateStateMetadata, map[types.PrivateStateIdentifier]*mps.PrivateStateMetadata, error) {
+	residentGroupByKey := make(map[string]*mps.PrivateStateMetadata)
+	privacyGroupById := make(map[types.PrivateStateIdentifier]*mps.PrivateStateMetadata)
+	for _, group := range groups {
+		if group.Type == engine.PrivacyGroupResident {
+			// Resident group IDs come in base64 encoded, so revert to original ID
+			decoded, err := base64.StdEncoding.DecodeString(group.PrivacyGroupId)
+			if err != nil {
+				return nil, nil, err
+			}
+			group.PrivacyGroupId = string(decoded)
+		}
+		psi := types.ToPrivateStateIdentifier(group.PrivacyGroupId)
+		existing, found := privacyGroupById[psi]
+		if found {
+			return nil, nil, fmt.Errorf("privacy groups id clash id=%s existing.Name=%s duplicate.Name=%s", existing.ID, existing.Name, group.Name)
+		}
+		privacyGroupById[psi] = privacyGroupToPrivateStateMetadata(group)
+		if group.Type == engine.PrivacyGroupResident {
+			for _, address := range group.Members {
+				existing, found := residentGroupByKey[address]
+				if found {
+					return nil, nil, fmt.Errorf("same address is part of two different groups: address=%s existing.Name=%s duplicate.Name=%s", address, existing.Name, group.Name)
+				}
+				residentGroupByKey[address] = privacyGroupToPrivateStateMetadata(group)
+			}
+		}
+	}
+	return residentGroupByKey, privacyGroupById, nil
+}
+
 func privacyGroupToPrivateStateMetadata(group engine.PrivacyGroup) *mps.PrivateStateMetadata {
 	return mps.NewPrivateStateMetadata(
 		types.ToPrivateStateIdentifier(group.PrivacyGroupId),
@@ -80,3 +109,86 @@ func strTypeToPrivateStateType(strType string) mps.PrivateStateType {
 		return mps.Resident
 	}
 }
+
+// PrivateStateRepositoryWithoutSnapshots returns the private state repository of the block for
+// re-executing blocks which are not imported, e.g. by the tracers or the private state verifier.
+// Its private states neither read from nor update the snapshot trees of the private states, which
+// only follow the imported blocks.
+func (bc *BlockChain) PrivateStateRepositoryWithoutSnapshots(blockHash common.Hash) (mps.PrivateStateRepository, error) {
+	switch psm := bc.privateStateManager.(type) {
+	case *DefaultPrivateStateManager:
+		return psm.stateRepository(blockHash, nil)
+	case *MultiplePrivateStateManager:
+		return psm.stateRepository(blockHash, nil)
+	}
+	return bc.privateStateManager.StateRepository(blockHash)
+}
+
+// loadPrivateSnapshots opens the snapshot trees of the private states of the head block,
+// so that the journaled snapshots are used instead of being regenerated on the first commit
+func (bc *BlockChain) loadPrivateSnapshots(head *types.Block) {
+	if bc.privateSnaps == nil {
+		return
+	}
+	privateStateRepo, err := bc.privateStateManager.StateRepository(head.Root())
+	if err != nil {
+		log.Warn("Failed to load private state snapshots", "number", head.NumberU64(), "err", err)
+		return
+	}
+	for _, psi := range bc.verifiablePSIs(head.NumberU64()) {
+		if root, err := privateStateRepo.PrivateStateRoot(psi); err == nil && root != (common.Hash{}) {
+			bc.privateSnaps.Load(psi, root)
+		}
+	}
+}
+
+// journalPrivateSnapshots writes the snapshot trees of the private states to disk, with the
+// private states of the head block as the heads of the trees
+func (bc *BlockChain) journalPrivateSnapshots(head *types.Block) {
+	if bc.privateSnaps == nil {
+		return
+	}
+	privateStateRepo, err := bc.privateStateManager.StateRepository(head.Root())
+	if err != nil {
+		log.Error("Failed to journal private state snapshots", "number", head.NumberU64(), "err", err)
+		return
+	}
+	bc.privateSnaps.Journal(privateStateRepo.PrivateStateRoot)
+}
+
+// RefreshPrivateStates reloads the private states from the privacy groups of the private
+// transaction manager, so that tenants can be onboarded without restarting the node. The
+// private states which were added are returned, their state starting from the given block -
+// the block after the head block by default - as a branch of the empty state.
+//
+// Private states and resident members cannot be removed, as this would orphan their state.
+func (bc *BlockChain) RefreshPrivateStates(startBlock *uint64) ([]types.PrivateStateIdentifier, error) {
+	psm, ok := bc.privateStateManager.(*MultiplePrivateStateManager)
+	if !ok {
+		return nil, errors.New("private states can only be refreshed if multiple private states are enabled")
+	}
+	groups, err := private.P.Groups()
+	if err != nil {
+		return nil, err
+	}
+	// no block is inserted while the private states change
+	bc.chainmu.Lock()
+	defer bc.chainmu.Unlock()
+
+	head := bc.CurrentBlock().NumberU64()
+	start := head + 1
+	if startBlock != nil {
+		if *startBlock <= head {
+			return nil, fmt.Errorf("start block %d of the new private states must be after the head block %d", *startBlock, head)
+		}
+		start = *startBlock
+	}
+	added, err := psm.Refresh(groups, start)
+	if err != nil {
+		return nil, err
+	}
+	for _, psi := range added {
+		log.Info("Added private state", "psi", psi, "start", start)
+	}
+	return added, nil
+}
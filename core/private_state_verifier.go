@@ -0,0 +1,243 @@
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/mps"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+// PrivateStateDivergence describes a block whose re-executed private state does not
+// match the private state stored for it.
+type PrivateStateDivergence struct {
+	Block    *types.Block
+	PSI      types.PrivateStateIdentifier
+	Expected common.Hash // private state root stored for the block
+	Actual   common.Hash // private state root after re-executing the block
+
+	// Tx is the first transaction whose re-executed receipt differs from the stored
+	// one, nil if the receipts do not tell which transaction caused the divergence
+	Tx *types.Transaction
+
+	// MissingPayloads are the private transactions of the block whose payload the
+	// private transaction manager does not hold. This is expected for transactions
+	// this node is not a party to, but a payload lost by the private transaction
+	// manager makes the re-executed private state diverge.
+	MissingPayloads []common.Hash
+}
+
+// VerifyPrivateState re-executes the blocks from first to last, starting from the stored state
+// of the parent of the first block and carrying the re-executed states forward, and compares
+// the resulting private state roots - one per private state under MPS - with the ones stored
+// for the block. It returns the first divergence found or nil if all private states match.
+// Nothing is written to the database.
+//
+// Only the public and private states of the parent of the first block must be available, so
+// a node which does not run in archive mode can verify the chain from any block whose state
+// it still holds, e.g. from the genesis block. The stored private state roots are looked up
+// without opening the private states. The progress callback, if any, is invoked after each
+// verified block.
+func (bc *BlockChain) VerifyPrivateState(first, last uint64, progress func(block *types.Block)) (*PrivateStateDivergence, error) {
+	if first == 0 {
+		first = 1 // the genesis block is not executed
+	}
+	parent := bc.GetHeaderByNumber(first - 1)
+	if parent == nil {
+		return nil, fmt.Errorf("block #%d not found", first-1)
+	}
+	// an ephemeral trie database keeps the re-executed public states out of the live one
+	database := state.NewDatabaseWithConfig(bc.db, &trie.Config{Cache: 16})
+	statedb, err := state.New(parent.Root, database, nil)
+	if err != nil {
+		return nil, fmt.Errorf("public state of block #%d not available: %v", parent.Number, err)
+	}
+	privateStateRepo, err := bc.PrivateStateRepositoryWithoutSnapshots(parent.Root)
+	if err != nil {
+		return nil, fmt.Errorf("private state of block #%d not available: %v", parent.Number, err)
+	}
+	var parentRoot common.Hash
+	for number := first; number <= last; number++ {
+		block := bc.GetBlockByNumber(number)
+		if block == nil {
+			return nil, fmt.Errorf("block #%d not found", number)
+		}
+		receipts, privateReceipts, _, _, err := bc.processor.Process(block, statedb, privateStateRepo, bc.vmConfig)
+		if err != nil {
+			return nil, fmt.Errorf("failed to re-execute block #%d: %v", number, err)
+		}
+		isEIP158 := bc.chainConfig.IsEIP158(block.Number())
+		psis := bc.verifiablePSIs(number)
+		expectedRoots, err := bc.storedPrivateStateRoots(block, psis)
+		if err != nil {
+			return nil, err
+		}
+		for _, psi := range psis {
+			privateState, err := privateStateRepo.StatePSI(psi)
+			if err != nil {
+				return nil, fmt.Errorf("failed to open private state of psi %s at block #%d: %v", psi, number, err)
+			}
+			if actual := privateState.IntermediateRoot(isEIP158); actual != expectedRoots[psi] {
+				storedReceipts := bc.GetReceiptsByHash(block.Hash())
+				return &PrivateStateDivergence{
+					Block:           block,
+					PSI:             psi,
+					Expected:        expectedRoots[psi],
+					Actual:          actual,
+					Tx:              firstDivergentTransaction(block, privateStateRepo.MergeReceipts(receipts, privateReceipts), storedReceipts, psi),
+					MissingPayloads: missingPrivatePayloads(block, storedReceipts, psi, privateStateRepo.IsMPS()),
+				}, nil
+			}
+		}
+
+		// carry the re-executed states forward to the next block
+		root, err := statedb.Commit(isEIP158)
+		if err != nil {
+			return nil, fmt.Errorf("failed to commit public state of block #%d: %v", number, err)
+		}
+		if root != block.Root() {
+			return nil, fmt.Errorf("public state of block #%d diverges: stored root %x, recomputed root %x", number, block.Root(), root)
+		}
+		if statedb, err = state.New(root, database, nil); err != nil {
+			return nil, fmt.Errorf("public state reset after block #%d failed: %v", number, err)
+		}
+		database.TrieDB().Reference(root, common.Hash{})
+		if parentRoot != (common.Hash{}) {
+			database.TrieDB().Dereference(parentRoot)
+		}
+		parentRoot = root
+		if err := privateStateRepo.Commit(isEIP158, block); err != nil {
+			return nil, fmt.Errorf("failed to commit private state of block #%d: %v", number, err)
+		}
+		if err := privateStateRepo.Reset(); err != nil {
+			return nil, fmt.Errorf("private state reset after block #%d failed: %v", number, err)
+		}
+		if progress != nil {
+			progress(block)
+		}
+	}
+	return nil, nil
+}
+
+// storedPrivateStateRoots returns the private state roots stored for the given block. Unlike
+// opening its private state repository this does not need the private state of the block.
+func (bc *BlockChain) storedPrivateStateRoots(block *types.Block, psis []types.PrivateStateIdentifier) (map[types.PrivateStateIdentifier]common.Hash, error) {
+	roots := make(map[types.PrivateStateIdentifier]common.Hash, len(psis))
+	if !bc.chainConfig.IsMPS {
+		for _, psi := range psis {
+			roots[psi] = rawdb.GetPrivateStateRoot(bc.db, block.Root())
+		}
+		return roots, nil
+	}
+	// with multiple private states only the trie of private state roots is opened
+	storedRepo, err := bc.privateStateManager.StateRepository(block.Root())
+	if err != nil {
+		return nil, fmt.Errorf("private state roots of block #%d not available: %v", block.NumberU64(), err)
+	}
+	for _, psi := range psis {
+		root, err := storedRepo.PrivateStateRoot(psi)
+		if err != nil {
+			return nil, fmt.Errorf("private state root of psi %s at block #%d not available: %v", psi, block.NumberU64(), err)
+		}
+		roots[psi] = root
+	}
+	return roots, nil
+}
+
+// verifiablePSIs returns the private states of the given block to verify, in a stable order
+func (bc *BlockChain) verifiablePSIs(number uint64) []types.PrivateStateIdentifier {
+	psis := PrivateStatesAt(bc.privateStateManager, number)
+	if bc.chainConfig.IsMPS {
+		found := false
+		for _, psi := range psis {
+			found = found || psi == mps.EmptyPrivateStateMetadata.ID
+		}
+		if !found {
+			psis = append(psis, mps.EmptyPrivateStateMetadata.ID)
+		}
+	}
+	sort.Slice(psis, func(i, j int) bool { return psis[i] < psis[j] })
+	return psis
+}
+
+// PrivateStatesAt returns the private states which exist at the given block, which are all
+// private states unless private states were added to the running node
+func PrivateStatesAt(psm mps.PrivateStateMetadataResolver, number uint64) []types.PrivateStateIdentifier {
+	if psmAt, ok := psm.(interface {
+		PSIsAt(number uint64) []types.PrivateStateIdentifier
+	}); ok {
+		return psmAt.PSIsAt(number)
+	}
+	return psm.PSIs()
+}
+
+// firstDivergentTransaction returns the first private transaction whose receipt for the
+// private state differs between re-execution and the database
+func firstDivergentTransaction(block *types.Block, actual, expected types.Receipts, psi types.PrivateStateIdentifier) *types.Transaction {
+	if len(actual) != len(expected) {
+		return nil
+	}
+	for i, tx := range block.Transactions() {
+		if !tx.IsPrivate() && !tx.IsPrivacyMarker() {
+			continue
+		}
+		a, e := receiptForPSI(actual[i], psi), receiptForPSI(expected[i], psi)
+		if a.Status != e.Status || a.Bloom != e.Bloom || a.ContractAddress != e.ContractAddress || !equalLogs(a.Logs, e.Logs) {
+			return tx
+		}
+	}
+	return nil
+}
+
+// equalLogs reports whether both receipts logged the same events
+func equalLogs(a, b []*types.Log) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Address != b[i].Address || !bytes.Equal(a[i].Data, b[i].Data) || len(a[i].Topics) != len(b[i].Topics) {
+			return false
+		}
+		for j := range a[i].Topics {
+			if a[i].Topics[j] != b[i].Topics[j] {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func receiptForPSI(receipt *types.Receipt, psi types.PrivateStateIdentifier) *types.Receipt {
+	if psReceipt, ok := receipt.PSReceipts[psi]; ok {
+		return psReceipt
+	}
+	return receipt
+}
+
+// missingPrivatePayloads returns the hashes of the private transactions of the block the given
+// private state took part in, according to the stored receipts, whose payload the private
+// transaction manager does not hold. The payloads of the other private transactions are not
+// expected to be held.
+func missingPrivatePayloads(block *types.Block, receipts types.Receipts, psi types.PrivateStateIdentifier, isMPS bool) []common.Hash {
+	if len(receipts) != len(block.Transactions()) {
+		return nil
+	}
+	var missing []common.Hash
+	for i, tx := range block.Transactions() {
+		if !tx.IsPrivate() && !tx.IsPrivacyMarker() {
+			continue
+		}
+		if _, party := partyPrivateStates(receipts, i, tx, isMPS)[psi]; !party {
+			continue
+		}
+		if missingTx := missingPrivateTransaction(tx); missingTx != nil {
+			missing = append(missing, missingTx.Hash())
+		}
+	}
+	return missing
+}
@@ -0,0 +1,143 @@
+package core
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/private"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyPrivateState_whenTypical(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	saved := private.P
+	defer func() {
+		private.P = saved
+	}()
+	private.P = buildMockPTM(mockCtrl)
+
+	blocks, _, blockchain := buildCacheProviderTestChain(5, params.QuorumTestChainConfig, nil)
+	_, err := blockchain.InsertChain(blocks)
+	require.NoError(t, err)
+
+	var verified []uint64
+	divergence, err := blockchain.VerifyPrivateState(0, uint64(len(blocks)), func(block *types.Block) {
+		verified = append(verified, block.NumberU64())
+	})
+
+	require.NoError(t, err)
+	assert.Nil(t, divergence)
+	assert.Equal(t, []uint64{1, 2, 3, 4, 5}, verified)
+}
+
+func TestVerifyPrivateState_whenPayloadIsMissing(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	saved := private.P
+	defer func() {
+		private.P = saved
+	}()
+	private.P = buildMockPTM(mockCtrl)
+
+	blocks, _, blockchain := buildCacheProviderTestChain(5, params.QuorumTestChainConfig, nil)
+	_, err := blockchain.InsertChain(blocks)
+	require.NoError(t, err)
+
+	// the private transaction manager has lost the payload of the increments
+	lossyPTM := private.NewMockPrivateTransactionManager(mockCtrl)
+	deployAccumulatorContractConstructor, _ := AccumulatorParsedABI.Pack("", big.NewInt(1))
+	deployAccumulatorContract := append(common.FromHex(AccumulatorBin), deployAccumulatorContractConstructor...)
+	lossyPTM.EXPECT().Receive(deployContract).Return("", []string{"AAA"}, deployAccumulatorContract, nil, nil).AnyTimes()
+	lossyPTM.EXPECT().Receive(incrementByOne).Return("", nil, nil, nil, nil).AnyTimes()
+	private.P = lossyPTM
+
+	divergence, err := blockchain.VerifyPrivateState(1, uint64(len(blocks)), nil)
+
+	require.NoError(t, err)
+	require.NotNil(t, divergence)
+	assert.Equal(t, uint64(2), divergence.Block.NumberU64())
+	assert.Equal(t, types.DefaultPrivateStateIdentifier, divergence.PSI)
+	assert.NotEqual(t, divergence.Expected, divergence.Actual)
+	assert.Equal(t, []common.Hash{blocks[1].Transactions()[0].Hash()}, divergence.MissingPayloads)
+}
+
+func TestVerifyPrivateState_whenIntermediateStatesAreNotPersisted(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	saved := private.P
+	defer func() {
+		private.P = saved
+	}()
+	private.P = buildMockPTM(mockCtrl)
+
+	blocks, _, blockchain := buildCacheProviderTestChain(5, params.QuorumTestChainConfig, nil)
+	_, err := blockchain.InsertChain(blocks)
+	require.NoError(t, err)
+
+	// the node does not run in archive mode, so the states of the intermediate blocks are
+	// gone once it has been restarted
+	blockchain.Stop()
+	blockchain, err = NewBlockChain(blockchain.db, &CacheConfig{TrieCleanLimit: 256, TrieDirtyLimit: 256, TrieTimeLimit: 5 * time.Minute, SnapshotWait: true}, params.QuorumTestChainConfig, ethash.NewFaker(), vm.Config{}, nil, nil, nil)
+	require.NoError(t, err)
+	defer blockchain.Stop()
+	_, err = state.New(blocks[2].Root(), state.NewDatabase(blockchain.db), nil)
+	require.Error(t, err)
+
+	divergence, err := blockchain.VerifyPrivateState(1, uint64(len(blocks)), nil)
+
+	require.NoError(t, err)
+	assert.Nil(t, divergence)
+}
+
+func TestFirstDivergentTransaction_whenLogsDiffer(t *testing.T) {
+	tx, err := types.SignTx(types.NewTransaction(0, Contract1AddressAfterDeployment, big.NewInt(0), testGas, nil, incrementByOne.Bytes()), types.QuorumPrivateTxSigner{}, testKey)
+	require.NoError(t, err)
+	block := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(1)}).WithBody([]*types.Transaction{tx}, nil)
+	receipt := func(data byte) types.Receipts {
+		return types.Receipts{{Status: types.ReceiptStatusSuccessful, Logs: []*types.Log{{Address: Contract1AddressAfterDeployment, Data: []byte{data}}}}}
+	}
+
+	assert.Nil(t, firstDivergentTransaction(block, receipt(1), receipt(1), types.DefaultPrivateStateIdentifier))
+	assert.Equal(t, tx, firstDivergentTransaction(block, receipt(2), receipt(1), types.DefaultPrivateStateIdentifier))
+}
+
+func TestMissingPrivatePayloads_whenNotParty(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	saved := private.P
+	defer func() {
+		private.P = saved
+	}()
+	mockptm := private.NewMockPrivateTransactionManager(mockCtrl)
+	mockptm.EXPECT().Receive(gomock.Any()).Return("", nil, nil, nil, nil).AnyTimes()
+	private.P = mockptm
+
+	signer := types.QuorumPrivateTxSigner{}
+	partyTx, err := types.SignTx(types.NewTransaction(0, Contract1AddressAfterDeployment, big.NewInt(0), testGas, nil, incrementByOne.Bytes()), signer, testKey)
+	require.NoError(t, err)
+	nonPartyTx, err := types.SignTx(types.NewTransaction(1, Contract1AddressAfterDeployment, big.NewInt(0), testGas, nil, deployContract.Bytes()), signer, testKey)
+	require.NoError(t, err)
+	block := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(1)}).WithBody([]*types.Transaction{partyTx, nonPartyTx}, nil)
+	receipts := types.Receipts{
+		{Status: types.ReceiptStatusSuccessful, Logs: []*types.Log{{Address: Contract1AddressAfterDeployment}}},
+		{Status: types.ReceiptStatusSuccessful},
+	}
+
+	missing := missingPrivatePayloads(block, receipts, types.DefaultPrivateStateIdentifier, false)
+
+	assert.Equal(t, []common.Hash{partyTx.Hash()}, missing)
+}
@@ -19,9 +19,16 @@
 package rawdb
 
 import (
+	"bytes"
+	"encoding/binary"
+
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/private/engine"
+	"github.com/ethereum/go-ethereum/rlp"
 )
 
 var (
@@ -33,6 +40,17 @@ var (
 	// we introduce a generic approach to store extra data for an account. PrivacyMetadata is wrapped.
 	// However, this value is kept as-is to support backward compatibility
 	stateRootToExtraDataRootPrefix = []byte("PSR2PMDR")
+	// privateSnapshotPrefix + hash(psi) is the namespace of the snapshot of a private state
+	privateSnapshotPrefix = []byte("PSnap")
+	// privateBloomBitsPrefix + hash(psi) is the namespace of the bloom bits index of the private logs
+	// of a private state, including the progress of its chain indexer
+	privateBloomBitsPrefix = []byte("PBloom")
+	// privateStateStartPrefix + psi -> number of the first block of a private state added to a running node
+	privateStateStartPrefix = []byte("quorumPSIStart")
+	// privateContractPrefix + hash(psi) + num (uint64 big endian) + hash + address -> creation of a private contract
+	privateContractPrefix = []byte("PContract")
+	// privateBatchTxLookupPrefix + hash -> hash of the privacy marker transaction carrying the batched private transaction
+	privateBatchTxLookupPrefix = []byte("PBatchTx")
 	// emptyRoot is the known root hash of an empty trie. Duplicate from `trie/trie.go#emptyRoot`
 	emptyRoot = common.HexToHash("56e81f171bcc55a6ff8345e692c0f86e5b48e01b996cadc001622fb5e363b421")
 )
@@ -49,6 +67,96 @@ func WriteQuorumEIP155Activation(db ethdb.KeyValueWriter) error {
 	return db.Put(quorumEIP155ActivatedPrefix, []byte{1})
 }
 
+// ReadPrivateStateStartBlock returns the number of the first block of the private state if it
+// was added to a running node, false if the private state exists from genesis
+func ReadPrivateStateStartBlock(db ethdb.KeyValueReader, psi types.PrivateStateIdentifier) (uint64, bool) {
+	data, _ := db.Get(append(privateStateStartPrefix, psi...))
+	if len(data) != 8 {
+		return 0, false
+	}
+	return binary.BigEndian.Uint64(data), true
+}
+
+// WritePrivateStateStartBlock stores the number of the first block of a private state added to a
+// running node
+func WritePrivateStateStartBlock(db ethdb.KeyValueWriter, psi types.PrivateStateIdentifier, number uint64) error {
+	return db.Put(append(privateStateStartPrefix, psi...), encodeBlockNumber(number))
+}
+
+// ReadPrivateBatchTxLookup returns the hash of the privacy marker transaction carrying the batched
+// private transaction with the given hash, false if unknown
+func ReadPrivateBatchTxLookup(db ethdb.KeyValueReader, txHash common.Hash) (common.Hash, bool) {
+	data, _ := db.Get(append(privateBatchTxLookupPrefix, txHash.Bytes()...))
+	if len(data) != common.HashLength {
+		return common.Hash{}, false
+	}
+	return common.BytesToHash(data), true
+}
+
+// WritePrivateBatchTxLookup stores the hash of the privacy marker transaction carrying the batched
+// private transaction with the given hash
+func WritePrivateBatchTxLookup(db ethdb.KeyValueWriter, txHash, pmtHash common.Hash) {
+	if err := db.Put(append(privateBatchTxLookupPrefix, txHash.Bytes()...), pmtHash.Bytes()); err != nil {
+		log.Crit("Failed to store batched private transaction lookup", "err", err)
+	}
+}
+
+// PrivateContractEntry is the record of the creation of a private contract in a private state
+type PrivateContractEntry struct {
+	Address             common.Address
+	BlockNumber         uint64
+	BlockHash           common.Hash
+	TxHash              common.Hash                 // the private transaction which created the contract
+	PrivacyMarkerTxHash common.Hash                 // the privacy marker transaction carrying the private transaction, if any
+	CreationTxHash      common.EncryptedPayloadHash // the hash of the private payload of the private transaction
+	PrivacyFlag         engine.PrivacyFlagType
+}
+
+func privateContractsKeyPrefix(psi types.PrivateStateIdentifier) []byte {
+	return append(common.CopyBytes(privateContractPrefix), crypto.Keccak256([]byte(psi))...)
+}
+
+// WritePrivateContractEntry stores the creation of a private contract in the private state
+func WritePrivateContractEntry(db ethdb.KeyValueWriter, psi types.PrivateStateIdentifier, entry *PrivateContractEntry) {
+	data, err := rlp.EncodeToBytes(entry)
+	if err != nil {
+		log.Crit("Failed to encode private contract entry", "err", err)
+	}
+	key := append(append(append(privateContractsKeyPrefix(psi), encodeBlockNumber(entry.BlockNumber)...), entry.BlockHash.Bytes()...), entry.Address.Bytes()...)
+	if err := db.Put(key, data); err != nil {
+		log.Crit("Failed to store private contract entry", "err", err)
+	}
+}
+
+// ReadPrivateContractEntries returns the creations of private contracts in the private state from
+// block from to block to included, in the order of the blocks. The entries of the blocks are read
+// until at least limit entries are found, so that the entries of a block number are never split.
+// The entries of all the blocks imported at a number are returned, canonical or not.
+func ReadPrivateContractEntries(db ethdb.Iteratee, psi types.PrivateStateIdentifier, from, to uint64, limit int) []*PrivateContractEntry {
+	prefix := privateContractsKeyPrefix(psi)
+	it := db.NewIterator(prefix, encodeBlockNumber(from))
+	defer it.Release()
+
+	var entries []*PrivateContractEntry
+	for it.Next() {
+		key := it.Key()
+		if len(key) != len(prefix)+8+common.HashLength+common.AddressLength {
+			continue
+		}
+		number := binary.BigEndian.Uint64(key[len(prefix):])
+		if number > to || (len(entries) >= limit && number != entries[len(entries)-1].BlockNumber) {
+			break
+		}
+		entry := new(PrivateContractEntry)
+		if err := rlp.DecodeBytes(it.Value(), entry); err != nil {
+			log.Error("Invalid private contract entry RLP", "psi", psi, "number", number, "err", err)
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
 func GetPrivateStateRoot(db ethdb.Database, blockRoot common.Hash) common.Hash {
 	root, _ := db.Get(append(privateRootPrefix, blockRoot[:]...))
 	return common.BytesToHash(root)
@@ -78,6 +186,64 @@ func WriteRootHashMapping(db ethdb.KeyValueWriter, stateRoot, extraDataRoot comm
 	return db.Put(append(stateRootToExtraDataRootPrefix, stateRoot[:]...), extraDataRoot[:])
 }
 
+// DeletePrivateStateRoot removes the mapping between the root hash of the public state of a
+// block and the root hash of its private state
+func DeletePrivateStateRoot(db ethdb.KeyValueWriter, blockRoot common.Hash) error {
+	return db.Delete(append(privateRootPrefix, blockRoot[:]...))
+}
+
+// DeletePrivateStatesTrieRoot removes the mapping between the root hash of the public state
+// of a block and the root hash of its trie of private states
+func DeletePrivateStatesTrieRoot(db ethdb.KeyValueWriter, blockRoot common.Hash) error {
+	return db.Delete(append(privateStatesTrieRootPrefix, blockRoot[:]...))
+}
+
+// DeleteRootHashMapping removes the mapping between root hash of state trie and
+// root hash of state.AccountExtraData trie
+func DeleteRootHashMapping(db ethdb.KeyValueWriter, stateRoot common.Hash) error {
+	return db.Delete(append(stateRootToExtraDataRootPrefix, stateRoot[:]...))
+}
+
+// IsPrivateStateRootKey reports whether the given byte slice is the key of a mapping to the root
+// hash of a private state, and returns the root hash of the public state it is mapped from
+func IsPrivateStateRootKey(key []byte) (bool, common.Hash) {
+	if bytes.HasPrefix(key, privateRootPrefix) && len(key) == common.HashLength+len(privateRootPrefix) {
+		return true, common.BytesToHash(key[len(privateRootPrefix):])
+	}
+	return false, common.Hash{}
+}
+
+// IsPrivateStatesTrieRootKey reports whether the given byte slice is the key of a mapping to the
+// root hash of a trie of private states, and returns the root hash of the public state it is
+// mapped from
+func IsPrivateStatesTrieRootKey(key []byte) (bool, common.Hash) {
+	if bytes.HasPrefix(key, privateStatesTrieRootPrefix) && len(key) == common.HashLength+len(privateStatesTrieRootPrefix) {
+		return true, common.BytesToHash(key[len(privateStatesTrieRootPrefix):])
+	}
+	return false, common.Hash{}
+}
+
+// IsRootHashMappingKey reports whether the given byte slice is the key of a mapping to the root
+// hash of a state.AccountExtraData trie, and returns the root hash of the state it is mapped from
+func IsRootHashMappingKey(key []byte) (bool, common.Hash) {
+	if bytes.HasPrefix(key, stateRootToExtraDataRootPrefix) && len(key) == common.HashLength+len(stateRootToExtraDataRootPrefix) {
+		return true, common.BytesToHash(key[len(stateRootToExtraDataRootPrefix):])
+	}
+	return false, common.Hash{}
+}
+
+// NewPrivateSnapshotTable returns a database which stores the snapshot of the private state
+// with the given identifier apart from the snapshot of the public state
+func NewPrivateSnapshotTable(db ethdb.Database, psi types.PrivateStateIdentifier) ethdb.Database {
+	return NewTable(db, string(privateSnapshotPrefix)+string(crypto.Keccak256([]byte(psi))))
+}
+
+// NewPrivateBloomBitsTable returns a database which stores the bloom bits index of the private
+// logs of the private state with the given identifier apart from the index of the public logs
+func NewPrivateBloomBitsTable(db ethdb.Database, psi types.PrivateStateIdentifier) ethdb.Database {
+	return NewTable(db, string(privateBloomBitsPrefix)+string(crypto.Keccak256([]byte(psi))))
+}
+
 // WritePrivateBlockBloom creates a bloom filter for the given receipts and saves it to the database
 // with the number given as identifier (i.e. block number).
 func WritePrivateBlockBloom(db ethdb.Database, number uint64, receipts types.Receipts) error {
@@ -165,6 +165,21 @@ func TestPrivateStatesTrieRoot(t *testing.T) {
 	assert.Equal(t, common.Hash{}, retrievedEmptyRoot)
 }
 
+func TestPrivateStateStartBlock(t *testing.T) {
+	db := NewMemoryDatabase()
+
+	_, found := ReadPrivateStateStartBlock(db, "psi1")
+	assert.False(t, found)
+
+	assert.Nil(t, WritePrivateStateStartBlock(db, "psi1", 42))
+
+	number, found := ReadPrivateStateStartBlock(db, "psi1")
+	assert.True(t, found)
+	assert.Equal(t, uint64(42), number)
+	_, found = ReadPrivateStateStartBlock(db, "psi2")
+	assert.False(t, found)
+}
+
 func TestPrivateStateRoot(t *testing.T) {
 	db := NewMemoryDatabase()
 	blockRoot := common.HexToHash("0x4c50c7d11e58e5c6f40fa1a630ffcb3a017453e7f9d0ec8ccb01033fcf9f2210")
@@ -179,3 +194,84 @@ func TestPrivateStateRoot(t *testing.T) {
 	retrievedEmptyRoot := GetPrivateStateRoot(db, common.Hash{})
 	assert.Equal(t, common.Hash{}, retrievedEmptyRoot)
 }
+
+func TestDeleteRootMappings(t *testing.T) {
+	db := NewMemoryDatabase()
+	blockRoot := common.HexToHash("0x4c50c7d11e58e5c6f40fa1a630ffcb3a017453e7f9d0ec8ccb01033fcf9f2210")
+	root := common.HexToHash("0x5c46375b6b333983077e152d1b6ca101d0586a6565fa75750deb1b07154bbdca")
+	assert.Nil(t, WritePrivateStateRoot(db, blockRoot, root))
+	assert.Nil(t, WritePrivateStatesTrieRoot(db, blockRoot, root))
+	assert.Nil(t, WriteRootHashMapping(db, blockRoot, root))
+
+	found := make(map[string]common.Hash)
+	iter := db.NewIterator(nil, nil)
+	for iter.Next() {
+		if ok, mappedFrom := IsPrivateStateRootKey(iter.Key()); ok {
+			found["privateStateRoot"] = mappedFrom
+		} else if ok, mappedFrom := IsPrivateStatesTrieRootKey(iter.Key()); ok {
+			found["privateStatesTrieRoot"] = mappedFrom
+		} else if ok, mappedFrom := IsRootHashMappingKey(iter.Key()); ok {
+			found["rootHashMapping"] = mappedFrom
+		}
+	}
+	iter.Release()
+	assert.Equal(t, map[string]common.Hash{
+		"privateStateRoot":      blockRoot,
+		"privateStatesTrieRoot": blockRoot,
+		"rootHashMapping":       blockRoot,
+	}, found)
+
+	assert.Nil(t, DeletePrivateStateRoot(db, blockRoot))
+	assert.Nil(t, DeletePrivateStatesTrieRoot(db, blockRoot))
+	assert.Nil(t, DeleteRootHashMapping(db, blockRoot))
+
+	assert.Equal(t, common.Hash{}, GetPrivateStateRoot(db, blockRoot))
+	assert.Equal(t, common.Hash{}, GetPrivateStatesTrieRoot(db, blockRoot))
+	assert.Equal(t, common.Hash{}, GetAccountExtraDataRoot(db, blockRoot))
+}
+
+func TestPrivateContractEntries(t *testing.T) {
+	db := NewMemoryDatabase()
+	newEntry := func(number uint64, address byte) *PrivateContractEntry {
+		return &PrivateContractEntry{
+			Address:        common.BytesToAddress([]byte{address}),
+			BlockNumber:    number,
+			BlockHash:      common.BytesToHash([]byte{byte(number)}),
+			TxHash:         common.BytesToHash([]byte{address}),
+			CreationTxHash: common.BytesToEncryptedPayloadHash([]byte{address}),
+		}
+	}
+	WritePrivateContractEntry(db, "psi1", newEntry(1, 1))
+	WritePrivateContractEntry(db, "psi1", newEntry(3, 2))
+	WritePrivateContractEntry(db, "psi1", newEntry(3, 3))
+	WritePrivateContractEntry(db, "psi1", newEntry(7, 4))
+	WritePrivateContractEntry(db, "psi2", newEntry(2, 5))
+
+	assert.Equal(t, []*PrivateContractEntry{newEntry(1, 1), newEntry(3, 2), newEntry(3, 3), newEntry(7, 4)}, ReadPrivateContractEntries(db, "psi1", 0, 10, 10))
+	assert.Equal(t, []*PrivateContractEntry{newEntry(3, 2), newEntry(3, 3)}, ReadPrivateContractEntries(db, "psi1", 2, 6, 10))
+	// the entries of a block are not split by the limit
+	assert.Equal(t, []*PrivateContractEntry{newEntry(1, 1), newEntry(3, 2), newEntry(3, 3)}, ReadPrivateContractEntries(db, "psi1", 0, 10, 2))
+	assert.Equal(t, []*PrivateContractEntry{newEntry(2, 5)}, ReadPrivateContractEntries(db, "psi2", 0, 10, 10))
+	assert.Empty(t, ReadPrivateContractEntries(db, "psi3", 0, 10, 10))
+
+	// the same contract created in a sibling block does not overwrite the entry of the other block
+	sibling := newEntry(7, 4)
+	sibling.BlockHash = common.BytesToHash([]byte{0xff})
+	WritePrivateContractEntry(db, "psi1", sibling)
+	entries := ReadPrivateContractEntries(db, "psi1", 7, 7, 10)
+	assert.ElementsMatch(t, []*PrivateContractEntry{newEntry(7, 4), sibling}, entries)
+}
+
+func TestPrivateBatchTxLookup(t *testing.T) {
+	db := NewMemoryDatabase()
+	txHash, pmtHash := common.BytesToHash([]byte{1}), common.BytesToHash([]byte{2})
+
+	_, ok := ReadPrivateBatchTxLookup(db, txHash)
+	assert.False(t, ok)
+
+	WritePrivateBatchTxLookup(db, txHash, pmtHash)
+
+	got, ok := ReadPrivateBatchTxLookup(db, txHash)
+	assert.True(t, ok)
+	assert.Equal(t, pmtHash, got)
+}
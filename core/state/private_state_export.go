@@ -0,0 +1,185 @@
+package state
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+// ErrMissingPreimages is returned when a state is exported or diffed without the preimages of its
+// trie keys, which are only recorded with --cache.preimages
+var ErrMissingPreimages = errors.New("preimages of the state are not recorded, the node must run with --cache.preimages")
+
+// Quorum
+// PrivateStateExportHeader is the first line of a private state export. It is followed by one
+// PrivateStateExportAccount per line.
+type PrivateStateExportHeader struct {
+	PSI         types.PrivateStateIdentifier `json:"psi"`
+	BlockNumber uint64                       `json:"blockNumber"`
+	BlockHash   common.Hash                  `json:"blockHash"`
+	Root        common.Hash                  `json:"root"` // root of the exported private state
+}
+
+// Quorum
+// PrivateStateExportAccount is an account of an exported private state, including the
+// AccountExtraData which is not part of a regular state dump
+type PrivateStateExportAccount struct {
+	Address         common.Address              `json:"address"`
+	Balance         *hexutil.Big                `json:"balance"`
+	Nonce           uint64                      `json:"nonce"`
+	Code            hexutil.Bytes               `json:"code,omitempty"`
+	Storage         map[common.Hash]common.Hash `json:"storage,omitempty"`
+	PrivacyMetadata *PrivacyMetadata            `json:"privacyMetadata,omitempty"`
+	ManagedParties  []string                    `json:"managedParties,omitempty"`
+}
+
+// Quorum
+// ExportPrivateState writes the header followed by all accounts of the state, one JSON object
+// per line, and returns the number of accounts written. The root of the header is set to the
+// root of the state.
+//
+// The preimages of the account addresses and storage keys must be available, as the state
+// cannot be imported without them. Nothing is written if the preimage of the first account is
+// missing, i.e. if the preimages have not been recorded.
+func (s *StateDB) ExportPrivateState(header PrivateStateExportHeader, w io.Writer) (int, error) {
+	if first := trie.NewIterator(s.trie.NodeIterator(nil)); first.Next() && s.trie.GetKey(first.Key) == nil {
+		return 0, ErrMissingPreimages
+	}
+	header.Root = s.trie.Hash()
+	enc := json.NewEncoder(w)
+	if err := enc.Encode(&header); err != nil {
+		return 0, err
+	}
+	var count int
+	it := trie.NewIterator(s.trie.NodeIterator(nil))
+	for it.Next() {
+		var data Account
+		if err := rlp.DecodeBytes(it.Value, &data); err != nil {
+			return count, err
+		}
+		addrBytes := s.trie.GetKey(it.Key)
+		if addrBytes == nil {
+			return count, fmt.Errorf("missing preimage of account key %x", it.Key)
+		}
+		addr := common.BytesToAddress(addrBytes)
+		obj := newObject(s, addr, data)
+		account := PrivateStateExportAccount{
+			Address: addr,
+			Balance: (*hexutil.Big)(data.Balance),
+			Nonce:   data.Nonce,
+			Code:    obj.Code(s.db),
+			Storage: make(map[common.Hash]common.Hash),
+		}
+		storageIt := trie.NewIterator(obj.getTrie(s.db).NodeIterator(nil))
+		for storageIt.Next() {
+			key := s.trie.GetKey(storageIt.Key)
+			if key == nil {
+				return count, fmt.Errorf("missing preimage of storage key %x of account %s", storageIt.Key, addr.Hex())
+			}
+			_, content, _, err := rlp.Split(storageIt.Value)
+			if err != nil {
+				return count, err
+			}
+			account.Storage[common.BytesToHash(key)] = common.BytesToHash(content)
+		}
+		extraData, err := obj.AccountExtraData()
+		if err != nil && !errors.Is(err, common.ErrNoAccountExtraData) {
+			return count, err
+		}
+		if extraData != nil {
+			account.PrivacyMetadata = extraData.PrivacyMetadata
+			account.ManagedParties = extraData.ManagedParties
+		}
+		if err := enc.Encode(&account); err != nil {
+			return count, err
+		}
+		count++
+	}
+	if it.Err != nil {
+		return count, it.Err
+	}
+	return count, nil
+}
+
+// Quorum
+// PrivateStateImport reads a private state written by ExportPrivateState
+type PrivateStateImport struct {
+	Header PrivateStateExportHeader
+	dec    *json.Decoder
+}
+
+// Quorum
+// NewPrivateStateImport reads the header of a private state export. The accounts are read
+// when the export is imported into a state.
+func NewPrivateStateImport(r io.Reader) (*PrivateStateImport, error) {
+	imp := &PrivateStateImport{dec: json.NewDecoder(bufio.NewReader(r))}
+	if err := imp.dec.Decode(&imp.Header); err != nil {
+		return nil, fmt.Errorf("invalid private state export header: %v", err)
+	}
+	return imp, nil
+}
+
+// Quorum
+// ImportPrivateState applies the accounts of the export to the state, replacing the accounts
+// which already exist, and returns the number of accounts imported. The state must be
+// committed by the caller.
+//
+// The accounts are read in memory and validated against the root of the header before they
+// are applied, so that a truncated or altered export leaves the state untouched.
+func (s *StateDB) ImportPrivateState(imp *PrivateStateImport) (int, error) {
+	var accounts []*PrivateStateExportAccount
+	for {
+		account := new(PrivateStateExportAccount)
+		if err := imp.dec.Decode(account); err == io.EOF {
+			break
+		} else if err != nil {
+			return 0, fmt.Errorf("invalid account #%d: %v", len(accounts), err)
+		}
+		accounts = append(accounts, account)
+	}
+	exported, err := New(common.Hash{}, NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	if err != nil {
+		return 0, err
+	}
+	for _, account := range accounts {
+		exported.importAccount(account)
+	}
+	if root := exported.IntermediateRoot(true); root != imp.Header.Root {
+		return 0, fmt.Errorf("accounts of the export do not match the root of its header: have %x, want %x", root, imp.Header.Root)
+	}
+	for _, account := range accounts {
+		s.importAccount(account)
+	}
+	return len(accounts), nil
+}
+
+func (s *StateDB) importAccount(account *PrivateStateExportAccount) {
+	// start from an empty account, so that storage which is not part of the export is dropped
+	s.CreateAccount(account.Address)
+	balance := new(big.Int)
+	if account.Balance != nil {
+		balance = account.Balance.ToInt()
+	}
+	s.SetBalance(account.Address, balance)
+	s.SetNonce(account.Address, account.Nonce)
+	if len(account.Code) > 0 {
+		s.SetCode(account.Address, account.Code)
+	}
+	for key, value := range account.Storage {
+		s.SetState(account.Address, key, value)
+	}
+	if account.PrivacyMetadata != nil {
+		s.SetPrivacyMetadata(account.Address, account.PrivacyMetadata)
+	}
+	s.SetManagedParties(account.Address, account.ManagedParties)
+}
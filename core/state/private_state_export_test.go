@@ -0,0 +1,97 @@
+package state
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/private/engine"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportImportPrivateState(t *testing.T) {
+	source, _ := New(common.Hash{}, NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	contract := common.BytesToAddress([]byte{1})
+	account := common.BytesToAddress([]byte{2})
+	metadata := &PrivacyMetadata{
+		CreationTxHash: common.BytesToEncryptedPayloadHash([]byte("creation")),
+		PrivacyFlag:    engine.PrivacyFlagStateValidation,
+	}
+	source.SetNonce(contract, 1)
+	source.SetCode(contract, []byte{1, 2, 3})
+	source.SetState(contract, common.HexToHash("0x01"), common.HexToHash("0x0a"))
+	source.SetState(contract, common.HexToHash("0x02"), common.HexToHash("0x0b"))
+	source.SetPrivacyMetadata(contract, metadata)
+	source.SetManagedParties(contract, []string{"AAA", "BBB"})
+	source.SetBalance(account, big.NewInt(10))
+	root, err := source.Commit(false)
+	require.NoError(t, err)
+
+	var export bytes.Buffer
+	count, err := source.ExportPrivateState(PrivateStateExportHeader{PSI: "psi1", BlockNumber: 5}, &export)
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	target, _ := New(common.Hash{}, NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	target.SetState(contract, common.HexToHash("0x03"), common.HexToHash("0x0c")) // dropped by the import
+	imp, err := NewPrivateStateImport(&export)
+	require.NoError(t, err)
+	count, err = target.ImportPrivateState(imp)
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+	assert.Equal(t, types.PrivateStateIdentifier("psi1"), imp.Header.PSI)
+	assert.Equal(t, uint64(5), imp.Header.BlockNumber)
+	assert.Equal(t, root, imp.Header.Root)
+	importedRoot, err := target.Commit(false)
+	require.NoError(t, err)
+	assert.Equal(t, root, importedRoot)
+	assert.Equal(t, common.HexToHash("0x0a"), target.GetState(contract, common.HexToHash("0x01")))
+	actualMetadata, err := target.GetPrivacyMetadata(contract)
+	require.NoError(t, err)
+	assert.Equal(t, metadata, actualMetadata)
+	managedParties, err := target.GetManagedParties(contract)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"AAA", "BBB"}, managedParties)
+	assert.Equal(t, big.NewInt(10), target.GetBalance(account))
+}
+
+func TestExportPrivateState_whenPreimagesNotRecorded(t *testing.T) {
+	source, _ := New(common.Hash{}, NewDatabaseWithConfig(rawdb.NewMemoryDatabase(), &trie.Config{Preimages: false}), nil)
+	source.SetBalance(common.BytesToAddress([]byte{1}), big.NewInt(10))
+	_, err := source.Commit(false)
+	require.NoError(t, err)
+
+	var export bytes.Buffer
+	_, err = source.ExportPrivateState(PrivateStateExportHeader{PSI: "psi1"}, &export)
+
+	assert.Equal(t, ErrMissingPreimages, err)
+	assert.Zero(t, export.Len())
+}
+
+func TestImportPrivateState_whenRootDoesNotMatch(t *testing.T) {
+	source, _ := New(common.Hash{}, NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	account := common.BytesToAddress([]byte{1})
+	source.SetBalance(account, big.NewInt(10))
+	_, err := source.Commit(false)
+	require.NoError(t, err)
+	var export bytes.Buffer
+	_, err = source.ExportPrivateState(PrivateStateExportHeader{PSI: "psi1"}, &export)
+	require.NoError(t, err)
+	// the balance is altered after the export
+	altered := bytes.Replace(export.Bytes(), []byte(`"balance":"0xa"`), []byte(`"balance":"0xb"`), 1)
+	require.NotEqual(t, export.Bytes(), altered)
+
+	target, _ := New(common.Hash{}, NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	imp, err := NewPrivateStateImport(bytes.NewReader(altered))
+	require.NoError(t, err)
+	_, err = target.ImportPrivateState(imp)
+
+	assert.Error(t, err)
+	assert.False(t, target.Exist(account))
+}
@@ -199,6 +199,12 @@ func prune(snaptree *snapshot.Tree, root common.Hash, maindb ethdb.Database, sta
 	if _, err := snaptree.Journal(root); err != nil {
 		return err
 	}
+	// Quorum
+	// The private states of the pruned states are gone, so are their
+	// root mappings.
+	if err := pruneRootMappings(maindb, root); err != nil {
+		return err
+	}
 	// Delete the state bloom, it marks the entire pruning procedure is
 	// finished. If any crashes or manual exit happens before this,
 	// `RecoverPruning` will pick it up in the next restarts to redo all
@@ -326,6 +332,12 @@ func (p *Pruner) Prune(root common.Hash) error {
 	if err := extractGenesis(p.db, p.stateBloom); err != nil {
 		return err
 	}
+	// Quorum
+	// Traverse the private states of the target state, they are not part
+	// of the snapshot.
+	if err := extractPrivateState(p.db, root, p.stateBloom); err != nil {
+		return err
+	}
 	filterName := bloomFilterName(p.datadir, root)
 
 	log.Info("Writing state bloom to disk", "name", filterName)
@@ -411,7 +423,17 @@ func extractGenesis(db ethdb.Database, stateBloom *stateBloom) error {
 	if genesis == nil {
 		return errors.New("missing genesis block")
 	}
-	t, err := trie.NewSecure(genesis.Root(), trie.NewDatabase(db))
+	if err := extractState(db, genesis.Root(), stateBloom); err != nil {
+		return err
+	}
+	// Quorum
+	return extractPrivateState(db, genesis.Root(), stateBloom)
+}
+
+// extractState traverses the state with the given root and commits all the
+// state entries into the given bloomfilter.
+func extractState(db ethdb.Database, root common.Hash, stateBloom *stateBloom) error {
+	t, err := trie.NewSecure(root, trie.NewDatabase(db))
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,173 @@
+package pruner
+
+import (
+	"errors"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+// Quorum
+//
+// The private states are not part of the snapshot, so they are traversed from the
+// root mappings of the public state: the private state of a block, or the trie of
+// private states of a block if multiple private states are enabled, and the
+// state.AccountExtraData trie of each state.
+
+// extractPrivateState commits all the entries of the private states linked to the
+// given public state root into the given bloomfilter, together with the tries of
+// their account extra data.
+func extractPrivateState(db ethdb.Database, root common.Hash, stateBloom *stateBloom) error {
+	if err := extractAccountExtraData(db, root, stateBloom); err != nil {
+		return err
+	}
+	if mpsRoot := rawdb.GetPrivateStatesTrieRoot(db, root); mpsRoot != (common.Hash{}) {
+		if err := extractTrie(db, mpsRoot, stateBloom); err != nil {
+			return err
+		}
+	}
+	privateRoots, err := privateStateRoots(db, root)
+	if err != nil {
+		return err
+	}
+	for _, privateRoot := range privateRoots {
+		if err := extractState(db, privateRoot, stateBloom); err != nil {
+			return err
+		}
+		if err := extractAccountExtraData(db, privateRoot, stateBloom); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// privateStateRoots returns the roots of the private states linked to the given
+// public state root, one per private state if multiple private states are enabled
+func privateStateRoots(db ethdb.Database, root common.Hash) ([]common.Hash, error) {
+	var roots []common.Hash
+	if privateRoot := rawdb.GetPrivateStateRoot(db, root); privateRoot != (common.Hash{}) && privateRoot != emptyRoot {
+		roots = append(roots, privateRoot)
+	}
+	mpsRoot := rawdb.GetPrivateStatesTrieRoot(db, root)
+	if mpsRoot == (common.Hash{}) || mpsRoot == emptyRoot {
+		return roots, nil
+	}
+	t, err := trie.New(mpsRoot, trie.NewDatabase(db))
+	if err != nil {
+		return nil, err
+	}
+	iter := t.NodeIterator(nil)
+	for iter.Next(true) {
+		if iter.Leaf() {
+			if privateRoot := common.BytesToHash(iter.LeafBlob()); privateRoot != emptyRoot {
+				roots = append(roots, privateRoot)
+			}
+		}
+	}
+	return roots, iter.Error()
+}
+
+// extractAccountExtraData commits the nodes of the state.AccountExtraData trie
+// linked to the given state root into the given bloomfilter
+func extractAccountExtraData(db ethdb.Database, root common.Hash, stateBloom *stateBloom) error {
+	extraDataRoot := rawdb.GetAccountExtraDataRoot(db, root)
+	if extraDataRoot == (common.Hash{}) {
+		return nil
+	}
+	return extractTrie(db, extraDataRoot, stateBloom)
+}
+
+// extractTrie commits the nodes of the trie with the given root into the given
+// bloomfilter. The leaves are not followed.
+func extractTrie(db ethdb.Database, root common.Hash, stateBloom *stateBloom) error {
+	if root == emptyRoot {
+		return nil
+	}
+	t, err := trie.New(root, trie.NewDatabase(db))
+	if err != nil {
+		return err
+	}
+	iter := t.NodeIterator(nil)
+	for iter.Next(true) {
+		if hash := iter.Hash(); hash != (common.Hash{}) {
+			stateBloom.Put(hash.Bytes(), nil)
+		}
+	}
+	return iter.Error()
+}
+
+// pruneRootMappings deletes the mappings to private state roots and account extra
+// data roots of all the states other than the given one and the genesis state, as
+// their trie nodes have been pruned.
+func pruneRootMappings(maindb ethdb.Database, root common.Hash) error {
+	genesisHash := rawdb.ReadCanonicalHash(maindb, 0)
+	if genesisHash == (common.Hash{}) {
+		return errors.New("missing genesis hash")
+	}
+	genesis := rawdb.ReadBlock(maindb, genesisHash, 0)
+	if genesis == nil {
+		return errors.New("missing genesis block")
+	}
+	retained := make(map[common.Hash]struct{})
+	for _, publicRoot := range []common.Hash{root, genesis.Root()} {
+		retained[publicRoot] = struct{}{}
+		privateRoots, err := privateStateRoots(maindb, publicRoot)
+		if err != nil {
+			return err
+		}
+		for _, privateRoot := range privateRoots {
+			retained[privateRoot] = struct{}{}
+		}
+	}
+	var (
+		count         int
+		start         = time.Now()
+		batch         = maindb.NewBatch()
+		iter          = maindb.NewIterator(nil, nil)
+		deleteMapping = func(key []byte, mappedFrom common.Hash) error {
+			if _, ok := retained[mappedFrom]; ok {
+				return nil
+			}
+			count++
+			if err := batch.Delete(key); err != nil {
+				return err
+			}
+			if batch.ValueSize() >= ethdb.IdealBatchSize {
+				if err := batch.Write(); err != nil {
+					return err
+				}
+				batch.Reset()
+			}
+			return nil
+		}
+	)
+	defer iter.Release()
+	for iter.Next() {
+		key := iter.Key()
+		if ok, mappedFrom := rawdb.IsPrivateStateRootKey(key); ok {
+			if err := deleteMapping(key, mappedFrom); err != nil {
+				return err
+			}
+		} else if ok, mappedFrom := rawdb.IsPrivateStatesTrieRootKey(key); ok {
+			if err := deleteMapping(key, mappedFrom); err != nil {
+				return err
+			}
+		} else if ok, mappedFrom := rawdb.IsRootHashMappingKey(key); ok {
+			if err := deleteMapping(key, mappedFrom); err != nil {
+				return err
+			}
+		}
+	}
+	if err := iter.Error(); err != nil {
+		return err
+	}
+	if err := batch.Write(); err != nil {
+		return err
+	}
+	log.Info("Pruned private state root mappings", "count", count, "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
@@ -0,0 +1,109 @@
+package pruner
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/state/snapshot"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/private/engine"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeTestState writes a state with a contract, including its privacy metadata, on top of the parent
+// state and returns its root. The state is added to the snapshot tree, if any.
+func writeTestState(t *testing.T, db state.Database, snaps *snapshot.Tree, parent common.Hash, value int64) common.Hash {
+	statedb, err := state.New(parent, db, snaps)
+	require.NoError(t, err)
+	contract := common.BytesToAddress([]byte{1})
+	statedb.SetCode(contract, []byte{byte(value)})
+	statedb.SetState(contract, common.Hash{}, common.BigToHash(big.NewInt(value)))
+	statedb.SetPrivacyMetadata(contract, &state.PrivacyMetadata{
+		CreationTxHash: common.BytesToEncryptedPayloadHash(big.NewInt(value).Bytes()),
+		PrivacyFlag:    engine.PrivacyFlagPartyProtection,
+	})
+	root, err := statedb.Commit(false)
+	require.NoError(t, err)
+	require.NoError(t, db.TrieDB().Commit(root, false, nil))
+	return root
+}
+
+// writeTestPrivateStatesTrie writes a trie of private states and returns its root
+func writeTestPrivateStatesTrie(t *testing.T, db state.Database, privateRoots map[string]common.Hash) common.Hash {
+	tr, err := trie.NewSecure(common.Hash{}, db.TrieDB())
+	require.NoError(t, err)
+	for psi, root := range privateRoots {
+		require.NoError(t, tr.TryUpdate([]byte(psi), root.Bytes()))
+	}
+	root, err := tr.Commit(nil)
+	require.NoError(t, err)
+	require.NoError(t, db.TrieDB().Commit(root, false, nil))
+	return root
+}
+
+func TestPrivateStatePruning(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	statedb := state.NewDatabase(db)
+
+	genesisRoot := writeTestState(t, statedb, nil, common.Hash{}, 0)
+	genesis := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(0), Root: genesisRoot})
+	rawdb.WriteBlock(db, genesis)
+	rawdb.WriteCanonicalHash(db, genesis.Hash(), 0)
+	genesisPrivateRoot := writeTestState(t, statedb, nil, common.Hash{}, 1)
+	require.NoError(t, rawdb.WritePrivateStateRoot(db, genesisRoot, genesisPrivateRoot))
+
+	// the public states of the following blocks are diff layers of the snapshot of the genesis state
+	snaps, err := snapshot.New(db, statedb.TrieDB(), 256, genesisRoot, false, true, false)
+	require.NoError(t, err)
+
+	// the stale state and its private state are pruned
+	staleRoot := writeTestState(t, statedb, snaps, genesisRoot, 2)
+	stalePrivateRoot := writeTestState(t, statedb, nil, common.Hash{}, 3)
+	require.NoError(t, rawdb.WritePrivateStateRoot(db, staleRoot, stalePrivateRoot))
+
+	// the target state has multiple private states
+	targetRoot := writeTestState(t, statedb, snaps, staleRoot, 4)
+	privateRoots := map[string]common.Hash{
+		"private": writeTestState(t, statedb, nil, common.Hash{}, 5),
+		"psi1":    writeTestState(t, statedb, nil, common.Hash{}, 6),
+	}
+	mpsRoot := writeTestPrivateStatesTrie(t, statedb, privateRoots)
+	require.NoError(t, rawdb.WritePrivateStatesTrieRoot(db, targetRoot, mpsRoot))
+
+	headRoot := writeTestState(t, statedb, snaps, targetRoot, 7)
+	head := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(3), Root: headRoot})
+	rawdb.WriteBlock(db, head)
+	rawdb.WriteCanonicalHash(db, head.Hash(), 3)
+	rawdb.WriteHeadBlockHash(db, head.Hash())
+	_, err = snaps.Journal(headRoot)
+	require.NoError(t, err)
+
+	pruner, err := NewPruner(db, t.TempDir(), "", 0)
+	require.NoError(t, err)
+	require.NoError(t, pruner.Prune(targetRoot))
+
+	reopened := state.NewDatabase(db)
+	for _, root := range []common.Hash{genesisRoot, genesisPrivateRoot, targetRoot, privateRoots["private"], privateRoots["psi1"]} {
+		s, err := state.New(root, reopened, nil)
+		require.NoError(t, err, "state %x must be retained", root)
+		metadata, err := s.GetPrivacyMetadata(common.BytesToAddress([]byte{1}))
+		require.NoError(t, err, "account extra data of state %x must be retained", root)
+		assert.NotNil(t, metadata)
+		assert.NotEmpty(t, s.GetCode(common.BytesToAddress([]byte{1})))
+	}
+	_, err = state.New(staleRoot, reopened, nil)
+	assert.Error(t, err, "stale state must be pruned")
+	_, err = state.New(stalePrivateRoot, reopened, nil)
+	assert.Error(t, err, "stale private state must be pruned")
+
+	assert.Equal(t, genesisPrivateRoot, rawdb.GetPrivateStateRoot(db, genesisRoot))
+	assert.Equal(t, mpsRoot, rawdb.GetPrivateStatesTrieRoot(db, targetRoot))
+	assert.Equal(t, common.Hash{}, rawdb.GetPrivateStateRoot(db, staleRoot))
+	assert.Equal(t, common.Hash{}, rawdb.GetAccountExtraDataRoot(db, stalePrivateRoot))
+	assert.NotEqual(t, common.Hash{}, rawdb.GetAccountExtraDataRoot(db, privateRoots["psi1"]))
+}
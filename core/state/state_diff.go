@@ -0,0 +1,246 @@
+package state
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+// Quorum
+// AccountDiff is the change of an account between two states. Only the changed fields are set,
+// a field of an account which does not exist in one of the states having its zero value.
+type AccountDiff struct {
+	Address         common.Address               `json:"address"`
+	Created         bool                         `json:"created,omitempty"`
+	Deleted         bool                         `json:"deleted,omitempty"`
+	Balance         *BalanceDiff                 `json:"balance,omitempty"`
+	Nonce           *NonceDiff                   `json:"nonce,omitempty"`
+	Code            *CodeDiff                    `json:"code,omitempty"`
+	Storage         map[common.Hash]*StorageDiff `json:"storage,omitempty"`
+	PrivacyMetadata *PrivacyMetadataDiff         `json:"privacyMetadata,omitempty"`
+	ManagedParties  *ManagedPartiesDiff          `json:"managedParties,omitempty"`
+}
+
+type BalanceDiff struct {
+	From *hexutil.Big `json:"from"`
+	To   *hexutil.Big `json:"to"`
+}
+
+type NonceDiff struct {
+	From hexutil.Uint64 `json:"from"`
+	To   hexutil.Uint64 `json:"to"`
+}
+
+type CodeDiff struct {
+	From hexutil.Bytes `json:"from"`
+	To   hexutil.Bytes `json:"to"`
+}
+
+type StorageDiff struct {
+	From common.Hash `json:"from"`
+	To   common.Hash `json:"to"`
+}
+
+type PrivacyMetadataDiff struct {
+	From *PrivacyMetadata `json:"from"`
+	To   *PrivacyMetadata `json:"to"`
+}
+
+type ManagedPartiesDiff struct {
+	From []string `json:"from"`
+	To   []string `json:"to"`
+}
+
+// Quorum
+// DiffStates returns the accounts which differ between the two states, ordered by address. The
+// modified accounts, storage slots and AccountExtraData are found by comparing the tries of the
+// states, so the preimages of the addresses and storage keys must be available, i.e. recorded
+// with --cache.preimages since the states were written. ErrMissingPreimages is returned upfront
+// if the preimages are not recorded.
+func DiffStates(from, to *StateDB) ([]*AccountDiff, error) {
+	for _, s := range []*StateDB{from, to} {
+		if !s.db.TrieDB().Preimages() {
+			return nil, ErrMissingPreimages
+		}
+	}
+	addresses, err := diffTrieKeys(from.trie, to.trie)
+	if err != nil {
+		return nil, err
+	}
+	extraDataAddresses, err := diffTrieKeys(from.accountExtraDataTrie, to.accountExtraDataTrie)
+	if err != nil {
+		return nil, err
+	}
+	addresses = mergeTrieKeys(addresses, extraDataAddresses)
+
+	diffs := make([]*AccountDiff, 0, len(addresses))
+	for _, key := range addresses {
+		diff, err := diffAccount(from, to, common.BytesToAddress(key))
+		if err != nil {
+			return nil, err
+		}
+		if diff != nil {
+			diffs = append(diffs, diff)
+		}
+	}
+	return diffs, nil
+}
+
+// diffAccount returns the changes of the account, nil if the account did not change
+func diffAccount(from, to *StateDB, addr common.Address) (*AccountDiff, error) {
+	fromObj, toObj := from.getStateObject(addr), to.getStateObject(addr)
+	diff := &AccountDiff{
+		Address: addr,
+		Created: fromObj == nil && toObj != nil,
+		Deleted: fromObj != nil && toObj == nil,
+	}
+	changed := diff.Created || diff.Deleted
+	if fromBalance, toBalance := from.GetBalance(addr), to.GetBalance(addr); fromBalance.Cmp(toBalance) != 0 {
+		diff.Balance = &BalanceDiff{From: (*hexutil.Big)(fromBalance), To: (*hexutil.Big)(toBalance)}
+		changed = true
+	}
+	if fromNonce, toNonce := from.GetNonce(addr), to.GetNonce(addr); fromNonce != toNonce {
+		diff.Nonce = &NonceDiff{From: hexutil.Uint64(fromNonce), To: hexutil.Uint64(toNonce)}
+		changed = true
+	}
+	if fromCode, toCode := from.GetCode(addr), to.GetCode(addr); !bytes.Equal(fromCode, toCode) {
+		diff.Code = &CodeDiff{From: fromCode, To: toCode}
+		changed = true
+	}
+	storage, err := diffStorage(from, to, addr)
+	if err != nil {
+		return nil, err
+	}
+	if len(storage) > 0 {
+		diff.Storage = storage
+		changed = true
+	}
+	fromExtraData, err := accountExtraDataOf(fromObj)
+	if err != nil {
+		return nil, err
+	}
+	toExtraData, err := accountExtraDataOf(toObj)
+	if err != nil {
+		return nil, err
+	}
+	if !privacyMetadataEqual(fromExtraData.PrivacyMetadata, toExtraData.PrivacyMetadata) {
+		diff.PrivacyMetadata = &PrivacyMetadataDiff{From: fromExtraData.PrivacyMetadata, To: toExtraData.PrivacyMetadata}
+		changed = true
+	}
+	if !sortedStringsEqual(fromExtraData.ManagedParties, toExtraData.ManagedParties) {
+		diff.ManagedParties = &ManagedPartiesDiff{From: fromExtraData.ManagedParties, To: toExtraData.ManagedParties}
+		changed = true
+	}
+	if !changed {
+		return nil, nil
+	}
+	return diff, nil
+}
+
+// diffStorage returns the storage slots of the account which differ between the states
+func diffStorage(from, to *StateDB, addr common.Address) (map[common.Hash]*StorageDiff, error) {
+	fromTrie, err := storageTrieOf(from, addr)
+	if err != nil {
+		return nil, err
+	}
+	toTrie, err := storageTrieOf(to, addr)
+	if err != nil {
+		return nil, err
+	}
+	keys, err := diffTrieKeys(fromTrie, toTrie)
+	if err != nil {
+		return nil, err
+	}
+	storage := make(map[common.Hash]*StorageDiff)
+	for _, key := range keys {
+		slot := common.BytesToHash(key)
+		if fromValue, toValue := from.GetState(addr, slot), to.GetState(addr, slot); fromValue != toValue {
+			storage[slot] = &StorageDiff{From: fromValue, To: toValue}
+		}
+	}
+	return storage, nil
+}
+
+// storageTrieOf returns the storage trie of the account, an empty trie if the account does not exist
+func storageTrieOf(s *StateDB, addr common.Address) (Trie, error) {
+	if obj := s.getStateObject(addr); obj != nil {
+		return obj.getTrie(s.db), nil
+	}
+	return s.db.OpenStorageTrie(crypto.Keccak256Hash(addr.Bytes()), common.Hash{})
+}
+
+// accountExtraDataOf returns the AccountExtraData of the account, empty if there is none
+func accountExtraDataOf(obj *stateObject) (*AccountExtraData, error) {
+	if obj == nil {
+		return &AccountExtraData{}, nil
+	}
+	extraData, err := obj.AccountExtraData()
+	if errors.Is(err, common.ErrNoAccountExtraData) {
+		return &AccountExtraData{}, nil
+	}
+	return extraData, err
+}
+
+// diffTrieKeys returns the preimages of the keys whose values differ between the tries, in order
+func diffTrieKeys(a, b Trie) ([][]byte, error) {
+	var keys [][]byte
+	for _, tries := range [][2]Trie{{a, b}, {b, a}} {
+		diff, _ := trie.NewDifferenceIterator(tries[0].NodeIterator(nil), tries[1].NodeIterator(nil))
+		it := trie.NewIterator(diff)
+		var found [][]byte
+		for it.Next() {
+			key := tries[1].GetKey(it.Key)
+			if key == nil {
+				return nil, fmt.Errorf("no preimage found for hash %x", it.Key)
+			}
+			found = append(found, common.CopyBytes(key))
+		}
+		if it.Err != nil {
+			return nil, it.Err
+		}
+		keys = mergeTrieKeys(keys, found)
+	}
+	return keys, nil
+}
+
+// mergeTrieKeys returns the union of the keys, sorted and without duplicates
+func mergeTrieKeys(a, b [][]byte) [][]byte {
+	keys := append(append(make([][]byte, 0, len(a)+len(b)), a...), b...)
+	sort.Slice(keys, func(i, j int) bool { return bytes.Compare(keys[i], keys[j]) < 0 })
+	var merged [][]byte
+	for _, key := range keys {
+		if len(merged) == 0 || !bytes.Equal(key, merged[len(merged)-1]) {
+			merged = append(merged, key)
+		}
+	}
+	return merged
+}
+
+func privacyMetadataEqual(a, b *PrivacyMetadata) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.PrivacyFlag == b.PrivacyFlag && a.CreationTxHash == b.CreationTxHash
+}
+
+// sortedStringsEqual returns whether the strings are the same regardless of their order
+func sortedStringsEqual(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	a, b = append([]string(nil), a...), append([]string(nil), b...)
+	sort.Strings(a)
+	sort.Strings(b)
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
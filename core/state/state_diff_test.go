@@ -0,0 +1,118 @@
+package state
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/private/engine"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffStates(t *testing.T) {
+	db := NewDatabaseWithConfig(rawdb.NewMemoryDatabase(), &trie.Config{Preimages: true})
+	var (
+		contract  = common.BytesToAddress([]byte{1})
+		deleted   = common.BytesToAddress([]byte{2})
+		created   = common.BytesToAddress([]byte{3})
+		unchanged = common.BytesToAddress([]byte{4})
+		metadata  = &PrivacyMetadata{
+			CreationTxHash: common.BytesToEncryptedPayloadHash([]byte("creation")),
+			PrivacyFlag:    engine.PrivacyFlagStateValidation,
+		}
+	)
+	before, _ := New(common.Hash{}, db, nil)
+	before.SetCode(contract, []byte{1, 2, 3})
+	before.SetState(contract, common.HexToHash("0x01"), common.HexToHash("0x0a"))
+	before.SetState(contract, common.HexToHash("0x02"), common.HexToHash("0x0b"))
+	before.SetManagedParties(contract, []string{"AAA"})
+	before.SetBalance(deleted, big.NewInt(10))
+	before.SetNonce(unchanged, 1)
+	fromRoot, err := before.Commit(false)
+	require.NoError(t, err)
+
+	after, _ := New(fromRoot, db, nil)
+	after.SetState(contract, common.HexToHash("0x01"), common.HexToHash("0x0c"))
+	after.SetState(contract, common.HexToHash("0x02"), common.Hash{})
+	after.SetState(contract, common.HexToHash("0x03"), common.HexToHash("0x0d"))
+	after.SetManagedParties(contract, []string{"AAA", "BBB"})
+	after.SetPrivacyMetadata(contract, metadata)
+	after.Suicide(deleted)
+	after.SetNonce(created, 2)
+	after.SetCode(created, []byte{4})
+	toRoot, err := after.Commit(true)
+	require.NoError(t, err)
+
+	from, _ := New(fromRoot, db, nil)
+	to, _ := New(toRoot, db, nil)
+	diffs, err := DiffStates(from, to)
+
+	require.NoError(t, err)
+	require.Len(t, diffs, 3)
+	assert.Equal(t, &AccountDiff{
+		Address: contract,
+		Storage: map[common.Hash]*StorageDiff{
+			common.HexToHash("0x01"): {From: common.HexToHash("0x0a"), To: common.HexToHash("0x0c")},
+			common.HexToHash("0x02"): {From: common.HexToHash("0x0b"), To: common.Hash{}},
+			common.HexToHash("0x03"): {From: common.Hash{}, To: common.HexToHash("0x0d")},
+		},
+		PrivacyMetadata: &PrivacyMetadataDiff{To: metadata},
+		ManagedParties:  &ManagedPartiesDiff{From: []string{"AAA"}, To: []string{"AAA", "BBB"}},
+	}, diffs[0])
+	assert.Equal(t, &AccountDiff{
+		Address: deleted,
+		Deleted: true,
+		Balance: &BalanceDiff{From: (*hexutil.Big)(big.NewInt(10)), To: (*hexutil.Big)(new(big.Int))},
+	}, diffs[1])
+	assert.Equal(t, &AccountDiff{
+		Address: created,
+		Created: true,
+		Nonce:   &NonceDiff{To: 2},
+		Code:    &CodeDiff{To: []byte{4}},
+	}, diffs[2])
+
+	reversed, err := DiffStates(to, from)
+	require.NoError(t, err)
+	require.Len(t, reversed, 3)
+	assert.True(t, reversed[1].Created)
+	assert.True(t, reversed[2].Deleted)
+}
+
+func TestDiffStates_whenManagedPartiesReordered(t *testing.T) {
+	db := NewDatabaseWithConfig(rawdb.NewMemoryDatabase(), &trie.Config{Preimages: true})
+	contract := common.BytesToAddress([]byte{1})
+	before, _ := New(common.Hash{}, db, nil)
+	before.SetNonce(contract, 1)
+	before.SetManagedParties(contract, []string{"AAA", "BBB"})
+	fromRoot, err := before.Commit(false)
+	require.NoError(t, err)
+	after, _ := New(fromRoot, db, nil)
+	after.SetManagedParties(contract, []string{"BBB", "AAA"})
+	toRoot, err := after.Commit(false)
+	require.NoError(t, err)
+
+	from, _ := New(fromRoot, db, nil)
+	to, _ := New(toRoot, db, nil)
+	diffs, err := DiffStates(from, to)
+
+	require.NoError(t, err)
+	assert.Empty(t, diffs)
+}
+
+func TestDiffStates_whenPreimagesNotRecorded(t *testing.T) {
+	db := NewDatabaseWithConfig(rawdb.NewMemoryDatabase(), &trie.Config{Preimages: false})
+	before, _ := New(common.Hash{}, db, nil)
+	before.SetNonce(common.BytesToAddress([]byte{1}), 1)
+	root, err := before.Commit(false)
+	require.NoError(t, err)
+
+	from, _ := New(common.Hash{}, db, nil)
+	to, _ := New(root, db, nil)
+	_, err = DiffStates(from, to)
+
+	assert.Equal(t, ErrMissingPreimages, err)
+}
@@ -678,9 +678,14 @@ func (s *stateObject) getCommittedAccountExtraData() (*AccountExtraData, error)
 	return &extraData, nil
 }
 
+// Quorum - Privacy Enhancements
+// ErrNoPrivacyMetadata is returned for a contract whose AccountExtraData has no PrivacyMetadata,
+// e.g. a standard private contract
+var ErrNoPrivacyMetadata = errors.New("no privacy metadata")
+
 // Quorum - Privacy Enhancements
 // PrivacyMetadata returns the reference to PrivacyMetadata.
-// It will returrn an error if no PrivacyMetadata is in the AccountExtraData.
+// It will returrn ErrNoPrivacyMetadata if no PrivacyMetadata is in the AccountExtraData.
 func (s *stateObject) PrivacyMetadata() (*PrivacyMetadata, error) {
 	extraData, err := s.AccountExtraData()
 	if err != nil {
@@ -688,7 +693,7 @@ func (s *stateObject) PrivacyMetadata() (*PrivacyMetadata, error) {
 	}
 	// extraData can't be nil. Refer to s.AccountExtraData()
 	if extraData.PrivacyMetadata == nil {
-		return nil, fmt.Errorf("no privacy metadata data for contract %s", s.address.Hex())
+		return nil, fmt.Errorf("%w for contract %s", ErrNoPrivacyMetadata, s.address.Hex())
 	}
 	return extraData.PrivacyMetadata, nil
 }
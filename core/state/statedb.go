@@ -106,6 +106,9 @@ type StateDB struct {
 	// Per-transaction access list
 	accessList *accessList
 
+	// Transient storage
+	transientStorage transientStorage
+
 	// Journal of state modifications. This is the backbone of
 	// Snapshot and RevertToSnapshot.
 	journal        *journal
@@ -154,6 +157,7 @@ func New(root common.Hash, db Database, snaps *snapshot.Tree) (*StateDB, error)
 		preimages:           make(map[common.Hash][]byte),
 		journal:             newJournal(),
 		accessList:          newAccessList(),
+		transientStorage:    newTransientStorage(),
 		hasher:              crypto.NewKeccakState(),
 		// Quorum - Privacy Enhancements
 		accountExtraDataTrie: accountExtraDataTrie,
@@ -358,6 +362,7 @@ func (s *StateDB) Reset(root common.Hash) error {
 		}
 	}
 	s.accessList = newAccessList()
+	s.transientStorage = newTransientStorage()
 	return nil
 }
 
@@ -474,6 +479,15 @@ func (s *StateDB) HasSuicided(addr common.Address) bool {
 	return false
 }
 
+// Quorum
+// DisableSnapshot detaches the state from its snapshot tree, so that it is neither read from nor
+// updated by the state any more. It is used for a private state branched from another one, which
+// must not add its changes to the snapshot tree of the state it is branched from.
+func (s *StateDB) DisableSnapshot() {
+	s.snaps, s.snap = nil, nil
+	s.snapDestructs, s.snapAccounts, s.snapStorage = nil, nil, nil
+}
+
 // Quorum
 // GetStorageRoot returns the root of the storage associated with the given address.
 func (s *StateDB) GetStorageRoot(addr common.Address) (common.Hash, error) {
@@ -883,6 +897,7 @@ func (s *StateDB) Copy() *StateDB {
 	// However, it doesn't cost us much to copy an empty list, so we do it anyway
 	// to not blow up if we ever decide copy it in the middle of a transaction
 	state.accessList = s.accessList.Copy()
+	state.transientStorage = s.transientStorage.Copy()
 
 	// If there's a prefetcher running, make an inactive copy of it that can
 	// only access data but does not actively preload (since the user will not
@@ -1071,6 +1086,7 @@ func (s *StateDB) Prepare(thash, bhash common.Hash, ti int) {
 	s.bhash = bhash
 	s.txIndex = ti
 	s.accessList = newAccessList()
+	s.transientStorage = newTransientStorage()
 }
 
 func (s *StateDB) clearJournalAndRefund() {
@@ -1231,6 +1247,33 @@ func (s *StateDB) AddSlotToAccessList(addr common.Address, slot common.Hash) {
 	}
 }
 
+// SetTransientState sets transient storage for a given account. It
+// adds the change to the journal so that it can be rolled back
+// to its previous value if there is a revert.
+func (s *StateDB) SetTransientState(addr common.Address, key, value common.Hash) {
+	prev := s.GetTransientState(addr, key)
+	if prev == value {
+		return
+	}
+	s.journal.append(transientStorageChange{
+		account:  &addr,
+		key:      key,
+		prevalue: prev,
+	})
+	s.setTransientState(addr, key, value)
+}
+
+// setTransientState is a lower level setter for transient storage. It
+// is called during a revert to prevent modifications to the journal.
+func (s *StateDB) setTransientState(addr common.Address, key, value common.Hash) {
+	s.transientStorage.Set(addr, key, value)
+}
+
+// GetTransientState gets transient storage for a given account.
+func (s *StateDB) GetTransientState(addr common.Address, key common.Hash) common.Hash {
+	return s.transientStorage.Get(addr, key)
+}
+
 // AddressInAccessList returns true if the given address is in the access list.
 func (s *StateDB) AddressInAccessList(addr common.Address) bool {
 	return s.accessList.ContainsAddress(addr)
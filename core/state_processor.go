@@ -260,7 +260,7 @@ func ApplyTransactionOnMPS(config *params.ChainConfig, bc ChainContext, author *
 	}
 	// execute in all the managed private states
 	// TODO this could be enhanced to run in parallel
-	for _, psi := range bc.PrivateStateManager().PSIs() {
+	for _, psi := range PrivateStatesAt(bc.PrivateStateManager(), header.Number.Uint64()) {
 		if cfg.ApplyOnPartyOverride != nil && *cfg.ApplyOnPartyOverride != psi {
 			continue
 		}
@@ -362,6 +362,7 @@ func applyTransaction(msg types.Message, config *params.ChainConfig, bc ChainCon
 			}
 			privateReceipt = types.NewReceipt(privateRoot, result.Failed(), *usedGas)
 			privateReceipt.TxHash = tx.Hash()
+			privateReceipt.PrivatePayloadHash = common.BytesToEncryptedPayloadHash(tx.Data())
 			privateReceipt.GasUsed = result.UsedGas
 			if msg.To() == nil {
 				privateReceipt.ContractAddress = crypto.CreateAddress(evm.TxContext.Origin, tx.Nonce())
@@ -369,12 +370,17 @@ func applyTransaction(msg types.Message, config *params.ChainConfig, bc ChainCon
 
 			privateReceipt.Logs = privateStateDB.GetLogs(tx.Hash())
 			privateReceipt.Bloom = types.CreateBloom(types.Receipts{privateReceipt})
+			privateReceipt.PrivacyVerificationFailure = result.PrivacyVerificationFailure
 		} else {
 			// This may have been a privacy marker transaction, in which case need to retrieve the receipt for the
 			// inner private transaction (note that this can be an mpsReceipt, containing private receipts in PSReceipts).
 			if evm.InnerPrivateReceipt != nil {
 				privateReceipt = evm.InnerPrivateReceipt
 			}
+			// or the receipts for the private transactions of the batch carried by the privacy marker transaction
+			if len(evm.InnerPrivateBatchReceipts) > 0 {
+				privateReceipt = newPrivacyMarkerBatchReceipt(tx, evm.InnerPrivateBatchReceipts, privateStateRepo != nil && privateStateRepo.IsMPS())
+			}
 		}
 	}
 
@@ -483,6 +489,52 @@ func ApplyInnerTransaction(bc ChainContext, author *common.Address, gp *GasPool,
 	return nil
 }
 
+// Quorum
+// newPrivacyMarkerBatchReceipt combines the receipts of the private transactions of the batch
+// carried by a privacy marker transaction into a single private receipt, which holds them in
+// BatchReceipts and concatenates their logs. On MPS the receipts are auxiliary MPS receipts, and
+// the returned auxiliary receipt holds a combined receipt in PSReceipts for each private state.
+func newPrivacyMarkerBatchReceipt(tx *types.Transaction, innerReceipts []*types.Receipt, isMPS bool) *types.Receipt {
+	if !isMPS {
+		return combineBatchReceipts(tx, innerReceipts)
+	}
+	mpsReceipt := &types.Receipt{
+		QuorumReceiptExtraData: types.QuorumReceiptExtraData{
+			PSReceipts: make(map[types.PrivateStateIdentifier]*types.Receipt),
+		},
+		Logs: make([]*types.Log, 0),
+	}
+	psiReceipts := make(map[types.PrivateStateIdentifier][]*types.Receipt)
+	for _, innerMPSReceipt := range innerReceipts {
+		for psi, psiReceipt := range innerMPSReceipt.PSReceipts {
+			psiReceipts[psi] = append(psiReceipts[psi], psiReceipt)
+		}
+		mpsReceipt.Logs = append(mpsReceipt.Logs, innerMPSReceipt.Logs...)
+	}
+	for psi, receipts := range psiReceipts {
+		mpsReceipt.PSReceipts[psi] = combineBatchReceipts(tx, receipts)
+	}
+	return mpsReceipt
+}
+
+func combineBatchReceipts(tx *types.Transaction, innerReceipts []*types.Receipt) *types.Receipt {
+	receipt := &types.Receipt{
+		Status: types.ReceiptStatusSuccessful,
+		TxHash: tx.Hash(),
+		Logs:   make([]*types.Log, 0),
+		QuorumReceiptExtraData: types.QuorumReceiptExtraData{
+			BatchReceipts: innerReceipts,
+		},
+	}
+	for _, innerReceipt := range innerReceipts {
+		receipt.GasUsed += innerReceipt.GasUsed
+		receipt.Logs = append(receipt.Logs, innerReceipt.Logs...)
+	}
+	receipt.CumulativeGasUsed = receipt.GasUsed
+	receipt.Bloom = types.CreateBloom(types.Receipts{receipt})
+	return receipt
+}
+
 // Quorum
 func prepareStates(tx *types.Transaction, stateDB *state.StateDB, privateStateDB *state.StateDB, txIndex int) {
 	stateDB.Prepare(tx.Hash(), stateDB.BlockHash(), txIndex)
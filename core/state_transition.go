@@ -30,6 +30,7 @@ import (
 	"github.com/ethereum/go-ethereum/multitenancy"
 	"github.com/ethereum/go-ethereum/params"
 	"github.com/ethereum/go-ethereum/private"
+	"github.com/ethereum/go-ethereum/private/engine"
 )
 
 /*
@@ -84,6 +85,8 @@ type ExecutionResult struct {
 	UsedGas    uint64 // Total used gas but include the refunded gas
 	Err        error  // Any error encountered during the execution(listed in core/vm/errors.go)
 	ReturnData []byte // Returned data from evm(function result or data supplied with revert opcode)
+
+	PrivacyVerificationFailure *types.PrivacyVerificationFailure // Quorum - why the privacy enhancements checks failed
 }
 
 // Unwrap returns the internal evm error which allows us for further
@@ -120,6 +123,14 @@ type PrivateMessage interface {
 	IsInnerPrivate() bool
 }
 
+// SimulatedPrivateMessage implements a private message simulated before it is sent, whose data is the
+// private payload and whose privacy metadata is given instead of received from the private transaction
+// manager. The privacy metadata is nil for a private message which is not simulated.
+type SimulatedPrivateMessage interface {
+	PrivateMessage
+	SimulatedPrivacyMetadata() *engine.ExtraMetadata
+}
+
 // IntrinsicGas computes the 'intrinsic gas' for a message with the given data.
 func IntrinsicGas(data []byte, accessList types.AccessList, isContractCreation bool, isHomestead, isEIP2028 bool) (uint64, error) {
 	// Set the starting gas for the raw transaction
@@ -273,13 +284,22 @@ func (st *StateTransition) TransitionDb() (*ExecutionResult, error) {
 
 	var data []byte
 	isPrivate := false
+	isSimulated := false
+	intrinsicData := st.data
 	publicState := st.state
 	pmh := newPMH(st)
 	if msg, ok := msg.(PrivateMessage); ok && isQuorum && msg.IsPrivate() {
 		isPrivate = true
 		pmh.snapshot = snapshot
-		pmh.eph = common.BytesToEncryptedPayloadHash(st.data)
-		_, _, data, pmh.receivedPrivacyMetadata, err = private.P.Receive(pmh.eph)
+		if simulated, ok := msg.(SimulatedPrivateMessage); ok && simulated.SimulatedPrivacyMetadata() != nil {
+			// the data is the private payload, the transaction will carry the hash of the encrypted payload instead
+			isSimulated = true
+			intrinsicData = common.Hex2Bytes(common.MaxPrivateIntrinsicDataHex)
+			data, pmh.receivedPrivacyMetadata = st.data, simulated.SimulatedPrivacyMetadata()
+		} else {
+			pmh.eph = common.BytesToEncryptedPayloadHash(st.data)
+			_, _, data, pmh.receivedPrivacyMetadata, err = private.P.Receive(pmh.eph)
+		}
 		// Increment the public account nonce if:
 		// 1. Tx is private and *not* a participant of the group and either call or create
 		// 2. Tx is private we are part of the group and is a call
@@ -312,7 +332,7 @@ func (st *StateTransition) TransitionDb() (*ExecutionResult, error) {
 	// not the private data retrieved above. This is because we need any (participant) validator
 	// node to get the same result as a (non-participant) minter node, to avoid out-of-gas issues.
 	// Check clauses 4-5, subtract intrinsic gas if everything is correct
-	gas, err := IntrinsicGas(st.data, st.msg.AccessList(), contractCreation, homestead, istanbul)
+	gas, err := IntrinsicGas(intrinsicData, st.msg.AccessList(), contractCreation, homestead, istanbul)
 	if err != nil {
 		return nil, err
 	}
@@ -329,6 +349,12 @@ func (st *StateTransition) TransitionDb() (*ExecutionResult, error) {
 	// Set up the initial access list.
 	if rules := st.evm.ChainConfig().Rules(st.evm.Context.BlockNumber); rules.IsBerlin {
 		st.state.PrepareAccessList(msg.From(), msg.To(), vm.ActivePrecompiles(rules), msg.AccessList())
+		// Quorum: a private transaction executes in the private state, which holds its own access list.
+		// It is only set up from Shanghai on, so that the private transactions already executed under
+		// Berlin keep their gas usage and outcome.
+		if isPrivate && rules.IsShanghai {
+			st.evm.PrivateState().PrepareAccessList(msg.From(), msg.To(), vm.ActivePrecompiles(rules), msg.AccessList())
+		}
 	}
 
 	var (
@@ -387,10 +413,16 @@ func (st *StateTransition) TransitionDb() (*ExecutionResult, error) {
 		var exitEarly bool
 		exitEarly, err = pmh.verify(vmerr)
 		if exitEarly {
+			verificationErr := ErrPrivateContractInteractionVerificationFailed
+			if isSimulated {
+				// explain the failure to the caller of the simulation
+				verificationErr = pmh.verificationError()
+			}
 			return &ExecutionResult{
-				UsedGas:    0,
-				Err:        ErrPrivateContractInteractionVerificationFailed,
-				ReturnData: nil,
+				UsedGas:                    0,
+				Err:                        verificationErr,
+				ReturnData:                 nil,
+				PrivacyVerificationFailure: pmh.verificationFailure,
 			}, err
 		}
 	}
@@ -31,6 +31,10 @@ type privateMessageHandler struct {
 	snapshot                int
 	receivedPrivacyMetadata *engine.ExtraMetadata
 	eph                     common.EncryptedPayloadHash
+
+	// verificationFailure explains why the verification failed, it is recorded in the private receipt
+	// and returned to the caller of a simulation
+	verificationFailure *types.PrivacyVerificationFailure
 }
 
 func (pmh *privateMessageHandler) mustVerify() bool {
@@ -71,10 +75,9 @@ func (pmh *privateMessageHandler) prepare() (vmError, consensusErr error) {
 // return error will crash the node and only use when that's the case
 func (pmh *privateMessageHandler) verify(vmerr error) (bool, error) {
 	// convenient function to return error. It has the same signature as the main function
-	returnErrorFunc := func(anError error, logMsg string, ctx ...interface{}) (exitEarly bool, err error) {
-		if logMsg != "" {
-			log.Debug(logMsg, ctx...)
-		}
+	returnErrorFunc := func(anError error, failure *types.PrivacyVerificationFailure) (exitEarly bool, err error) {
+		log.Debug("Private contract interaction verification failed", "reason", failure.Reason, "details", failure.String())
+		pmh.verificationFailure = failure
 		pmh.stAPI.RevertToSnapshot(pmh.snapshot)
 		exitEarly = true
 		if anError != nil {
@@ -95,7 +98,11 @@ func (pmh *privateMessageHandler) verify(vmerr error) (bool, error) {
 		//when privacyMetadata should have been recovered but wasnt (includes non-party)
 		//non party will only be caught here if sender provides privacyFlag
 		if err != nil && privacyFlag.IsNotStandardPrivate() {
-			return returnErrorFunc(nil, "Unable to find PrivacyMetadata for affected contract", "err", err, "addr", addr.Hex())
+			return returnErrorFunc(nil, &types.PrivacyVerificationFailure{
+				Reason:          types.PrivacyMetadataNotFound,
+				ContractAddress: addr,
+				Error:           err.Error(),
+			})
 		}
 		log.Trace("Privacy metadata", "affectedAddress", addr.Hex(), "metadata", actualPrivacyMetadata)
 		// both public and standard private contracts will be nil and can be skipped in acoth check
@@ -107,16 +114,20 @@ func (pmh *privateMessageHandler) verify(vmerr error) (bool, error) {
 		// Check that the affected contracts privacy flag matches the transaction privacy flag.
 		// I know that this is also checked by tessera, but it only checks for non standard private transactions.
 		if actualPrivacyMetadata.PrivacyFlag != pmh.receivedPrivacyMetadata.PrivacyFlag {
-			return returnErrorFunc(nil, "Mismatched privacy flags",
-				"affectedContract.Address", addr.Hex(),
-				"affectedContract.PrivacyFlag", actualPrivacyMetadata.PrivacyFlag,
-				"received.PrivacyFlag", pmh.receivedPrivacyMetadata.PrivacyFlag)
+			return returnErrorFunc(nil, &types.PrivacyVerificationFailure{
+				Reason:              types.PrivacyFlagMismatch,
+				ContractAddress:     addr,
+				ExpectedPrivacyFlag: pmh.receivedPrivacyMetadata.PrivacyFlag,
+				ActualPrivacyFlag:   actualPrivacyMetadata.PrivacyFlag,
+			})
 		}
 		// acoth check - case where node isn't privy to one of actual affecteds
 		if pmh.receivedPrivacyMetadata.ACHashes.NotExist(actualPrivacyMetadata.CreationTxHash) {
-			return returnErrorFunc(nil, "Participation check failed",
-				"affectedContractAddress", addr.Hex(),
-				"missingCreationTxHash", actualPrivacyMetadata.CreationTxHash.Hex())
+			return returnErrorFunc(nil, &types.PrivacyVerificationFailure{
+				Reason:                types.AffectedContractMissing,
+				ContractAddress:       addr,
+				MissingCreationTxHash: actualPrivacyMetadata.CreationTxHash,
+			})
 		}
 	}
 
@@ -125,12 +136,27 @@ func (pmh *privateMessageHandler) verify(vmerr error) (bool, error) {
 		log.Trace("Verify merkle root", "merkleRoot", pmh.receivedPrivacyMetadata.ACMerkleRoot)
 		actualACMerkleRoot, err := pmh.stAPI.CalculateMerkleRoot()
 		if err != nil {
-			return returnErrorFunc(err, "")
+			return returnErrorFunc(err, &types.PrivacyVerificationFailure{
+				Reason: types.MerkleRootUnavailable,
+				Error:  err.Error(),
+			})
 		}
 		if actualACMerkleRoot != pmh.receivedPrivacyMetadata.ACMerkleRoot {
-			return returnErrorFunc(nil, "Merkle Root check failed", "actual", actualACMerkleRoot,
-				"expect", pmh.receivedPrivacyMetadata.ACMerkleRoot)
+			return returnErrorFunc(nil, &types.PrivacyVerificationFailure{
+				Reason:             types.MerkleRootMismatch,
+				ExpectedMerkleRoot: pmh.receivedPrivacyMetadata.ACMerkleRoot,
+				ActualMerkleRoot:   actualACMerkleRoot,
+			})
 		}
 	}
 	return false, nil
 }
+
+// verificationError returns an error wrapping ErrPrivateContractInteractionVerificationFailed, which
+// explains the failure of the verification
+func (pmh *privateMessageHandler) verificationError() error {
+	if pmh.verificationFailure == nil {
+		return ErrPrivateContractInteractionVerificationFailed
+	}
+	return fmt.Errorf("%w: %s", ErrPrivateContractInteractionVerificationFailed, pmh.verificationFailure)
+}
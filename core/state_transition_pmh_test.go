@@ -50,4 +50,53 @@ func TestPrivateMessageContextVerify_WithMerkleRootCreationError(t *testing.T) {
 	assert.Error(err, "verify must return an error due to the MerkleRoot calculation error")
 	assert.Equal(pmc.snapshot, stateTransitionAPI.snapshot, "Revert should have been called")
 	assert.True(exitEarly, "Exit early should be true")
+	assert.Equal(types.MerkleRootUnavailable, pmc.verificationFailure.Reason)
+	assert.Equal("Unable to calculate MerkleRoot", pmc.verificationFailure.Error)
+	assert.ErrorIs(pmc.verificationError(), ErrPrivateContractInteractionVerificationFailed)
+	assert.Contains(pmc.verificationError().Error(), "unable to calculate merkle root: Unable to calculate MerkleRoot")
+}
+
+type stubPmhStateTransitionWithAffectedContract struct {
+	stubPmhStateTransition
+}
+
+func (s *stubPmhStateTransitionWithAffectedContract) AffectedContracts() []common.Address {
+	return []common.Address{common.HexToAddress("0x1")}
+}
+
+func TestPrivateMessageContextVerify_ExplainsMismatchedPrivacyFlags(t *testing.T) {
+	assert := testifyassert.New(t)
+	stateTransitionAPI := &stubPmhStateTransitionWithAffectedContract{}
+
+	pmc := newPMH(stateTransitionAPI)
+	pmc.receivedPrivacyMetadata = &engine.ExtraMetadata{PrivacyFlag: engine.PrivacyFlagPartyProtection}
+	exitEarly, err := pmc.verify(nil)
+
+	assert.NoError(err)
+	assert.True(exitEarly, "Exit early should be true")
+	assert.Equal(&types.PrivacyVerificationFailure{
+		Reason:              types.PrivacyFlagMismatch,
+		ContractAddress:     common.HexToAddress("0x1"),
+		ExpectedPrivacyFlag: engine.PrivacyFlagPartyProtection,
+		ActualPrivacyFlag:   engine.PrivacyFlagStateValidation,
+	}, pmc.verificationFailure)
+	assert.ErrorIs(pmc.verificationError(), ErrPrivateContractInteractionVerificationFailed)
+	assert.Contains(pmc.verificationError().Error(), "affected contract 0x0000000000000000000000000000000000000001 has privacy flag 3, transaction has privacy flag 1")
+}
+
+func TestPrivateMessageContextVerify_ExplainsMissingAffectedContract(t *testing.T) {
+	assert := testifyassert.New(t)
+	stateTransitionAPI := &stubPmhStateTransitionWithAffectedContract{}
+
+	pmc := newPMH(stateTransitionAPI)
+	pmc.receivedPrivacyMetadata = &engine.ExtraMetadata{PrivacyFlag: engine.PrivacyFlagStateValidation}
+	exitEarly, err := pmc.verify(nil)
+
+	assert.NoError(err)
+	assert.True(exitEarly, "Exit early should be true")
+	assert.Equal(&types.PrivacyVerificationFailure{
+		Reason:                types.AffectedContractMissing,
+		ContractAddress:       common.HexToAddress("0x1"),
+		MissingCreationTxHash: common.EncryptedPayloadHash{1},
+	}, pmc.verificationFailure)
 }
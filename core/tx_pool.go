@@ -95,6 +95,10 @@ var (
 	// ErrEtherValueUnsupported is returned if a transaction specifies an Ether Value
 	// for a private Quorum transaction.
 	ErrEtherValueUnsupported = errors.New("ether value is not supported for private transactions")
+
+	// ErrPrivacyMarkerBatchNotEnabled is returned if a privacy marker transaction carries a batch of
+	// private transactions before batches are enabled by the chain config.
+	ErrPrivacyMarkerBatchNotEnabled = errors.New("privacy marker transaction batches are not enabled")
 )
 
 var (
@@ -573,9 +577,16 @@ func (pool *TxPool) validateTx(tx *types.Transaction, local bool) error {
 	}
 	if pool.chainconfig.IsQuorum {
 		// Quorum
+		if tx.IsPrivacyMarkerBatch() {
+			next := new(big.Int).Add(pool.chain.CurrentBlock().Number(), common.Big1)
+			if !pool.chainconfig.IsPrivacyMarkerBatchEnabled(next) {
+				return ErrPrivacyMarkerBatchNotEnabled
+			}
+		}
 		if tx.IsPrivacyMarker() {
-			innerTx, _, _, _ := private.FetchPrivateTransaction(tx.Data())
-			if innerTx != nil {
+			// a privacy marker transaction may carry a batch of private transactions
+			innerTxs, _, _, _ := private.FetchPrivateTransactions(tx.Data())
+			for _, innerTx := range innerTxs {
 				if err := pool.validateTx(innerTx, local); err != nil {
 					return err
 				}
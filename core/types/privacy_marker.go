@@ -0,0 +1,117 @@
+package types
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+var errNotPrivacyMarkerBatch = errors.New("not the data of a privacy marker transaction batch")
+
+// privacyMarkerBatchPrefix starts the data of a privacy marker transaction carrying a batch
+var privacyMarkerBatchPrefix = []byte{0x50, 0x4d, 0x54, 0x42} // "PMTB"
+
+// PrivacyMarkerBatchEntry identifies a private transaction of the batch carried by a privacy
+// marker transaction. The entries are public, so that all the nodes consume the nonces of the
+// senders of the batch, whether or not they are parties to its private transactions. The
+// signature of the sender proves that it authorized the batch to consume its nonce.
+type PrivacyMarkerBatchEntry struct {
+	From      common.Address
+	Nonce     uint64
+	TxHash    common.Hash // hash of the private transaction
+	Signature []byte      // signature by From of the PrivacyMarkerBatchAuthorization of the private transaction
+}
+
+// NewPrivacyMarkerBatchEntry returns the entry of the signed private transaction in a batch, given
+// the signature of its PrivacyMarkerBatchAuthorization by its sender
+func NewPrivacyMarkerBatchEntry(tx *Transaction, signature []byte) PrivacyMarkerBatchEntry {
+	return PrivacyMarkerBatchEntry{
+		From:      tx.From(),
+		Nonce:     tx.Nonce(),
+		TxHash:    tx.Hash(),
+		Signature: common.CopyBytes(signature),
+	}
+}
+
+// PrivacyMarkerBatchAuthorization returns the message which the sender of the private transaction
+// signs, as a text, to authorize a privacy marker transaction batch to carry the transaction and
+// consume its nonce
+func PrivacyMarkerBatchAuthorization(chainID *big.Int, tx *Transaction) []byte {
+	return privacyMarkerBatchAuthorization(chainID, tx.Hash(), tx.Nonce())
+}
+
+func privacyMarkerBatchAuthorization(chainID *big.Int, txHash common.Hash, nonce uint64) []byte {
+	if chainID == nil {
+		chainID = new(big.Int)
+	}
+	message := append(common.CopyBytes(privacyMarkerBatchPrefix), common.BigToHash(chainID).Bytes()...)
+	message = append(message, txHash.Bytes()...)
+	return binary.BigEndian.AppendUint64(message, nonce)
+}
+
+// Authorized returns true if the entry is signed by its sender for the given chain
+func (e *PrivacyMarkerBatchEntry) Authorized(chainID *big.Int) bool {
+	if len(e.Signature) != crypto.SignatureLength {
+		return false
+	}
+	message := privacyMarkerBatchAuthorization(chainID, e.TxHash, e.Nonce)
+	hash := crypto.Keccak256([]byte(fmt.Sprintf("\x19Ethereum Signed Message:\n%d%s", len(message), message)))
+	sig := common.CopyBytes(e.Signature)
+	if sig[crypto.RecoveryIDOffset] >= 27 {
+		// signatures of text are returned with the legacy recovery id
+		sig[crypto.RecoveryIDOffset] -= 27
+	}
+	pub, err := crypto.SigToPub(hash, sig)
+	if err != nil {
+		return false
+	}
+	return crypto.PubkeyToAddress(*pub) == e.From
+}
+
+// IsPrivacyMarkerBatch returns true if the transaction is a privacy marker transaction carrying a
+// batch of private transactions. Its data is a prefix and the RLP encoded entries of the batch,
+// followed by the hash of the batch in the private transaction manager, so that the hash is
+// retrieved from the data as for a single private transaction.
+func (tx *Transaction) IsPrivacyMarkerBatch() bool {
+	return tx.IsPrivacyMarker() && isPrivacyMarkerBatchData(tx.Data())
+}
+
+func isPrivacyMarkerBatchData(data []byte) bool {
+	return len(data) > len(privacyMarkerBatchPrefix)+common.EncryptedPayloadHashLength && bytes.HasPrefix(data, privacyMarkerBatchPrefix)
+}
+
+// NewPrivacyMarkerBatchData returns the data of a privacy marker transaction carrying the batch
+// of private transactions stored in the private transaction manager under the given hash
+func NewPrivacyMarkerBatchData(hash common.EncryptedPayloadHash, entries []PrivacyMarkerBatchEntry) ([]byte, error) {
+	if len(entries) == 0 {
+		return nil, errors.New("empty privacy marker transaction batch")
+	}
+	encoded, err := rlp.EncodeToBytes(entries)
+	if err != nil {
+		return nil, err
+	}
+	data := append(common.CopyBytes(privacyMarkerBatchPrefix), encoded...)
+	return append(data, hash.Bytes()...), nil
+}
+
+// DecodePrivacyMarkerBatchData returns the hash of the batch in the private transaction manager
+// and the entries of the batch carried by a privacy marker transaction
+func DecodePrivacyMarkerBatchData(data []byte) (common.EncryptedPayloadHash, []PrivacyMarkerBatchEntry, error) {
+	if !isPrivacyMarkerBatchData(data) {
+		return common.EncryptedPayloadHash{}, nil, errNotPrivacyMarkerBatch
+	}
+	var entries []PrivacyMarkerBatchEntry
+	if err := rlp.DecodeBytes(data[len(privacyMarkerBatchPrefix):len(data)-common.EncryptedPayloadHashLength], &entries); err != nil {
+		return common.EncryptedPayloadHash{}, nil, err
+	}
+	if len(entries) == 0 {
+		return common.EncryptedPayloadHash{}, nil, errors.New("empty privacy marker transaction batch")
+	}
+	return common.BytesToEncryptedPayloadHash(data), entries, nil
+}
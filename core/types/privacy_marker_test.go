@@ -0,0 +1,93 @@
+package types
+
+import (
+	"crypto/ecdsa"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrivacyMarkerBatchData(t *testing.T) {
+	key1, _ := crypto.GenerateKey()
+	key2, _ := crypto.GenerateKey()
+	signer := QuorumPrivateTxSigner{HomesteadSigner{}}
+	tx1, err := SignTx(NewTransaction(3, common.HexToAddress("0x1"), new(big.Int), 100000, new(big.Int), nil), signer, key1)
+	require.NoError(t, err)
+	tx2, err := SignTx(NewTransaction(7, common.HexToAddress("0x2"), new(big.Int), 100000, new(big.Int), nil), signer, key2)
+	require.NoError(t, err)
+	hash := common.BytesToEncryptedPayloadHash([]byte("batch"))
+
+	batch := []PrivacyMarkerBatchEntry{
+		signPrivacyMarkerBatchEntry(t, big.NewInt(1), tx1, key1),
+		signPrivacyMarkerBatchEntry(t, big.NewInt(1), tx2, key2),
+	}
+
+	data, err := NewPrivacyMarkerBatchData(hash, batch)
+	require.NoError(t, err)
+
+	decodedHash, entries, err := DecodePrivacyMarkerBatchData(data)
+	require.NoError(t, err)
+	assert.Equal(t, hash, decodedHash)
+	assert.Equal(t, batch, entries)
+	assert.Equal(t, crypto.PubkeyToAddress(key1.PublicKey), entries[0].From)
+	assert.Equal(t, uint64(7), entries[1].Nonce)
+	assert.Equal(t, tx2.Hash(), entries[1].TxHash)
+	assert.Equal(t, hash, common.BytesToEncryptedPayloadHash(data))
+
+	pmt := NewTransaction(0, common.QuorumPrivacyPrecompileContractAddress(), new(big.Int), 0, new(big.Int), data)
+	assert.True(t, pmt.IsPrivacyMarkerBatch())
+}
+
+func TestPrivacyMarkerBatchData_whenSinglePrivateTransaction(t *testing.T) {
+	hash := common.BytesToEncryptedPayloadHash([]byte("private tx"))
+	for _, data := range [][]byte{hash.Bytes(), append(common.HexToAddress("0x1").Bytes(), hash.Bytes()...)} {
+		pmt := NewTransaction(0, common.QuorumPrivacyPrecompileContractAddress(), new(big.Int), 0, new(big.Int), data)
+
+		assert.True(t, pmt.IsPrivacyMarker())
+		assert.False(t, pmt.IsPrivacyMarkerBatch())
+		_, _, err := DecodePrivacyMarkerBatchData(pmt.Data())
+		assert.Error(t, err)
+	}
+
+	_, err := NewPrivacyMarkerBatchData(hash, nil)
+	assert.Error(t, err)
+}
+
+func TestPrivacyMarkerBatchEntry_Authorized(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	otherKey, _ := crypto.GenerateKey()
+	tx, err := SignTx(NewTransaction(3, common.HexToAddress("0x1"), new(big.Int), 100000, new(big.Int), nil), QuorumPrivateTxSigner{HomesteadSigner{}}, key)
+	require.NoError(t, err)
+	chainID := big.NewInt(10)
+
+	entry := signPrivacyMarkerBatchEntry(t, chainID, tx, key)
+	assert.True(t, entry.Authorized(chainID))
+	assert.False(t, entry.Authorized(big.NewInt(11)), "signed for another chain")
+
+	legacy := entry
+	legacy.Signature = common.CopyBytes(entry.Signature)
+	legacy.Signature[crypto.RecoveryIDOffset] += 27
+	assert.True(t, legacy.Authorized(chainID), "signature with the legacy recovery id")
+
+	otherNonce := entry
+	otherNonce.Nonce = 4
+	assert.False(t, otherNonce.Authorized(chainID), "nonce not signed by the sender")
+
+	otherSigner := signPrivacyMarkerBatchEntry(t, chainID, tx, otherKey)
+	assert.False(t, otherSigner.Authorized(chainID), "signed by another account")
+
+	unsigned := NewPrivacyMarkerBatchEntry(tx, nil)
+	assert.False(t, unsigned.Authorized(chainID))
+}
+
+func signPrivacyMarkerBatchEntry(t *testing.T, chainID *big.Int, tx *Transaction, key *ecdsa.PrivateKey) PrivacyMarkerBatchEntry {
+	message := PrivacyMarkerBatchAuthorization(chainID, tx)
+	sig, err := crypto.Sign(crypto.Keccak256([]byte(fmt.Sprintf("\x19Ethereum Signed Message:\n%d%s", len(message), message))), key)
+	require.NoError(t, err)
+	return NewPrivacyMarkerBatchEntry(tx, sig)
+}
@@ -0,0 +1,89 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/private/engine"
+)
+
+// PrivacyVerificationFailureReason identifies the check of the privacy enhancements which failed
+// when a private transaction was applied
+type PrivacyVerificationFailureReason string
+
+const (
+	// PrivacyMetadataNotFound is the reason when an affected contract has no privacy metadata, e.g.
+	// as the node is not a party to the contract
+	PrivacyMetadataNotFound PrivacyVerificationFailureReason = "privacyMetadataNotFound"
+	// PrivacyFlagMismatch is the reason when the privacy flag of an affected contract differs from
+	// the privacy flag of the transaction
+	PrivacyFlagMismatch PrivacyVerificationFailureReason = "privacyFlagMismatch"
+	// AffectedContractMissing is the reason when the creation transaction of an affected contract is
+	// not one of the affected contract transactions received from the private transaction manager
+	AffectedContractMissing PrivacyVerificationFailureReason = "affectedContractMissing"
+	// MerkleRootMismatch is the reason when the merkle root of the affected contracts differs from
+	// the one received from the private transaction manager
+	MerkleRootMismatch PrivacyVerificationFailureReason = "merkleRootMismatch"
+	// MerkleRootUnavailable is the reason when the merkle root of the affected contracts can not be
+	// calculated
+	MerkleRootUnavailable PrivacyVerificationFailureReason = "merkleRootUnavailable"
+)
+
+// PrivacyVerificationFailure explains why the verification of a party protection or private state
+// validation transaction failed. Only the fields relevant to the reason are set.
+type PrivacyVerificationFailure struct {
+	Reason                PrivacyVerificationFailureReason
+	ContractAddress       common.Address
+	ExpectedPrivacyFlag   engine.PrivacyFlagType // the privacy flag of the transaction
+	ActualPrivacyFlag     engine.PrivacyFlagType // the privacy flag of the affected contract
+	MissingCreationTxHash common.EncryptedPayloadHash
+	ExpectedMerkleRoot    common.Hash
+	ActualMerkleRoot      common.Hash
+	Error                 string
+}
+
+func (f *PrivacyVerificationFailure) String() string {
+	switch f.Reason {
+	case PrivacyMetadataNotFound:
+		return fmt.Sprintf("unable to find privacy metadata for affected contract %s: %s", f.ContractAddress.Hex(), f.Error)
+	case PrivacyFlagMismatch:
+		return fmt.Sprintf("mismatched privacy flags: affected contract %s has privacy flag %d, transaction has privacy flag %d",
+			f.ContractAddress.Hex(), f.ActualPrivacyFlag, f.ExpectedPrivacyFlag)
+	case AffectedContractMissing:
+		return fmt.Sprintf("participation check failed: creation transaction %s of affected contract %s is missing",
+			f.MissingCreationTxHash.ToBase64(), f.ContractAddress.Hex())
+	case MerkleRootMismatch:
+		return fmt.Sprintf("merkle root check failed: expected %s, actual %s", f.ExpectedMerkleRoot.Hex(), f.ActualMerkleRoot.Hex())
+	case MerkleRootUnavailable:
+		return fmt.Sprintf("unable to calculate merkle root: %s", f.Error)
+	default:
+		return string(f.Reason)
+	}
+}
+
+// MarshalJSON returns the reason of the failure and the fields relevant to the reason
+func (f *PrivacyVerificationFailure) MarshalJSON() ([]byte, error) {
+	fields := map[string]interface{}{
+		"reason":  f.Reason,
+		"message": f.String(),
+	}
+	switch f.Reason {
+	case PrivacyMetadataNotFound:
+		fields["contractAddress"] = f.ContractAddress
+		fields["error"] = f.Error
+	case PrivacyFlagMismatch:
+		fields["contractAddress"] = f.ContractAddress
+		fields["expectedPrivacyFlag"] = f.ExpectedPrivacyFlag
+		fields["actualPrivacyFlag"] = f.ActualPrivacyFlag
+	case AffectedContractMissing:
+		fields["contractAddress"] = f.ContractAddress
+		fields["missingCreationTxHash"] = f.MissingCreationTxHash.ToBase64()
+	case MerkleRootMismatch:
+		fields["expectedMerkleRoot"] = f.ExpectedMerkleRoot
+		fields["actualMerkleRoot"] = f.ActualMerkleRoot
+	case MerkleRootUnavailable:
+		fields["error"] = f.Error
+	}
+	return json.Marshal(fields)
+}
@@ -71,6 +71,11 @@ type Receipt struct {
 	BlockHash        common.Hash `json:"blockHash,omitempty"`
 	BlockNumber      *big.Int    `json:"blockNumber,omitempty"`
 	TransactionIndex uint        `json:"transactionIndex"`
+
+	// Quorum
+	// PrivatePayloadHash is the hash of the private payload of the private transaction, set when the
+	// transaction is executed. It is neither stored nor derived, so it is empty for stored receipts.
+	PrivatePayloadHash common.EncryptedPayloadHash `json:"-"`
 }
 
 // (Quorum)
@@ -105,6 +110,13 @@ type QuorumReceiptExtraData struct {
 	PSReceipts map[PrivateStateIdentifier]*Receipt `json:"-"`
 	// support saving the revert reason into the receipt itself for later consultation.
 	RevertReason []byte `json:"revertReason,omitempty"`
+	// BatchReceipts holds the receipts of the private transactions of a batch carried by a privacy
+	// marker transaction, in order, for the receipts in PSReceipts of the privacy marker transaction.
+	// The logs of the batch receipt are the logs of these receipts.
+	BatchReceipts []*Receipt `json:"-"`
+	// PrivacyVerificationFailure explains why the private receipt of a party protection or private
+	// state validation transaction is failed by the privacy enhancements checks
+	PrivacyVerificationFailure *PrivacyVerificationFailure `json:"privacyVerificationFailure,omitempty"`
 }
 
 // End Quorum
@@ -491,6 +503,9 @@ func (r Receipts) DeriveFields(config *params.ChainConfig, hash common.Hash, num
 				psiReceipt.TxHash = r[i].PSReceipts[psi].TxHash
 				psiReceipt.ContractAddress = r[i].PSReceipts[psi].ContractAddress
 			}
+			if r[i].PSReceipts != nil && r[i].PSReceipts[psi] != nil && r[i].PSReceipts[psi].BatchReceipts != nil {
+				psiReceipt.BatchReceipts = deriveBatchReceiptsFields(psiReceipt, r[i].PSReceipts[psi].BatchReceipts)
+			}
 			tmp[i].PSReceipts[psi] = psiReceipt
 		}
 	}
@@ -501,6 +516,26 @@ func (r Receipts) DeriveFields(config *params.ChainConfig, hash common.Hash, num
 	return nil
 }
 
+// deriveBatchReceiptsFields returns copies of the receipts of the private transactions of a batch
+// with the location fields of the receipt of the batch. The logs of the batch receipt, whose fields
+// are derived, are the logs of the receipts of the batch in order.
+func deriveBatchReceiptsFields(batchReceipt *Receipt, batchReceipts []*Receipt) []*Receipt {
+	result := make([]*Receipt, len(batchReceipts))
+	logIndex := 0
+	for i, receiptOrig := range batchReceipts {
+		receiptCopy := *receiptOrig
+		receiptCopy.BlockHash = batchReceipt.BlockHash
+		receiptCopy.BlockNumber = batchReceipt.BlockNumber
+		receiptCopy.TransactionIndex = batchReceipt.TransactionIndex
+		if logIndex+len(receiptCopy.Logs) <= len(batchReceipt.Logs) {
+			receiptCopy.Logs = batchReceipt.Logs[logIndex : logIndex+len(receiptCopy.Logs)]
+		}
+		logIndex += len(receiptCopy.Logs)
+		result[i] = &receiptCopy
+	}
+	return result
+}
+
 // deriveFieldsOrig is the original DeriveFields from upstream
 func (r Receipts) deriveFieldsOrig(config *params.ChainConfig, hash common.Hash, number uint64, txs Transactions) error {
 	signer := MakeSigner(config, new(big.Int).SetUint64(number))
@@ -556,6 +591,12 @@ func (r *Receipt) FillReceiptExtraDataFromStorage(data *QuorumReceiptExtraData)
 	if data.RevertReason != nil {
 		r.RevertReason = data.RevertReason
 	}
+	if data.BatchReceipts != nil {
+		r.BatchReceipts = data.BatchReceipts
+	}
+	if data.PrivacyVerificationFailure != nil {
+		r.PrivacyVerificationFailure = data.PrivacyVerificationFailure
+	}
 }
 
 // storedQuorumReceiptExtraDataV1RLP is the storage encoding of a receipt extra data which contains
@@ -581,6 +622,35 @@ type storedReceiptExtraDataV1 struct {
 	ContractAddress   common.Address
 }
 
+// storedQuorumReceiptExtraDataV2RLP is the storage encoding of a receipt extra data which contains
+// receipts per PSI, with the receipts of the private transactions of a batch, Revert Reason and
+// the failure of the privacy enhancements checks
+type storedQuorumReceiptExtraDataV2RLP struct {
+	Version                    uint
+	PSReceipts                 []storedPSIToReceiptMapEntryV2
+	RevertReason               []byte
+	BatchReceipts              []storedReceiptExtraDataV2
+	PrivacyVerificationFailure *PrivacyVerificationFailure `rlp:"nil"`
+}
+
+type storedPSIToReceiptMapEntryV2 struct {
+	Key   PrivateStateIdentifier
+	Value storedReceiptExtraDataV2
+}
+
+// storedReceiptExtraDataV2 is the storage encoding of a receipt from geth upstream, with added revert
+// reason, receipts of the private transactions of a batch and failure of the privacy enhancements checks
+type storedReceiptExtraDataV2 struct {
+	PostStateOrStatus          []byte
+	CumulativeGasUsed          uint64
+	Logs                       []*LogForStorage
+	RevertReason               []byte
+	TxHash                     common.Hash
+	ContractAddress            common.Address
+	BatchReceipts              []storedReceiptExtraDataV2
+	PrivacyVerificationFailure *PrivacyVerificationFailure `rlp:"nil"`
+}
+
 // Flatten takes a list of private receipts, which will be the "private" PSI receipt,
 // and flatten all the MPS receipts into a single list, which the bloom can work with
 func (r Receipts) Flatten() []*Receipt {
@@ -594,30 +664,64 @@ func (r Receipts) Flatten() []*Receipt {
 	return flattenedReceipts
 }
 
-func convertPrivateReceiptsForEncoding(psReceipts map[PrivateStateIdentifier]*Receipt) []storedPSIToReceiptMapEntryV1 {
+func convertPrivateReceiptsForEncodingV1(psReceipts map[PrivateStateIdentifier]*Receipt) []storedPSIToReceiptMapEntryV1 {
 	if psReceipts == nil {
 		return nil
 	}
 	result := make([]storedPSIToReceiptMapEntryV1, len(psReceipts))
 	idx := 0
 	for key, val := range psReceipts {
-		rec := storedReceiptExtraDataV1{
+		result[idx] = storedPSIToReceiptMapEntryV1{Key: key, Value: storedReceiptExtraDataV1{
 			PostStateOrStatus: val.statusEncoding(),
 			CumulativeGasUsed: val.CumulativeGasUsed,
 			Logs:              convertLogsForEncoding(val.Logs),
 			RevertReason:      val.RevertReason,
 			TxHash:            val.TxHash,
 			ContractAddress:   val.ContractAddress,
-		}
-		for i, log := range val.Logs {
-			rec.Logs[i] = (*LogForStorage)(log)
-		}
-		result[idx] = storedPSIToReceiptMapEntryV1{Key: key, Value: rec}
+		}}
 		idx++
 	}
 	return result
 }
 
+func convertPrivateReceiptsForEncoding(psReceipts map[PrivateStateIdentifier]*Receipt) []storedPSIToReceiptMapEntryV2 {
+	if psReceipts == nil {
+		return nil
+	}
+	result := make([]storedPSIToReceiptMapEntryV2, len(psReceipts))
+	idx := 0
+	for key, val := range psReceipts {
+		result[idx] = storedPSIToReceiptMapEntryV2{Key: key, Value: convertReceiptForEncoding(val)}
+		idx++
+	}
+	return result
+}
+
+func convertReceiptForEncoding(receipt *Receipt) storedReceiptExtraDataV2 {
+	rec := storedReceiptExtraDataV2{
+		PostStateOrStatus:          receipt.statusEncoding(),
+		CumulativeGasUsed:          receipt.CumulativeGasUsed,
+		Logs:                       convertLogsForEncoding(receipt.Logs),
+		RevertReason:               receipt.RevertReason,
+		TxHash:                     receipt.TxHash,
+		ContractAddress:            receipt.ContractAddress,
+		PrivacyVerificationFailure: receipt.PrivacyVerificationFailure,
+	}
+	rec.BatchReceipts = convertBatchReceiptsForEncoding(receipt.BatchReceipts)
+	return rec
+}
+
+func convertBatchReceiptsForEncoding(batchReceipts []*Receipt) []storedReceiptExtraDataV2 {
+	if batchReceipts == nil {
+		return nil
+	}
+	result := make([]storedReceiptExtraDataV2, len(batchReceipts))
+	for i, batchReceipt := range batchReceipts {
+		result[i] = convertReceiptForEncoding(batchReceipt)
+	}
+	return result
+}
+
 func convertPrivateReceiptsForDecoding(storedPSReceipts []storedPSIToReceiptMapEntryV1) (map[PrivateStateIdentifier]*Receipt, error) {
 	if len(storedPSReceipts) <= 0 {
 		return nil, nil
@@ -625,25 +729,83 @@ func convertPrivateReceiptsForDecoding(storedPSReceipts []storedPSIToReceiptMapE
 
 	result := make(map[PrivateStateIdentifier]*Receipt)
 	for _, entry := range storedPSReceipts {
-		rec := &Receipt{}
-		if err := rec.setStatus(entry.Value.PostStateOrStatus); err != nil {
+		rec, err := convertReceiptForDecoding(entry.Key, entry.Value)
+		if err != nil {
 			return nil, err
 		}
-		rec.CumulativeGasUsed = entry.Value.CumulativeGasUsed
-		rec.Logs = make([]*Log, len(entry.Value.Logs))
-		for i, log := range entry.Value.Logs {
-			rec.Logs[i] = (*Log)(log)
-			rec.Logs[i].PSI = entry.Key
+		result[entry.Key] = rec
+	}
+	return result, nil
+}
+
+func convertPrivateReceiptsForDecodingV2(storedPSReceipts []storedPSIToReceiptMapEntryV2) (map[PrivateStateIdentifier]*Receipt, error) {
+	if len(storedPSReceipts) <= 0 {
+		return nil, nil
+	}
+
+	result := make(map[PrivateStateIdentifier]*Receipt)
+	for _, entry := range storedPSReceipts {
+		rec, err := convertReceiptForDecodingV2(entry.Key, entry.Value)
+		if err != nil {
+			return nil, err
 		}
-		rec.Bloom = CreateBloom(Receipts{rec})
-		rec.RevertReason = entry.Value.RevertReason
-		rec.TxHash = entry.Value.TxHash
-		rec.ContractAddress = entry.Value.ContractAddress
 		result[entry.Key] = rec
 	}
 	return result, nil
 }
 
+func convertReceiptForDecodingV2(psi PrivateStateIdentifier, stored storedReceiptExtraDataV2) (*Receipt, error) {
+	rec, err := convertReceiptForDecoding(psi, storedReceiptExtraDataV1{
+		PostStateOrStatus: stored.PostStateOrStatus,
+		CumulativeGasUsed: stored.CumulativeGasUsed,
+		Logs:              stored.Logs,
+		RevertReason:      stored.RevertReason,
+		TxHash:            stored.TxHash,
+		ContractAddress:   stored.ContractAddress,
+	})
+	if err != nil {
+		return nil, err
+	}
+	rec.PrivacyVerificationFailure = stored.PrivacyVerificationFailure
+	if rec.BatchReceipts, err = convertBatchReceiptsForDecodingV2(psi, stored.BatchReceipts); err != nil {
+		return nil, err
+	}
+	return rec, nil
+}
+
+func convertBatchReceiptsForDecodingV2(psi PrivateStateIdentifier, storedBatchReceipts []storedReceiptExtraDataV2) ([]*Receipt, error) {
+	if len(storedBatchReceipts) == 0 {
+		return nil, nil
+	}
+	result := make([]*Receipt, len(storedBatchReceipts))
+	for i, storedBatchReceipt := range storedBatchReceipts {
+		rec, err := convertReceiptForDecodingV2(psi, storedBatchReceipt)
+		if err != nil {
+			return nil, err
+		}
+		result[i] = rec
+	}
+	return result, nil
+}
+
+func convertReceiptForDecoding(psi PrivateStateIdentifier, stored storedReceiptExtraDataV1) (*Receipt, error) {
+	rec := &Receipt{}
+	if err := rec.setStatus(stored.PostStateOrStatus); err != nil {
+		return nil, err
+	}
+	rec.CumulativeGasUsed = stored.CumulativeGasUsed
+	rec.Logs = make([]*Log, len(stored.Logs))
+	for i, log := range stored.Logs {
+		rec.Logs[i] = (*Log)(log)
+		rec.Logs[i].PSI = psi
+	}
+	rec.Bloom = CreateBloom(Receipts{rec})
+	rec.RevertReason = stored.RevertReason
+	rec.TxHash = stored.TxHash
+	rec.ContractAddress = stored.ContractAddress
+	return rec, nil
+}
+
 func convertLogsForEncoding(logs []*Log) []*LogForStorage {
 	result := make([]*LogForStorage, len(logs))
 	for i, log := range logs {
@@ -666,6 +828,27 @@ func decodeStoredQuorumReceiptExtraDataV1(r *QuorumReceiptExtraData, blob []byte
 	return nil
 }
 
+func decodeStoredQuorumReceiptExtraDataV2(r *QuorumReceiptExtraData, blob []byte) error {
+	var stored storedQuorumReceiptExtraDataV2RLP
+	if err := rlp.DecodeBytes(blob, &stored); err != nil {
+		return err
+	}
+	psReceipts, err := convertPrivateReceiptsForDecodingV2(stored.PSReceipts)
+	if err != nil {
+		return err
+	}
+	r.PSReceipts = psReceipts
+	r.RevertReason = stored.RevertReason
+	r.PrivacyVerificationFailure = stored.PrivacyVerificationFailure
+	// the batch receipts of the receipt itself are not bound to a private state
+	batchReceipts, err := convertBatchReceiptsForDecodingV2("", stored.BatchReceipts)
+	if err != nil {
+		return err
+	}
+	r.BatchReceipts = batchReceipts
+	return nil
+}
+
 func (r *QuorumReceiptExtraData) DecodeRLP(s *rlp.Stream) error {
 	blob, err := s.Raw()
 	if err != nil {
@@ -688,22 +871,49 @@ func (r *QuorumReceiptExtraData) DecodeRLP(s *rlp.Stream) error {
 	switch version {
 	case 1:
 		return decodeStoredQuorumReceiptExtraDataV1(r, blob)
+	case 2:
+		return decodeStoredQuorumReceiptExtraDataV2(r, blob)
 	default:
 		return fmt.Errorf("unknown version %d", version)
 	}
 }
 
+// EncodeRLP writes the extra data in the V1 layout unless it, or one of its private receipts,
+// carries batch receipts or a privacy verification failure, so that receipts which do not use
+// them can still be read by nodes which only know about V1
 func (r *QuorumReceiptExtraData) EncodeRLP(w io.Writer) error {
-	enc := &storedQuorumReceiptExtraDataV1RLP{
-		Version:      1,
-		PSReceipts:   convertPrivateReceiptsForEncoding(r.PSReceipts),
-		RevertReason: r.RevertReason,
+	if !r.requiresV2() {
+		return rlp.Encode(w, &storedQuorumReceiptExtraDataV1RLP{
+			Version:      1,
+			PSReceipts:   convertPrivateReceiptsForEncodingV1(r.PSReceipts),
+			RevertReason: r.RevertReason,
+		})
+	}
+	enc := &storedQuorumReceiptExtraDataV2RLP{
+		Version:                    2,
+		PSReceipts:                 convertPrivateReceiptsForEncoding(r.PSReceipts),
+		RevertReason:               r.RevertReason,
+		PrivacyVerificationFailure: r.PrivacyVerificationFailure,
+		BatchReceipts:              convertBatchReceiptsForEncoding(r.BatchReceipts),
 	}
 	return rlp.Encode(w, enc)
 }
 
+// requiresV2 reports whether any of the fields introduced in V2 is set
+func (r *QuorumReceiptExtraData) requiresV2() bool {
+	if len(r.BatchReceipts) > 0 || r.PrivacyVerificationFailure != nil {
+		return true
+	}
+	for _, psReceipt := range r.PSReceipts {
+		if len(psReceipt.BatchReceipts) > 0 || psReceipt.PrivacyVerificationFailure != nil {
+			return true
+		}
+	}
+	return false
+}
+
 func (r *QuorumReceiptExtraData) IsEmpty() bool {
-	return len(r.PSReceipts) == 0 && r.RevertReason == nil
+	return len(r.PSReceipts) == 0 && r.RevertReason == nil && len(r.BatchReceipts) == 0 && r.PrivacyVerificationFailure == nil
 }
 
 // LEGACY STRUCTURES TO COPE WITH MPS RECEIPT RLP ENCODING
@@ -18,6 +18,7 @@ package types
 
 import (
 	"bytes"
+	"encoding/json"
 	"math"
 	"math/big"
 	"reflect"
@@ -683,14 +684,14 @@ func TestQuorumReceiptExtraDataDecodingSuccess(t *testing.T) {
 
 func TestQuorumReceiptExtraDataDecodingFailDueToUnknownVersion(t *testing.T) {
 	rlpData, err := rlp.EncodeToBytes(&storedQuorumReceiptExtraDataV1RLP{
-		Version:      2,
+		Version:      4,
 		RevertReason: []byte("arbitrary reason"),
 	})
 	assert.Nil(t, err)
 	var decodedExtraData QuorumReceiptExtraData
 	err = rlp.DecodeBytes(rlpData, &decodedExtraData)
 	assert.NotNil(t, err)
-	assert.EqualError(t, err, "unknown version 2")
+	assert.EqualError(t, err, "unknown version 4")
 }
 
 func TestQuorumReceiptExtraDataDecodingFailDueToGarbageData(t *testing.T) {
@@ -699,3 +700,264 @@ func TestQuorumReceiptExtraDataDecodingFailDueToGarbageData(t *testing.T) {
 	assert.NotNil(t, err)
 	assert.EqualError(t, err, "unexpected content type (expecting list) 0")
 }
+
+func TestQuorumReceiptExtraDataDecodingWithBatchReceipts(t *testing.T) {
+	innerTx1 := NewTransaction(1, common.HexToAddress("0x1"), big.NewInt(0), 1, big.NewInt(0), nil)
+	innerTx2 := NewTransaction(2, common.HexToAddress("0x2"), big.NewInt(0), 1, big.NewInt(0), nil)
+	log1 := &Log{Address: common.BytesToAddress([]byte{0x11}), Topics: []common.Hash{common.HexToHash("dead")}}
+	log2 := &Log{Address: common.BytesToAddress([]byte{0x22}), Topics: []common.Hash{common.HexToHash("beef")}}
+	batchReceipt := &Receipt{
+		Status: ReceiptStatusSuccessful,
+		Logs:   []*Log{log1, log2},
+		QuorumReceiptExtraData: QuorumReceiptExtraData{
+			BatchReceipts: []*Receipt{
+				{Status: ReceiptStatusSuccessful, Logs: []*Log{log1, log2}, TxHash: innerTx1.Hash(), ContractAddress: common.BytesToAddress([]byte{0x33})},
+				{Status: ReceiptStatusFailed, Logs: []*Log{}, TxHash: innerTx2.Hash(), QuorumReceiptExtraData: QuorumReceiptExtraData{RevertReason: []byte("reverted")}},
+			},
+		},
+	}
+
+	rlpData, err := rlp.EncodeToBytes(&QuorumReceiptExtraData{
+		PSReceipts: map[PrivateStateIdentifier]*Receipt{PrivateStateIdentifier("psi1"): batchReceipt},
+	})
+	assert.Nil(t, err)
+	var decodedExtraData QuorumReceiptExtraData
+	err = rlp.DecodeBytes(rlpData, &decodedExtraData)
+	assert.Nil(t, err)
+
+	decodedReceipt := decodedExtraData.PSReceipts[PrivateStateIdentifier("psi1")]
+	assert.NotNil(t, decodedReceipt)
+	assert.Len(t, decodedReceipt.Logs, 2)
+	assert.Len(t, decodedReceipt.BatchReceipts, 2)
+	assert.Equal(t, innerTx1.Hash(), decodedReceipt.BatchReceipts[0].TxHash)
+	assert.Equal(t, common.BytesToAddress([]byte{0x33}), decodedReceipt.BatchReceipts[0].ContractAddress)
+	assert.Equal(t, ReceiptStatusSuccessful, decodedReceipt.BatchReceipts[0].Status)
+	assert.Len(t, decodedReceipt.BatchReceipts[0].Logs, 2)
+	assert.Equal(t, PrivateStateIdentifier("psi1"), decodedReceipt.BatchReceipts[0].Logs[0].PSI)
+	assert.Equal(t, innerTx2.Hash(), decodedReceipt.BatchReceipts[1].TxHash)
+	assert.Equal(t, ReceiptStatusFailed, decodedReceipt.BatchReceipts[1].Status)
+	assert.Equal(t, []byte("reverted"), decodedReceipt.BatchReceipts[1].RevertReason)
+}
+
+func TestQuorumReceiptExtraDataDecodingWithTopLevelBatchReceipts(t *testing.T) {
+	innerTx := NewTransaction(1, common.HexToAddress("0x1"), big.NewInt(0), 1, big.NewInt(0), nil)
+	extraData := &QuorumReceiptExtraData{
+		BatchReceipts: []*Receipt{{Status: ReceiptStatusSuccessful, Logs: []*Log{}, TxHash: innerTx.Hash()}},
+	}
+	assert.False(t, extraData.IsEmpty())
+
+	rlpData, err := rlp.EncodeToBytes(extraData)
+	assert.Nil(t, err)
+	var decodedExtraData QuorumReceiptExtraData
+	err = rlp.DecodeBytes(rlpData, &decodedExtraData)
+	assert.Nil(t, err)
+
+	assert.Len(t, decodedExtraData.BatchReceipts, 1)
+	assert.Equal(t, innerTx.Hash(), decodedExtraData.BatchReceipts[0].TxHash)
+	assert.Equal(t, ReceiptStatusSuccessful, decodedExtraData.BatchReceipts[0].Status)
+}
+
+func TestQuorumReceiptExtraDataDecodingV1(t *testing.T) {
+	tx := NewTransaction(1, common.HexToAddress("0x1"), big.NewInt(1), 1, big.NewInt(1), nil)
+	rlpData, err := rlp.EncodeToBytes(&storedQuorumReceiptExtraDataV1RLP{
+		Version: 1,
+		PSReceipts: []storedPSIToReceiptMapEntryV1{{
+			Key: PrivateStateIdentifier("psi1"),
+			Value: storedReceiptExtraDataV1{
+				PostStateOrStatus: receiptStatusSuccessfulRLP,
+				CumulativeGasUsed: 1,
+				Logs:              []*LogForStorage{},
+				TxHash:            tx.Hash(),
+			},
+		}},
+		RevertReason: []byte("arbitrary reason"),
+	})
+	assert.Nil(t, err)
+	var decodedExtraData QuorumReceiptExtraData
+	err = rlp.DecodeBytes(rlpData, &decodedExtraData)
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("arbitrary reason"), decodedExtraData.RevertReason)
+	decodedReceipt := decodedExtraData.PSReceipts[PrivateStateIdentifier("psi1")]
+	assert.NotNil(t, decodedReceipt)
+	assert.Equal(t, tx.Hash(), decodedReceipt.TxHash)
+	assert.Equal(t, ReceiptStatusSuccessful, decodedReceipt.Status)
+	assert.Nil(t, decodedReceipt.BatchReceipts)
+}
+
+func TestQuorumReceiptExtraDataEncodingV1RoundTrip(t *testing.T) {
+	tx := NewTransaction(1, common.HexToAddress("0x1"), big.NewInt(1), 1, big.NewInt(1), nil)
+	stored := &storedQuorumReceiptExtraDataV1RLP{
+		Version: 1,
+		PSReceipts: []storedPSIToReceiptMapEntryV1{{
+			Key: PrivateStateIdentifier("psi1"),
+			Value: storedReceiptExtraDataV1{
+				PostStateOrStatus: receiptStatusSuccessfulRLP,
+				CumulativeGasUsed: 1,
+				Logs: []*LogForStorage{{
+					Address: common.BytesToAddress([]byte{0x11}),
+					Topics:  []common.Hash{common.HexToHash("dead")},
+					Data:    []byte{0x01, 0x00, 0xff},
+				}},
+				RevertReason:    []byte("ps reason"),
+				TxHash:          tx.Hash(),
+				ContractAddress: common.BytesToAddress([]byte{0x01, 0x11, 0x11}),
+			},
+		}},
+		RevertReason: []byte("arbitrary reason"),
+	}
+	rlpData, err := rlp.EncodeToBytes(stored)
+	assert.Nil(t, err)
+	var decodedExtraData QuorumReceiptExtraData
+	assert.Nil(t, rlp.DecodeBytes(rlpData, &decodedExtraData))
+
+	reencoded, err := rlp.EncodeToBytes(&decodedExtraData)
+
+	assert.Nil(t, err)
+	assert.Equal(t, rlpData, reencoded, "expected a V1 receipt to be stored unchanged")
+}
+
+func TestQuorumReceiptExtraDataEncodingV2(t *testing.T) {
+	tx := NewTransaction(1, common.HexToAddress("0x1"), big.NewInt(1), 1, big.NewInt(1), nil)
+	batchReceipt := &Receipt{Status: ReceiptStatusSuccessful, Logs: []*Log{}, TxHash: tx.Hash()}
+	for name, extraData := range map[string]*QuorumReceiptExtraData{
+		"batch receipts": {BatchReceipts: []*Receipt{batchReceipt}},
+		"privacy verification failure": {
+			PrivacyVerificationFailure: &PrivacyVerificationFailure{Reason: PrivacyFlagMismatch},
+		},
+		"private receipt with batch receipts": {
+			PSReceipts: map[PrivateStateIdentifier]*Receipt{
+				PrivateStateIdentifier("psi1"): {
+					Status:                 ReceiptStatusSuccessful,
+					Logs:                   []*Log{},
+					QuorumReceiptExtraData: QuorumReceiptExtraData{BatchReceipts: []*Receipt{batchReceipt}},
+				},
+			},
+		},
+	} {
+		rlpData, err := rlp.EncodeToBytes(extraData)
+		assert.Nil(t, err, name)
+		_, content, _, err := rlp.Split(rlpData)
+		assert.Nil(t, err, name)
+		version, _, err := rlp.SplitUint64(content)
+		assert.Nil(t, err, name)
+		assert.Equal(t, uint64(2), version, name)
+	}
+}
+
+func TestQuorumReceiptExtraDataDecodingV2(t *testing.T) {
+	tx := NewTransaction(1, common.HexToAddress("0x1"), big.NewInt(1), 1, big.NewInt(1), nil)
+	rlpData, err := rlp.EncodeToBytes(&storedQuorumReceiptExtraDataV2RLP{
+		Version: 2,
+		PSReceipts: []storedPSIToReceiptMapEntryV2{{
+			Key: PrivateStateIdentifier("psi1"),
+			Value: storedReceiptExtraDataV2{
+				PostStateOrStatus: receiptStatusSuccessfulRLP,
+				CumulativeGasUsed: 1,
+				Logs:              []*LogForStorage{},
+				TxHash:            tx.Hash(),
+				BatchReceipts: []storedReceiptExtraDataV2{{
+					PostStateOrStatus: receiptStatusFailedRLP,
+					Logs:              []*LogForStorage{},
+					TxHash:            tx.Hash(),
+				}},
+			},
+		}},
+	})
+	assert.Nil(t, err)
+	var decodedExtraData QuorumReceiptExtraData
+	err = rlp.DecodeBytes(rlpData, &decodedExtraData)
+	assert.Nil(t, err)
+	decodedReceipt := decodedExtraData.PSReceipts[PrivateStateIdentifier("psi1")]
+	assert.NotNil(t, decodedReceipt)
+	assert.Equal(t, tx.Hash(), decodedReceipt.TxHash)
+	assert.Len(t, decodedReceipt.BatchReceipts, 1)
+	assert.Equal(t, ReceiptStatusFailed, decodedReceipt.BatchReceipts[0].Status)
+	assert.Nil(t, decodedReceipt.PrivacyVerificationFailure)
+	assert.Nil(t, decodedExtraData.BatchReceipts)
+}
+
+func TestQuorumReceiptExtraDataDecodingWithPrivacyVerificationFailure(t *testing.T) {
+	flagMismatch := &PrivacyVerificationFailure{
+		Reason:              PrivacyFlagMismatch,
+		ContractAddress:     common.HexToAddress("0x1"),
+		ExpectedPrivacyFlag: 0,
+		ActualPrivacyFlag:   3,
+	}
+	merkleRootMismatch := &PrivacyVerificationFailure{
+		Reason:             MerkleRootMismatch,
+		ExpectedMerkleRoot: common.HexToHash("0x2"),
+		ActualMerkleRoot:   common.HexToHash("0x3"),
+	}
+	rlpData, err := rlp.EncodeToBytes(&QuorumReceiptExtraData{
+		PSReceipts: map[PrivateStateIdentifier]*Receipt{
+			PrivateStateIdentifier("psi1"): {
+				Status: ReceiptStatusSuccessful,
+				Logs:   []*Log{},
+				QuorumReceiptExtraData: QuorumReceiptExtraData{
+					BatchReceipts: []*Receipt{{Status: ReceiptStatusFailed, Logs: []*Log{}, QuorumReceiptExtraData: QuorumReceiptExtraData{PrivacyVerificationFailure: merkleRootMismatch}}},
+				},
+			},
+			PrivateStateIdentifier("psi2"): {
+				Status:                 ReceiptStatusFailed,
+				Logs:                   []*Log{},
+				QuorumReceiptExtraData: QuorumReceiptExtraData{PrivacyVerificationFailure: flagMismatch},
+			},
+		},
+		PrivacyVerificationFailure: flagMismatch,
+	})
+	assert.Nil(t, err)
+	var decodedExtraData QuorumReceiptExtraData
+	err = rlp.DecodeBytes(rlpData, &decodedExtraData)
+	assert.Nil(t, err)
+
+	assert.Equal(t, flagMismatch, decodedExtraData.PrivacyVerificationFailure)
+	assert.Nil(t, decodedExtraData.PSReceipts[PrivateStateIdentifier("psi1")].PrivacyVerificationFailure)
+	assert.Equal(t, merkleRootMismatch, decodedExtraData.PSReceipts[PrivateStateIdentifier("psi1")].BatchReceipts[0].PrivacyVerificationFailure)
+	assert.Equal(t, flagMismatch, decodedExtraData.PSReceipts[PrivateStateIdentifier("psi2")].PrivacyVerificationFailure)
+}
+
+func TestPrivacyVerificationFailureMarshalJSON(t *testing.T) {
+	data, err := json.Marshal(&PrivacyVerificationFailure{
+		Reason:              PrivacyFlagMismatch,
+		ContractAddress:     common.HexToAddress("0x1"),
+		ExpectedPrivacyFlag: 1,
+		ActualPrivacyFlag:   3,
+	})
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{
+		"reason": "privacyFlagMismatch",
+		"message": "mismatched privacy flags: affected contract 0x0000000000000000000000000000000000000001 has privacy flag 3, transaction has privacy flag 1",
+		"contractAddress": "0x0000000000000000000000000000000000000001",
+		"expectedPrivacyFlag": 1,
+		"actualPrivacyFlag": 3
+	}`, string(data))
+}
+
+func TestDeriveBatchReceiptsFields(t *testing.T) {
+	batchReceipt := &Receipt{
+		Logs:             []*Log{{Index: 3, TxIndex: 2}, {Index: 4, TxIndex: 2}, {Index: 5, TxIndex: 2}},
+		BlockHash:        common.HexToHash("0x1"),
+		BlockNumber:      big.NewInt(7),
+		TransactionIndex: 2,
+	}
+	batchReceipts := []*Receipt{
+		{Logs: []*Log{{}, {}}, TxHash: common.HexToHash("0x2")},
+		{Logs: []*Log{}, TxHash: common.HexToHash("0x3")},
+		{Logs: []*Log{{}}, TxHash: common.HexToHash("0x4")},
+	}
+
+	derived := deriveBatchReceiptsFields(batchReceipt, batchReceipts)
+
+	assert.Len(t, derived, 3)
+	for _, receipt := range derived {
+		assert.Equal(t, batchReceipt.BlockHash, receipt.BlockHash)
+		assert.Equal(t, batchReceipt.BlockNumber, receipt.BlockNumber)
+		assert.Equal(t, uint(2), receipt.TransactionIndex)
+	}
+	assert.Equal(t, batchReceipt.Logs[:2], derived[0].Logs)
+	assert.Empty(t, derived[1].Logs)
+	assert.Equal(t, batchReceipt.Logs[2:], derived[2].Logs)
+	assert.Equal(t, common.HexToHash("0x4"), derived[2].TxHash)
+	// the original receipts are not modified
+	assert.Nil(t, batchReceipts[0].BlockNumber)
+}
@@ -501,6 +501,8 @@ type Message struct {
 	// Quorum
 	isPrivate      bool
 	isInnerPrivate bool
+	// privacy metadata of a private message simulated before it is sent, whose data is the private payload
+	simulatedPrivacyMetadata *engine.ExtraMetadata
 }
 
 func NewMessage(from common.Address, to *common.Address, nonce uint64, amount *big.Int, gasLimit uint64, gasPrice *big.Int, data []byte, accessList AccessList, checkNonce bool) Message {
@@ -638,6 +640,21 @@ func (m Message) WithInnerPrivateFlag(isInnerPrivateTxn bool) Message {
 	return m
 }
 
+// Quorum
+// WithSimulatedPrivacyMetadata returns the private message simulating a private transaction before it is
+// sent, e.g. by eth_call. Its data is the private payload, and the privacy metadata the private transaction
+// manager would return for the transaction is given instead of received.
+func (m Message) WithSimulatedPrivacyMetadata(metadata *engine.ExtraMetadata) Message {
+	m.isPrivate = true
+	m.simulatedPrivacyMetadata = metadata
+	return m
+}
+
+// Quorum
+func (m Message) SimulatedPrivacyMetadata() *engine.ExtraMetadata {
+	return m.simulatedPrivacyMetadata
+}
+
 // overriding msg.data so that when tesseera.receive is invoked we get nothing back
 func (m Message) WithEmptyPrivateData(b bool) Message {
 	if b {
@@ -85,6 +85,16 @@ func (c *privacyMarker) Run(evm *EVM, _ []byte) ([]byte, error) {
 		return nil, nil
 	}
 
+	if evm.currentTx.IsPrivacyMarkerBatch() {
+		if !evm.chainRules.IsPrivacyMarkerBatch {
+			// before the fork the batch is left untouched, as by the nodes which do not support batches
+			logger.Warn("Privacy marker transaction batches are not enabled, skipping execution")
+			return nil, nil
+		}
+		runPrivacyMarkerBatch(evm, logger)
+		return nil, nil
+	}
+
 	tx, _, _, err := private.FetchPrivateTransaction(evm.currentTx.Data())
 	if err != nil {
 		logger.Error("Failed to retrieve inner transaction from private transaction manager", "err", err)
@@ -143,3 +153,72 @@ func applyTransactionWithoutIncrementingNonce(evm *EVM, tx *types.Transaction) e
 
 	return evm.InnerApply(tx)
 }
+
+// runPrivacyMarkerBatch executes the private transactions of the batch carried by the PMT, in
+// order. The senders, nonces and authorizations of the batch are public, so every node, party or
+// not, sets the nonce of each sender past the nonce of its transaction and the public state remains
+// in sync. A transaction which is not authorized by its sender, or whose nonce is not the current
+// nonce of its sender, is skipped by every node without touching the nonce.
+func runPrivacyMarkerBatch(evm *EVM, logger log.Logger) {
+	_, entries, err := types.DecodePrivacyMarkerBatchData(evm.currentTx.Data())
+	if err != nil {
+		logger.Warn("Invalid privacy marker transaction batch", "err", err)
+		return
+	}
+
+	txs, _, _, err := private.FetchPrivateTransactions(evm.currentTx.Data())
+	if err != nil {
+		logger.Error("Failed to retrieve inner transactions from private transaction manager", "err", err)
+		txs = nil
+	}
+	if txs == nil {
+		logger.Debug("Not a participant, skipping execution of the batch")
+	} else if len(txs) != len(entries) {
+		logger.Warn("PMT batch and inner private transactions have different sizes, skipping execution", "batch", len(entries), "txs", len(txs))
+		txs = nil
+	}
+
+	for i, entry := range entries {
+		if !entry.Authorized(evm.chainConfig.ChainID) {
+			logger.Warn("Inner private transaction of the batch is not authorized by its sender, skipping execution", "index", i, "from", entry.From, "nonce", entry.Nonce)
+			continue
+		}
+		if nonce := evm.publicState.GetNonce(entry.From); nonce != entry.Nonce {
+			logger.Warn("Inner private transaction of the batch has an invalid nonce, skipping execution", "index", i, "from", entry.From, "nonce", entry.Nonce, "expected", nonce)
+			continue
+		}
+		if txs != nil {
+			applyPrivacyMarkerBatchEntry(evm, logger, i, entry, txs[i])
+		}
+		evm.publicState.SetNonce(entry.From, entry.Nonce+1)
+	}
+}
+
+// applyPrivacyMarkerBatchEntry validates and executes a private transaction of the batch carried
+// by the PMT, and collects its receipt
+func applyPrivacyMarkerBatchEntry(evm *EVM, logger log.Logger, index int, entry types.PrivacyMarkerBatchEntry, tx *types.Transaction) {
+	logger = logger.New("index", index, "txHash", tx.Hash())
+	if !tx.IsPrivate() {
+		logger.Warn("Inner transaction of the batch is not a private transaction, skipping execution")
+		return
+	}
+	//validate the private tx is the one authorized by the sender of its entry in the batch. Its nonce
+	//is consumed anyway, as by the nodes which are not parties to the batch
+	if signedBy := tx.From(); signedBy == (common.Address{}) || signedBy != entry.From || tx.Nonce() != entry.Nonce || tx.Hash() != entry.TxHash {
+		logger.Warn("PMT batch entry and inner private transaction have different signers, nonces or hashes, skipping execution")
+		return
+	}
+	if evm.InnerApply == nil {
+		logger.Warn("Unable to apply PMT's inner transaction to EVM, skipping execution", "err", "nil inner apply function")
+		return
+	}
+
+	evm.InnerPrivateReceipt = nil
+	if err := evm.InnerApply(tx); err != nil {
+		logger.Warn("Unable to apply PMT's inner transaction to EVM, skipping execution", "err", err)
+	} else if evm.InnerPrivateReceipt != nil {
+		evm.InnerPrivateBatchReceipts = append(evm.InnerPrivateBatchReceipts, evm.InnerPrivateReceipt)
+		logger.Debug("Inner private transaction of the batch applied")
+	}
+	evm.InnerPrivateReceipt = nil
+}
@@ -6,9 +6,11 @@ import (
 	"errors"
 	"testing"
 
+	"github.com/ethereum/go-ethereum/accounts"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
 	"github.com/ethereum/go-ethereum/private"
 	"github.com/ethereum/go-ethereum/private/mock_private"
 	"github.com/golang/mock/gomock"
@@ -439,6 +441,191 @@ func TestPrivacyMarker_Run_SupportedTransaction_ExecutionSucceeds_NonceUnchanged
 	require.EqualValues(t, signedPrivateTx.String(), executedTx.String())
 }
 
+func TestPrivacyMarker_Run_Batch_AppliesTransactionsInOrder(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	otherSenderPrivateKey, _ := crypto.GenerateKey()
+	otherSender := crypto.PubkeyToAddress(otherSenderPrivateKey.PublicKey)
+	signPrivateTx := func(nonce uint64, key *ecdsa.PrivateKey) *types.Transaction {
+		tx := types.NewTransaction(nonce, common.QuorumPrivacyPrecompileContractAddress(), nil, 0, nil, []byte{})
+		tx.SetPrivate()
+		signed, err := types.SignTx(tx, types.QuorumPrivateTxSigner{}, key)
+		require.NoError(t, err)
+		return signed
+	}
+	txs := []*types.Transaction{signPrivateTx(10, senderPrivateKey), signPrivateTx(3, otherSenderPrivateKey), signPrivateTx(11, senderPrivateKey)}
+	txsByt, err := json.Marshal(txs)
+	require.NoError(t, err)
+	data, err := types.NewPrivacyMarkerBatchData(tmPrivateTxHash, []types.PrivacyMarkerBatchEntry{
+		signPrivacyMarkerBatchEntry(t, txs[0], senderPrivateKey),
+		signPrivacyMarkerBatchEntry(t, txs[1], otherSenderPrivateKey),
+		signPrivacyMarkerBatchEntry(t, txs[2], senderPrivateKey),
+	})
+	require.NoError(t, err)
+	privacyMarkerTx := types.NewTransaction(0, common.QuorumPrivacyPrecompileContractAddress(), nil, 0, nil, data)
+	require.True(t, privacyMarkerTx.IsPrivacyMarkerBatch())
+
+	privacyManager := mock_private.NewMockPrivateTransactionManager(ctrl)
+	saved := private.P
+	defer func() { private.P = saved }()
+	private.P = privacyManager
+	publicState := NewMockStateDB(ctrl)
+
+	evm := &EVM{
+		currentTx:   privacyMarkerTx,
+		publicState: publicState,
+		chainConfig: params.QuorumTestChainConfig,
+		chainRules:  params.Rules{IsPrivacyPrecompile: true, IsPrivacyMarkerBatch: true},
+	}
+	var applied []*types.Transaction
+	evm.InnerApply = func(innerTx *types.Transaction) error {
+		applied = append(applied, innerTx)
+		evm.InnerPrivateReceipt = &types.Receipt{TxHash: innerTx.Hash()}
+		return nil
+	}
+
+	privacyManager.EXPECT().Receive(tmPrivateTxHash).Return("", []string{}, txsByt, nil, nil)
+	gomock.InOrder(
+		publicState.EXPECT().GetNonce(sender).Return(uint64(10)),
+		publicState.EXPECT().SetNonce(sender, uint64(11)),
+		// the nonce of the other sender was consumed, the transaction is skipped
+		publicState.EXPECT().GetNonce(otherSender).Return(uint64(4)),
+		publicState.EXPECT().GetNonce(sender).Return(uint64(11)),
+		publicState.EXPECT().SetNonce(sender, uint64(12)),
+	)
+
+	gotByt, gotErr := (&privacyMarker{}).Run(evm, []byte{})
+
+	require.Nil(t, gotByt)
+	require.Nil(t, gotErr)
+	require.Len(t, applied, 2)
+	require.Equal(t, txs[0].Hash(), applied[0].Hash())
+	require.Equal(t, txs[2].Hash(), applied[1].Hash())
+	require.Len(t, evm.InnerPrivateBatchReceipts, 2)
+	require.Equal(t, txs[2].Hash(), evm.InnerPrivateBatchReceipts[1].TxHash)
+	require.Nil(t, evm.InnerPrivateReceipt)
+}
+
+func TestPrivacyMarker_Run_Batch_NonParticipant_SetsNonces(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tx := types.NewTransaction(5, common.QuorumPrivacyPrecompileContractAddress(), nil, 0, nil, []byte{})
+	tx.SetPrivate()
+	tx, err := types.SignTx(tx, types.QuorumPrivateTxSigner{}, senderPrivateKey)
+	require.NoError(t, err)
+	data, err := types.NewPrivacyMarkerBatchData(tmPrivateTxHash, []types.PrivacyMarkerBatchEntry{signPrivacyMarkerBatchEntry(t, tx, senderPrivateKey)})
+	require.NoError(t, err)
+
+	privacyManager := mock_private.NewMockPrivateTransactionManager(ctrl)
+	saved := private.P
+	defer func() { private.P = saved }()
+	private.P = privacyManager
+	publicState := NewMockStateDB(ctrl)
+	innerApplier := &stubInnerApplier{}
+
+	evm := &EVM{
+		currentTx:   types.NewTransaction(0, common.QuorumPrivacyPrecompileContractAddress(), nil, 0, nil, data),
+		publicState: publicState,
+		InnerApply:  innerApplier.InnerApply,
+		chainConfig: params.QuorumTestChainConfig,
+		chainRules:  params.Rules{IsPrivacyPrecompile: true, IsPrivacyMarkerBatch: true},
+	}
+
+	privacyManager.EXPECT().Receive(tmPrivateTxHash).Return("", []string{}, nil, nil, nil)
+	gomock.InOrder(
+		publicState.EXPECT().GetNonce(sender).Return(uint64(5)),
+		publicState.EXPECT().SetNonce(sender, uint64(6)),
+	)
+
+	gotByt, gotErr := (&privacyMarker{}).Run(evm, []byte{})
+
+	require.Nil(t, gotByt)
+	require.Nil(t, gotErr)
+	require.False(t, innerApplier.wasCalled())
+	require.Empty(t, evm.InnerPrivateBatchReceipts)
+}
+
+func TestPrivacyMarker_Run_Batch_BeforeFork_DoesNothing(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tx := types.NewTransaction(5, common.QuorumPrivacyPrecompileContractAddress(), nil, 0, nil, []byte{})
+	tx.SetPrivate()
+	tx, err := types.SignTx(tx, types.QuorumPrivateTxSigner{}, senderPrivateKey)
+	require.NoError(t, err)
+	data, err := types.NewPrivacyMarkerBatchData(tmPrivateTxHash, []types.PrivacyMarkerBatchEntry{signPrivacyMarkerBatchEntry(t, tx, senderPrivateKey)})
+	require.NoError(t, err)
+
+	// no call is expected on the private transaction manager and the public state
+	privacyManager := mock_private.NewMockPrivateTransactionManager(ctrl)
+	saved := private.P
+	defer func() { private.P = saved }()
+	private.P = privacyManager
+	innerApplier := &stubInnerApplier{}
+
+	evm := &EVM{
+		currentTx:   types.NewTransaction(0, common.QuorumPrivacyPrecompileContractAddress(), nil, 0, nil, data),
+		publicState: NewMockStateDB(ctrl),
+		InnerApply:  innerApplier.InnerApply,
+		chainRules:  params.Rules{IsPrivacyPrecompile: true},
+	}
+
+	gotByt, gotErr := (&privacyMarker{}).Run(evm, []byte{})
+
+	require.Nil(t, gotByt)
+	require.Nil(t, gotErr)
+	require.False(t, innerApplier.wasCalled())
+	require.Empty(t, evm.InnerPrivateBatchReceipts)
+}
+
+func TestPrivacyMarker_Run_Batch_UnauthorizedEntry_LeavesNonce(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	attackerPrivateKey, _ := crypto.GenerateKey()
+	tx := types.NewTransaction(5, common.QuorumPrivacyPrecompileContractAddress(), nil, 0, nil, []byte{})
+	tx.SetPrivate()
+	tx, err := types.SignTx(tx, types.QuorumPrivateTxSigner{}, senderPrivateKey)
+	require.NoError(t, err)
+	unsigned := types.NewPrivacyMarkerBatchEntry(tx, nil)
+	// an entry for the sender, authorized by another account
+	forged := signPrivacyMarkerBatchEntry(t, tx, attackerPrivateKey)
+	forged.From = sender
+	data, err := types.NewPrivacyMarkerBatchData(tmPrivateTxHash, []types.PrivacyMarkerBatchEntry{unsigned, forged})
+	require.NoError(t, err)
+
+	privacyManager := mock_private.NewMockPrivateTransactionManager(ctrl)
+	saved := private.P
+	defer func() { private.P = saved }()
+	private.P = privacyManager
+	innerApplier := &stubInnerApplier{}
+
+	// no call is expected on the public state
+	evm := &EVM{
+		currentTx:   types.NewTransaction(0, common.QuorumPrivacyPrecompileContractAddress(), nil, 0, nil, data),
+		publicState: NewMockStateDB(ctrl),
+		InnerApply:  innerApplier.InnerApply,
+		chainConfig: params.QuorumTestChainConfig,
+		chainRules:  params.Rules{IsPrivacyPrecompile: true, IsPrivacyMarkerBatch: true},
+	}
+
+	privacyManager.EXPECT().Receive(tmPrivateTxHash).Return("", []string{}, nil, nil, nil)
+
+	gotByt, gotErr := (&privacyMarker{}).Run(evm, []byte{})
+
+	require.Nil(t, gotByt)
+	require.Nil(t, gotErr)
+	require.False(t, innerApplier.wasCalled())
+}
+
+func signPrivacyMarkerBatchEntry(t *testing.T, tx *types.Transaction, key *ecdsa.PrivateKey) types.PrivacyMarkerBatchEntry {
+	sig, err := crypto.Sign(accounts.TextHash(types.PrivacyMarkerBatchAuthorization(params.QuorumTestChainConfig.ChainID, tx)), key)
+	require.NoError(t, err)
+	return types.NewPrivacyMarkerBatchEntry(tx, sig)
+}
+
 type innerApplier interface {
 	InnerApply(innerTx *types.Transaction) error
 	wasCalled() bool
@@ -201,8 +201,9 @@ type EVM struct {
 	currentTx         *types.Transaction                // transaction currently being applied on this EVM
 
 	// Quorum: these are for privacy marker transactions
-	InnerApply          func(innerTx *types.Transaction) error //Quorum
-	InnerPrivateReceipt *types.Receipt                         //Quorum
+	InnerApply                func(innerTx *types.Transaction) error //Quorum
+	InnerPrivateReceipt       *types.Receipt                         //Quorum
+	InnerPrivateBatchReceipts []*types.Receipt                       //Quorum: receipts of the transactions of a PMT batch
 }
 
 // AffectedReason defines a type of operation that was applied to a contract.
@@ -864,6 +864,32 @@ func (mr *MockStateDBMockRecorder) AddSlotToAccessList(addr, slot interface{}) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddSlotToAccessList", reflect.TypeOf((*MockStateDB)(nil).AddSlotToAccessList), addr, slot)
 }
 
+// GetTransientState mocks base method
+func (m *MockStateDB) GetTransientState(addr common.Address, key common.Hash) common.Hash {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTransientState", addr, key)
+	ret0, _ := ret[0].(common.Hash)
+	return ret0
+}
+
+// GetTransientState indicates an expected call of GetTransientState
+func (mr *MockStateDBMockRecorder) GetTransientState(addr, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTransientState", reflect.TypeOf((*MockStateDB)(nil).GetTransientState), addr, key)
+}
+
+// SetTransientState mocks base method
+func (m *MockStateDB) SetTransientState(addr common.Address, key, value common.Hash) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetTransientState", addr, key, value)
+}
+
+// SetTransientState indicates an expected call of SetTransientState
+func (mr *MockStateDBMockRecorder) SetTransientState(addr, key, value interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTransientState", reflect.TypeOf((*MockStateDB)(nil).SetTransientState), addr, key, value)
+}
+
 // RevertToSnapshot mocks base method
 func (m *MockStateDB) RevertToSnapshot(arg0 int) {
 	m.ctrl.T.Helper()
@@ -34,6 +34,8 @@ import (
 	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/internal/ethapi"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/private"
 	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/ethereum/go-ethereum/trie"
@@ -246,6 +248,68 @@ func (api *PrivateAdminAPI) ImportChain(file string) (bool, error) {
 	return true, nil
 }
 
+// PtmEndpoints returns the health of each private transaction manager endpoint
+// when the node is configured with failover endpoints.
+func (api *PrivateAdminAPI) PtmEndpoints() ([]private.EndpointStatus, error) {
+	ptm, ok := private.P.(private.HasEndpointStatus)
+	if !ok {
+		return nil, errors.New("private transaction manager is not configured with failover endpoints")
+	}
+	return ptm.EndpointStatus(), nil
+}
+
+// MissingPrivatePayloads starts scanning the blocks from first to last, or to the head block if
+// last is nil, for the private transactions whose payload the private transaction manager does
+// not hold. The scan runs in the background, its progress and result being returned by
+// MissingPrivatePayloadsStatus, and covers at most 100000 blocks.
+func (api *PrivateAdminAPI) MissingPrivatePayloads(first uint64, last *uint64) (*MissingPrivatePayloadsScan, error) {
+	if !private.IsQuorumPrivacyEnabled() {
+		return nil, errors.New("private transaction manager is not enabled")
+	}
+	if last == nil {
+		head := api.eth.BlockChain().CurrentHeader().Number.Uint64()
+		last = &head
+	}
+	if first > *last {
+		return nil, errors.New("first cannot be after last")
+	}
+	// the managed parties are only reported for each transaction, so the scan does not depend on them
+	managedParties, err := private.ManagedParties(private.P)
+	if err != nil {
+		log.Warn("Failed to retrieve the parties managed by the private transaction manager", "err", err)
+	}
+	return api.eth.missingPayloadsScanner.start(api.eth.BlockChain(), first, *last, managedParties)
+}
+
+// MissingPrivatePayloadsStatus returns the progress and the result of the running or the last
+// scan started by MissingPrivatePayloads, nil if no scan has been started
+func (api *PrivateAdminAPI) MissingPrivatePayloadsStatus() *MissingPrivatePayloadsScan {
+	return api.eth.missingPayloadsScanner.status()
+}
+
+// ResendPrivatePayloads asks the Tessera node of each peer, given by the URL of its P2P server,
+// to send again the payloads it holds for the parties managed by this node. The private state
+// of the affected blocks must then be rebuilt offline with "geth privatestate recover".
+func (api *PrivateAdminAPI) ResendPrivatePayloads(peerURLs []string) (bool, error) {
+	if !private.IsQuorumPrivacyEnabled() {
+		return false, errors.New("private transaction manager is not enabled")
+	}
+	if err := private.RequestResendFromPeers(private.P, peerURLs); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// RefreshPrivateStates adds the private states which were added to the private transaction manager
+// since the node started, their state starting from block startBlock, or from the block after the
+// head block if startBlock is nil. It returns the identifiers of the added private states.
+func (api *PrivateAdminAPI) RefreshPrivateStates(startBlock *uint64) ([]types.PrivateStateIdentifier, error) {
+	if !private.IsQuorumPrivacyEnabled() {
+		return nil, errors.New("private transaction manager is not enabled")
+	}
+	return api.eth.RefreshPrivateStates(startBlock)
+}
+
 // PublicDebugAPI is the collection of Ethereum full node APIs exposed
 // over the public debugging endpoint.
 type PublicDebugAPI struct {
@@ -559,6 +623,72 @@ func (api *PrivateDebugAPI) getModifiedAccounts(startBlock, endBlock *types.Bloc
 
 // Quorum
 
+// PublicStateDiff returns the accounts of the public state which changed between the two
+// blocks, with the changed balance, nonce, code and storage slots of each account. The
+// preimages of the trie keys must be recorded (--cache.preimages).
+func (api *PrivateDebugAPI) PublicStateDiff(fromNum, toNum uint64) ([]*state.AccountDiff, error) {
+	fromBlock, toBlock, err := api.stateDiffBlocks(fromNum, toNum)
+	if err != nil {
+		return nil, err
+	}
+	from, err := state.New(fromBlock.Root(), api.eth.blockchain.StateCache(), nil)
+	if err != nil {
+		return nil, err
+	}
+	to, err := state.New(toBlock.Root(), api.eth.blockchain.StateCache(), nil)
+	if err != nil {
+		return nil, err
+	}
+	return state.DiffStates(from, to)
+}
+
+// PrivateStateDiff returns the accounts of the private state which changed between the two
+// blocks, with the changed balance, nonce, code, storage slots and AccountExtraData, i.e.
+// privacy metadata and managed parties, of each account. The private state defaults to the
+// private state of the caller. The preimages of the trie keys must be recorded (--cache.preimages).
+func (api *PrivateDebugAPI) PrivateStateDiff(ctx context.Context, fromNum, toNum uint64, psi *types.PrivateStateIdentifier) ([]*state.AccountDiff, error) {
+	psm, err := api.eth.blockchain.PrivateStateManager().ResolveForUserContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if psi != nil && *psi != psm.ID {
+		if _, ok := rpc.PrivateStateIdentifierFromContext(ctx); ok {
+			return nil, fmt.Errorf("private state %s is not the private state of the caller", *psi)
+		}
+		if psm, err = api.eth.blockchain.PrivateStateManager().ResolveForUserContext(rpc.WithPrivateStateIdentifier(ctx, *psi)); err != nil {
+			return nil, err
+		}
+	}
+	fromBlock, toBlock, err := api.stateDiffBlocks(fromNum, toNum)
+	if err != nil {
+		return nil, err
+	}
+	_, from, err := api.eth.blockchain.StateAtPSI(fromBlock.Root(), psm.ID)
+	if err != nil {
+		return nil, err
+	}
+	_, to, err := api.eth.blockchain.StateAtPSI(toBlock.Root(), psm.ID)
+	if err != nil {
+		return nil, err
+	}
+	return state.DiffStates(from, to)
+}
+
+func (api *PrivateDebugAPI) stateDiffBlocks(fromNum, toNum uint64) (*types.Block, *types.Block, error) {
+	if fromNum >= toNum {
+		return nil, nil, fmt.Errorf("start block height (%d) must be less than end block height (%d)", fromNum, toNum)
+	}
+	fromBlock := api.eth.blockchain.GetBlockByNumber(fromNum)
+	if fromBlock == nil {
+		return nil, nil, fmt.Errorf("start block %d not found", fromNum)
+	}
+	toBlock := api.eth.blockchain.GetBlockByNumber(toNum)
+	if toBlock == nil {
+		return nil, nil, fmt.Errorf("end block %d not found", toNum)
+	}
+	return fromBlock, toBlock, nil
+}
+
 // StorageRoot returns the storage root of an account on the given (optional) block height.
 // If block number is not given the latest block is used.
 func (s *PublicEthereumAPI) StorageRoot(ctx context.Context, addr common.Address, blockNr *rpc.BlockNumber) (common.Hash, error) {
@@ -21,6 +21,7 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"sync"
 	"time"
 
 	"github.com/ethereum/go-ethereum/accounts"
@@ -297,13 +298,14 @@ func (b *EthAPIBackend) GetEVM(ctx context.Context, msg core.Message, state vm.M
 	context := core.NewEVMBlockContext(header, b.eth.BlockChain(), nil)
 
 	// Quorum
-	// Set the private state to public state if contract address is not present in the private state
+	// Set the private state to public state if contract address is not present in the private state,
+	// unless the message simulates a private transaction
 	to := common.Address{}
 	if msg.To() != nil {
 		to = *msg.To()
 	}
 	privateState := statedb.privateState
-	if !privateState.Exist(to) {
+	if privateMsg, ok := msg.(core.PrivateMessage); (!ok || !privateMsg.IsPrivate()) && !privateState.Exist(to) {
 		privateState = statedb.state
 	}
 	// End Quorum
@@ -438,6 +440,43 @@ func (b *EthAPIBackend) ServiceFilter(ctx context.Context, session *bloombits.Ma
 	}
 }
 
+// Quorum
+// PrivateBloomStatus returns the section size and the number of indexed sections of the bloom bits
+// index of the private logs of the private state, failing if the private state is not indexed
+func (b *EthAPIBackend) PrivateBloomStatus(psi types.PrivateStateIdentifier) (uint64, uint64, error) {
+	indexer := b.eth.privateBloomIndexer(psi)
+	if indexer == nil {
+		return params.BloomBitsBlocks, 0, fmt.Errorf("unknown private state %s", psi)
+	}
+	sections, _, _ := indexer.Sections()
+	return params.BloomBitsBlocks, sections, nil
+}
+
+// Quorum
+// ServicePrivateFilter services the matcher session from the bloom bits index of the private logs
+// of the private state
+func (b *EthAPIBackend) ServicePrivateFilter(ctx context.Context, psi types.PrivateStateIdentifier, session *bloombits.MatcherSession) {
+	if !b.ChainConfig().IsMPS {
+		psi = types.DefaultPrivateStateIdentifier
+	}
+	var (
+		requests = make(chan chan *bloombits.Retrieval)
+		wg       sync.WaitGroup
+	)
+	wg.Add(bloomFilterThreads)
+	for i := 0; i < bloomFilterThreads; i++ {
+		go func() {
+			defer wg.Done()
+			session.Multiplex(bloomRetrievalBatch, bloomRetrievalWait, requests)
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(requests)
+	}()
+	go b.eth.servicePrivateBloomRequests(psi, requests)
+}
+
 func (b *EthAPIBackend) Engine() consensus.Engine {
 	return b.eth.engine
 }
@@ -491,6 +530,10 @@ func (b *EthAPIBackend) IsPrivacyMarkerTransactionCreationEnabled() bool {
 	return b.eth.config.QuorumChainConfig.PrivacyMarkerEnabled() && b.ChainConfig().IsPrivacyPrecompileEnabled(b.eth.blockchain.CurrentBlock().Number())
 }
 
+func (b *EthAPIBackend) PrivacyMarkerBatcher() *ethapi.PrivacyMarkerBatcher {
+	return b.eth.privacyMarkerBatcher
+}
+
 // used by Quorum
 type EthAPIState struct {
 	state, privateState *state.StateDB
@@ -91,6 +91,17 @@ type Ethereum struct {
 	bloomIndexer      *core.ChainIndexer             // Bloom indexer operating during block imports
 	closeBloomHandler chan struct{}
 
+	// Quorum
+	privateBloomRequests chan *privateBloomRequest                           // Channel receiving private bloom data retrieval requests
+	privateBloomIndexers map[types.PrivateStateIdentifier]*core.ChainIndexer // Bloom indexers of the private logs of each private state
+	privateBloomLock     sync.RWMutex                                        // Protects the private bloom indexers, which are added with the private states
+
+	closePrivateStatesRefresh chan struct{}
+
+	privacyMarkerBatcher *ethapi.PrivacyMarkerBatcher // batches the private transactions into privacy marker transactions, nil if disabled
+
+	missingPayloadsScanner *missingPrivatePayloadsScanner
+
 	APIBackend *EthAPIBackend
 
 	miner     *miner.Miner
@@ -187,6 +198,9 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 		bloomIndexer:      core.NewBloomIndexer(chainDb, params.BloomBitsBlocks, params.BloomConfirms),
 		p2pServer:         stack.Server(),
 		// Quorum
+		privateBloomRequests:            make(chan *privateBloomRequest),
+		closePrivateStatesRefresh:       make(chan struct{}),
+		missingPayloadsScanner:          newMissingPrivatePayloadsScanner(),
 		qlightP2pServer:                 stack.QServer(),
 		consensusServicePendingLogsFeed: new(event.Feed),
 	}
@@ -274,6 +288,8 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 		rawdb.WriteChainConfig(chainDb, genesisHash, chainConfig)
 	}
 	eth.bloomIndexer.Start(eth.blockchain)
+	// Quorum
+	eth.startPrivateBloomIndexers(chainConfig.IsMPS)
 
 	if config.TxPool.Journal != "" {
 		config.TxPool.Journal = stack.ResolvePath(config.TxPool.Journal)
@@ -443,6 +459,14 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 	if eth.APIBackend.allowUnprotectedTxs {
 		log.Info("Unprotected transactions allowed")
 	}
+	// Quorum
+	if config.PrivacyMarkerBatchAccount != (common.Address{}) {
+		if !config.QuorumChainConfig.PrivacyMarkerEnabled() {
+			return nil, errors.New("privacy marker transaction batching requires privacy marker transactions to be enabled")
+		}
+		eth.privacyMarkerBatcher = ethapi.NewPrivacyMarkerBatcher(eth.APIBackend, config.PrivacyMarkerBatchAccount, config.PrivacyMarkerBatchSize, config.PrivacyMarkerBatchInterval)
+		log.Info("Batching private transactions into privacy marker transactions", "account", config.PrivacyMarkerBatchAccount, "size", config.PrivacyMarkerBatchSize, "interval", config.PrivacyMarkerBatchInterval)
+	}
 	gpoParams := config.GPO
 	if gpoParams.Default == nil {
 		gpoParams.Default = config.Miner.GasPrice
@@ -787,6 +811,14 @@ func (s *Ethereum) Start() error {
 	// Start the bloom bits servicing goroutines
 	s.startBloomHandlers(params.BloomBitsBlocks)
 
+	// Quorum
+	if s.config.PrivateStatesRefresh > 0 && s.blockchain.Config().IsMPS {
+		go s.refreshPrivateStatesLoop(s.config.PrivateStatesRefresh)
+	}
+	if s.privacyMarkerBatcher != nil {
+		s.privacyMarkerBatcher.Start()
+	}
+
 	// Figure out a max peers count based on the server limits
 	maxPeers := s.p2pServer.MaxPeers
 	if s.config.LightServ > 0 {
@@ -824,7 +856,13 @@ func (s *Ethereum) Stop() error {
 	}
 
 	// Then stop everything else.
+	if s.privacyMarkerBatcher != nil {
+		s.privacyMarkerBatcher.Stop()
+	}
+	s.missingPayloadsScanner.stop()
 	s.bloomIndexer.Close()
+	s.closePrivateBloomIndexers()
+	close(s.closePrivateStatesRefresh)
 	close(s.closeBloomHandler)
 	s.txPool.Stop()
 	s.miner.Stop()
@@ -872,3 +910,38 @@ func (s *Ethereum) NotifyRegisteredPluginService(pluginManager *plugin.PluginMan
 	}
 	return nil
 }
+
+// Quorum
+// RefreshPrivateStates adds the new private states of the private transaction manager to the
+// running node, their state starting from the given block - the block after the head block by
+// default - and returns the private states which were added.
+func (s *Ethereum) RefreshPrivateStates(startBlock *uint64) ([]types.PrivateStateIdentifier, error) {
+	added, err := s.blockchain.RefreshPrivateStates(startBlock)
+	if err != nil {
+		return nil, err
+	}
+	s.privateBloomLock.Lock()
+	defer s.privateBloomLock.Unlock()
+	for _, psi := range added {
+		s.startPrivateBloomIndexer(psi, true)
+	}
+	return added, nil
+}
+
+// Quorum
+// refreshPrivateStatesLoop periodically adds the new private states of the private transaction
+// manager to the running node
+func (s *Ethereum) refreshPrivateStatesLoop(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if _, err := s.RefreshPrivateStates(nil); err != nil {
+				log.Warn("Failed to refresh private states", "err", err)
+			}
+		case <-s.closePrivateStatesRefresh:
+			return
+		}
+	}
+}
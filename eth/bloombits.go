@@ -20,7 +20,12 @@ import (
 	"time"
 
 	"github.com/ethereum/go-ethereum/common/bitutil"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/bloombits"
 	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/params"
 )
 
 const (
@@ -53,22 +58,105 @@ func (eth *Ethereum) startBloomHandlers(sectionSize uint64) {
 
 				case request := <-eth.bloomRequests:
 					task := <-request
-					task.Bitsets = make([][]byte, len(task.Sections))
-					for i, section := range task.Sections {
-						head := rawdb.ReadCanonicalHash(eth.chainDb, (section+1)*sectionSize-1)
-						if compVector, err := rawdb.ReadBloomBits(eth.chainDb, task.Bit, section, head); err == nil {
-							if blob, err := bitutil.DecompressBytes(compVector, int(sectionSize/8)); err == nil {
-								task.Bitsets[i] = blob
-							} else {
-								task.Error = err
-							}
-						} else {
-							task.Error = err
-						}
-					}
+					eth.retrieveBloomBits(eth.chainDb, task, sectionSize)
 					request <- task
+
+				// Quorum
+				case request := <-eth.privateBloomRequests:
+					task := <-request.retrieval
+					eth.retrieveBloomBits(rawdb.NewPrivateBloomBitsTable(eth.chainDb, request.psi), task, sectionSize)
+					request.retrieval <- task
 				}
 			}
 		}()
 	}
 }
+
+// retrieveBloomBits fills the bitsets of the retrieval task from the bloom bits index in the
+// given database
+func (eth *Ethereum) retrieveBloomBits(db ethdb.KeyValueReader, task *bloombits.Retrieval, sectionSize uint64) {
+	task.Bitsets = make([][]byte, len(task.Sections))
+	for i, section := range task.Sections {
+		head := rawdb.ReadCanonicalHash(eth.chainDb, (section+1)*sectionSize-1)
+		if compVector, err := rawdb.ReadBloomBits(db, task.Bit, section, head); err == nil {
+			if blob, err := bitutil.DecompressBytes(compVector, int(sectionSize/8)); err == nil {
+				task.Bitsets[i] = blob
+			} else {
+				task.Error = err
+			}
+		} else {
+			task.Error = err
+		}
+	}
+}
+
+// Quorum
+// privateBloomRequest is a bloom bit retrieval request for the private logs index of a private state
+type privateBloomRequest struct {
+	psi       types.PrivateStateIdentifier
+	retrieval chan *bloombits.Retrieval
+}
+
+// Quorum
+// startPrivateBloomIndexers starts a bloom indexer of the private logs for each private state
+// of the node, or for the single private state if MPS is disabled
+func (eth *Ethereum) startPrivateBloomIndexers(isMPS bool) {
+	eth.privateBloomLock.Lock()
+	defer eth.privateBloomLock.Unlock()
+	eth.privateBloomIndexers = make(map[types.PrivateStateIdentifier]*core.ChainIndexer)
+	psis := []types.PrivateStateIdentifier{types.DefaultPrivateStateIdentifier}
+	if isMPS {
+		psis = eth.blockchain.PrivateStateManager().PSIs()
+	}
+	for _, psi := range psis {
+		eth.startPrivateBloomIndexer(psi, isMPS)
+	}
+}
+
+// Quorum
+// startPrivateBloomIndexer starts the bloom indexer of the private logs of the private state, if
+// not started yet. The caller must hold the privateBloomLock.
+func (eth *Ethereum) startPrivateBloomIndexer(psi types.PrivateStateIdentifier, isMPS bool) {
+	if _, ok := eth.privateBloomIndexers[psi]; ok {
+		return
+	}
+	indexer := core.NewPrivateBloomIndexer(eth.chainDb, psi, isMPS, params.BloomBitsBlocks, params.BloomConfirms)
+	indexer.Start(eth.blockchain)
+	eth.privateBloomIndexers[psi] = indexer
+}
+
+// Quorum
+// closePrivateBloomIndexers stops the bloom indexers of the private logs
+func (eth *Ethereum) closePrivateBloomIndexers() {
+	eth.privateBloomLock.Lock()
+	defer eth.privateBloomLock.Unlock()
+	for _, indexer := range eth.privateBloomIndexers {
+		indexer.Close()
+	}
+}
+
+// Quorum
+// privateBloomIndexer returns the bloom indexer of the private logs of the private state, nil if
+// the private state is not indexed
+func (eth *Ethereum) privateBloomIndexer(psi types.PrivateStateIdentifier) *core.ChainIndexer {
+	if !eth.blockchain.Config().IsMPS {
+		psi = types.DefaultPrivateStateIdentifier
+	}
+	eth.privateBloomLock.RLock()
+	defer eth.privateBloomLock.RUnlock()
+	return eth.privateBloomIndexers[psi]
+}
+
+// Quorum
+// servicePrivateBloomRequests forwards the bloom bit retrievals multiplexed by a matcher session
+// to the servicing goroutines, tagged with the private state whose index is to be read. It returns
+// once the session is terminated and the requests channel is closed.
+func (eth *Ethereum) servicePrivateBloomRequests(psi types.PrivateStateIdentifier, requests chan chan *bloombits.Retrieval) {
+	for request := range requests {
+		select {
+		case eth.privateBloomRequests <- &privateBloomRequest{psi: psi, retrieval: request}:
+		case <-eth.closeBloomHandler:
+			return
+		}
+	}
+}
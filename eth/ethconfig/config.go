@@ -178,6 +178,15 @@ type Config struct {
 	// timeout value for call
 	EVMCallTimeOut time.Duration
 
+	// interval to refresh the private states from the private transaction manager, 0 to disable
+	PrivateStatesRefresh time.Duration
+
+	// account signing the privacy marker transactions carrying batches of private transactions,
+	// zero address to disable the batching
+	PrivacyMarkerBatchAccount  common.Address
+	PrivacyMarkerBatchSize     int
+	PrivacyMarkerBatchInterval time.Duration
+
 	// Quorum
 	core.QuorumChainConfig `toml:"-"`
 
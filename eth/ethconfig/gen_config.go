@@ -18,52 +18,56 @@ import (
 // MarshalTOML marshals as TOML.
 func (c Config) MarshalTOML() (interface{}, error) {
 	type Config struct {
-		Genesis                 *core.Genesis `toml:",omitempty"`
-		NetworkId               uint64
-		SyncMode                downloader.SyncMode
-		EthDiscoveryURLs        []string
-		SnapDiscoveryURLs       []string
-		NoPruning               bool
-		NoPrefetch              bool
-		TxLookupLimit           uint64                 `toml:",omitempty"`
-		AuthorizationList       map[uint64]common.Hash `toml:"-"`
-		LightServ               int                    `toml:",omitempty"`
-		LightIngress            int                    `toml:",omitempty"`
-		LightEgress             int                    `toml:",omitempty"`
-		LightPeers              int                    `toml:",omitempty"`
-		LightNoPrune            bool                   `toml:",omitempty"`
-		LightNoSyncServe        bool                   `toml:",omitempty"`
-		SyncFromCheckpoint      bool                   `toml:",omitempty"`
-		UltraLightServers       []string               `toml:",omitempty"`
-		UltraLightFraction      int                    `toml:",omitempty"`
-		UltraLightOnlyAnnounce  bool                   `toml:",omitempty"`
-		SkipBcVersionCheck      bool                   `toml:"-"`
-		DatabaseHandles         int                    `toml:"-"`
-		DatabaseCache           int
-		DatabaseFreezer         string
-		TrieCleanCache          int
-		TrieCleanCacheJournal   string        `toml:",omitempty"`
-		TrieCleanCacheRejournal time.Duration `toml:",omitempty"`
-		TrieDirtyCache          int
-		TrieTimeout             time.Duration `toml:",omitempty"`
-		SnapshotCache           int
-		Preimages               bool
-		Miner                   miner.Config
-		TxPool                  core.TxPoolConfig
-		GPO                     gasprice.Config
-		EnablePreimageRecording bool
-		RaftMode                bool
-		EnableNodePermission    bool
-		Istanbul                istanbul.Config
-		DocRoot                 string `toml:"-"`
-		EWASMInterpreter        string
-		EVMInterpreter          string
-		RPCGasCap               uint64                         `toml:",omitempty"`
-		RPCTxFeeCap             float64                        `toml:",omitempty"`
-		Checkpoint              *params.TrustedCheckpoint      `toml:",omitempty"`
-		CheckpointOracle        *params.CheckpointOracleConfig `toml:",omitempty"`
-		OverrideBerlin          *big.Int                       `toml:",omitempty"`
-		EVMCallTimeOut          time.Duration
+		Genesis                    *core.Genesis `toml:",omitempty"`
+		NetworkId                  uint64
+		SyncMode                   downloader.SyncMode
+		EthDiscoveryURLs           []string
+		SnapDiscoveryURLs          []string
+		NoPruning                  bool
+		NoPrefetch                 bool
+		TxLookupLimit              uint64                 `toml:",omitempty"`
+		AuthorizationList          map[uint64]common.Hash `toml:"-"`
+		LightServ                  int                    `toml:",omitempty"`
+		LightIngress               int                    `toml:",omitempty"`
+		LightEgress                int                    `toml:",omitempty"`
+		LightPeers                 int                    `toml:",omitempty"`
+		LightNoPrune               bool                   `toml:",omitempty"`
+		LightNoSyncServe           bool                   `toml:",omitempty"`
+		SyncFromCheckpoint         bool                   `toml:",omitempty"`
+		UltraLightServers          []string               `toml:",omitempty"`
+		UltraLightFraction         int                    `toml:",omitempty"`
+		UltraLightOnlyAnnounce     bool                   `toml:",omitempty"`
+		SkipBcVersionCheck         bool                   `toml:"-"`
+		DatabaseHandles            int                    `toml:"-"`
+		DatabaseCache              int
+		DatabaseFreezer            string
+		TrieCleanCache             int
+		TrieCleanCacheJournal      string        `toml:",omitempty"`
+		TrieCleanCacheRejournal    time.Duration `toml:",omitempty"`
+		TrieDirtyCache             int
+		TrieTimeout                time.Duration `toml:",omitempty"`
+		SnapshotCache              int
+		Preimages                  bool
+		Miner                      miner.Config
+		TxPool                     core.TxPoolConfig
+		GPO                        gasprice.Config
+		EnablePreimageRecording    bool
+		RaftMode                   bool
+		EnableNodePermission       bool
+		Istanbul                   istanbul.Config
+		DocRoot                    string `toml:"-"`
+		EWASMInterpreter           string
+		EVMInterpreter             string
+		RPCGasCap                  uint64                         `toml:",omitempty"`
+		RPCTxFeeCap                float64                        `toml:",omitempty"`
+		Checkpoint                 *params.TrustedCheckpoint      `toml:",omitempty"`
+		CheckpointOracle           *params.CheckpointOracleConfig `toml:",omitempty"`
+		OverrideBerlin             *big.Int                       `toml:",omitempty"`
+		EVMCallTimeOut             time.Duration
+		PrivateStatesRefresh       time.Duration
+		PrivacyMarkerBatchAccount  common.Address
+		PrivacyMarkerBatchSize     int
+		PrivacyMarkerBatchInterval time.Duration
 	}
 	var enc Config
 	enc.Genesis = c.Genesis
@@ -112,58 +116,66 @@ func (c Config) MarshalTOML() (interface{}, error) {
 	enc.CheckpointOracle = c.CheckpointOracle
 	enc.OverrideBerlin = c.OverrideBerlin
 	enc.EVMCallTimeOut = c.EVMCallTimeOut
+	enc.PrivateStatesRefresh = c.PrivateStatesRefresh
+	enc.PrivacyMarkerBatchAccount = c.PrivacyMarkerBatchAccount
+	enc.PrivacyMarkerBatchSize = c.PrivacyMarkerBatchSize
+	enc.PrivacyMarkerBatchInterval = c.PrivacyMarkerBatchInterval
 	return &enc, nil
 }
 
 // UnmarshalTOML unmarshals from TOML.
 func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	type Config struct {
-		Genesis                 *core.Genesis `toml:",omitempty"`
-		NetworkId               *uint64
-		SyncMode                *downloader.SyncMode
-		EthDiscoveryURLs        []string
-		SnapDiscoveryURLs       []string
-		NoPruning               *bool
-		NoPrefetch              *bool
-		TxLookupLimit           *uint64                `toml:",omitempty"`
-		AuthorizationList       map[uint64]common.Hash `toml:"-"`
-		LightServ               *int                   `toml:",omitempty"`
-		LightIngress            *int                   `toml:",omitempty"`
-		LightEgress             *int                   `toml:",omitempty"`
-		LightPeers              *int                   `toml:",omitempty"`
-		LightNoPrune            *bool                  `toml:",omitempty"`
-		LightNoSyncServe        *bool                  `toml:",omitempty"`
-		SyncFromCheckpoint      *bool                  `toml:",omitempty"`
-		UltraLightServers       []string               `toml:",omitempty"`
-		UltraLightFraction      *int                   `toml:",omitempty"`
-		UltraLightOnlyAnnounce  *bool                  `toml:",omitempty"`
-		SkipBcVersionCheck      *bool                  `toml:"-"`
-		DatabaseHandles         *int                   `toml:"-"`
-		DatabaseCache           *int
-		DatabaseFreezer         *string
-		TrieCleanCache          *int
-		TrieCleanCacheJournal   *string        `toml:",omitempty"`
-		TrieCleanCacheRejournal *time.Duration `toml:",omitempty"`
-		TrieDirtyCache          *int
-		TrieTimeout             *time.Duration `toml:",omitempty"`
-		SnapshotCache           *int
-		Preimages               *bool
-		Miner                   *miner.Config
-		TxPool                  *core.TxPoolConfig
-		GPO                     *gasprice.Config
-		EnablePreimageRecording *bool
-		RaftMode                *bool
-		EnableNodePermission    *bool
-		Istanbul                *istanbul.Config
-		DocRoot                 *string `toml:"-"`
-		EWASMInterpreter        *string
-		EVMInterpreter          *string
-		RPCGasCap               *uint64                        `toml:",omitempty"`
-		RPCTxFeeCap             *float64                       `toml:",omitempty"`
-		Checkpoint              *params.TrustedCheckpoint      `toml:",omitempty"`
-		CheckpointOracle        *params.CheckpointOracleConfig `toml:",omitempty"`
-		OverrideBerlin          *big.Int                       `toml:",omitempty"`
-		EVMCallTimeOut          *time.Duration
+		Genesis                    *core.Genesis `toml:",omitempty"`
+		NetworkId                  *uint64
+		SyncMode                   *downloader.SyncMode
+		EthDiscoveryURLs           []string
+		SnapDiscoveryURLs          []string
+		NoPruning                  *bool
+		NoPrefetch                 *bool
+		TxLookupLimit              *uint64                `toml:",omitempty"`
+		AuthorizationList          map[uint64]common.Hash `toml:"-"`
+		LightServ                  *int                   `toml:",omitempty"`
+		LightIngress               *int                   `toml:",omitempty"`
+		LightEgress                *int                   `toml:",omitempty"`
+		LightPeers                 *int                   `toml:",omitempty"`
+		LightNoPrune               *bool                  `toml:",omitempty"`
+		LightNoSyncServe           *bool                  `toml:",omitempty"`
+		SyncFromCheckpoint         *bool                  `toml:",omitempty"`
+		UltraLightServers          []string               `toml:",omitempty"`
+		UltraLightFraction         *int                   `toml:",omitempty"`
+		UltraLightOnlyAnnounce     *bool                  `toml:",omitempty"`
+		SkipBcVersionCheck         *bool                  `toml:"-"`
+		DatabaseHandles            *int                   `toml:"-"`
+		DatabaseCache              *int
+		DatabaseFreezer            *string
+		TrieCleanCache             *int
+		TrieCleanCacheJournal      *string        `toml:",omitempty"`
+		TrieCleanCacheRejournal    *time.Duration `toml:",omitempty"`
+		TrieDirtyCache             *int
+		TrieTimeout                *time.Duration `toml:",omitempty"`
+		SnapshotCache              *int
+		Preimages                  *bool
+		Miner                      *miner.Config
+		TxPool                     *core.TxPoolConfig
+		GPO                        *gasprice.Config
+		EnablePreimageRecording    *bool
+		RaftMode                   *bool
+		EnableNodePermission       *bool
+		Istanbul                   *istanbul.Config
+		DocRoot                    *string `toml:"-"`
+		EWASMInterpreter           *string
+		EVMInterpreter             *string
+		RPCGasCap                  *uint64                        `toml:",omitempty"`
+		RPCTxFeeCap                *float64                       `toml:",omitempty"`
+		Checkpoint                 *params.TrustedCheckpoint      `toml:",omitempty"`
+		CheckpointOracle           *params.CheckpointOracleConfig `toml:",omitempty"`
+		OverrideBerlin             *big.Int                       `toml:",omitempty"`
+		EVMCallTimeOut             *time.Duration
+		PrivateStatesRefresh       *time.Duration
+		PrivacyMarkerBatchAccount  *common.Address
+		PrivacyMarkerBatchSize     *int
+		PrivacyMarkerBatchInterval *time.Duration
 	}
 	var dec Config
 	if err := unmarshal(&dec); err != nil {
@@ -307,5 +319,17 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.EVMCallTimeOut != nil {
 		c.EVMCallTimeOut = *dec.EVMCallTimeOut
 	}
+	if dec.PrivateStatesRefresh != nil {
+		c.PrivateStatesRefresh = *dec.PrivateStatesRefresh
+	}
+	if dec.PrivacyMarkerBatchAccount != nil {
+		c.PrivacyMarkerBatchAccount = *dec.PrivacyMarkerBatchAccount
+	}
+	if dec.PrivacyMarkerBatchSize != nil {
+		c.PrivacyMarkerBatchSize = *dec.PrivacyMarkerBatchSize
+	}
+	if dec.PrivacyMarkerBatchInterval != nil {
+		c.PrivacyMarkerBatchInterval = *dec.PrivacyMarkerBatchInterval
+	}
 	return nil
 }
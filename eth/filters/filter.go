@@ -54,6 +54,17 @@ type Backend interface {
 	PSMR() mps.PrivateStateMetadataResolver
 }
 
+// Quorum
+// PrivateBloomBackend is implemented by the backends which maintain a bloom bits index of the
+// private logs of each private state. The bloom bits index of the other backends is expected to
+// cover all logs.
+type PrivateBloomBackend interface {
+	// PrivateBloomStatus returns the section size and the number of indexed sections of the
+	// private logs index of the private state, or an error if the private state is not indexed
+	PrivateBloomStatus(psi types.PrivateStateIdentifier) (uint64, uint64, error)
+	ServicePrivateFilter(ctx context.Context, psi types.PrivateStateIdentifier, session *bloombits.MatcherSession)
+}
+
 // Filter can be used to retrieve and filter logs.
 type Filter struct {
 	backend Backend
@@ -66,7 +77,8 @@ type Filter struct {
 	block      common.Hash // Block hash if filtering a single block
 	begin, end int64       // Range interval if filtering multiple blocks
 
-	matcher *bloombits.Matcher
+	matcher        *bloombits.Matcher
+	privateMatcher *bloombits.Matcher // Quorum: matcher of the private logs index of the private state
 }
 
 // NewRangeFilter creates a new filter which uses a bloom filter on blocks to
@@ -96,6 +108,10 @@ func NewRangeFilter(backend Backend, begin, end int64, addresses []common.Addres
 	filter := newFilter(backend, addresses, topics, psi)
 
 	filter.matcher = bloombits.NewMatcher(size, filters)
+	if privateBackend, ok := backend.(PrivateBloomBackend); ok {
+		privateSize, _, _ := privateBackend.PrivateBloomStatus(psi)
+		filter.privateMatcher = bloombits.NewMatcher(privateSize, filters)
+	}
 	filter.begin = begin
 	filter.end = end
 
@@ -157,7 +173,19 @@ func (f *Filter) Logs(ctx context.Context) ([]*types.Log, error) {
 		err  error
 	)
 	size, sections := f.backend.BloomStatus()
-	if indexed := sections * size; indexed > uint64(f.begin) {
+	indexed := sections * size
+	// Quorum
+	// the private logs are indexed separately, so blocks are only indexed if covered by both indexes
+	if privateBackend, ok := f.backend.(PrivateBloomBackend); ok {
+		privateSize, privateSections, err := privateBackend.PrivateBloomStatus(f.psi)
+		if err != nil {
+			return nil, err
+		}
+		if privateSections*privateSize < indexed {
+			indexed = privateSections * privateSize
+		}
+	}
+	if indexed > uint64(f.begin) {
 		if indexed > end {
 			logs, err = f.indexedLogs(ctx, end)
 		} else {
@@ -186,6 +214,24 @@ func (f *Filter) indexedLogs(ctx context.Context, end uint64) ([]*types.Log, err
 
 	f.backend.ServiceFilter(ctx, session)
 
+	// Quorum
+	// merge the blocks matching the public logs with the ones matching the private logs
+	var privateSession *bloombits.MatcherSession
+	if privateBackend, ok := f.backend.(PrivateBloomBackend); ok && f.privateMatcher != nil {
+		privateMatches := make(chan uint64, 64)
+		privateSession, err = f.privateMatcher.Start(ctx, uint64(f.begin), end, privateMatches)
+		if err != nil {
+			return nil, err
+		}
+		defer privateSession.Close()
+
+		privateBackend.ServicePrivateFilter(ctx, f.psi, privateSession)
+
+		done := make(chan struct{})
+		defer close(done)
+		matches = mergeMatches(done, matches, privateMatches)
+	}
+
 	// Iterate over the matches until exhausted or context closed
 	var logs []*types.Log
 
@@ -195,6 +241,9 @@ func (f *Filter) indexedLogs(ctx context.Context, end uint64) ([]*types.Log, err
 			// Abort if all matches have been fulfilled
 			if !ok {
 				err := session.Error()
+				if err == nil && privateSession != nil {
+					err = privateSession.Error()
+				}
 				if err == nil {
 					f.begin = int64(end) + 1
 				}
@@ -219,6 +268,48 @@ func (f *Filter) indexedLogs(ctx context.Context, end uint64) ([]*types.Log, err
 	}
 }
 
+// Quorum
+// mergeMatches merges two ascending streams of matching block numbers into a single ascending
+// stream without duplicates, which is closed once both streams are closed or done is closed.
+func mergeMatches(done <-chan struct{}, a, b <-chan uint64) chan uint64 {
+	merged := make(chan uint64, 64)
+	go func() {
+		defer close(merged)
+
+		recv := func(ch <-chan uint64) (uint64, bool) {
+			select {
+			case number, ok := <-ch:
+				return number, ok
+			case <-done:
+				return 0, false
+			}
+		}
+		nextA, okA := recv(a)
+		nextB, okB := recv(b)
+		for okA || okB {
+			var number uint64
+			switch {
+			case okA && (!okB || nextA < nextB):
+				number = nextA
+				nextA, okA = recv(a)
+			case okB && (!okA || nextB < nextA):
+				number = nextB
+				nextB, okB = recv(b)
+			default: // the block matches both streams
+				number = nextA
+				nextA, okA = recv(a)
+				nextB, okB = recv(b)
+			}
+			select {
+			case merged <- number:
+			case <-done:
+				return
+			}
+		}
+	}()
+	return merged
+}
+
 // unindexedLogs returns the logs matching the filter criteria based on raw block
 // iteration and bloom matching.
 func (f *Filter) unindexedLogs(ctx context.Context, end uint64) ([]*types.Log, error) {
@@ -18,18 +18,27 @@ package filters
 
 import (
 	"context"
+	"fmt"
 	"math/big"
 	"os"
+	"reflect"
 	"testing"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/bitutil"
 	"github.com/ethereum/go-ethereum/consensus/ethash"
 	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/bloombits"
 	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/params"
 	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func makeReceipt(addr common.Address) *types.Receipt {
@@ -389,3 +398,172 @@ func TestMPSFilters(t *testing.T) {
 		t.Error("expected 3 logs, got", len(logs))
 	}
 }
+
+// privateBloomTestBackend services the private logs filters from the bloom bits indexes of the
+// private states
+type privateBloomTestBackend struct {
+	*testBackend
+	indexers map[types.PrivateStateIdentifier]*core.ChainIndexer
+}
+
+func (b *privateBloomTestBackend) PrivateBloomStatus(psi types.PrivateStateIdentifier) (uint64, uint64, error) {
+	indexer, ok := b.indexers[psi]
+	if !ok {
+		return params.BloomBitsBlocks, 0, fmt.Errorf("unknown private state %s", psi)
+	}
+	sections, _, _ := indexer.Sections()
+	return params.BloomBitsBlocks, sections, nil
+}
+
+func (b *privateBloomTestBackend) ServiceFilter(ctx context.Context, session *bloombits.MatcherSession) {
+	serviceBloomBits(ctx, b.db, b.db, session)
+}
+
+func (b *privateBloomTestBackend) ServicePrivateFilter(ctx context.Context, psi types.PrivateStateIdentifier, session *bloombits.MatcherSession) {
+	serviceBloomBits(ctx, b.db, rawdb.NewPrivateBloomBitsTable(b.db, psi), session)
+}
+
+// serviceBloomBits services the matcher session from the bloom bits of the index database,
+// decompressing them as the bloom handlers of the node do
+func serviceBloomBits(ctx context.Context, db ethdb.Database, indexDb ethdb.KeyValueReader, session *bloombits.MatcherSession) {
+	requests := make(chan chan *bloombits.Retrieval)
+
+	go session.Multiplex(16, 0, requests)
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+
+			case request := <-requests:
+				task := <-request
+
+				task.Bitsets = make([][]byte, len(task.Sections))
+				for i, section := range task.Sections {
+					head := rawdb.ReadCanonicalHash(db, (section+1)*params.BloomBitsBlocks-1)
+					if compressed, err := rawdb.ReadBloomBits(indexDb, task.Bit, section, head); err == nil {
+						task.Bitsets[i], _ = bitutil.DecompressBytes(compressed, int(params.BloomBitsBlocks/8))
+					}
+				}
+				request <- task
+			}
+		}
+	}()
+}
+
+// testIndexerChain feeds the chain indexers with the head of a generated chain
+type testIndexerChain struct {
+	head *types.Header
+	feed event.Feed
+}
+
+func (c *testIndexerChain) CurrentHeader() *types.Header {
+	return c.head
+}
+
+func (c *testIndexerChain) SubscribeChainHeadEvent(ch chan<- core.ChainHeadEvent) event.Subscription {
+	return c.feed.Subscribe(ch)
+}
+
+// waitIndexed waits for the indexer to process the given number of sections
+func waitIndexed(t *testing.T, indexer *core.ChainIndexer, sections uint64) {
+	for i := 0; i < 100; i++ {
+		if indexed, _, _ := indexer.Sections(); indexed >= sections {
+			return
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	t.Fatalf("indexer did not process %d sections", sections)
+}
+
+func TestMPSFilters_privateBloomIndex(t *testing.T) {
+	var (
+		db      = rawdb.NewMemoryDatabase()
+		backend = &privateBloomTestBackend{testBackend: &testBackend{db: db}, indexers: make(map[types.PrivateStateIdentifier]*core.ChainIndexer)}
+		key1, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		addr    = crypto.PubkeyToAddress(key1.PublicKey)
+		hash1   = common.BytesToHash([]byte("topic1"))
+		psi1Log = &types.Log{Address: addr, Topics: []common.Hash{hash1}, PSI: "psi1"}
+	)
+
+	// a private transaction with a log for psi1 only, leaving the block bloom empty
+	genesis := core.GenesisBlockForTesting(db, addr, big.NewInt(1000000))
+	chain, receipts := core.GenerateChain(params.QuorumMPSTestChainConfig, genesis, ethash.NewFaker(), db, int(params.BloomBitsBlocks), func(i int, gen *core.BlockGen) {
+		if i == 1 {
+			tx := types.NewTransaction(1, common.HexToAddress("0x1"), big.NewInt(1), 1, big.NewInt(1), nil)
+			tx.SetPrivate()
+			privateReceipt := types.NewReceipt(nil, false, 0)
+			privateReceipt.PSReceipts = map[types.PrivateStateIdentifier]*types.Receipt{
+				"psi1":                            {Logs: []*types.Log{psi1Log}},
+				types.EmptyPrivateStateIdentifier: {},
+			}
+			gen.AddUncheckedReceipt(privateReceipt)
+			gen.AddUncheckedTx(tx)
+		}
+	})
+	for i, block := range chain {
+		rawdb.WriteBlock(db, block)
+		rawdb.WriteCanonicalHash(db, block.Hash(), block.NumberU64())
+		rawdb.WriteHeadBlockHash(db, block.Hash())
+		rawdb.WriteReceipts(db, block.Hash(), block.NumberU64(), receipts[i])
+	}
+	require.Equal(t, types.Bloom{}, chain[1].Bloom())
+
+	indexerChain := &testIndexerChain{head: chain[len(chain)-1].Header()}
+	publicIndexer := core.NewBloomIndexer(db, params.BloomBitsBlocks, 0)
+	defer publicIndexer.Close()
+	publicIndexer.Start(indexerChain)
+	for _, psi := range []types.PrivateStateIdentifier{"psi1", "psi2"} {
+		indexer := core.NewPrivateBloomIndexer(db, psi, true, params.BloomBitsBlocks, 0)
+		defer indexer.Close()
+		indexer.Start(indexerChain)
+		backend.indexers[psi] = indexer
+	}
+	waitIndexed(t, publicIndexer, 1)
+	waitIndexed(t, backend.indexers["psi1"], 1)
+	waitIndexed(t, backend.indexers["psi2"], 1)
+	backend.sections = 1
+
+	// the range is fully indexed, so the private log can only be found through the private index
+	end := int64(params.BloomBitsBlocks - 1)
+	ctx := rpc.WithPrivateStateIdentifier(context.Background(), "psi1")
+	filter := NewRangeFilter(backend, 0, end, []common.Address{addr}, [][]common.Hash{{hash1}}, "psi1")
+	logs, err := filter.Logs(ctx)
+	require.NoError(t, err)
+	require.Len(t, logs, 1)
+	assert.Equal(t, chain[1].NumberU64(), logs[0].BlockNumber)
+	assert.Equal(t, types.PrivateStateIdentifier("psi1"), logs[0].PSI)
+
+	ctx = rpc.WithPrivateStateIdentifier(context.Background(), "psi2")
+	filter = NewRangeFilter(backend, 0, end, []common.Address{addr}, [][]common.Hash{{hash1}}, "psi2")
+	logs, err = filter.Logs(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, logs)
+
+	ctx = rpc.WithPrivateStateIdentifier(context.Background(), "psi3")
+	filter = NewRangeFilter(backend, 0, end, []common.Address{addr}, [][]common.Hash{{hash1}}, "psi3")
+	_, err = filter.Logs(ctx)
+	assert.EqualError(t, err, "unknown private state psi3")
+}
+
+func TestMergeMatches(t *testing.T) {
+	stream := func(numbers ...uint64) <-chan uint64 {
+		ch := make(chan uint64, len(numbers))
+		for _, number := range numbers {
+			ch <- number
+		}
+		close(ch)
+		return ch
+	}
+	done := make(chan struct{})
+	defer close(done)
+
+	var merged []uint64
+	for number := range mergeMatches(done, stream(1, 4, 5, 9), stream(2, 4, 10, 11)) {
+		merged = append(merged, number)
+	}
+	expected := []uint64{1, 2, 4, 5, 9, 10, 11}
+	if !reflect.DeepEqual(merged, expected) {
+		t.Errorf("merged matches mismatch: have %v, want %v", merged, expected)
+	}
+}
@@ -0,0 +1,114 @@
+package eth
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// maxMissingPrivatePayloadsScanBlocks bounds the number of blocks of a scan for missing private
+// payloads, as the private transaction manager is called for every private transaction
+const maxMissingPrivatePayloadsScanBlocks = 100000
+
+var errMissingPrivatePayloadsScanRunning = errors.New("a scan for missing private payloads is already running")
+
+// MissingPrivatePayloadsScan is the progress and the result of a scan for missing private payloads
+type MissingPrivatePayloadsScan struct {
+	First   uint64                        `json:"first"`
+	Last    uint64                        `json:"last"`
+	Next    uint64                        `json:"next"` // number of the next block to scan
+	Done    bool                          `json:"done"`
+	Error   string                        `json:"error,omitempty"`
+	Missing []*core.MissingPrivatePayload `json:"missing"`
+}
+
+func (s *MissingPrivatePayloadsScan) copy() *MissingPrivatePayloadsScan {
+	cpy := *s
+	cpy.Missing = append([]*core.MissingPrivatePayload{}, s.Missing...)
+	return &cpy
+}
+
+// missingPrivatePayloadsScanner runs one scan for missing private payloads at a time in the
+// background, keeping the result of the last scan until the next one is started
+type missingPrivatePayloadsScanner struct {
+	scan *MissingPrivatePayloadsScan // nil if no scan has been started
+	mu   sync.Mutex
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+func newMissingPrivatePayloadsScanner() *missingPrivatePayloadsScanner {
+	return &missingPrivatePayloadsScanner{quit: make(chan struct{})}
+}
+
+// start starts scanning the blocks from first to last of the chain, unless a scan is running
+func (s *missingPrivatePayloadsScanner) start(chain *core.BlockChain, first, last uint64, managedParties []string) (*MissingPrivatePayloadsScan, error) {
+	if last-first >= maxMissingPrivatePayloadsScanBlocks {
+		return nil, fmt.Errorf("cannot scan more than %d blocks at once", maxMissingPrivatePayloadsScanBlocks)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	select {
+	case <-s.quit:
+		return nil, errors.New("node is shutting down")
+	default:
+	}
+	if s.scan != nil && !s.scan.Done {
+		return nil, errMissingPrivatePayloadsScanRunning
+	}
+	s.scan = &MissingPrivatePayloadsScan{First: first, Last: last, Next: first}
+	s.wg.Add(1)
+	go s.run(chain, s.scan, managedParties)
+	return s.scan.copy(), nil
+}
+
+func (s *missingPrivatePayloadsScanner) run(chain *core.BlockChain, scan *MissingPrivatePayloadsScan, managedParties []string) {
+	defer s.wg.Done()
+	var err error
+	for number := scan.First; number <= scan.Last && err == nil; number++ {
+		select {
+		case <-s.quit:
+			err = errors.New("node is shutting down")
+			continue
+		default:
+		}
+		var missing []*core.MissingPrivatePayload
+		if missing, err = chain.MissingPrivatePayloadsAt(number, managedParties); err == nil {
+			s.mu.Lock()
+			scan.Missing = append(scan.Missing, missing...)
+			scan.Next = number + 1
+			s.mu.Unlock()
+		}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	scan.Done = true
+	if err != nil {
+		scan.Error = err.Error()
+		log.Warn("Failed to scan for missing private payloads", "first", scan.First, "last", scan.Last, "next", scan.Next, "err", err)
+		return
+	}
+	log.Info("Scanned for missing private payloads", "first", scan.First, "last", scan.Last, "missing", len(scan.Missing))
+}
+
+// status returns the progress and the result of the running or the last scan, nil if none has been started
+func (s *missingPrivatePayloadsScanner) status() *MissingPrivatePayloadsScan {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.scan == nil {
+		return nil
+	}
+	return s.scan.copy()
+}
+
+// stop aborts the running scan and waits for it to return
+func (s *missingPrivatePayloadsScanner) stop() {
+	s.mu.Lock()
+	close(s.quit)
+	s.mu.Unlock()
+	s.wg.Wait()
+}
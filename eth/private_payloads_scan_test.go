@@ -0,0 +1,47 @@
+package eth
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMissingPrivatePayloadsScanner(t *testing.T) {
+	handler := newTestHandlerWithBlocks(10)
+	defer handler.close()
+	scanner := newMissingPrivatePayloadsScanner()
+
+	scan, err := scanner.start(handler.chain, 1, 10, nil)
+
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), scan.First)
+	assert.Equal(t, uint64(10), scan.Last)
+	assert.Eventually(t, func() bool { return scanner.status().Done }, time.Second, 10*time.Millisecond, "expected the scan to run in the background")
+	scan = scanner.status()
+	assert.Empty(t, scan.Error)
+	assert.Equal(t, uint64(11), scan.Next)
+	assert.Empty(t, scan.Missing)
+
+	scan, err = scanner.start(handler.chain, 5, 20, nil)
+
+	require.NoError(t, err, "expected a new scan to be started once the last one is done")
+	assert.Eventually(t, func() bool { return scanner.status().Done }, time.Second, 10*time.Millisecond)
+	assert.Equal(t, "block #11 not found", scanner.status().Error)
+	assert.Equal(t, uint64(11), scanner.status().Next)
+
+	scanner.stop()
+	_, err = scanner.start(handler.chain, 1, 10, nil)
+	assert.Error(t, err, "expected no scan to be started once stopped")
+}
+
+func TestMissingPrivatePayloadsScanner_whenTooManyBlocks(t *testing.T) {
+	scanner := newMissingPrivatePayloadsScanner()
+	defer scanner.stop()
+
+	_, err := scanner.start(nil, 1, maxMissingPrivatePayloadsScanBlocks+1, nil)
+
+	assert.EqualError(t, err, "cannot scan more than 100000 blocks at once")
+	assert.Nil(t, scanner.status())
+}
@@ -71,7 +71,7 @@ func (eth *Ethereum) stateAtBlock(block *types.Block, reexec uint64, base *state
 			statedb, err = state.New(current.Root(), database, nil)
 			if err == nil {
 				// Quorum
-				_, privateStateDB, err = eth.blockchain.StateAt(current.Root())
+				privateStateDB, err = eth.blockchain.PrivateStateRepositoryWithoutSnapshots(current.Root())
 				if err == nil {
 					return statedb, privateStateDB, nil
 				}
@@ -92,7 +92,7 @@ func (eth *Ethereum) stateAtBlock(block *types.Block, reexec uint64, base *state
 			statedb, err = state.New(current.Root(), database, nil)
 			if err == nil {
 				// Quorum
-				_, privateStateDB, err = eth.blockchain.StateAt(current.Root())
+				privateStateDB, err = eth.blockchain.PrivateStateRepositoryWithoutSnapshots(current.Root())
 				if err == nil {
 					break
 				}
@@ -43,6 +43,7 @@ import (
 	"github.com/ethereum/go-ethereum/private"
 	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/jpmorganchase/quorum-security-plugin-sdk-go/proto"
 )
 
 const (
@@ -73,6 +74,7 @@ type Backend interface {
 
 	// Quorum
 	GetBlockchain() *core.BlockChain
+	SupportsMultitenancy(rpcCtx context.Context) (*proto.PreAuthenticatedAuthenticationToken, bool)
 }
 
 // API is the collection of tracing APIs exposed over the private debugging endpoint.
@@ -246,6 +248,7 @@ type txTraceTask struct {
 	// Quorum
 	privateStateDb   *state.StateDB
 	privateStateRepo mps.PrivateStateRepository
+	psi              types.PrivateStateIdentifier // Private state to trace the transaction in, if not the caller's
 }
 
 // TraceChain returns the structured logs created during the execution of EVM
@@ -927,10 +930,16 @@ func (api *API) TraceCall(ctx context.Context, args ethapi.CallArgs, blockNrOrHa
 // executes the given message in the provided environment. The return value will
 // be tracer dependent.
 func (api *API) traceTx(ctx context.Context, message core.Message, txctx *txTraceContext, vmctx vm.BlockContext, statedb, privateStateDb *state.StateDB, privateStateRepo mps.PrivateStateRepository, config *TraceConfig) (interface{}, error) {
+	// Quorum
+	psm, err := api.chainContext(ctx).PrivateStateManager().ResolveForUserContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("tracing failed: %w", err)
+	}
+	// End Quorum
+
 	// Assemble the structured logger or the JavaScript tracer
 	var (
 		tracer    vm.Tracer
-		err       error
 		txContext = core.NewEVMTxContext(message)
 	)
 	switch {
@@ -942,8 +951,11 @@ func (api *API) traceTx(ctx context.Context, message core.Message, txctx *txTrac
 				return nil, err
 			}
 		}
-		// Construct the JavaScript tracer to execute with
-		if tracer, err = New(*config.Tracer, txContext); err != nil {
+		// Quorum: construct the native tracer aware of the private state, if any,
+		// the JavaScript tracer otherwise
+		if native, ok := newNativeTracer(*config.Tracer, psm.ID); ok {
+			tracer = native
+		} else if tracer, err = New(*config.Tracer, txContext); err != nil {
 			return nil, err
 		}
 		// Handle timeouts and RPC cancellations
@@ -951,7 +963,7 @@ func (api *API) traceTx(ctx context.Context, message core.Message, txctx *txTrac
 		go func() {
 			<-deadlineCtx.Done()
 			if deadlineCtx.Err() == context.DeadlineExceeded {
-				tracer.(*Tracer).Stop(errors.New("execution timeout"))
+				tracer.(txTracer).Stop(errors.New("execution timeout"))
 			}
 		}()
 		defer cancel()
@@ -967,11 +979,6 @@ func (api *API) traceTx(ctx context.Context, message core.Message, txctx *txTrac
 	// Set the private state to public state if it is not a private tx
 	privateStateDbToUse := core.PrivateStateDBForTxn(api.backend.ChainConfig().IsQuorum, txctx.tx, statedb, privateStateDb)
 
-	psm, err := api.chainContext(ctx).PrivateStateManager().ResolveForUserContext(ctx)
-	if err != nil {
-		return nil, fmt.Errorf("tracing failed: %w", err)
-	}
-
 	// Run the transaction with tracing enabled.
 	vmconf := &vm.Config{Debug: true, Tracer: tracer, ApplyOnPartyOverride: &psm.ID}
 	vmenv := vm.NewEVM(vmctx, txContext, statedb, privateStateDbToUse, api.backend.ChainConfig(), *vmconf)
@@ -1009,7 +1016,7 @@ func (api *API) traceTx(ctx context.Context, message core.Message, txctx *txTrac
 			StructLogs:  ethapi.FormatLogs(tracer.StructLogs()),
 		}, nil
 
-	case *Tracer:
+	case txTracer:
 		return tracer.GetResult()
 
 	default:
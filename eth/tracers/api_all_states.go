@@ -0,0 +1,225 @@
+package tracers
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"sort"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/mps"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/multitenancy"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// TraceBlockAllStates returns the structured logs created during the execution of EVM in each
+// private state of the node, re-executing the block only once. The traces are keyed by the private
+// state identifier, with one item per transaction as with TraceBlockByNumber. When multitenancy is
+// enabled, the caller must be authorized to access all the private states.
+func (api *API) TraceBlockAllStates(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash, config *TraceConfig) (map[types.PrivateStateIdentifier][]*txTraceResult, error) {
+	var (
+		err   error
+		block *types.Block
+	)
+	if hash, ok := blockNrOrHash.Hash(); ok {
+		block, err = api.blockByHash(ctx, hash)
+	} else if number, ok := blockNrOrHash.Number(); ok {
+		block, err = api.blockByNumber(ctx, number)
+	} else {
+		return nil, errors.New("invalid arguments; neither block nor hash specified")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return api.traceBlockAllStates(ctx, block, config)
+}
+
+// traceBlockAllStates configures a new tracer according to the provided configuration for each
+// private state, and executes all the transactions contained within. The public transactions are
+// traced once as they do not depend on the private state.
+func (api *API) traceBlockAllStates(ctx context.Context, block *types.Block, config *TraceConfig) (map[types.PrivateStateIdentifier][]*txTraceResult, error) {
+	if block.NumberU64() == 0 {
+		return nil, errors.New("genesis is not traceable")
+	}
+	psManager := api.chainContext(ctx).PrivateStateManager()
+	psis := core.PrivateStatesAt(psManager, block.NumberU64())
+	sort.Slice(psis, func(i, j int) bool { return psis[i] < psis[j] })
+	if authToken, ok := api.backend.SupportsMultitenancy(ctx); ok {
+		for _, psi := range psis {
+			authorized, err := multitenancy.IsPSIAuthorized(authToken, psi)
+			if err != nil {
+				return nil, err
+			}
+			if !authorized {
+				return nil, multitenancy.ErrNotAuthorized
+			}
+		}
+	}
+	parent, err := api.blockByNumberAndHash(ctx, rpc.BlockNumber(block.NumberU64()-1), block.ParentHash())
+	if err != nil {
+		return nil, err
+	}
+	reexec := defaultTraceReexec
+	if config != nil && config.Reexec != nil {
+		reexec = *config.Reexec
+	}
+	statedb, privateStateRepo, err := api.backend.StateAtBlock(ctx, parent, reexec, nil, true)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		signer          = types.MakeSigner(api.backend.ChainConfig(), block.Number())
+		txs             = block.Transactions()
+		psms            = make(map[types.PrivateStateIdentifier]*mps.PrivateStateMetadata, len(psis))
+		privateStateDbs = make(map[types.PrivateStateIdentifier]*state.StateDB, len(psis))
+		results         = make(map[types.PrivateStateIdentifier][]*txTraceResult, len(psis))
+	)
+	for _, psi := range psis {
+		if psms[psi], err = psManager.ResolveForUserContext(rpc.WithPrivateStateIdentifier(ctx, psi)); err != nil {
+			return nil, err
+		}
+		if privateStateDbs[psi], err = privateStateRepo.StatePSI(psi); err != nil {
+			return nil, err
+		}
+		results[psi] = make([]*txTraceResult, len(txs))
+	}
+	if len(psis) == 0 {
+		return results, nil
+	}
+
+	// Execute all the transaction contained within the block concurrently
+	var (
+		pend = new(sync.WaitGroup)
+		jobs = make(chan *txTraceTask, len(txs)*len(psis))
+	)
+	threads := runtime.NumCPU()
+	if threads > cap(jobs) {
+		threads = cap(jobs)
+	}
+	blockCtx := core.NewEVMBlockContext(block.Header(), api.chainContext(ctx), nil)
+	blockHash := block.Hash()
+	for th := 0; th < threads; th++ {
+		pend.Add(1)
+		go func() {
+			defer pend.Done()
+			// Fetch and execute the next transaction trace tasks
+			for task := range jobs {
+				tx := txs[task.index]
+				msg, _ := tx.AsMessage(signer)
+				msg = api.clearMessageDataIfNonParty(msg, psms[task.psi])
+				txctx := &txTraceContext{
+					index: task.index,
+					hash:  tx.Hash(),
+					block: blockHash,
+					tx:    tx,
+				}
+				res, err := api.traceTx(rpc.WithPrivateStateIdentifier(ctx, task.psi), msg, txctx, blockCtx, task.statedb, task.privateStateDb, task.privateStateRepo, config)
+				if err != nil {
+					results[task.psi][task.index] = &txTraceResult{Error: err.Error()}
+					continue
+				}
+				results[task.psi][task.index] = &txTraceResult{Result: res}
+			}
+		}()
+	}
+	// Feed the transactions into the tracers and return
+	var failed error
+	isQuorum := api.backend.ChainConfig().IsQuorum
+	for i, tx := range txs {
+		isPrivate := isQuorum && (tx.IsPrivate() || tx.IsPrivacyMarker())
+		for _, psi := range psis {
+			task := &txTraceTask{
+				statedb:          statedb.Copy(),
+				index:            i,
+				privateStateDb:   privateStateDbs[psi].Copy(),
+				privateStateRepo: privateStateRepo,
+				psi:              psi,
+			}
+			// The inner private transaction of a PMT is applied on MPS to the private state held by
+			// the repository, so each task gets its own copy of the repository to trace it in
+			if isQuorum && tx.IsPrivacyMarker() && privateStateRepo.IsMPS() {
+				task.privateStateRepo = privateStateRepo.Copy()
+				if task.privateStateDb, failed = task.privateStateRepo.StatePSI(psi); failed != nil {
+					break
+				}
+			}
+			// Send the trace task over for execution
+			jobs <- task
+			// The public transactions are traced in the first private state only
+			if !isPrivate {
+				break
+			}
+		}
+		if failed != nil {
+			break
+		}
+		// Generate the next states snapshot fast without tracing, the public state being changed
+		// by the execution of the transaction in the first private state only
+		publicStateDb := statedb
+		if isPrivate {
+			publicStateDb = statedb.Copy()
+		}
+		for j, psi := range psis {
+			if j > 0 && !isPrivate {
+				break
+			}
+			statedbToUse := statedb
+			if j > 0 {
+				statedbToUse = publicStateDb.Copy()
+			}
+			if failed = api.applyTransaction(block, tx, i, signer, blockCtx, statedbToUse, privateStateDbs[psi], privateStateRepo, psms[psi]); failed != nil {
+				break
+			}
+		}
+		if failed != nil {
+			break
+		}
+		// Finalize the states so any modifications are written to the trie
+		// Only delete empty objects if EIP158/161 (a.k.a Spurious Dragon) is in effect
+		eip158 := api.backend.ChainConfig().IsEIP158(block.Number())
+		statedb.Finalise(eip158)
+		for _, privateStateDb := range privateStateDbs {
+			privateStateDb.Finalise(eip158)
+		}
+	}
+	close(jobs)
+	pend.Wait()
+
+	// If execution failed in between, abort
+	if failed != nil {
+		return nil, failed
+	}
+	// Share the traces of the public transactions among the private states
+	for i, tx := range txs {
+		if isQuorum && (tx.IsPrivate() || tx.IsPrivacyMarker()) {
+			continue
+		}
+		for _, psi := range psis[1:] {
+			results[psi][i] = results[psis[0]][i]
+		}
+	}
+	return results, nil
+}
+
+// applyTransaction applies the transaction of the block to the public state and to the private
+// state of the given private state metadata, without tracing.
+func (api *API) applyTransaction(block *types.Block, tx *types.Transaction, index int, signer types.Signer, blockCtx vm.BlockContext, statedb, privateStateDb *state.StateDB, privateStateRepo mps.PrivateStateRepository, psm *mps.PrivateStateMetadata) error {
+	msg, _ := tx.AsMessage(signer)
+	msg = api.clearMessageDataIfNonParty(msg, psm)
+	privateStateDbToUse := core.PrivateStateDBForTxn(api.backend.ChainConfig().IsQuorum, tx, statedb, privateStateDb)
+	statedb.Prepare(tx.Hash(), block.Hash(), index)
+	privateStateDbToUse.Prepare(tx.Hash(), block.Hash(), index)
+
+	vmconf := vm.Config{ApplyOnPartyOverride: &psm.ID}
+	vmenv := vm.NewEVM(blockCtx, core.NewEVMTxContext(msg), statedb, privateStateDbToUse, api.backend.ChainConfig(), vmconf)
+	vmenv.SetCurrentTX(tx)
+	vmenv.InnerApply = func(innerTx *types.Transaction) error {
+		return applyInnerTransaction(api.backend.GetBlockchain(), statedb, privateStateDbToUse, block.Header(), tx, vmconf, privateStateRepo.IsMPS(), privateStateRepo, vmenv, innerTx, index)
+	}
+	_, err := core.ApplyMessage(vmenv, msg, new(core.GasPool).AddGas(msg.Gas()))
+	return err
+}
@@ -20,6 +20,7 @@ import (
 	"bytes"
 	"context"
 	"crypto/ecdsa"
+	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -42,8 +43,13 @@ import (
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/internal/ethapi"
+	"github.com/ethereum/go-ethereum/multitenancy"
 	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/private"
+	"github.com/ethereum/go-ethereum/private/engine"
 	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/golang/mock/gomock"
+	"github.com/jpmorganchase/quorum-security-plugin-sdk-go/proto"
 )
 
 var (
@@ -57,6 +63,8 @@ type testBackend struct {
 	engine      consensus.Engine
 	chaindb     ethdb.Database
 	chain       *core.BlockChain
+
+	multitenancySupported bool
 }
 
 var _ Backend = &testBackend{}
@@ -191,6 +199,14 @@ func (b *testBackend) GetBlockchain() *core.BlockChain {
 	return b.chain
 }
 
+func (b *testBackend) SupportsMultitenancy(rpcCtx context.Context) (*proto.PreAuthenticatedAuthenticationToken, bool) {
+	authToken := rpc.PreauthenticatedTokenFromContext(rpcCtx)
+	if authToken != nil && b.multitenancySupported {
+		return authToken, true
+	}
+	return nil, false
+}
+
 func TestTraceCall(t *testing.T) {
 	t.Parallel()
 
@@ -613,6 +629,193 @@ func TestTraceBlock(t *testing.T) {
 	}
 }
 
+func TestTraceBlockAllStates(t *testing.T) {
+	t.Parallel()
+
+	// Initialize test accounts
+	accounts := newAccounts(2)
+	genesis := &core.Genesis{Alloc: core.GenesisAlloc{
+		accounts[0].addr: {Balance: big.NewInt(params.Ether)},
+		accounts[1].addr: {Balance: big.NewInt(params.Ether)},
+	}}
+	genBlocks := 2
+	signer := types.HomesteadSigner{}
+	backend := newTestBackend(t, genBlocks, genesis, func(i int, b *core.BlockGen) {
+		// Transfer from account[0] to account[1]
+		//    value: 1000 wei
+		//    fee:   0 wei
+		tx, _ := types.SignTx(types.NewTransaction(uint64(i), accounts[1].addr, big.NewInt(1000), params.TxGas, big.NewInt(0), nil), signer, accounts[0].key)
+		b.AddTx(tx)
+	})
+	backend.multitenancySupported = true
+	api := NewAPI(backend)
+	head := rpc.BlockNumberOrHashWithNumber(rpc.BlockNumber(genBlocks))
+	expect := map[types.PrivateStateIdentifier][]*txTraceResult{
+		types.DefaultPrivateStateIdentifier: {
+			{
+				Result: &ethapi.ExecutionResult{
+					Gas:         params.TxGas,
+					Failed:      false,
+					ReturnValue: "",
+					StructLogs:  []ethapi.StructLogRes{},
+				},
+			},
+		},
+	}
+
+	result, err := api.TraceBlockAllStates(context.Background(), head, nil)
+	if err != nil {
+		t.Fatalf("Expect no error, get %v", err)
+	}
+	if !reflect.DeepEqual(result, expect) {
+		t.Errorf("Result mismatch, want %v, get %v", expect, result)
+	}
+
+	// The caller of a multitenant node must be authorized to access all the private states
+	authorized := rpc.WithPreauthenticatedToken(context.Background(), &proto.PreAuthenticatedAuthenticationToken{
+		Authorities: []*proto.GrantedAuthority{{Raw: "psi://" + types.DefaultPrivateStateIdentifier.String()}},
+	})
+	result, err = api.TraceBlockAllStates(authorized, head, nil)
+	if err != nil {
+		t.Fatalf("Expect no error, get %v", err)
+	}
+	if !reflect.DeepEqual(result, expect) {
+		t.Errorf("Result mismatch, want %v, get %v", expect, result)
+	}
+
+	unauthorized := rpc.WithPreauthenticatedToken(context.Background(), &proto.PreAuthenticatedAuthenticationToken{
+		Authorities: []*proto.GrantedAuthority{{Raw: "psi://other"}},
+	})
+	if _, err := api.TraceBlockAllStates(unauthorized, head, nil); err != multitenancy.ErrNotAuthorized {
+		t.Errorf("Error mismatch, want %v, get %v", multitenancy.ErrNotAuthorized, err)
+	}
+}
+
+func TestTraceBlockAllStates_whenMPSPrivacyMarkerTransaction(t *testing.T) {
+	backend, _, _ := newMPSPrivacyMarkerTestBackend(t)
+
+	result, err := NewAPI(backend).TraceBlockAllStates(context.Background(), rpc.BlockNumberOrHashWithNumber(1), nil)
+	if err != nil {
+		t.Fatalf("Expect no error, get %v", err)
+	}
+	for psi, traces := range result {
+		if len(traces) != 2 {
+			t.Fatalf("private state %s: expected 2 traces, got %d", psi, len(traces))
+		}
+		for i, trace := range traces {
+			if trace.Error != "" {
+				t.Errorf("private state %s, transaction %d: unexpected error %s", psi, i, trace.Error)
+			}
+		}
+	}
+	// the inner private contract creation is only executed in the private state of its party
+	if logs := result["psi1"][0].Result.(*ethapi.ExecutionResult).StructLogs; len(logs) != 4 {
+		t.Errorf("Expect the inner transaction to be traced in the private state of its party, get %d logs", len(logs))
+	}
+	if logs := result["psi2"][0].Result.(*ethapi.ExecutionResult).StructLogs; len(logs) != 0 {
+		t.Errorf("Expect the inner transaction not to be traced in another private state, get %d logs", len(logs))
+	}
+}
+
+// newMPSPrivacyMarkerTestBackend returns a backend of an MPS chain whose block 1 holds a PMT creating
+// a private contract for the private state psi1, followed by a public transfer
+func newMPSPrivacyMarkerTestBackend(t *testing.T) (*testBackend, Accounts, *types.Transaction) {
+	mockCtrl := gomock.NewController(t)
+	t.Cleanup(mockCtrl.Finish)
+	mockptm := private.NewMockPrivateTransactionManager(mockCtrl)
+	saved := private.P
+	t.Cleanup(func() { private.P = saved })
+	private.P = mockptm
+
+	config := *params.QuorumMPSTestChainConfig
+	config.PrivacyPrecompileBlock = big.NewInt(0)
+	accounts := newAccounts(2)
+	var (
+		payloadHash = common.BytesToEncryptedPayloadHash([]byte("private contract creation"))
+		pmtHash     = common.BytesToEncryptedPayloadHash([]byte("privacy marker"))
+		// stores 1 in slot 0 when deployed
+		privateCode = common.FromHex("0x600160005500")
+	)
+	innerTx := types.NewContractCreation(0, common.Big0, 1000000, common.Big0, payloadHash.Bytes())
+	innerTx.SetPrivate()
+	innerTx, _ = types.SignTx(innerTx, types.QuorumPrivateTxSigner{}, accounts[0].key)
+	innerTxJSON, _ := innerTx.MarshalJSON()
+	mockptm.EXPECT().HasFeature(engine.MultiplePrivateStates).Return(true).AnyTimes()
+	mockptm.EXPECT().Groups().Return([]engine.PrivacyGroup{
+		{Type: engine.PrivacyGroupResident, Name: "psi1", PrivacyGroupId: base64.StdEncoding.EncodeToString([]byte("psi1")), Members: []string{"AAA"}},
+		{Type: engine.PrivacyGroupResident, Name: "psi2", PrivacyGroupId: base64.StdEncoding.EncodeToString([]byte("psi2")), Members: []string{"BBB"}},
+	}, nil).AnyTimes()
+	mockptm.EXPECT().Receive(common.EncryptedPayloadHash{}).Return("", nil, nil, nil, nil).AnyTimes()
+	mockptm.EXPECT().Receive(pmtHash).Return("", []string{"AAA"}, innerTxJSON, nil, nil).AnyTimes()
+	mockptm.EXPECT().Receive(payloadHash).Return("", []string{"AAA"}, privateCode, nil, nil).AnyTimes()
+
+	backend := &testBackend{
+		chainConfig: &config,
+		engine:      ethash.NewFaker(),
+		chaindb:     rawdb.NewMemoryDatabase(),
+	}
+	gspec := &core.Genesis{Config: &config, Alloc: core.GenesisAlloc{
+		accounts[0].addr: {Balance: big.NewInt(params.Ether)},
+	}}
+	gspec.MustCommit(backend.chaindb)
+	chain, err := core.NewBlockChain(backend.chaindb, &core.CacheConfig{TrieCleanLimit: 256, TrieDirtyLimit: 256, TrieTimeLimit: 5 * time.Minute, TrieDirtyDisabled: true}, &config, backend.engine, vm.Config{}, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create tester chain: %v", err)
+	}
+	backend.chain = chain
+	gendb := rawdb.NewMemoryDatabase()
+	var pmt *types.Transaction
+	blocks, _ := core.GenerateChain(&config, gspec.MustCommit(gendb), backend.engine, gendb, 1, func(i int, b *core.BlockGen) {
+		// a PMT followed by a public transfer, so that the next states are generated while the PMT is traced
+		pmt, _ = types.SignTx(types.NewTransaction(0, common.QuorumPrivacyPrecompileContractAddress(), common.Big0, 1000000, common.Big0, pmtHash.Bytes()), types.HomesteadSigner{}, accounts[0].key)
+		b.AddTxWithChain(chain, pmt)
+		transfer, _ := types.SignTx(types.NewTransaction(1, accounts[1].addr, big.NewInt(1000), params.TxGas, common.Big0, nil), types.HomesteadSigner{}, accounts[0].key)
+		b.AddTxWithChain(chain, transfer)
+	})
+	if n, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("block %d: failed to insert into chain: %v", n, err)
+	}
+	return backend, accounts, pmt
+}
+
+func TestTraceTransaction_whenMPSPrivacyMarkerTransactionWithPrestateTracer(t *testing.T) {
+	backend, accounts, pmt := newMPSPrivacyMarkerTestBackend(t)
+
+	tracer := "prestateTracer"
+	ctx := rpc.WithPrivateStateIdentifier(context.Background(), "psi1")
+	result, err := NewAPI(backend).TraceTransaction(ctx, pmt.Hash(), &TraceConfig{Tracer: &tracer})
+	if err != nil {
+		t.Fatalf("Expect no error, get %v", err)
+	}
+	ret := new(prestateResult)
+	if err := json.Unmarshal(result.(json.RawMessage), ret); err != nil {
+		t.Fatalf("failed to unmarshal trace result: %v", err)
+	}
+	// the sender of the PMT, which pays for it, is reported as before the PMT
+	sender, ok := ret.Public[accounts[0].addr]
+	if !ok {
+		t.Fatalf("expected sender %v in the public state", accounts[0].addr)
+	}
+	if have, want := sender.Balance.ToInt(), big.NewInt(params.Ether); have.Cmp(want) != 0 {
+		t.Errorf("unexpected balance of the sender: have %v, want %v", have, want)
+	}
+	if sender.Nonce != 0 {
+		t.Errorf("unexpected nonce of the sender: have %d, want 0", sender.Nonce)
+	}
+	// the recipient of the PMT is reported rather than the one of its inner private transaction
+	if _, ok := ret.Private["psi1"][common.QuorumPrivacyPrecompileContractAddress()]; !ok {
+		t.Errorf("expected the privacy precompile in the private state")
+	}
+	// the contract created by the inner private transaction did not exist before
+	created := crypto.CreateAddress(accounts[0].addr, 0)
+	if _, ok := ret.Private["psi1"][created]; ok {
+		t.Errorf("unexpected created contract %v in the private state", created)
+	}
+	if _, ok := ret.Public[created]; ok {
+		t.Errorf("unexpected created contract %v in the public state", created)
+	}
+}
+
 type Account struct {
 	key  *ecdsa.PrivateKey
 	addr common.Address
@@ -0,0 +1,119 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package tracers
+
+import (
+	"encoding/json"
+	"errors"
+	"sync/atomic"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+)
+
+// errTraceNotStarted is returned when the result of a native tracer is requested
+// before the tracing of a transaction started.
+var errTraceNotStarted = errors.New("tracing not started")
+
+// txTracer is a tracer of a transaction which collects the result of the tracing
+// and can be stopped, like the JavaScript tracers and the native tracers.
+type txTracer interface {
+	vm.Tracer
+	GetResult() (json.RawMessage, error)
+	Stop(err error)
+}
+
+// nativeTracers are the tracers implemented in Go, which are used instead of the
+// JavaScript tracers of the same name. They are given the private state the
+// transaction is traced in.
+var nativeTracers = map[string]func(psi types.PrivateStateIdentifier) txTracer{
+	"callTracer":     newNativeCallTracer,
+	"prestateTracer": newNativePrestateTracer,
+}
+
+// newNativeTracer returns the native tracer of the given name, if any.
+func newNativeTracer(name string, psi types.PrivateStateIdentifier) (txTracer, bool) {
+	if ctor, ok := nativeTracers[name]; ok {
+		return ctor(psi), true
+	}
+	return nil, false
+}
+
+// interruption allows a native tracer to be stopped.
+type interruption struct {
+	interrupt uint32 // Atomic flag to signal execution interruption
+	reason    error  // Textual reason for the interruption
+}
+
+// Stop terminates execution of the tracer at the first opportune moment.
+func (i *interruption) Stop(err error) {
+	i.reason = err
+	atomic.StoreUint32(&i.interrupt, 1)
+}
+
+func (i *interruption) stopped() bool {
+	return atomic.LoadUint32(&i.interrupt) > 0
+}
+
+// Quorum
+//
+// The states a frame of a transaction is executed in: the public state, the
+// private state, or the public state read by a private transaction, in which
+// the EVM does not allow any change. Once a private transaction reads the public
+// state, the frames of the nested calls are read only too, see vm.EVM.Push.
+const (
+	publicFrame   = "public"
+	privateFrame  = "private"
+	readOnlyFrame = "readOnly"
+)
+
+// frameState returns the state a frame executed in db is executed in, given
+// whether its parent frame is read only.
+func frameState(env *vm.EVM, db vm.StateDB, parentReadOnly bool) string {
+	if env.PrivateState() == env.PublicState() {
+		return publicFrame
+	}
+	if parentReadOnly || db != env.PrivateState() {
+		return readOnlyFrame
+	}
+	return privateFrame
+}
+
+// dualState returns the state the EVM reads the account of the address from:
+// the private state if the account is private, the public state otherwise.
+func dualState(env *vm.EVM, addr common.Address) vm.StateDB {
+	if env.PrivateState().Exist(addr) {
+		return env.PrivateState()
+	}
+	if env.PublicState().Exist(addr) {
+		return env.PublicState()
+	}
+	return env.StateDB
+}
+
+// End Quorum
+
+// memorySlice returns a copy of the memory from begin to end, which is empty if
+// the memory is out of bound.
+func memorySlice(memory *vm.Memory, begin, end uint64) hexutil.Bytes {
+	if end <= begin || end > uint64(memory.Len()) {
+		return hexutil.Bytes{}
+	}
+	return memory.GetCopy(int64(begin), int64(end-begin))
+}
@@ -0,0 +1,285 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package tracers
+
+import (
+	"encoding/json"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+)
+
+// callFrame is a call of the trace of the native call tracer.
+type callFrame struct {
+	Type    string          `json:"type"`
+	From    common.Address  `json:"from"`
+	To      *common.Address `json:"to,omitempty"`
+	Value   *hexutil.Big    `json:"value,omitempty"`
+	Gas     *hexutil.Uint64 `json:"gas,omitempty"`
+	GasUsed *hexutil.Uint64 `json:"gasUsed,omitempty"`
+	Input   *hexutil.Bytes  `json:"input,omitempty"`
+	Output  *hexutil.Bytes  `json:"output,omitempty"`
+	Error   string          `json:"error,omitempty"`
+	Time    string          `json:"time,omitempty"`
+	Calls   []*callFrame    `json:"calls,omitempty"`
+	State   string          `json:"state,omitempty"` // Quorum: public, private or readOnly
+
+	gasIn   uint64 // Gas available when the call was made
+	gasCost uint64 // Cost of the call opcode
+	outOff  uint64 // Memory offset of the output of the call
+	outLen  uint64 // Memory length of the output of the call
+}
+
+// callTracer is the native implementation of the callTracer, which additionally
+// reports whether each call is executed in the public state, in the private state
+// or in the public state read by a private transaction.
+type callTracer struct {
+	interruption
+
+	callstack []*callFrame // Calls in progress, the first one collecting the calls of the transaction
+	descended bool         // Whether the last opcode descended into a call
+	err       error        // Error, if one has occurred
+
+	ctx *callFrame // Top level call of the transaction
+}
+
+func newNativeCallTracer(psi types.PrivateStateIdentifier) txTracer {
+	return &callTracer{callstack: []*callFrame{{}}}
+}
+
+// CaptureStart implements the Tracer interface to initialize the tracing operation.
+func (t *callTracer) CaptureStart(env *vm.EVM, from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) {
+	t.ctx = &callFrame{
+		Type:  "CALL",
+		From:  from,
+		To:    &to,
+		Input: bytesPtr(input),
+		Gas:   uint64Ptr(gas),
+		State: frameState(env, dualState(env, to), false),
+	}
+	if create {
+		t.ctx.Type = "CREATE"
+		t.ctx.State = frameState(env, env.StateDB, false)
+	}
+	if value != nil {
+		t.ctx.Value = (*hexutil.Big)(new(big.Int).Set(value))
+	}
+	t.callstack[0].State = t.ctx.State
+}
+
+// CaptureState implements the Tracer interface to trace a single step of VM execution.
+func (t *callTracer) CaptureState(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, rData []byte, depth int, err error) {
+	if t.err != nil {
+		return
+	}
+	// If tracing was interrupted, set the error and stop
+	if t.stopped() {
+		t.err = t.reason
+		return
+	}
+	if err != nil {
+		t.fault(err)
+		return
+	}
+	stack, memory, contract := scope.Stack, scope.Memory, scope.Contract
+	top := t.callstack[len(t.callstack)-1]
+
+	switch op {
+	case vm.CREATE, vm.CREATE2:
+		// Assemble the internal call report and store for completion
+		inOff := stack.Back(1).Uint64()
+		call := &callFrame{
+			Type:    op.String(),
+			From:    contract.Address(),
+			Input:   bytesPtr(memorySlice(memory, inOff, inOff+stack.Back(2).Uint64())),
+			Value:   (*hexutil.Big)(stack.Back(0).ToBig()),
+			State:   frameState(env, env.StateDB, top.State == readOnlyFrame),
+			gasIn:   gas,
+			gasCost: cost,
+		}
+		t.callstack = append(t.callstack, call)
+		t.descended = true
+		return
+
+	case vm.SELFDESTRUCT:
+		// Self destructs are reported but don't descend into a call
+		to := common.Address(stack.Back(0).Bytes20())
+		top.Calls = append(top.Calls, &callFrame{
+			Type:  op.String(),
+			From:  contract.Address(),
+			To:    &to,
+			Value: (*hexutil.Big)(new(big.Int).Set(env.StateDB.GetBalance(contract.Address()))),
+			State: top.State,
+		})
+		return
+
+	case vm.CALL, vm.CALLCODE, vm.DELEGATECALL, vm.STATICCALL:
+		// Skip any pre-compile invocations, those are just fancy opcodes
+		to := common.Address(stack.Back(1).Bytes20())
+		if _, ok := vm.PrecompiledContractsIstanbul[to]; ok {
+			return
+		}
+		off := 1
+		if op == vm.DELEGATECALL || op == vm.STATICCALL {
+			off = 0
+		}
+		// Assemble the internal call report and store for completion
+		inOff := stack.Back(2 + off).Uint64()
+		call := &callFrame{
+			Type:    op.String(),
+			From:    contract.Address(),
+			To:      &to,
+			Input:   bytesPtr(memorySlice(memory, inOff, inOff+stack.Back(3+off).Uint64())),
+			State:   frameState(env, dualState(env, to), top.State == readOnlyFrame),
+			gasIn:   gas,
+			gasCost: cost,
+			outOff:  stack.Back(4 + off).Uint64(),
+			outLen:  stack.Back(5 + off).Uint64(),
+		}
+		if off == 1 {
+			call.Value = (*hexutil.Big)(stack.Back(2).ToBig())
+		}
+		t.callstack = append(t.callstack, call)
+		t.descended = true
+		return
+	}
+	// If we've just descended into an inner call, retrieve it's true allowance. We
+	// need to extract if from within the call as there may be funky gas dynamics
+	// with regard to requested and actually given gas (2300 stipend, 63/64 rule).
+	if t.descended {
+		if depth >= len(t.callstack) {
+			top.Gas = uint64Ptr(gas)
+		}
+		t.descended = false
+	}
+	// If an existing call is returning, pop off the call stack
+	if op == vm.REVERT {
+		top.Error = "execution reverted"
+		return
+	}
+	if depth != len(t.callstack)-1 {
+		return
+	}
+	// Pop off the last call and get the execution results
+	call := top
+	t.callstack = t.callstack[:len(t.callstack)-1]
+
+	ret := stack.Back(0)
+	if call.Type == vm.CREATE.String() || call.Type == vm.CREATE2.String() {
+		// If the call was a contract creation, retrieve the new address and code
+		call.GasUsed = uint64Ptr(call.gasIn - call.gasCost - gas)
+		if !ret.IsZero() {
+			to := common.Address(ret.Bytes20())
+			call.To = &to
+			call.Output = bytesPtr(env.StateDB.GetCode(to))
+		} else if call.Error == "" {
+			call.Error = "internal failure"
+		}
+	} else {
+		// If the call was a plain call, retrieve the output and gas used
+		if call.Gas != nil {
+			call.GasUsed = uint64Ptr(call.gasIn - call.gasCost + uint64(*call.Gas) - gas)
+		}
+		if !ret.IsZero() {
+			call.Output = bytesPtr(memorySlice(memory, call.outOff, call.outOff+call.outLen))
+		} else if call.Error == "" {
+			call.Error = "internal failure"
+		}
+	}
+	parent := t.callstack[len(t.callstack)-1]
+	parent.Calls = append(parent.Calls, call)
+}
+
+// CaptureFault implements the Tracer interface to trace an execution fault.
+func (t *callTracer) CaptureFault(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, depth int, err error) {
+	if t.err != nil {
+		return
+	}
+	t.fault(err)
+}
+
+// fault records the error of the current call and closes it, unless the call
+// already failed on a revert.
+func (t *callTracer) fault(err error) {
+	if t.callstack[len(t.callstack)-1].Error != "" {
+		return
+	}
+	// Pop off the just failed call
+	call := t.callstack[len(t.callstack)-1]
+	t.callstack = t.callstack[:len(t.callstack)-1]
+	call.Error = err.Error()
+
+	// Consume all available gas and clean any leftovers
+	if call.Gas != nil {
+		call.GasUsed = call.Gas
+	}
+	// Flatten the failed call into its parent
+	if len(t.callstack) > 0 {
+		parent := t.callstack[len(t.callstack)-1]
+		parent.Calls = append(parent.Calls, call)
+		return
+	}
+	// Last call failed too, leave it in the stack
+	t.callstack = append(t.callstack, call)
+}
+
+// CaptureEnd is called after the call finishes to finalize the tracing.
+func (t *callTracer) CaptureEnd(output []byte, gasUsed uint64, d time.Duration, err error) {
+	t.ctx.Output = bytesPtr(output)
+	t.ctx.GasUsed = uint64Ptr(gasUsed)
+	t.ctx.Time = d.String()
+	if err != nil {
+		t.ctx.Error = err.Error()
+	}
+}
+
+// GetResult returns the call of the transaction with its nested calls, or any
+// error which occurred during the tracing.
+func (t *callTracer) GetResult() (json.RawMessage, error) {
+	if t.err != nil {
+		return nil, t.err
+	}
+	if t.ctx == nil {
+		return nil, errTraceNotStarted
+	}
+	result := *t.ctx
+	result.Calls = t.callstack[0].Calls
+	if t.callstack[0].Error != "" {
+		result.Error = t.callstack[0].Error
+	}
+	if result.Error != "" && (result.Error != "execution reverted" || result.Output == nil || len(*result.Output) == 0) {
+		result.Output = nil
+	}
+	return json.Marshal(&result)
+}
+
+func bytesPtr(b []byte) *hexutil.Bytes {
+	blob := hexutil.Bytes(common.CopyBytes(b))
+	if blob == nil {
+		blob = hexutil.Bytes{}
+	}
+	return &blob
+}
+
+func uint64Ptr(n uint64) *hexutil.Uint64 {
+	v := hexutil.Uint64(n)
+	return &v
+}
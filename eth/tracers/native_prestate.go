@@ -0,0 +1,256 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package tracers
+
+import (
+	"encoding/json"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// prestateAccount is the state of an account before the transaction, with the
+// storage slots the transaction accesses.
+type prestateAccount struct {
+	Balance *hexutil.Big                `json:"balance"`
+	Nonce   uint64                      `json:"nonce"`
+	Code    hexutil.Bytes               `json:"code"`
+	Storage map[common.Hash]common.Hash `json:"storage"`
+}
+
+type prestate map[common.Address]*prestateAccount
+
+// prestateResult is the result of the native prestate tracer for a private
+// transaction: the accounts of the public state, and the accounts of the private
+// state, keyed by the private state identifier, which the transaction accesses.
+// The result for a public transaction is the prestate of the public state only,
+// as reported by the JavaScript tracer.
+type prestateResult struct {
+	Public  prestate                                  `json:"public"`
+	Private map[types.PrivateStateIdentifier]prestate `json:"private,omitempty"`
+}
+
+// prestateTracer is the native implementation of the prestateTracer, which
+// reports the accounts of the public state and of the private state of a private
+// transaction separately.
+type prestateTracer struct {
+	interruption
+
+	psi     types.PrivateStateIdentifier // Private state the transaction is traced in
+	public  prestate
+	private prestate
+	err     error // Error, if one has occurred
+
+	env          *vm.EVM
+	from, to     common.Address
+	created      []common.Address // Contracts created by the transaction and its inner private transactions
+	value        *big.Int
+	intrinsicGas uint64
+	gasUsed      *uint64  // Gas used by the transaction, set once it ended
+	toState      prestate // Section of the prestate holding the recipient
+	depth        int      // Number of transactions being executed, more than one for the inner private transaction of a PMT
+}
+
+func newNativePrestateTracer(psi types.PrivateStateIdentifier) txTracer {
+	return &prestateTracer{
+		psi:     psi,
+		public:  make(prestate),
+		private: make(prestate),
+	}
+}
+
+// CaptureStart implements the Tracer interface to initialize the tracing operation.
+func (t *prestateTracer) CaptureStart(env *vm.EVM, from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) {
+	t.depth++
+	if t.env != nil {
+		t.captureInnerStart(env, from, to, create)
+		return
+	}
+	t.env = env
+	t.from, t.to = from, to
+	if create {
+		t.created = append(t.created, to)
+	}
+	t.value = new(big.Int)
+	if value != nil {
+		t.value.Set(value)
+	}
+	db := env.StateDB
+	if !create {
+		db = dualState(env, to)
+	}
+	t.toState = t.lookupAccount(env, to, db)
+
+	// Compute intrinsic gas
+	isHomestead := env.ChainConfig().IsHomestead(env.Context.BlockNumber)
+	isIstanbul := env.ChainConfig().IsIstanbul(env.Context.BlockNumber)
+	intrinsicGas, err := core.IntrinsicGas(input, nil, create, isHomestead, isIstanbul)
+	if err != nil {
+		return
+	}
+	t.intrinsicGas = intrinsicGas
+}
+
+// Quorum
+// captureInnerStart records the accounts of the inner private transaction of a
+// privacy marker transaction, which is executed by another EVM traced by the same
+// tracer. The privacy marker transaction remains the traced transaction.
+func (t *prestateTracer) captureInnerStart(env *vm.EVM, from common.Address, to common.Address, create bool) {
+	// The sender of the privacy marker transaction is recorded once it ended. The
+	// senders of the private transactions of a batch paid no gas, but their nonce
+	// was incremented.
+	if from != t.from {
+		if section := t.section(env, env.PublicState()); section[from] == nil {
+			if acc := t.lookupAccount(env, from, env.PublicState())[from]; acc.Nonce > 0 {
+				acc.Nonce--
+			}
+		}
+	}
+	if create {
+		t.created = append(t.created, to)
+		return
+	}
+	t.lookupAccount(env, to, dualState(env, to))
+}
+
+// CaptureState implements the Tracer interface to trace a single step of VM execution.
+func (t *prestateTracer) CaptureState(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, rData []byte, depth int, err error) {
+	if t.err != nil {
+		return
+	}
+	// If tracing was interrupted, set the error and stop
+	if t.stopped() {
+		t.err = t.reason
+		return
+	}
+	// The opcode failed before accessing any account
+	if err != nil {
+		return
+	}
+	stack, contract := scope.Stack, scope.Contract
+	switch op {
+	case vm.EXTCODECOPY, vm.EXTCODESIZE, vm.BALANCE:
+		addr := common.Address(stack.Back(0).Bytes20())
+		t.lookupAccount(env, addr, dualState(env, addr))
+
+	case vm.CREATE:
+		// Quorum: contracts derive the nonce of their creations from the private state
+		nonce := env.PrivateState().GetNonce(contract.Address())
+		t.lookupAccount(env, crypto.CreateAddress(contract.Address(), nonce), env.StateDB)
+
+	case vm.CREATE2:
+		offset, size := stack.Back(1).Uint64(), stack.Back(2).Uint64()
+		code := memorySlice(scope.Memory, offset, offset+size)
+		addr := crypto.CreateAddress2(contract.Address(), stack.Back(3).Bytes32(), crypto.Keccak256(code))
+		t.lookupAccount(env, addr, env.StateDB)
+
+	case vm.CALL, vm.CALLCODE, vm.DELEGATECALL, vm.STATICCALL:
+		addr := common.Address(stack.Back(1).Bytes20())
+		t.lookupAccount(env, addr, dualState(env, addr))
+
+	case vm.SSTORE, vm.SLOAD:
+		addr := contract.Address()
+		t.lookupStorage(env, addr, common.Hash(stack.Back(0).Bytes32()), dualState(env, addr))
+	}
+}
+
+// CaptureFault implements the Tracer interface to trace an execution fault.
+func (t *prestateTracer) CaptureFault(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, depth int, err error) {
+}
+
+// CaptureEnd is called after the call finishes to finalize the tracing.
+func (t *prestateTracer) CaptureEnd(output []byte, gasUsed uint64, d time.Duration, err error) {
+	if t.depth--; t.depth == 0 {
+		t.gasUsed = &gasUsed
+	}
+}
+
+// GetResult returns the accounts the transaction accesses as they were before the
+// transaction, or any error which occurred during the tracing.
+func (t *prestateTracer) GetResult() (json.RawMessage, error) {
+	if t.err != nil {
+		return nil, t.err
+	}
+	if t.env == nil || t.gasUsed == nil {
+		return nil, errTraceNotStarted
+	}
+	// The sender paid for the transaction, which is accounted in the public state
+	fromAcc := t.lookupAccount(t.env, t.from, t.env.PublicState())[t.from]
+	if toAcc, ok := t.toState[t.to]; ok {
+		toAcc.Balance = (*hexutil.Big)(new(big.Int).Sub(toAcc.Balance.ToInt(), t.value))
+	}
+	fee := new(big.Int)
+	if gasPrice := t.env.TxContext.GasPrice; gasPrice != nil {
+		fee.Mul(new(big.Int).SetUint64(*t.gasUsed+t.intrinsicGas), gasPrice)
+	}
+	balance := new(big.Int).Add(fromAcc.Balance.ToInt(), t.value)
+	fromAcc.Balance = (*hexutil.Big)(balance.Add(balance, fee))
+	if fromAcc.Nonce > 0 {
+		fromAcc.Nonce--
+	}
+	for _, addr := range t.created {
+		delete(t.public, addr)
+		delete(t.private, addr)
+	}
+	if t.env.PrivateState() == t.env.PublicState() {
+		return json.Marshal(t.public)
+	}
+	result := &prestateResult{Public: t.public}
+	if len(t.private) > 0 {
+		result.Private = map[types.PrivateStateIdentifier]prestate{t.psi: t.private}
+	}
+	return json.Marshal(result)
+}
+
+// section returns the section of the prestate holding the accounts of the state
+// of the EVM.
+func (t *prestateTracer) section(env *vm.EVM, db vm.StateDB) prestate {
+	if db == env.PublicState() || env.PrivateState() == env.PublicState() {
+		return t.public
+	}
+	return t.private
+}
+
+// lookupAccount records the account of the state, if not recorded yet, and returns
+// the section of the prestate holding it.
+func (t *prestateTracer) lookupAccount(env *vm.EVM, addr common.Address, db vm.StateDB) prestate {
+	section := t.section(env, db)
+	if _, ok := section[addr]; !ok {
+		section[addr] = &prestateAccount{
+			Balance: (*hexutil.Big)(new(big.Int).Set(db.GetBalance(addr))),
+			Nonce:   db.GetNonce(addr),
+			Code:    common.CopyBytes(db.GetCode(addr)),
+			Storage: make(map[common.Hash]common.Hash),
+		}
+	}
+	return section
+}
+
+// lookupStorage records the storage slot of the account of the state, if not
+// recorded yet.
+func (t *prestateTracer) lookupStorage(env *vm.EVM, addr common.Address, key common.Hash, db vm.StateDB) {
+	acc := t.lookupAccount(env, addr, db)[addr]
+	if _, ok := acc.Storage[key]; !ok {
+		acc.Storage[key] = db.GetState(addr, key)
+	}
+}
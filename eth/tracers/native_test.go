@@ -0,0 +1,257 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package tracers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/tests"
+)
+
+var (
+	testPSI            = types.PrivateStateIdentifier("psi1")
+	testOrigin         = common.HexToAddress("0x1000000000000000000000000000000000000001")
+	testPrivateAddress = common.HexToAddress("0x2000000000000000000000000000000000000002")
+	testPublicAddress  = common.HexToAddress("0x3000000000000000000000000000000000000003")
+)
+
+// runPrivateCallToPublicContract traces a call to a private contract which reads
+// the storage of a public contract, and returns the result of the tracer.
+func runPrivateCallToPublicContract(t *testing.T, tracer txTracer) json.RawMessage {
+	// The public contract returns its storage slot 0
+	_, publicState := tests.MakePreState(rawdb.NewMemoryDatabase(), core.GenesisAlloc{
+		testOrigin: {Balance: big.NewInt(1000000)},
+		testPublicAddress: {
+			Code:    hexutil.MustDecode("0x60005460005260206000f3"),
+			Storage: map[common.Hash]common.Hash{{}: common.BigToHash(big.NewInt(42))},
+			Balance: new(big.Int),
+		},
+	}, false)
+	// The private contract calls the public contract and returns its output
+	_, privateState := tests.MakePreState(rawdb.NewMemoryDatabase(), core.GenesisAlloc{
+		testPrivateAddress: {
+			Code:    hexutil.MustDecode("0x6020600060006000600073" + testPublicAddress.Hex()[2:] + "5af160206000f3"),
+			Balance: new(big.Int),
+		},
+	}, false)
+
+	txContext := vm.TxContext{Origin: testOrigin, GasPrice: new(big.Int)}
+	context := vm.BlockContext{
+		CanTransfer: core.CanTransfer,
+		Transfer:    core.Transfer,
+		BlockNumber: big.NewInt(1),
+		Time:        big.NewInt(5),
+		Difficulty:  big.NewInt(0x30000),
+		GasLimit:    uint64(6000000),
+	}
+	evm := vm.NewEVM(context, txContext, publicState, privateState, params.QuorumTestChainConfig, vm.Config{Debug: true, Tracer: tracer})
+	if _, _, err := evm.Call(vm.AccountRef(testOrigin), testPrivateAddress, nil, 100000, new(big.Int)); err != nil {
+		t.Fatalf("failed to execute call: %v", err)
+	}
+	res, err := tracer.GetResult()
+	if err != nil {
+		t.Fatalf("failed to retrieve trace result: %v", err)
+	}
+	return res
+}
+
+func TestNativeCallTracer_whenPrivateContractReadsPublicContract(t *testing.T) {
+	res := runPrivateCallToPublicContract(t, newNativeCallTracer(testPSI))
+
+	ret := new(callFrame)
+	if err := json.Unmarshal(res, ret); err != nil {
+		t.Fatalf("failed to unmarshal trace result: %v", err)
+	}
+	if ret.State != privateFrame {
+		t.Errorf("unexpected state of the transaction: have %q, want %q", ret.State, privateFrame)
+	}
+	if len(ret.Calls) != 1 {
+		t.Fatalf("unexpected number of calls: have %d, want 1", len(ret.Calls))
+	}
+	call := ret.Calls[0]
+	if call.To == nil || *call.To != testPublicAddress {
+		t.Errorf("unexpected recipient of the call: have %v, want %v", call.To, testPublicAddress)
+	}
+	if call.State != readOnlyFrame {
+		t.Errorf("unexpected state of the call: have %q, want %q", call.State, readOnlyFrame)
+	}
+	want := hexutil.Bytes(common.BigToHash(big.NewInt(42)).Bytes())
+	if call.Output == nil || !bytes.Equal(*call.Output, want) {
+		t.Errorf("unexpected output of the call: have %v, want %v", call.Output, want)
+	}
+}
+
+func TestNativePrestateTracer_whenPrivateContractReadsPublicContract(t *testing.T) {
+	res := runPrivateCallToPublicContract(t, newNativePrestateTracer(testPSI))
+
+	ret := new(prestateResult)
+	if err := json.Unmarshal(res, ret); err != nil {
+		t.Fatalf("failed to unmarshal trace result: %v", err)
+	}
+	public, ok := ret.Public[testPublicAddress]
+	if !ok {
+		t.Fatalf("expected public contract %v in the public state", testPublicAddress)
+	}
+	if have, want := public.Storage[common.Hash{}], common.BigToHash(big.NewInt(42)); have != want {
+		t.Errorf("unexpected storage of the public contract: have %v, want %v", have, want)
+	}
+	if _, ok := ret.Public[testOrigin]; !ok {
+		t.Errorf("expected sender %v in the public state", testOrigin)
+	}
+	if _, ok := ret.Public[testPrivateAddress]; ok {
+		t.Errorf("unexpected private contract %v in the public state", testPrivateAddress)
+	}
+	if _, ok := ret.Private[testPSI][testPrivateAddress]; !ok {
+		t.Errorf("expected private contract %v in the private state %s", testPrivateAddress, testPSI)
+	}
+	if _, ok := ret.Private[testPSI][testPublicAddress]; ok {
+		t.Errorf("unexpected public contract %v in the private state %s", testPublicAddress, testPSI)
+	}
+}
+
+func TestNativeCallTracer_whenPublicTransaction(t *testing.T) {
+	_, statedb := tests.MakePreState(rawdb.NewMemoryDatabase(), core.GenesisAlloc{
+		testOrigin: {Balance: big.NewInt(1000000)},
+		testPublicAddress: {
+			Code:    hexutil.MustDecode("0x60005460005260206000f3"),
+			Balance: new(big.Int),
+		},
+	}, false)
+	tracer := newNativeCallTracer(types.DefaultPrivateStateIdentifier)
+	context := vm.BlockContext{CanTransfer: core.CanTransfer, Transfer: core.Transfer, BlockNumber: big.NewInt(1)}
+	evm := vm.NewEVM(context, vm.TxContext{Origin: testOrigin}, statedb, statedb, params.QuorumTestChainConfig, vm.Config{Debug: true, Tracer: tracer})
+	if _, _, err := evm.Call(vm.AccountRef(testOrigin), testPublicAddress, nil, 100000, new(big.Int)); err != nil {
+		t.Fatalf("failed to execute call: %v", err)
+	}
+	res, err := tracer.GetResult()
+	if err != nil {
+		t.Fatalf("failed to retrieve trace result: %v", err)
+	}
+	ret := new(callFrame)
+	if err := json.Unmarshal(res, ret); err != nil {
+		t.Fatalf("failed to unmarshal trace result: %v", err)
+	}
+	if ret.State != publicFrame {
+		t.Errorf("unexpected state of the transaction: have %q, want %q", ret.State, publicFrame)
+	}
+}
+
+func TestNativePrestateTracer_whenPublicTransaction(t *testing.T) {
+	_, statedb := tests.MakePreState(rawdb.NewMemoryDatabase(), core.GenesisAlloc{
+		testOrigin: {Balance: big.NewInt(1000000)},
+		testPublicAddress: {
+			Code:    hexutil.MustDecode("0x60005460005260206000f3"),
+			Balance: new(big.Int),
+		},
+	}, false)
+	tracer := newNativePrestateTracer(types.DefaultPrivateStateIdentifier)
+	context := vm.BlockContext{CanTransfer: core.CanTransfer, Transfer: core.Transfer, BlockNumber: big.NewInt(1)}
+	evm := vm.NewEVM(context, vm.TxContext{Origin: testOrigin}, statedb, statedb, params.QuorumTestChainConfig, vm.Config{Debug: true, Tracer: tracer})
+	if _, _, err := evm.Call(vm.AccountRef(testOrigin), testPublicAddress, nil, 100000, new(big.Int)); err != nil {
+		t.Fatalf("failed to execute call: %v", err)
+	}
+	res, err := tracer.GetResult()
+	if err != nil {
+		t.Fatalf("failed to retrieve trace result: %v", err)
+	}
+	// the prestate of a public transaction has the shape of the JavaScript tracer's
+	ret := make(prestate)
+	if err := json.Unmarshal(res, &ret); err != nil {
+		t.Fatalf("failed to unmarshal trace result: %v", err)
+	}
+	for _, addr := range []common.Address{testOrigin, testPublicAddress} {
+		if _, ok := ret[addr]; !ok {
+			t.Errorf("expected account %v in the prestate", addr)
+		}
+	}
+}
+
+func TestNativePrestateTracer_whenPrivacyMarkerTransaction(t *testing.T) {
+	_, publicState := tests.MakePreState(rawdb.NewMemoryDatabase(), core.GenesisAlloc{
+		testOrigin: {Balance: big.NewInt(1000000)},
+	}, false)
+	_, privateState := tests.MakePreState(rawdb.NewMemoryDatabase(), core.GenesisAlloc{}, false)
+	tracer := newNativePrestateTracer(testPSI)
+	context := vm.BlockContext{CanTransfer: core.CanTransfer, Transfer: core.Transfer, BlockNumber: big.NewInt(1)}
+	config := vm.Config{Debug: true, Tracer: tracer}
+
+	// The PMT is traced while its inner private transaction, a contract creation, is
+	// executed by another EVM with the same tracer
+	pmt := vm.NewEVM(context, vm.TxContext{Origin: testOrigin, GasPrice: big.NewInt(1)}, publicState, privateState, params.QuorumTestChainConfig, config)
+	tracer.CaptureStart(pmt, testOrigin, common.QuorumPrivacyPrecompileContractAddress(), false, nil, 100000, new(big.Int))
+	inner := vm.NewEVM(context, vm.TxContext{Origin: testOrigin}, publicState, privateState, params.QuorumTestChainConfig, config)
+	_, created, _, err := inner.Create(vm.AccountRef(testOrigin), hexutil.MustDecode("0x600160005500"), 100000, new(big.Int))
+	if err != nil {
+		t.Fatalf("failed to execute the inner private transaction: %v", err)
+	}
+	tracer.CaptureEnd(nil, 0, 0, nil)
+
+	res, err := tracer.GetResult()
+	if err != nil {
+		t.Fatalf("failed to retrieve trace result: %v", err)
+	}
+	ret := new(prestateResult)
+	if err := json.Unmarshal(res, ret); err != nil {
+		t.Fatalf("failed to unmarshal trace result: %v", err)
+	}
+	// the sender paid the intrinsic gas of the PMT, not of the inner contract creation
+	sender, ok := ret.Public[testOrigin]
+	if !ok {
+		t.Fatalf("expected sender %v in the public state", testOrigin)
+	}
+	if have, want := sender.Balance.ToInt(), big.NewInt(1000000+int64(params.TxGas)); have.Cmp(want) != 0 {
+		t.Errorf("unexpected balance of the sender: have %v, want %v", have, want)
+	}
+	if _, ok := ret.Private[testPSI][common.QuorumPrivacyPrecompileContractAddress()]; !ok {
+		t.Errorf("expected the privacy precompile in the private state %s", testPSI)
+	}
+	if _, ok := ret.Private[testPSI][created]; ok {
+		t.Errorf("unexpected created contract %v in the private state %s", created, testPSI)
+	}
+}
+
+func TestNativeTracer_whenStopped(t *testing.T) {
+	_, statedb := tests.MakePreState(rawdb.NewMemoryDatabase(), core.GenesisAlloc{
+		testPublicAddress: {
+			Code:    hexutil.MustDecode("0x60005460005260206000f3"),
+			Balance: new(big.Int),
+		},
+	}, false)
+	for name := range nativeTracers {
+		tracer, _ := newNativeTracer(name, types.DefaultPrivateStateIdentifier)
+		tracer.Stop(errors.New("stop"))
+
+		context := vm.BlockContext{CanTransfer: core.CanTransfer, Transfer: core.Transfer, BlockNumber: big.NewInt(1)}
+		evm := vm.NewEVM(context, vm.TxContext{Origin: testOrigin}, statedb, statedb, params.QuorumTestChainConfig, vm.Config{Debug: true, Tracer: tracer})
+		evm.Call(vm.AccountRef(testOrigin), testPublicAddress, nil, 100000, new(big.Int))
+
+		if _, err := tracer.GetResult(); err == nil || err.Error() != "stop" {
+			t.Errorf("%s: expected the stop error, have %v", name, err)
+		}
+	}
+}
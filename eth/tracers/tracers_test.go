@@ -204,6 +204,21 @@ func TestPrestateTracerCreate2(t *testing.T) {
 // Iterates over all the input-output datasets in the tracer test harness and
 // runs the JavaScript tracers against them.
 func TestCallTracer(t *testing.T) {
+	testCallTracer(t, func(txContext vm.TxContext) (txTracer, error) {
+		return New("callTracer", txContext)
+	})
+}
+
+// Iterates over all the input-output datasets in the tracer test harness and
+// runs the native tracers against them.
+func TestNativeCallTracer(t *testing.T) {
+	testCallTracer(t, func(txContext vm.TxContext) (txTracer, error) {
+		tracer, _ := newNativeTracer("callTracer", types.DefaultPrivateStateIdentifier)
+		return tracer, nil
+	})
+}
+
+func testCallTracer(t *testing.T, newTracer func(txContext vm.TxContext) (txTracer, error)) {
 	files, err := os.ReadDir("testdata")
 	if err != nil {
 		t.Fatalf("failed to retrieve tracer test suite: %v", err)
@@ -248,7 +263,7 @@ func TestCallTracer(t *testing.T) {
 			_, statedb := tests.MakePreState(rawdb.NewMemoryDatabase(), test.Genesis.Alloc, false)
 
 			// Create the tracer, the EVM environment and run it
-			tracer, err := New("callTracer", txContext)
+			tracer, err := newTracer(txContext)
 			if err != nil {
 				t.Fatalf("failed to create call tracer: %v", err)
 			}
@@ -12,6 +12,7 @@ import (
 	"github.com/ethereum/go-ethereum/internal/ethapi"
 	"github.com/ethereum/go-ethereum/multitenancy"
 	"github.com/ethereum/go-ethereum/permission/core"
+	"github.com/ethereum/go-ethereum/rpc"
 )
 
 var (
@@ -50,10 +51,11 @@ func (api *PrivateExtensionAPI) ActiveExtensionContracts(ctx context.Context) []
 	return extracted
 }
 
-// checks of the passed contract address is under extension process
+// checks of the passed contract address is under extension process, either as the
+// extended contract or as one of its dependencies
 func (api *PrivateExtensionAPI) checkIfContractUnderExtension(ctx context.Context, toExtend common.Address) bool {
 	for _, v := range api.ActiveExtensionContracts(ctx) {
-		if v.ContractExtended == toExtend {
+		if v.ContractExtended == toExtend || checkAddressInList(toExtend, v.Dependencies) {
 			return true
 		}
 	}
@@ -260,6 +262,25 @@ func (api *PrivateExtensionAPI) ApproveExtension(ctx context.Context, addressToV
 // - the new PTM public key
 // - the Ethereum addresses of who can vote to extend the contract
 func (api *PrivateExtensionAPI) ExtendContract(ctx context.Context, toExtend common.Address, newRecipientPtmPublicKey string, recipientAddr common.Address, txa ethapi.SendTxArgs) (string, error) {
+	return api.extendContract(ctx, toExtend, false, nil, newRecipientPtmPublicKey, recipientAddr, txa)
+}
+
+// ExtendContractWithDependencies extends a contract to a new participant together with the private contracts it
+// depends on, e.g. the implementation of a proxy or its linked libraries. They are extended in the same voting round
+// as the contract, their states being shared in a single payload so that the new participant receives all of them
+// or none.
+//
+// Unless given, the dependencies are discovered heuristically: they are the private contracts whose address is
+// pushed by the code of the contract (the operand of a PUSH20 instruction) or held by one of its storage slots
+// (a value whose 12 upper bytes are zero), directly or through other dependencies. This finds linked libraries and
+// the contracts stored by proxies and registries, but may miss addresses which are computed or packed with other
+// values in a slot, and may include contracts which merely look referenced. The dependencies can be given
+// explicitly instead, in which case they are extended as they are.
+func (api *PrivateExtensionAPI) ExtendContractWithDependencies(ctx context.Context, toExtend common.Address, newRecipientPtmPublicKey string, recipientAddr common.Address, txa ethapi.SendTxArgs, dependencies *[]common.Address) (string, error) {
+	return api.extendContract(ctx, toExtend, true, dependencies, newRecipientPtmPublicKey, recipientAddr, txa)
+}
+
+func (api *PrivateExtensionAPI) extendContract(ctx context.Context, toExtend common.Address, withDependencies bool, givenDependencies *[]common.Address, newRecipientPtmPublicKey string, recipientAddr common.Address, txa ethapi.SendTxArgs) (string, error) {
 	// check if the contract to be extended is already under extension
 	// if yes throw an error
 	if api.checkIfContractUnderExtension(ctx, toExtend) {
@@ -334,10 +355,27 @@ func (api *PrivateExtensionAPI) ExtendContract(ctx context.Context, toExtend com
 		return "", errors.New("invalid transaction manager keys given in privateFor argument")
 	}
 
-	// get all participants for the contract being extended
-	participants, err := api.privacyService.GetAllParticipants(api.privacyService.stateFetcher.getCurrentBlockHash(), toExtend, psm.ID)
-	if err == nil {
-		txa.PrivateFor = append(txa.PrivateFor, participants...)
+	var dependencies []common.Address
+	if withDependencies {
+		if givenDependencies != nil {
+			dependencies, err = api.givenDependencies(toExtend, *givenDependencies, psm.ID)
+		} else {
+			dependencies, err = api.privacyService.stateFetcher.GetDependencies(api.privacyService.stateFetcher.getCurrentBlockHash(), toExtend, psm.ID)
+		}
+		if err != nil {
+			return "", err
+		}
+		if err := api.checkDependencies(ctx, toExtend, dependencies, psm.ID); err != nil {
+			return "", err
+		}
+	}
+
+	// get all participants for the contracts being extended
+	for _, address := range append([]common.Address{toExtend}, dependencies...) {
+		participants, err := api.privacyService.GetAllParticipants(api.privacyService.stateFetcher.getCurrentBlockHash(), address, psm.ID)
+		if err == nil {
+			txa.PrivateFor = common.AppendSkipDuplicates(txa.PrivateFor, participants...)
+		}
 	}
 
 	//generate some valid transaction options for sending in the transaction
@@ -346,11 +384,29 @@ func (api *PrivateExtensionAPI) ExtendContract(ctx context.Context, toExtend com
 		return "", err
 	}
 
+	// the dependencies are persisted before the management contract is deployed, so that they are
+	// known when its creation is seen even if the node restarts in between
+	var pending *ExtensionDependencies
+	if len(dependencies) > 0 {
+		pending = &ExtensionDependencies{
+			ContractExtended: toExtend,
+			Recipient:        recipientAddr,
+			RecipientPtmKey:  newRecipientPtmPublicKey,
+			Dependencies:     dependencies,
+		}
+		if err := api.privacyService.recordDependencies(psm.ID, pending); err != nil {
+			return "", fmt.Errorf("failed to record the dependencies of the extended contract: %v", err)
+		}
+	}
+
 	psiManagementContractClient := api.privacyService.managementContract(psm.ID)
 	defer psiManagementContractClient.Close()
 	//Deploy the contract
-	tx, err := psiManagementContractClient.Deploy(txArgs, toExtend, recipientAddr, newRecipientPtmPublicKey)
+	_, tx, err := psiManagementContractClient.Deploy(txArgs, toExtend, recipientAddr, newRecipientPtmPublicKey)
 	if err != nil {
+		if pending != nil {
+			api.privacyService.forgetDependencies(psm.ID, pending)
+		}
 		return "", err
 	}
 
@@ -359,6 +415,59 @@ func (api *PrivateExtensionAPI) ExtendContract(ctx context.Context, toExtend com
 	return msg, nil
 }
 
+// returns the dependencies given explicitly for a contract, without duplicates, after checking that they are
+// private contracts
+func (api *PrivateExtensionAPI) givenDependencies(toExtend common.Address, given []common.Address, psi types.PrivateStateIdentifier) ([]common.Address, error) {
+	var dependencies []common.Address
+	for _, dependency := range given {
+		if dependency == toExtend || checkAddressInList(dependency, dependencies) {
+			continue
+		}
+		isPublic, err := api.checkIfPublicContract(dependency)
+		if err != nil {
+			return nil, err
+		}
+		isPrivate, err := api.checkIfPrivateStateExists(psi, dependency)
+		if err != nil {
+			return nil, err
+		}
+		if isPublic || !isPrivate {
+			return nil, fmt.Errorf("dependency %s is not a private contract", dependency.Hex())
+		}
+		dependencies = append(dependencies, dependency)
+	}
+	if len(dependencies) > maxExtensionDependencies {
+		return nil, fmt.Errorf("more than %d dependencies given", maxExtensionDependencies)
+	}
+	return dependencies, nil
+}
+
+// checks that the dependencies of a contract can be extended together with it: they must not be under extension,
+// must have been created by this node and must have the same privacy flag as the contract
+func (api *PrivateExtensionAPI) checkDependencies(ctx context.Context, toExtend common.Address, dependencies []common.Address, psi types.PrivateStateIdentifier) error {
+	blockHash := api.privacyService.stateFetcher.getCurrentBlockHash()
+	privacyMetaData, err := api.privacyService.stateFetcher.GetPrivacyMetaData(blockHash, toExtend, psi)
+	if err != nil && !errors.Is(err, common.ErrNoAccountExtraData) {
+		return err
+	}
+	for _, dependency := range dependencies {
+		if api.checkIfContractUnderExtension(ctx, dependency) {
+			return fmt.Errorf("contract extension in progress for the dependency %s", dependency.Hex())
+		}
+		if !api.privacyService.CheckIfContractCreator(blockHash, dependency, psi) {
+			return fmt.Errorf("operation not allowed for the dependency %s", dependency.Hex())
+		}
+		dependencyMetaData, dependencyErr := api.privacyService.stateFetcher.GetPrivacyMetaData(blockHash, dependency, psi)
+		if dependencyErr != nil && !errors.Is(dependencyErr, common.ErrNoAccountExtraData) {
+			return dependencyErr
+		}
+		if privacyFlagOf(dependencyMetaData) != privacyFlagOf(privacyMetaData) {
+			return fmt.Errorf("dependency %s has a different privacy flag than the extended contract", dependency.Hex())
+		}
+	}
+	return nil
+}
+
 // CancelExtension allows the creator to cancel the given extension contract, ensuring
 // that no more calls for votes or accepting can be made
 func (api *PrivateExtensionAPI) CancelExtension(ctx context.Context, extensionContract common.Address, txa ethapi.SendTxArgs) (string, error) {
@@ -434,3 +543,91 @@ func (api *PrivateExtensionAPI) GetExtensionStatus(ctx context.Context, extensio
 
 	return extensionInProgress, nil
 }
+
+// PreviewExtension returns the state shared with the recipient of the extension: the code and storage of the
+// extended contract and of its dependencies, with their sizes. Once the initiator has shared the state, it is
+// read from the shared payload of the private transaction manager, which is what the recipient sets in its
+// private state. Before that, it is the local state at the current block, only available on the nodes of the
+// parties of the extended contract, and the dependencies are only included on the node of the initiator.
+func (api *PrivateExtensionAPI) PreviewExtension(ctx context.Context, extensionContract common.Address) (*ExtensionPreview, error) {
+	psm, err := api.privacyService.apiBackendHelper.PSMR().ResolveForUserContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	psiManagementContractClient := api.privacyService.managementContract(psm.ID)
+	defer psiManagementContractClient.Close()
+	caller, err := psiManagementContractClient.Caller(extensionContract)
+	if err != nil {
+		return nil, err
+	}
+	contractToExtend, err := caller.ContractToExtend(nil)
+	if err != nil {
+		return nil, err
+	}
+	if contractToExtend == (common.Address{}) {
+		return nil, fmt.Errorf("%s is not an extension management contract", extensionContract.Hex())
+	}
+
+	sharedDataHash, err := caller.SharedDataHash(nil)
+	if err != nil {
+		return nil, err
+	}
+	var preview *ExtensionPreview
+	if sharedDataHash != "" {
+		preview, err = api.privacyService.sharedExtensionPreview(sharedDataHash, contractToExtend)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		addresses := []common.Address{contractToExtend}
+		api.privacyService.mu.Lock()
+		if extension, ok := api.privacyService.psiContracts[psm.ID][extensionContract]; ok {
+			addresses = append(addresses, extension.Dependencies...)
+		}
+		api.privacyService.mu.Unlock()
+
+		preview, err = api.privacyService.stateFetcher.GetExtensionPreview(api.privacyService.stateFetcher.getCurrentBlockHash(), addresses, psm.ID)
+		if err != nil {
+			return nil, fmt.Errorf("state of contract %s not available: %v", contractToExtend.Hex(), err)
+		}
+	}
+	preview.ManagementContractAddress = extensionContract
+	return preview, nil
+}
+
+// ExtensionEvents creates a subscription that fires for each lifecycle event of the extensions in
+// the private state of the caller: created, voted, cancelled, completed and stateApplied.
+func (api *PrivateExtensionAPI) ExtensionEvents(ctx context.Context) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+	psm, err := api.privacyService.apiBackendHelper.PSMR().ResolveForUserContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rpcSub := notifier.CreateSubscription()
+
+	go func() {
+		events := make(chan ExtensionEvent)
+		eventsSub := api.privacyService.subscribeExtensionEvents(events)
+
+		for {
+			select {
+			case ev := <-events:
+				if ev.psi == psm.ID {
+					notifier.Notify(rpcSub.ID, ev)
+				}
+			case <-rpcSub.Err():
+				eventsSub.Unsubscribe()
+				return
+			case <-notifier.Closed():
+				eventsSub.Unsubscribe()
+				return
+			}
+		}
+	}()
+
+	return rpcSub, nil
+}
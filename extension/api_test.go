@@ -0,0 +1,113 @@
+package extension
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/mps"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/golang/mock/gomock"
+	"github.com/jpmorganchase/quorum-security-plugin-sdk-go/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubAPIBackendHelper struct {
+	psmr mps.PrivateStateMetadataResolver
+}
+
+func (b *stubAPIBackendHelper) AccountExtraDataStateGetterByNumber(context.Context, rpc.BlockNumber) (vm.AccountExtraDataStateGetter, error) {
+	panic("not implemented")
+}
+
+func (b *stubAPIBackendHelper) PSMR() mps.PrivateStateMetadataResolver { return b.psmr }
+
+func (b *stubAPIBackendHelper) CurrentBlock() *types.Block { panic("not implemented") }
+
+func (b *stubAPIBackendHelper) SupportsMultitenancy(context.Context) (*proto.PreAuthenticatedAuthenticationToken, bool) {
+	return nil, false
+}
+
+func (b *stubAPIBackendHelper) IsPrivacyMarkerTransactionCreationEnabled() bool { return false }
+
+func newExtensionEventsTestService(t *testing.T) *PrivacyService {
+	mockCtrl := gomock.NewController(t)
+	t.Cleanup(mockCtrl.Finish)
+	psmr := mps.NewMockPrivateStateMetadataResolver(mockCtrl)
+	psmr.EXPECT().ResolveForUserContext(gomock.Any()).Return(mps.DefaultPrivateStateMetadata, nil).AnyTimes()
+	return &PrivacyService{apiBackendHelper: &stubAPIBackendHelper{psmr: psmr}}
+}
+
+// sendExtensionEvent sends the event once the subscription of the API is set up
+func sendExtensionEvent(t *testing.T, service *PrivacyService, ev ExtensionEvent) {
+	for deadline := time.Now().Add(5 * time.Second); service.extensionFeed.Send(ev) == 0; time.Sleep(10 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatal("the extension events subscription has not been set up")
+		}
+	}
+}
+
+func subscribeExtensionEvents(t *testing.T, server *rpc.Server) (chan ExtensionEvent, *rpc.ClientSubscription) {
+	client := rpc.DialInProc(server)
+	t.Cleanup(client.Close)
+	events := make(chan ExtensionEvent)
+	sub, err := client.Subscribe(context.Background(), "quorumExtension", events, "extensionEvents")
+	require.NoError(t, err)
+	t.Cleanup(sub.Unsubscribe)
+	return events, sub
+}
+
+func receiveExtensionEvent(t *testing.T, events chan ExtensionEvent, sub *rpc.ClientSubscription) ExtensionEvent {
+	select {
+	case ev := <-events:
+		return ev
+	case err := <-sub.Err():
+		t.Fatalf("subscription failed: %v", err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("no extension event received")
+	}
+	return ExtensionEvent{}
+}
+
+func TestExtensionEvents_whenEventsOfSeveralPrivateStates(t *testing.T) {
+	service := newExtensionEventsTestService(t)
+	server := rpc.NewServer()
+	t.Cleanup(server.Stop)
+	require.NoError(t, server.RegisterName("quorumExtension", NewPrivateExtensionAPI(service)))
+	events, sub := subscribeExtensionEvents(t, server)
+
+	other := ExtensionEvent{Type: ExtensionCreated, ManagementContractAddress: common.HexToAddress("0x1"), psi: "other"}
+	own := ExtensionEvent{Type: ExtensionVoted, ManagementContractAddress: common.HexToAddress("0x2"), psi: types.DefaultPrivateStateIdentifier}
+	sendExtensionEvent(t, service, other)
+	sendExtensionEvent(t, service, own)
+
+	ev := receiveExtensionEvent(t, events, sub)
+	assert.Equal(t, ExtensionVoted, ev.Type, "expected the events of the private state of the caller only")
+	assert.Equal(t, own.ManagementContractAddress, ev.ManagementContractAddress)
+}
+
+func TestExtensionEvents_whenQlightProxy(t *testing.T) {
+	serverService := newExtensionEventsTestService(t)
+	server := rpc.NewServer()
+	t.Cleanup(server.Stop)
+	require.NoError(t, server.RegisterName("quorumExtension", NewPrivateExtensionAPI(serverService)))
+	proxyClient := rpc.DialInProc(server)
+	t.Cleanup(proxyClient.Close)
+
+	lightService := newExtensionEventsTestService(t)
+	lightServer := rpc.NewServer()
+	t.Cleanup(lightServer.Stop)
+	require.NoError(t, lightServer.RegisterName("quorumExtension", &PrivateExtensionProxyAPI{PrivateExtensionAPI{lightService}, proxyClient}))
+	events, sub := subscribeExtensionEvents(t, lightServer)
+
+	completed := ExtensionEvent{Type: ExtensionCompleted, ManagementContractAddress: common.HexToAddress("0x3"), psi: types.DefaultPrivateStateIdentifier}
+	sendExtensionEvent(t, serverService, completed)
+
+	ev := receiveExtensionEvent(t, events, sub)
+	assert.Equal(t, ExtensionCompleted, ev.Type, "expected the events of the server node")
+	assert.Equal(t, completed.ManagementContractAddress, ev.ManagementContractAddress)
+}
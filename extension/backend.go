@@ -15,6 +15,7 @@ import (
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/extension/extensionContracts"
+	"github.com/ethereum/go-ethereum/extension/privacyExtension"
 	"github.com/ethereum/go-ethereum/internal/ethapi"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/node"
@@ -30,10 +31,14 @@ type PrivacyService struct {
 	accountManager   *accounts.Manager
 	dataHandler      DataHandler
 	stopFeed         event.Feed
+	extensionFeed    event.Feed // feed of the lifecycle events of the extensions
 	apiBackendHelper APIBackendHelper
 
 	mu           sync.Mutex
 	psiContracts map[types.PrivateStateIdentifier]map[common.Address]*ExtensionContract
+	// dependencies of the extensions initiated by this node, until the creation of their
+	// management contract is seen
+	psiDependencies map[types.PrivateStateIdentifier][]*ExtensionDependencies
 
 	node   *node.Node
 	config *params.ChainConfig
@@ -78,6 +83,26 @@ func (service *PrivacyService) subscribeStopEvent() (chan stopEvent, event.Subsc
 	return c, s
 }
 
+// subscribeExtensionEvents subscribes to the lifecycle events of the extensions
+// in all the private states
+func (service *PrivacyService) subscribeExtensionEvents(c chan<- ExtensionEvent) event.Subscription {
+	return service.extensionFeed.Subscribe(c)
+}
+
+// extensionEventOf returns the lifecycle event of the extension managed by the
+// contract which emitted the log
+func extensionEventOf(psi types.PrivateStateIdentifier, eventType ExtensionEventType, l types.Log, contractExtended common.Address) ExtensionEvent {
+	return ExtensionEvent{
+		Type:                      eventType,
+		ManagementContractAddress: l.Address,
+		ContractExtended:          contractExtended,
+		BlockNumber:               l.BlockNumber,
+		BlockHash:                 l.BlockHash,
+		TxHash:                    l.TxHash,
+		psi:                       psi,
+	}
+}
+
 func New(stack *node.Node, ptm private.PrivateTransactionManager, manager *accounts.Manager, handler DataHandler, fetcher *StateFetcher, apiBackendHelper APIBackendHelper, config *params.ChainConfig) (*PrivacyService, error) {
 	service := &PrivacyService{
 		psiContracts:     make(map[types.PrivateStateIdentifier]map[common.Address]*ExtensionContract),
@@ -103,6 +128,10 @@ func New(stack *node.Node, ptm private.PrivateTransactionManager, manager *accou
 	if err != nil {
 		return nil, errors.New("could not load existing extension contracts: " + err.Error())
 	}
+	service.psiDependencies, err = service.dataHandler.LoadDependencies()
+	if err != nil {
+		return nil, errors.New("could not load the dependencies of the initiated extensions: " + err.Error())
+	}
 
 	// Register service to node
 	stack.RegisterAPIs(service.apis())
@@ -136,6 +165,7 @@ func (service *PrivacyService) watchForNewContracts(psi types.PrivateStateIdenti
 			RecipientPtmKey:           newExtensionEvent.RecipientPTMKey,
 			ManagementContractAddress: foundLog.Address,
 			CreationData:              tx.Data(),
+			Dependencies:              service.takeDependencies(psi, newExtensionEvent.ToExtend, newExtensionEvent.RecipientAddress, newExtensionEvent.RecipientPTMKey),
 		}
 
 		enclaveKey := common.BytesToEncryptedPayloadHash(tx.Data())
@@ -157,6 +187,7 @@ func (service *PrivacyService) watchForNewContracts(psi types.PrivateStateIdenti
 			return
 		}
 		service.mu.Unlock()
+		service.extensionFeed.Send(extensionEventOf(psi, ExtensionCreated, foundLog, newContractExtension.ContractExtended))
 
 		// if party is sender then complete self voting
 
@@ -212,11 +243,110 @@ func (service *PrivacyService) watchForCancelledContracts(psi types.PrivateState
 			}
 		}
 		service.mu.Unlock()
+
+		// the extension is finished either by the creator cancelling it, or by the creator
+		// sharing the state once all the parties have voted
+		psiManagementContractClient := service.managementContract(psi)
+		defer psiManagementContractClient.Close()
+		caller, err := psiManagementContractClient.Caller(l.Address)
+		if err != nil {
+			log.Error("service.managementContractFacade.Caller", "address", l.Address.Hex(), "error", err)
+			return
+		}
+		contractToExtend, err := caller.ContractToExtend(nil)
+		if err != nil {
+			log.Error("[contract] caller.ContractToExtend", "error", err)
+			return
+		}
+		sharedDataHash, err := caller.SharedDataHash(nil)
+		if err != nil {
+			log.Error("[contract] caller.SharedDataHash", "error", err)
+			return
+		}
+		eventType := ExtensionCancelled
+		if sharedDataHash != "" {
+			eventType = ExtensionCompleted
+		}
+		service.extensionFeed.Send(extensionEventOf(psi, eventType, l, contractToExtend))
 	}
 
 	return handler.createSub(finishedExtensionQuery, cb)
 }
 
+func (service *PrivacyService) watchForVotes(psi types.PrivateStateIdentifier) error {
+	handler := NewSubscriptionHandler(service.node, psi, service.ptm, service)
+
+	cb := func(l types.Log) {
+		newVoteEvent, err := extensionContracts.UnpackNewVoteLog(l.Data)
+		if err != nil {
+			log.Error("Error unpacking extension vote log", "error", err)
+			return
+		}
+		service.mu.Lock()
+		extensionEntry, ok := service.psiContracts[psi][l.Address]
+		service.mu.Unlock()
+		if !ok {
+			log.Debug("Extension: this node doesn't participate in the contract extender", "address", l.Address.Hex())
+			return
+		}
+		ev := extensionEventOf(psi, ExtensionVoted, l, extensionEntry.ContractExtended)
+		ev.Voter, ev.Vote = &newVoteEvent.Voter, &newVoteEvent.Vote
+		service.extensionFeed.Send(ev)
+	}
+
+	return handler.createSub(newVoteQuery, cb)
+}
+
+// watchForAppliedStates notifies the recipient of an extension once the shared
+// state has been set in its private state
+func (service *PrivacyService) watchForAppliedStates(psi types.PrivateStateIdentifier) error {
+	handler := NewSubscriptionHandler(service.node, psi, service.ptm, service)
+
+	cb := func(l types.Log) {
+		toExtend, _, uuid, err := extensionContracts.UnpackStateSharedLog(l.Data)
+		if err != nil {
+			log.Error("Error unpacking extension state shared log", "error", err)
+			return
+		}
+		if !privacyExtension.DefaultExtensionHandler.UuidIsOwn(l.Address, uuid, psi) {
+			return
+		}
+		psiManagementContractClient := service.managementContract(psi)
+		defer psiManagementContractClient.Close()
+		caller, err := psiManagementContractClient.Caller(l.Address)
+		if err != nil {
+			log.Error("service.managementContractFacade.Caller", "address", l.Address.Hex(), "error", err)
+			return
+		}
+		// the creator of the extension votes as well, but already has the state
+		contractCreator, err := caller.Creator(nil)
+		if err != nil {
+			log.Error("[contract] caller.Creator", "error", err)
+			return
+		}
+		if _, err := service.accountManager.Find(accounts.Account{Address: contractCreator}); err == nil {
+			return
+		}
+		privateState, err := service.stateFetcher.privateState(l.BlockHash, psi)
+		if err != nil {
+			log.Error("Extension: unable to fetch the private state", "block", l.BlockHash.Hex(), "error", err)
+			return
+		}
+		if len(privateState.GetCode(toExtend)) == 0 {
+			log.Warn("Extension: the shared state was not applied", "address", l.Address.Hex(), "contract", toExtend.Hex())
+			return
+		}
+		// a state is shared once per vote, so several logs are emitted for an extension: the state
+		// has only been applied by this log if the contract did not exist at the parent block
+		if applied, err := service.stateFetcher.existedBefore(l.BlockHash, toExtend, psi); err != nil || applied {
+			return
+		}
+		service.extensionFeed.Send(extensionEventOf(psi, ExtensionStateApplied, l, toExtend))
+	}
+
+	return handler.createSub(stateSharedQuery, cb)
+}
+
 func (service *PrivacyService) watchForCompletionEvents(psi types.PrivateStateIdentifier) error {
 	handler := NewSubscriptionHandler(service.node, psi, service.ptm, service)
 
@@ -294,10 +424,13 @@ func (service *PrivacyService) watchForCompletionEvents(psi types.PrivateStateId
 			log.Error("[contract] caller.ContractToExtend", "error", err)
 			return
 		}
-		log.Debug("Extension: dump current state", "block", l.BlockHash, "contract", contractToExtend.Hex(), "psi", txPsi.ID)
-		entireStateData, err := service.stateFetcher.GetAddressStateFromBlock(l.BlockHash, contractToExtend, txPsi.ID)
+		// the states of the contract and of its dependencies are shared in a single payload, so
+		// that they are all set at once by the new recipient
+		contractsToExtend := append([]common.Address{contractToExtend}, extensionEntry.Dependencies...)
+		log.Debug("Extension: dump current state", "block", l.BlockHash, "contract", contractToExtend.Hex(), "dependencies", len(extensionEntry.Dependencies), "psi", txPsi.ID)
+		entireStateData, err := service.stateFetcher.GetAddressesStateFromBlock(l.BlockHash, contractsToExtend, txPsi.ID)
 		if err != nil {
-			log.Error("[state] service.stateFetcher.GetAddressStateFromBlock", "block", l.BlockHash.Hex(), "contract", contractToExtend.Hex(), "error", err)
+			log.Error("[state] service.stateFetcher.GetAddressesStateFromBlock", "block", l.BlockHash.Hex(), "contract", contractToExtend.Hex(), "error", err)
 			return
 		}
 
@@ -320,13 +453,15 @@ func (service *PrivacyService) watchForCompletionEvents(psi types.PrivateStateId
 			}
 			// Fetch mandatory recipients data from Tessera - only when privacy flag is 2
 			if privacyMetaData.PrivacyFlag == engine.PrivacyFlagMandatoryRecipients {
-				fetchedMandatoryRecipients, err := service.ptm.GetMandatory(privacyMetaData.CreationTxHash)
-				if err != nil || len(fetchedMandatoryRecipients) == 0 {
-					log.Error("Extension: Unable to fetch mandatory parties for extension management contract", "error", err)
-					return
+				for _, address := range contractsToExtend {
+					fetchedMandatoryRecipients, err := service.getMandatoryRecipients(l.BlockHash, address, txPsi.ID)
+					if err != nil || len(fetchedMandatoryRecipients) == 0 {
+						log.Error("Extension: Unable to fetch mandatory parties for extension management contract", "contract", address.Hex(), "error", err)
+						return
+					}
+					log.Debug("Extension: able to fetch mandatory recipients", "contract", address.Hex(), "mandatory", fetchedMandatoryRecipients)
+					extraMetaData.MandatoryRecipients = common.AppendSkipDuplicates(extraMetaData.MandatoryRecipients, fetchedMandatoryRecipients...)
 				}
-				log.Debug("Extension: able to fetch mandatory recipients", "mandatory", fetchedMandatoryRecipients)
-				extraMetaData.MandatoryRecipients = fetchedMandatoryRecipients
 			}
 		}
 
@@ -378,6 +513,8 @@ func (service *PrivacyService) Start() error {
 			service.watchForNewContracts,       // watch for new extension contract creation event
 			service.watchForCancelledContracts, // watch for extension contract cancellation event
 			service.watchForCompletionEvents,   // watch for extension contract voting complete event
+			service.watchForVotes,              // watch for extension contract vote event
+			service.watchForAppliedStates,      // watch for extension contract state shared event
 		} {
 			if err := f(psi); err != nil {
 				return err
@@ -424,6 +561,82 @@ func (service *PrivacyService) GenerateTransactOptions(txa ethapi.SendTxArgs) (*
 	return txArgs, nil
 }
 
+// returns the preview of the state payload shared by the initiator of an extension, as set by its recipient
+func (service *PrivacyService) sharedExtensionPreview(sharedDataHash string, contractExtended common.Address) (*ExtensionPreview, error) {
+	ptmHash, err := common.Base64ToEncryptedPayloadHash(sharedDataHash)
+	if err != nil {
+		return nil, fmt.Errorf("invalid shared state hash %s: %v", sharedDataHash, err)
+	}
+	_, _, stateData, _, err := service.ptm.Receive(ptmHash)
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch the shared state %s: %v", sharedDataHash, err)
+	}
+	if stateData == nil {
+		return nil, fmt.Errorf("shared state %s not found in the private transaction manager", sharedDataHash)
+	}
+	return newSharedExtensionPreview(stateData, contractExtended)
+}
+
+// records the dependencies of an extension initiated by this node, which are only known locally,
+// before its management contract is deployed
+func (service *PrivacyService) recordDependencies(psi types.PrivateStateIdentifier, dependencies *ExtensionDependencies) error {
+	service.mu.Lock()
+	defer service.mu.Unlock()
+
+	service.psiDependencies[psi] = append(service.psiDependencies[psi], dependencies)
+	if err := service.dataHandler.SaveDependencies(service.psiDependencies); err != nil {
+		service.removeDependencies(psi, dependencies)
+		return err
+	}
+	return nil
+}
+
+// forgets the dependencies of an extension whose management contract could not be deployed
+func (service *PrivacyService) forgetDependencies(psi types.PrivateStateIdentifier, dependencies *ExtensionDependencies) {
+	service.mu.Lock()
+	defer service.mu.Unlock()
+
+	service.removeDependencies(psi, dependencies)
+	if err := service.dataHandler.SaveDependencies(service.psiDependencies); err != nil {
+		log.Error("Extension: failed to save the dependencies of the initiated extensions", "error", err)
+	}
+}
+
+// returns the dependencies recorded for the extension whose management contract creation has been seen,
+// if it was initiated by this node, and stops tracking them. It must be called with the lock held.
+func (service *PrivacyService) takeDependencies(psi types.PrivateStateIdentifier, contractExtended, recipient common.Address, recipientPtmKey string) []common.Address {
+	for _, dependencies := range service.psiDependencies[psi] {
+		if !dependencies.matches(contractExtended, recipient, recipientPtmKey) {
+			continue
+		}
+		service.removeDependencies(psi, dependencies)
+		if err := service.dataHandler.SaveDependencies(service.psiDependencies); err != nil {
+			log.Error("Extension: failed to save the dependencies of the initiated extensions", "error", err)
+		}
+		return dependencies.Dependencies
+	}
+	return nil
+}
+
+func (service *PrivacyService) removeDependencies(psi types.PrivateStateIdentifier, dependencies *ExtensionDependencies) {
+	pending := service.psiDependencies[psi]
+	for i := range pending {
+		if pending[i] == dependencies {
+			service.psiDependencies[psi] = append(pending[:i:i], pending[i+1:]...)
+			return
+		}
+	}
+}
+
+// returns the mandatory recipients of a given private contract
+func (service *PrivacyService) getMandatoryRecipients(blockHash common.Hash, address common.Address, psi types.PrivateStateIdentifier) ([]string, error) {
+	privacyMetaData, err := service.stateFetcher.GetPrivacyMetaData(blockHash, address, psi)
+	if err != nil {
+		return nil, err
+	}
+	return service.ptm.GetMandatory(privacyMetaData.CreationTxHash)
+}
+
 // returns the participant list for a given private contract
 func (service *PrivacyService) GetAllParticipants(blockHash common.Hash, address common.Address, psi types.PrivateStateIdentifier) ([]string, error) {
 	privacyMetaData, err := service.stateFetcher.GetPrivacyMetaData(blockHash, address, psi)
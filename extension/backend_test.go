@@ -232,3 +232,50 @@ func TestGenerateTransactionOptionsGivesNonDefaultsWhenSpecified(t *testing.T) {
 		return
 	}
 }
+
+func TestRecordDependenciesSurvivesRestart(t *testing.T) {
+	datadir := t.TempDir()
+	var (
+		contractExtended = common.HexToAddress("0x1111111111111111111111111111111111111111")
+		recipient        = common.HexToAddress("0x4444444444444444444444444444444444444444")
+		recipientPtmKey  = "1234567891234567891234567891234567891234567="
+		dependency       = common.HexToAddress("0x5555555555555555555555555555555555555555")
+	)
+	service := &PrivacyService{
+		dataHandler:     NewJsonFileDataHandler(datadir),
+		psiDependencies: make(map[types.PrivateStateIdentifier][]*ExtensionDependencies),
+	}
+	err := service.recordDependencies(types.DefaultPrivateStateIdentifier, &ExtensionDependencies{
+		ContractExtended: contractExtended,
+		Recipient:        recipient,
+		RecipientPtmKey:  recipientPtmKey,
+		Dependencies:     []common.Address{dependency},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error recording the dependencies: %v", err)
+	}
+
+	// the node restarts before the creation of the management contract is seen
+	restarted := &PrivacyService{dataHandler: NewJsonFileDataHandler(datadir)}
+	restarted.psiDependencies, err = restarted.dataHandler.LoadDependencies()
+	if err != nil {
+		t.Fatalf("unexpected error loading the dependencies: %v", err)
+	}
+
+	if got := restarted.takeDependencies(types.DefaultPrivateStateIdentifier, contractExtended, common.Address{}, recipientPtmKey); got != nil {
+		t.Errorf("expected no dependencies for another recipient, got %v", got)
+	}
+	if got := restarted.takeDependencies(types.DefaultPrivateStateIdentifier, contractExtended, recipient, recipientPtmKey); len(got) != 1 || got[0] != dependency {
+		t.Errorf("expected the recorded dependencies, got %v", got)
+	}
+	if got := restarted.takeDependencies(types.DefaultPrivateStateIdentifier, contractExtended, recipient, recipientPtmKey); got != nil {
+		t.Errorf("expected the dependencies to be taken once, got %v", got)
+	}
+	persisted, err := restarted.dataHandler.LoadDependencies()
+	if err != nil {
+		t.Fatalf("unexpected error loading the dependencies: %v", err)
+	}
+	if len(persisted[types.DefaultPrivateStateIdentifier]) != 0 {
+		t.Errorf("expected the taken dependencies to be removed from the file, got %v", persisted)
+	}
+}
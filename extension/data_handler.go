@@ -32,21 +32,32 @@ import (
 
 */
 
-const extensionContractData = "activeExtensions.json"
+const (
+	extensionContractData   = "activeExtensions.json"
+	extensionDependencyData = "extensionDependencies.json"
+)
 
 type DataHandler interface {
 	Load() (map[types.PrivateStateIdentifier]map[common.Address]*ExtensionContract, error)
 
 	Save(extensionContracts map[types.PrivateStateIdentifier]map[common.Address]*ExtensionContract) error
+
+	// LoadDependencies loads the dependencies of the extensions initiated by this node whose
+	// management contract creation has not been seen yet
+	LoadDependencies() (map[types.PrivateStateIdentifier][]*ExtensionDependencies, error)
+
+	SaveDependencies(dependencies map[types.PrivateStateIdentifier][]*ExtensionDependencies) error
 }
 
 type JsonFileDataHandler struct {
-	saveFile string
+	saveFile           string
+	saveDependencyFile string
 }
 
 func NewJsonFileDataHandler(dataDirectory string) *JsonFileDataHandler {
 	return &JsonFileDataHandler{
-		saveFile: filepath.Join(dataDirectory, extensionContractData),
+		saveFile:           filepath.Join(dataDirectory, extensionContractData),
+		saveDependencyFile: filepath.Join(dataDirectory, extensionDependencyData),
 	}
 }
 
@@ -104,3 +115,29 @@ func (handler *JsonFileDataHandler) Save(extensionContracts map[types.PrivateSta
 	}
 	return nil
 }
+
+func (handler *JsonFileDataHandler) LoadDependencies() (map[types.PrivateStateIdentifier][]*ExtensionDependencies, error) {
+	dependencies := make(map[types.PrivateStateIdentifier][]*ExtensionDependencies)
+	blob, err := os.ReadFile(handler.saveDependencyFile)
+	if os.IsNotExist(err) {
+		return dependencies, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(blob, &dependencies); err != nil {
+		return nil, err
+	}
+	return dependencies, nil
+}
+
+func (handler *JsonFileDataHandler) SaveDependencies(dependencies map[types.PrivateStateIdentifier][]*ExtensionDependencies) error {
+	//no unmarshallable types, so can't error
+	output, _ := json.Marshal(dependencies)
+
+	if errSaving := os.WriteFile(handler.saveDependencyFile, output, 0644); errSaving != nil {
+		log.Error("Couldn't save the dependencies of the initiated extensions")
+		return errSaving
+	}
+	return nil
+}
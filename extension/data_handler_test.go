@@ -77,3 +77,33 @@ func TestLoadOldContents(t *testing.T) {
 		t.Errorf("expected data from file different to data written, expected %v, got %v", string(expected), string(actual))
 	}
 }
+
+func TestWriteDependenciesToFileWritesOkay(t *testing.T) {
+	dependencies := map[types.PrivateStateIdentifier][]*ExtensionDependencies{
+		types.DefaultPrivateStateIdentifier: {
+			{
+				ContractExtended: common.HexToAddress("0x1111111111111111111111111111111111111111"),
+				Recipient:        common.HexToAddress("0x4444444444444444444444444444444444444444"),
+				RecipientPtmKey:  "1234567891234567891234567891234567891234567=",
+				Dependencies:     []common.Address{common.HexToAddress("0x5555555555555555555555555555555555555555")},
+			},
+		},
+	}
+
+	datadir, err := os.MkdirTemp("", t.Name())
+	defer os.RemoveAll(datadir)
+	assert.Nil(t, err, "could not create temp directory for test")
+
+	dataHandler := NewJsonFileDataHandler(datadir)
+
+	loadedData, err := dataHandler.LoadDependencies()
+	assert.Nil(t, err, "error reading missing file")
+	assert.Empty(t, loadedData)
+
+	err = dataHandler.SaveDependencies(dependencies)
+	assert.Nil(t, err, "error writing data to file")
+
+	loadedData, err = dataHandler.LoadDependencies()
+	assert.Nil(t, err, "error reading data from file")
+	assert.Equal(t, dependencies, loadedData)
+}
@@ -0,0 +1,104 @@
+package extension
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/private/engine"
+)
+
+// maxExtensionDependencies bounds the number of contracts extended together with a contract, as
+// their states are shared in a single payload
+const maxExtensionDependencies = 64
+
+// discoverDependencies returns the private contracts referenced by the given contract, and by
+// those contracts in turn, in the order they are found. A contract is referenced by an address
+// pushed by the code, e.g. a linked library, or held in a storage slot, e.g. the implementation
+// of a proxy. Public contracts are not dependencies, as they are not extended.
+//
+// This is a heuristic: addresses which are computed, or packed with other values in a storage
+// slot, are not found, and any value which looks like the address of a private contract is
+// taken as a reference. The dependencies can be given explicitly to the extension API instead.
+func discoverDependencies(publicState, privateState *state.StateDB, root common.Address) ([]common.Address, error) {
+	var (
+		dependencies []common.Address
+		visited      = map[common.Address]bool{root: true}
+		queue        = []common.Address{root}
+	)
+	for len(queue) > 0 {
+		address := queue[0]
+		queue = queue[1:]
+		referenced, err := referencedAddresses(privateState, address)
+		if err != nil {
+			return nil, err
+		}
+		for _, candidate := range referenced {
+			if visited[candidate] {
+				continue
+			}
+			visited[candidate] = true
+			if len(privateState.GetCode(candidate)) == 0 || publicState.Exist(candidate) {
+				continue
+			}
+			if len(dependencies) == maxExtensionDependencies {
+				return nil, fmt.Errorf("contract %s has more than %d dependencies", root.Hex(), maxExtensionDependencies)
+			}
+			dependencies = append(dependencies, candidate)
+			queue = append(queue, candidate)
+		}
+	}
+	return dependencies, nil
+}
+
+// referencedAddresses returns the addresses pushed by the code of the contract and the addresses
+// held in its storage slots
+func referencedAddresses(privateState *state.StateDB, address common.Address) ([]common.Address, error) {
+	referenced := codeAddresses(privateState.GetCode(address))
+	err := privateState.ForEachStorage(address, func(_, value common.Hash) bool {
+		if storedAddress, ok := storageAddress(value); ok {
+			referenced = append(referenced, storedAddress)
+		}
+		return true
+	})
+	return referenced, err
+}
+
+// codeAddresses returns the operands of the PUSH20 instructions of the code
+func codeAddresses(code []byte) []common.Address {
+	var addresses []common.Address
+	for pc := 0; pc < len(code); pc++ {
+		op := vm.OpCode(code[pc])
+		if op < vm.PUSH1 || op > vm.PUSH32 {
+			continue
+		}
+		size := int(op-vm.PUSH1) + 1
+		if op == vm.PUSH20 && pc+size < len(code) {
+			addresses = append(addresses, common.BytesToAddress(code[pc+1:pc+1+size]))
+		}
+		pc += size
+	}
+	return addresses
+}
+
+// storageAddress returns the address held by the storage value, if the value is a non-zero
+// address padded with zeros
+func storageAddress(value common.Hash) (common.Address, bool) {
+	for _, b := range value[:common.HashLength-common.AddressLength] {
+		if b != 0 {
+			return common.Address{}, false
+		}
+	}
+	address := common.BytesToAddress(value.Bytes())
+	return address, address != (common.Address{})
+}
+
+// privacyFlagOf returns the privacy flag of a contract given its privacy metadata, which standard
+// private contracts may not have
+func privacyFlagOf(privacyMetaData *state.PrivacyMetadata) engine.PrivacyFlagType {
+	if privacyMetaData == nil {
+		return engine.PrivacyFlagStandardPrivate
+	}
+	return privacyMetaData.PrivacyFlag
+}
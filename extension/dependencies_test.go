@@ -0,0 +1,53 @@
+package extension
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCodeAddresses(t *testing.T) {
+	library := common.HexToAddress("0x3333333333333333333333333333333333333333")
+	code := []byte{byte(vm.PUSH1), byte(vm.PUSH20)} // the PUSH20 is the operand of the PUSH1
+	code = append(code, byte(vm.PUSH20))
+	code = append(code, library.Bytes()...)
+	code = append(code, byte(vm.DELEGATECALL), byte(vm.PUSH20), 0x01) // truncated PUSH20
+
+	assert.Equal(t, []common.Address{library}, codeAddresses(code))
+}
+
+func TestDiscoverDependencies(t *testing.T) {
+	var (
+		proxy          = common.HexToAddress("0x1111111111111111111111111111111111111111")
+		implementation = common.HexToAddress("0x2222222222222222222222222222222222222222")
+		library        = common.HexToAddress("0x3333333333333333333333333333333333333333")
+		public         = common.HexToAddress("0x4444444444444444444444444444444444444444")
+		noCode         = common.HexToAddress("0x5555555555555555555555555555555555555555")
+	)
+	publicState, err := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	require.NoError(t, err)
+	privateState, err := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	require.NoError(t, err)
+
+	publicState.SetCode(public, []byte{1})
+	privateState.SetCode(public, []byte{1})
+	privateState.SetCode(proxy, []byte{byte(vm.STOP)})
+	privateState.SetState(proxy, common.HexToHash("0x01"), common.BytesToHash(implementation.Bytes()))
+	privateState.SetState(proxy, common.HexToHash("0x02"), common.BytesToHash(public.Bytes()))
+	privateState.SetState(proxy, common.HexToHash("0x03"), common.BytesToHash(noCode.Bytes()))
+	privateState.SetState(proxy, common.HexToHash("0x04"), common.HexToHash("0x0100000000000000000000000000000000000000000000000000000000000000"))
+	privateState.SetCode(implementation, append([]byte{byte(vm.PUSH20)}, library.Bytes()...))
+	// the library references the proxy back
+	privateState.SetCode(library, append([]byte{byte(vm.PUSH20)}, proxy.Bytes()...))
+	privateState.Commit(false)
+
+	dependencies, err := discoverDependencies(publicState, privateState, proxy)
+
+	require.NoError(t, err)
+	assert.Equal(t, []common.Address{implementation, library}, dependencies)
+}
@@ -16,3 +16,10 @@ func UnpackNewExtensionCreatedLog(data []byte) (*ContractExtenderNewContractExte
 
 	return newExtensionEvent, err
 }
+
+func UnpackNewVoteLog(data []byte) (*ContractExtenderNewVote, error) {
+	newVoteEvent := new(ContractExtenderNewVote)
+	err := ContractExtenderParsedABI.UnpackIntoInterface(newVoteEvent, "NewVote", data)
+
+	return newVoteEvent, err
+}
@@ -81,6 +81,21 @@ func logContainsExtensionTopic(receivedLog *types.Log) bool {
 	return receivedLog.Topics[0].String() == extension.StateSharedTopicHash
 }
 
+// validateSharedAccounts checks that the shared state contains the extended contract, and that the other
+// accounts, i.e. the dependencies extended together with it, are keyed by their address
+func validateSharedAccounts(extendedAddress common.Address, sharedAccounts map[string]extension.AccountWithMetadata) bool {
+	addresses := []common.Address{extendedAddress}
+	for key := range sharedAccounts {
+		if !common.IsHexAddress(key) {
+			return false
+		}
+		if address := common.HexToAddress(key); address != extendedAddress {
+			addresses = append(addresses, address)
+		}
+	}
+	return validateAccountsExist(addresses, sharedAccounts)
+}
+
 // validateAccountsExist checks that all the accounts in the expected list are
 // present in the state map, and that no  other accounts exist in the state map
 // that are unexpected
@@ -366,3 +366,32 @@ func (mpm *mockPrivateTransactionManager) DecryptPayload(payload common.DecryptR
 func (mpm *mockPrivateTransactionManager) GetCache() state.Database {
 	return nil
 }
+
+func Test_validateSharedAccounts_WithDependencies(t *testing.T) {
+	extended := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	shared := map[string]extension.AccountWithMetadata{
+		extended.String(): {},
+		common.HexToAddress("0x3333333333333333333333333333333333333333").String(): {},
+	}
+
+	assert.True(t, validateSharedAccounts(extended, shared))
+}
+
+func Test_validateSharedAccounts_ExtendedContractMissing(t *testing.T) {
+	extended := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	shared := map[string]extension.AccountWithMetadata{
+		common.HexToAddress("0x3333333333333333333333333333333333333333").String(): {},
+	}
+
+	assert.False(t, validateSharedAccounts(extended, shared))
+}
+
+func Test_validateSharedAccounts_InvalidKey(t *testing.T) {
+	extended := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	shared := map[string]extension.AccountWithMetadata{
+		extended.String(): {},
+		"not an address":  {},
+	}
+
+	assert.False(t, validateSharedAccounts(extended, shared))
+}
@@ -56,11 +56,14 @@ func (handler *ExtensionHandler) CheckExtensionAndSetPrivateState(txLogs []*type
 				continue
 			}
 			// check the privacy flag of the contract. if its other than
-			// 0 then need to update the privacy metadata for the contract
+			// 0 then need to update the privacy metadata for the contract,
+			// and for the dependencies extended together with it
 			//TODO: validate the old and new parties to ensure that all old parties are there
-			setPrivacyMetadata(privateState, address, hash)
-			if handler.isMultitenant {
-				setManagedParties(handler.ptm, privateState, address, hash)
+			for _, extendedAddress := range handler.extendedAddresses(address, hash, privateState) {
+				setPrivacyMetadata(privateState, extendedAddress, hash)
+				if handler.isMultitenant {
+					setManagedParties(handler.ptm, privateState, extendedAddress, hash)
+				}
 			}
 			extraMetaDataUpdated = true
 		} else {
@@ -71,10 +74,18 @@ func (handler *ExtensionHandler) CheckExtensionAndSetPrivateState(txLogs []*type
 			if !handler.isMultitenant {
 				managedParties = nil
 			}
-			if !validateAccountsExist([]common.Address{address}, accounts) {
+			if !validateSharedAccounts(address, accounts) {
 				log.Error("Account mismatch", "expected", address, "found", accounts)
 				continue
 			}
+			// the dependencies this node already has are kept as they are
+			for key := range accounts {
+				if dependency := common.HexToAddress(key); dependency != address && privateState.GetCode(dependency) != nil {
+					log.Debug("Extension: dependency already exists", "address", dependency)
+					delete(accounts, key)
+				}
+			}
+			// the contract and its dependencies are set at once
 			snapshotId := privateState.Snapshot()
 
 			if success := setState(privateState, accounts, privacyMetaData, managedParties); !success {
@@ -105,6 +116,27 @@ func (handler *ExtensionHandler) FetchStateData(address common.Address, hash str
 	return managedParties, accounts, privacyMetaData, true
 }
 
+// extendedAddresses returns the addresses of the contracts extended by the state shared with the given hash
+// which exist in the private state: the extended contract and, for an extension with dependencies, those of
+// the dependencies which this node already has
+func (handler *ExtensionHandler) extendedAddresses(address common.Address, hash string, privateState *state.StateDB) []common.Address {
+	addresses := []common.Address{address}
+	_, stateData, _, ok := handler.FetchDataFromPTM(hash)
+	if !ok {
+		return addresses
+	}
+	var accounts map[string]extension.AccountWithMetadata
+	if err := json.Unmarshal(stateData, &accounts); err != nil {
+		return addresses
+	}
+	for key := range accounts {
+		if dependency := common.HexToAddress(key); dependency != address && privateState.GetCode(dependency) != nil {
+			addresses = append(addresses, dependency)
+		}
+	}
+	return addresses
+}
+
 // Checks
 
 func (handler *ExtensionHandler) FetchDataFromPTM(hash string) ([]string, []byte, *state.PrivacyMetadata, bool) {
@@ -2,6 +2,7 @@ package extension
 
 import (
 	"context"
+	"encoding/json"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/internal/ethapi"
@@ -68,9 +69,59 @@ func (api *PrivateExtensionProxyAPI) ExtendContract(ctx context.Context, toExten
 	return result, err
 }
 
+func (api *PrivateExtensionProxyAPI) ExtendContractWithDependencies(ctx context.Context, toExtend common.Address, newRecipientPtmPublicKey string, recipientAddr common.Address, txa ethapi.SendTxArgs, dependencies *[]common.Address) (string, error) {
+	log.Info("QLight - proxy enabled")
+	var result string
+	err := api.proxyClient.CallContext(ctx, &result, "quorumExtension_extendContractWithDependencies", toExtend, newRecipientPtmPublicKey, recipientAddr, txa, dependencies)
+	return result, err
+}
+
 func (api *PrivateExtensionProxyAPI) CancelExtension(ctx context.Context, extensionContract common.Address, txa ethapi.SendTxArgs) (string, error) {
 	log.Info("QLight - proxy enabled")
 	var result string
 	err := api.proxyClient.CallContext(ctx, &result, "quorumExtension_cancelExtension", extensionContract, txa)
 	return result, err
 }
+
+func (api *PrivateExtensionProxyAPI) PreviewExtension(ctx context.Context, extensionContract common.Address) (*ExtensionPreview, error) {
+	log.Info("QLight - proxy enabled")
+	var result ExtensionPreview
+	err := api.proxyClient.CallContext(ctx, &result, "quorumExtension_previewExtension", extensionContract)
+	return &result, err
+}
+
+// ExtensionEvents relays the lifecycle events of the extensions notified by the server node, which
+// tracks the extensions on behalf of the light node
+func (api *PrivateExtensionProxyAPI) ExtensionEvents(ctx context.Context) (*rpc.Subscription, error) {
+	log.Info("QLight - proxy enabled")
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+	events := make(chan json.RawMessage)
+	proxySub, err := api.proxyClient.Subscribe(ctx, "quorumExtension", events, "extensionEvents")
+	if err != nil {
+		return nil, err
+	}
+
+	rpcSub := notifier.CreateSubscription()
+
+	go func() {
+		defer proxySub.Unsubscribe()
+		for {
+			select {
+			case ev := <-events:
+				notifier.Notify(rpcSub.ID, ev)
+			case err := <-proxySub.Err():
+				log.Warn("QLight - extension events subscription to the server node ended", "err", err)
+				return
+			case <-rpcSub.Err():
+				return
+			case <-notifier.Closed():
+				return
+			}
+		}
+	}()
+
+	return rpcSub, nil
+}
@@ -1,9 +1,11 @@
 package extension
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
+	"sort"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/mps"
@@ -56,17 +58,72 @@ func (fetcher *StateFetcher) getCurrentBlockHash() common.Hash {
 // functions of a StateFetcher, retrieving the state of an address at a given
 // block, represented in JSON.
 func (fetcher *StateFetcher) GetAddressStateFromBlock(blockHash common.Hash, addressToFetch common.Address, psi types.PrivateStateIdentifier) ([]byte, error) {
+	return fetcher.GetAddressesStateFromBlock(blockHash, []common.Address{addressToFetch}, psi)
+}
+
+// GetAddressesStateFromBlock retrieves the states of the addresses at a given
+// block, represented in JSON, so that they can be shared in a single payload.
+func (fetcher *StateFetcher) GetAddressesStateFromBlock(blockHash common.Hash, addressesToFetch []common.Address, psi types.PrivateStateIdentifier) ([]byte, error) {
 	privateState, err := fetcher.privateState(blockHash, psi)
 	if err != nil {
 		return nil, err
 	}
-	stateData, err := fetcher.addressStateAsJson(privateState, addressToFetch)
+	stateData, err := fetcher.addressStateAsJson(privateState, addressesToFetch...)
 	if err != nil {
 		return nil, err
 	}
 	return stateData, nil
 }
 
+// GetExtensionPreview returns the states of the addresses at a given block as
+// they would be shared in the payload of an extension, with their sizes.
+func (fetcher *StateFetcher) GetExtensionPreview(blockHash common.Hash, addressesToFetch []common.Address, psi types.PrivateStateIdentifier) (*ExtensionPreview, error) {
+	block := fetcher.chainAccessor.GetBlockByHash(blockHash)
+	if block == nil {
+		return nil, fmt.Errorf("block %s not found", blockHash.Hex())
+	}
+	stateData, err := fetcher.GetAddressesStateFromBlock(blockHash, addressesToFetch, psi)
+	if err != nil {
+		return nil, err
+	}
+	preview, err := newExtensionPreview(stateData, addressesToFetch)
+	if err != nil {
+		return nil, err
+	}
+	preview.BlockNumber = block.NumberU64()
+	preview.BlockHash = blockHash
+	return preview, nil
+}
+
+// GetDependencies returns the private contracts the given contract depends on
+// at a given block, i.e. the private contracts referenced by its code or its
+// storage, directly or through other dependencies.
+func (fetcher *StateFetcher) GetDependencies(blockHash common.Hash, address common.Address, psi types.PrivateStateIdentifier) ([]common.Address, error) {
+	block := fetcher.chainAccessor.GetBlockByHash(blockHash)
+	if block == nil {
+		return nil, fmt.Errorf("block %s not found", blockHash.Hex())
+	}
+	publicState, privateState, err := fetcher.chainAccessor.StateAtPSI(block.Root(), psi)
+	if err != nil {
+		return nil, err
+	}
+	return discoverDependencies(publicState, privateState, address)
+}
+
+// existedBefore reports whether the given contract existed in the private state of the parent of
+// a given block.
+func (fetcher *StateFetcher) existedBefore(blockHash common.Hash, address common.Address, psi types.PrivateStateIdentifier) (bool, error) {
+	block := fetcher.chainAccessor.GetBlockByHash(blockHash)
+	if block == nil {
+		return false, fmt.Errorf("block %s not found", blockHash.Hex())
+	}
+	privateState, err := fetcher.privateState(block.ParentHash(), psi)
+	if err != nil {
+		return false, err
+	}
+	return len(privateState.GetCode(address)) > 0, nil
+}
+
 // privateState returns the private state database for a given block hash.
 func (fetcher *StateFetcher) privateState(blockHash common.Hash, psi types.PrivateStateIdentifier) (*state.StateDB, error) {
 	block := fetcher.chainAccessor.GetBlockByHash(blockHash)
@@ -75,23 +132,79 @@ func (fetcher *StateFetcher) privateState(blockHash common.Hash, psi types.Priva
 	return privateState, err
 }
 
-// addressStateAsJson returns the state of an address, including the balance,
+// addressStateAsJson returns the state of the addresses, including the balance,
 // nonce, code and state data as a JSON map.
-func (fetcher *StateFetcher) addressStateAsJson(privateState *state.StateDB, addressToShare common.Address) ([]byte, error) {
+func (fetcher *StateFetcher) addressStateAsJson(privateState *state.StateDB, addressesToShare ...common.Address) ([]byte, error) {
 	keepAddresses := make(map[string]extensionContracts.AccountWithMetadata)
 
-	if account, found := privateState.DumpAddress(addressToShare); found {
+	for _, addressToShare := range addressesToShare {
+		account, found := privateState.DumpAddress(addressToShare)
+		if !found {
+			return nil, fmt.Errorf("error in contract state fetch")
+		}
 		keepAddresses[addressToShare.Hex()] = extensionContracts.AccountWithMetadata{
 			State: account,
 		}
-	} else {
-		return nil, fmt.Errorf("error in contract state fetch")
 	}
 	//types can be marshalled, so errors can't occur
 	out, _ := json.Marshal(&keepAddresses)
 	return out, nil
 }
 
+// newExtensionPreview decodes the state payload of the addresses, the extended
+// contract first, and counts its storage slots and size.
+func newExtensionPreview(stateData []byte, addresses []common.Address) (*ExtensionPreview, error) {
+	var accounts map[string]extensionContracts.AccountWithMetadata
+	if err := json.Unmarshal(stateData, &accounts); err != nil {
+		return nil, err
+	}
+	preview := &ExtensionPreview{
+		ContractExtended: addresses[0],
+		Accounts:         make([]*ExtensionPreviewAccount, 0, len(addresses)),
+		Size:             len(stateData),
+	}
+	for _, address := range addresses {
+		account, found := accounts[address.Hex()]
+		if !found {
+			return nil, fmt.Errorf("state of %s not found in the state payload", address.Hex())
+		}
+		code := common.FromHex(account.State.Code)
+		preview.Accounts = append(preview.Accounts, &ExtensionPreviewAccount{
+			Address:      address,
+			Code:         code,
+			CodeSize:     len(code),
+			Storage:      account.State.Storage,
+			StorageSlots: len(account.State.Storage),
+		})
+		preview.StorageSlots += len(account.State.Storage)
+	}
+	return preview, nil
+}
+
+// newSharedExtensionPreview returns the preview of the state payload shared by the initiator of an
+// extension, which holds the extended contract and its dependencies, even if they are only known
+// to the initiator
+func newSharedExtensionPreview(stateData []byte, contractExtended common.Address) (*ExtensionPreview, error) {
+	var accounts map[string]json.RawMessage
+	if err := json.Unmarshal(stateData, &accounts); err != nil {
+		return nil, err
+	}
+	addresses := []common.Address{contractExtended}
+	var dependencies []common.Address
+	for key := range accounts {
+		if address := common.HexToAddress(key); address != contractExtended {
+			dependencies = append(dependencies, address)
+		}
+	}
+	sort.Slice(dependencies, func(i, j int) bool { return bytes.Compare(dependencies[i][:], dependencies[j][:]) < 0 })
+	preview, err := newExtensionPreview(stateData, append(addresses, dependencies...))
+	if err != nil {
+		return nil, err
+	}
+	preview.Shared = true
+	return preview, nil
+}
+
 // returns the privacy metadata
 func (fetcher *StateFetcher) GetPrivacyMetaData(blockHash common.Hash, address common.Address, psi types.PrivateStateIdentifier) (*state.PrivacyMetadata, error) {
 	privateState, err := fetcher.privateState(blockHash, psi)
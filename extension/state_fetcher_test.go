@@ -1,6 +1,7 @@
 package extension
 
 import (
+	"encoding/json"
 	"math/big"
 	"testing"
 
@@ -47,3 +48,118 @@ func TestDumpAddressWhenNotFound(t *testing.T) {
 		t.Errorf("dump mismatch:\ngot: %s\nwant: nil\n", string(out))
 	}
 }
+
+func TestDumpAddressesWithDependencies(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(db), nil)
+	address := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	dependency := common.HexToAddress("0x3333333333333333333333333333333333333333")
+
+	stateFetcher := NewStateFetcher(nil)
+
+	statedb.SetCode(address, []byte{3, 3, 3})
+	statedb.SetCode(dependency, []byte{4, 4, 4})
+	statedb.Commit(false)
+
+	out, err := stateFetcher.addressStateAsJson(statedb, address, dependency)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var accounts map[string]json.RawMessage
+	if err := json.Unmarshal(out, &accounts); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(accounts) != 2 || accounts[address.Hex()] == nil || accounts[dependency.Hex()] == nil {
+		t.Errorf("dump mismatch: got %s", string(out))
+	}
+
+	_, err = stateFetcher.addressStateAsJson(statedb, address, common.HexToAddress("0x4444444444444444444444444444444444444444"))
+	if err == nil {
+		t.Errorf("expected an error when an address is not found")
+	}
+}
+
+func TestNewExtensionPreview(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(db), nil)
+	address := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	dependency := common.HexToAddress("0x3333333333333333333333333333333333333333")
+
+	stateFetcher := NewStateFetcher(nil)
+
+	statedb.SetCode(address, []byte{3, 3, 3})
+	statedb.SetState(address, common.HexToHash("0x01"), common.HexToHash("0x0a"))
+	statedb.SetState(address, common.HexToHash("0x02"), common.HexToHash("0x0b"))
+	statedb.SetCode(dependency, []byte{4, 4})
+	statedb.SetState(dependency, common.HexToHash("0x01"), common.HexToHash("0x0c"))
+	statedb.Commit(false)
+
+	out, err := stateFetcher.addressStateAsJson(statedb, address, dependency)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	preview, err := newExtensionPreview(out, []common.Address{address, dependency})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if preview.ContractExtended != address || preview.Size != len(out) || preview.StorageSlots != 3 {
+		t.Errorf("preview mismatch: got %+v", preview)
+	}
+	if len(preview.Accounts) != 2 {
+		t.Fatalf("expected 2 accounts, got %d", len(preview.Accounts))
+	}
+	if account := preview.Accounts[0]; account.Address != address || account.CodeSize != 3 || account.StorageSlots != 2 {
+		t.Errorf("account mismatch: got %+v", account)
+	}
+	if account := preview.Accounts[1]; account.Address != dependency || account.CodeSize != 2 || account.StorageSlots != 1 {
+		t.Errorf("account mismatch: got %+v", account)
+	}
+
+	_, err = newExtensionPreview(out, []common.Address{common.HexToAddress("0x4444444444444444444444444444444444444444")})
+	if err == nil {
+		t.Errorf("expected an error when an address is not in the payload")
+	}
+}
+
+func TestNewSharedExtensionPreview(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(db), nil)
+	address := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	dependencies := []common.Address{common.HexToAddress("0x1111111111111111111111111111111111111111"), common.HexToAddress("0x3333333333333333333333333333333333333333")}
+
+	stateFetcher := NewStateFetcher(nil)
+
+	statedb.SetCode(address, []byte{3, 3, 3})
+	for _, dependency := range dependencies {
+		statedb.SetCode(dependency, []byte{4, 4})
+	}
+	statedb.Commit(false)
+
+	// the payload shared by the initiator holds the dependencies unknown to the other nodes
+	out, err := stateFetcher.addressStateAsJson(statedb, dependencies[1], address, dependencies[0])
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	preview, err := newSharedExtensionPreview(out, address)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !preview.Shared || preview.ContractExtended != address || preview.Size != len(out) {
+		t.Errorf("preview mismatch: got %+v", preview)
+	}
+	if len(preview.Accounts) != 3 {
+		t.Fatalf("expected 3 accounts, got %d", len(preview.Accounts))
+	}
+	for i, expected := range append([]common.Address{address}, dependencies...) {
+		if preview.Accounts[i].Address != expected {
+			t.Errorf("account %d mismatch: got %s, expected %s", i, preview.Accounts[i].Address.Hex(), expected.Hex())
+		}
+	}
+
+	if _, err := newSharedExtensionPreview(out, common.HexToAddress("0x4444444444444444444444444444444444444444")); err == nil {
+		t.Errorf("expected an error when the extended contract is not in the payload")
+	}
+}
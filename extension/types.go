@@ -3,6 +3,8 @@ package extension
 import (
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/extension/extensionContracts"
 )
 
@@ -28,6 +30,20 @@ var (
 		Topics:    [][]common.Hash{{common.HexToHash(extensionContracts.CanPerformStateShareTopicHash)}},
 		Addresses: []common.Address{},
 	}
+
+	newVoteQuery = ethereum.FilterQuery{
+		FromBlock: nil,
+		ToBlock:   nil,
+		Topics:    [][]common.Hash{{common.HexToHash(extensionContracts.NewVoteTopicHash)}},
+		Addresses: []common.Address{},
+	}
+
+	stateSharedQuery = ethereum.FilterQuery{
+		FromBlock: nil,
+		ToBlock:   nil,
+		Topics:    [][]common.Hash{{common.HexToHash(extensionContracts.StateSharedTopicHash)}},
+		Addresses: []common.Address{},
+	}
 )
 
 type ExtensionContract struct {
@@ -37,4 +53,76 @@ type ExtensionContract struct {
 	ManagementContractAddress common.Address `json:"managementContractAddress"`
 	RecipientPtmKey           string         `json:"recipientPtmKey"`
 	CreationData              []byte         `json:"creationData"`
+	// Dependencies are the private contracts extended together with ContractExtended, only known
+	// to the node which initiated the extension
+	Dependencies []common.Address `json:"dependencies,omitempty"`
+}
+
+// ExtensionDependencies are the dependencies of an extension initiated by this node. They are
+// persisted before the management contract of the extension is deployed, and matched with the
+// extension by the contract extended and the recipient once its creation is seen.
+type ExtensionDependencies struct {
+	ContractExtended common.Address   `json:"contractExtended"`
+	Recipient        common.Address   `json:"recipient"`
+	RecipientPtmKey  string           `json:"recipientPtmKey"`
+	Dependencies     []common.Address `json:"dependencies"`
+}
+
+// matches reports whether the dependencies are the ones of the extension
+func (d *ExtensionDependencies) matches(contractExtended, recipient common.Address, recipientPtmKey string) bool {
+	return d.ContractExtended == contractExtended && d.Recipient == recipient && d.RecipientPtmKey == recipientPtmKey
+}
+
+// ExtensionEventType is the stage of the lifecycle of an extension notified to the subscribers
+// of extension events
+type ExtensionEventType string
+
+const (
+	ExtensionCreated      ExtensionEventType = "created"
+	ExtensionVoted        ExtensionEventType = "voted"
+	ExtensionCancelled    ExtensionEventType = "cancelled"
+	ExtensionCompleted    ExtensionEventType = "completed"
+	ExtensionStateApplied ExtensionEventType = "stateApplied"
+)
+
+// ExtensionEvent is a lifecycle event of an extension, as seen in a private state
+type ExtensionEvent struct {
+	Type                      ExtensionEventType `json:"type"`
+	ManagementContractAddress common.Address     `json:"managementContractAddress"`
+	ContractExtended          common.Address     `json:"contractExtended"`
+	BlockNumber               uint64             `json:"blockNumber"`
+	BlockHash                 common.Hash        `json:"blockHash"`
+	TxHash                    common.Hash        `json:"transactionHash"`
+	// Voter and Vote are only set for voted events
+	Voter *common.Address `json:"voter,omitempty"`
+	Vote  *bool           `json:"vote,omitempty"`
+
+	psi types.PrivateStateIdentifier
+}
+
+// ExtensionPreview is the state shared with the recipient of an extension: the payload shared by
+// the initiator once the extension completed, or else the state which would be shared if the
+// extension was completed at the given block
+type ExtensionPreview struct {
+	ManagementContractAddress common.Address             `json:"managementContractAddress"`
+	ContractExtended          common.Address             `json:"contractExtended"`
+	BlockNumber               uint64                     `json:"blockNumber,omitempty"`
+	BlockHash                 common.Hash                `json:"blockHash,omitempty"`
+	Accounts                  []*ExtensionPreviewAccount `json:"accounts"`
+	// Shared is true if the accounts are the ones of the payload shared by the initiator, which
+	// are the ones set by the recipient, rather than the ones of the local state at the block
+	Shared bool `json:"shared"`
+	// StorageSlots is the total number of storage slots shared
+	StorageSlots int `json:"storageSlots"`
+	// Size is the size in bytes of the state payload sent to the transaction manager
+	Size int `json:"size"`
+}
+
+// ExtensionPreviewAccount is the state of one of the extended contracts
+type ExtensionPreviewAccount struct {
+	Address      common.Address         `json:"address"`
+	Code         hexutil.Bytes          `json:"code"`
+	CodeSize     int                    `json:"codeSize"`
+	Storage      map[common.Hash]string `json:"storage"`
+	StorageSlots int                    `json:"storageSlots"`
 }
@@ -27,11 +27,13 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/eth/filters"
 	"github.com/ethereum/go-ethereum/internal/ethapi"
 	"github.com/ethereum/go-ethereum/private"
+	"github.com/ethereum/go-ethereum/private/engine"
 	"github.com/ethereum/go-ethereum/rpc"
 )
 
@@ -1238,7 +1240,7 @@ func (t *Transaction) PrivateTransaction(ctx context.Context) (*Transaction, err
 		return nil, nil
 	}
 
-	pvtTx, _, _, err := private.FetchPrivateTransaction(tx.Data())
+	pvtTx, managedParties, _, err := private.FetchPrivateTransaction(tx.Data())
 	if err != nil {
 		return nil, err
 	}
@@ -1247,6 +1249,15 @@ func (t *Transaction) PrivateTransaction(ctx context.Context) (*Transaction, err
 		return nil, nil
 	}
 
+	// the private transaction is only visible to the private states of its parties
+	psm, err := t.backend.PSMR().ResolveForUserContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if t.backend.PSMR().NotIncludeAny(psm, managedParties...) {
+		return nil, nil
+	}
+
 	return &Transaction{
 		backend:       t.backend,
 		hash:          t.hash,
@@ -1289,3 +1300,70 @@ func (t *Transaction) PrivateInputData(ctx context.Context) (*hexutil.Bytes, err
 	}
 	return &hexutil.Bytes{}, nil
 }
+
+// IsPrivate returns whether the account exists in the private state of the caller
+func (a *Account) IsPrivate(ctx context.Context) (bool, error) {
+	state, err := a.getState(ctx)
+	if err != nil {
+		return false, err
+	}
+	if _, err := state.GetManagedParties(a.address); err != nil {
+		if errors.Is(err, common.ErrNotPrivateContract) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// PrivacyMetadata returns the privacy metadata of the account if it is a private contract
+func (a *Account) PrivacyMetadata(ctx context.Context) (*PrivacyMetadata, error) {
+	stateDB, err := a.getState(ctx)
+	if err != nil {
+		return nil, err
+	}
+	privacyMetadata, err := stateDB.GetPrivacyMetadata(a.address)
+	if err != nil {
+		if errors.Is(err, common.ErrNotPrivateContract) || errors.Is(err, common.ErrNoAccountExtraData) || errors.Is(err, state.ErrNoPrivacyMetadata) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if privacyMetadata == nil {
+		return nil, nil
+	}
+	return &PrivacyMetadata{privacyMetadata}, nil
+}
+
+// PrivacyMetadata represents the privacy metadata of a private contract
+type PrivacyMetadata struct {
+	privacyMetadata *state.PrivacyMetadata
+}
+
+func (m *PrivacyMetadata) CreationTxHash() string {
+	return m.privacyMetadata.CreationTxHash.ToBase64()
+}
+
+func (m *PrivacyMetadata) PrivacyFlag() int32 {
+	return int32(m.privacyMetadata.PrivacyFlag)
+}
+
+func (m *PrivacyMetadata) MandatoryFor() (*[]string, error) {
+	if m.privacyMetadata.PrivacyFlag != engine.PrivacyFlagMandatoryRecipients {
+		return nil, nil
+	}
+	mandatoryRecipients, err := private.P.GetMandatory(m.privacyMetadata.CreationTxHash)
+	if err != nil {
+		return nil, err
+	}
+	return &mandatoryRecipients, nil
+}
+
+// PrivateStateIdentifier returns the identifier of the private state of the caller
+func (r *Resolver) PrivateStateIdentifier(ctx context.Context) (string, error) {
+	psm, err := r.backend.PSMR().ResolveForUserContext(ctx)
+	if err != nil {
+		return "", err
+	}
+	return psm.ID.String(), nil
+}
@@ -35,6 +35,8 @@ import (
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/bloombits"
 	"github.com/ethereum/go-ethereum/core/mps"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/crypto"
@@ -51,6 +53,7 @@ import (
 	"github.com/ethereum/go-ethereum/private/engine"
 	"github.com/ethereum/go-ethereum/private/engine/notinuse"
 	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/golang/mock/gomock"
 	"github.com/jpmorganchase/quorum-security-plugin-sdk-go/proto"
 	"github.com/stretchr/testify/assert"
 	"golang.org/x/crypto/sha3"
@@ -579,6 +582,134 @@ func TestQuorumTransaction_getReceipt_defaultReceiptGetter(t *testing.T) {
 	}
 }
 
+func TestQuorumSchema_PrivacyMarkerTransaction_whenNotParty(t *testing.T) {
+	saved := private.P
+	defer func() {
+		private.P = saved
+	}()
+
+	privateTx := types.NewTransaction(1, common.Address{}, big.NewInt(0), 0, big.NewInt(0), nil)
+	privateTx.SetPrivate()
+	_, r, s := privateTx.RawSignatureValues()
+	r.SetUint64(10)
+	s.SetUint64(10)
+	privateTxByt, _ := json.Marshal(privateTx)
+
+	encryptedPrivateTxHashByt := sha3.Sum512([]byte("encrypted pvt tx hash"))
+	encryptedPrivateTxHash := common.BytesToEncryptedPayloadHash(encryptedPrivateTxHashByt[:])
+	private.P = &stubPrivateTransactionManager{
+		responses: map[common.EncryptedPayloadHash]ptmResponse{
+			encryptedPrivateTxHash: {
+				body: privateTxByt,
+				err:  nil},
+		},
+	}
+
+	privacyMarkerTx := types.NewTransaction(0, common.QuorumPrivacyPrecompileContractAddress(), big.NewInt(0), 0, big.NewInt(0), encryptedPrivateTxHash.Bytes())
+
+	pmtQuery := &Transaction{tx: privacyMarkerTx, backend: &nonPartyStubBackend{}}
+	internalPrivateTxQuery, err := pmtQuery.PrivateTransaction(context.Background())
+
+	assert.NoError(t, err)
+	assert.Nil(t, internalPrivateTxQuery, "the private transaction must not be visible to a non party private state")
+}
+
+func TestQuorumAccount_PrivateContract(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	saved := private.P
+	defer func() {
+		private.P = saved
+	}()
+	mockTM := private.NewMockPrivateTransactionManager(mockCtrl)
+	private.P = mockTM
+
+	contractAddress := common.HexToAddress("0x1")
+	creationTxHash := common.BytesToEncryptedPayloadHash([]byte("creation tx hash"))
+	mockState := vm.NewMockMinimalApiState(mockCtrl)
+	mockState.EXPECT().GetManagedParties(contractAddress).Return([]string{"A"}, nil)
+	mockState.EXPECT().GetPrivacyMetadata(contractAddress).Return(&state.PrivacyMetadata{
+		CreationTxHash: creationTxHash,
+		PrivacyFlag:    engine.PrivacyFlagMandatoryRecipients,
+	}, nil)
+	mockTM.EXPECT().GetMandatory(creationTxHash).Return([]string{"B"}, nil)
+
+	account := &Account{
+		backend:       &stateStubBackend{state: mockState},
+		address:       contractAddress,
+		blockNrOrHash: rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber),
+	}
+	isPrivate, err := account.IsPrivate(context.Background())
+	assert.NoError(t, err)
+	assert.True(t, isPrivate)
+
+	privacyMetadata, err := account.PrivacyMetadata(context.Background())
+	assert.NoError(t, err)
+	if assert.NotNil(t, privacyMetadata) {
+		assert.Equal(t, creationTxHash.ToBase64(), privacyMetadata.CreationTxHash())
+		assert.Equal(t, int32(engine.PrivacyFlagMandatoryRecipients), privacyMetadata.PrivacyFlag())
+		mandatoryFor, err := privacyMetadata.MandatoryFor()
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"B"}, *mandatoryFor)
+	}
+}
+
+func TestQuorumAccount_StandardPrivateContract(t *testing.T) {
+	statedb, err := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	contractAddress := common.HexToAddress("0x1")
+	statedb.SetCode(contractAddress, []byte{0x00})
+	statedb.SetManagedParties(contractAddress, []string{"A"})
+
+	account := &Account{
+		backend:       &stateStubBackend{state: statedb},
+		address:       contractAddress,
+		blockNrOrHash: rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber),
+	}
+	isPrivate, err := account.IsPrivate(context.Background())
+	assert.NoError(t, err)
+	assert.True(t, isPrivate)
+
+	privacyMetadata, err := account.PrivacyMetadata(context.Background())
+	assert.NoError(t, err, "expected a contract without privacy metadata to have none")
+	assert.Nil(t, privacyMetadata)
+}
+
+func TestQuorumAccount_PublicAccount(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	address := common.HexToAddress("0x1")
+	mockState := vm.NewMockMinimalApiState(mockCtrl)
+	mockState.EXPECT().GetManagedParties(address).Return(nil, common.ErrNotPrivateContract)
+	mockState.EXPECT().GetPrivacyMetadata(address).Return(nil, fmt.Errorf("%x: %w", address, common.ErrNotPrivateContract))
+
+	account := &Account{
+		backend:       &stateStubBackend{state: mockState},
+		address:       address,
+		blockNrOrHash: rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber),
+	}
+	isPrivate, err := account.IsPrivate(context.Background())
+	assert.NoError(t, err)
+	assert.False(t, isPrivate)
+
+	privacyMetadata, err := account.PrivacyMetadata(context.Background())
+	assert.NoError(t, err)
+	assert.Nil(t, privacyMetadata)
+}
+
+func TestQuorumResolver_PrivateStateIdentifier(t *testing.T) {
+	r := &Resolver{backend: &StubBackend{}}
+
+	psi, err := r.PrivateStateIdentifier(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, types.DefaultPrivateStateIdentifier.String(), psi)
+}
+
 type ptmResponse struct {
 	body []byte
 	err  error
@@ -806,6 +937,10 @@ func (sb *StubBackend) IsPrivacyMarkerTransactionCreationEnabled() bool {
 	panic("implement me")
 }
 
+func (sb *StubBackend) PrivacyMarkerBatcher() *ethapi.PrivacyMarkerBatcher {
+	return nil
+}
+
 func (sb *StubBackend) UnprotectedAllowed() bool {
 	panic("implement me")
 }
@@ -825,3 +960,30 @@ func (psmr *StubPSMR) PSIs() []types.PrivateStateIdentifier {
 func (psmr *StubPSMR) NotIncludeAny(psm *mps.PrivateStateMetadata, managedParties ...string) bool {
 	return false
 }
+
+// nonPartyStubBackend resolves a private state which is not a party to any private transaction
+type nonPartyStubBackend struct {
+	StubBackend
+}
+
+func (sb *nonPartyStubBackend) PSMR() mps.PrivateStateMetadataResolver {
+	return &nonPartyStubPSMR{}
+}
+
+type nonPartyStubPSMR struct {
+	StubPSMR
+}
+
+func (psmr *nonPartyStubPSMR) NotIncludeAny(psm *mps.PrivateStateMetadata, managedParties ...string) bool {
+	return true
+}
+
+// stateStubBackend returns the given state for any block
+type stateStubBackend struct {
+	StubBackend
+	state vm.MinimalApiState
+}
+
+func (sb *stateStubBackend) StateAndHeaderByNumberOrHash(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) (vm.MinimalApiState, *types.Header, error) {
+	return sb.state, &types.Header{}, nil
+}
@@ -52,6 +52,24 @@ const schema string = `
         # Storage provides access to the storage of a contract account, indexed
         # by its 32 byte slot identifier.
         storage(slot: Bytes32!): Bytes32!
+        # IsPrivate is an indicator of Quorum private account, which exists in
+        # the private state of the caller. Balance, code and storage are then
+        # read from the private state.
+        isPrivate: Boolean!
+        # PrivacyMetadata is the privacy metadata of a Quorum private contract
+        privacyMetadata: PrivacyMetadata
+    }
+
+    # PrivacyMetadata is the privacy metadata of a Quorum private contract.
+    type PrivacyMetadata {
+        # CreationTxHash is the hash of the encrypted payload of the transaction
+        # which created the contract, represented in base64.
+        creationTxHash: String!
+        # PrivacyFlag is the privacy flag of the contract.
+        privacyFlag: Int!
+        # MandatoryFor is the list of the mandatory recipients of the contract
+        # when its privacy flag is 2 (mandatory recipients).
+        mandatoryFor: [String!]
     }
 
     # Log is an Ethereum event log.
@@ -330,6 +348,10 @@ const schema string = `
         syncing: SyncState
         # ChainID returns the current chain ID for transaction replay protection.
         chainID: BigInt!
+        # PrivateStateIdentifier returns the identifier of the Quorum private
+        # state of the caller, which private accounts, receipts and logs are
+        # read from.
+        privateStateIdentifier: String!
     }
 
     type Mutation {
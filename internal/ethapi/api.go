@@ -41,7 +41,7 @@ import (
 	"github.com/ethereum/go-ethereum/common/math"
 	"github.com/ethereum/go-ethereum/consensus/clique"
 	"github.com/ethereum/go-ethereum/core"
-	"github.com/ethereum/go-ethereum/core/mps"
+	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/core/vm"
@@ -444,6 +444,13 @@ func (s *PrivateAccountAPI) SendTransaction(ctx context.Context, args SendTxArgs
 		defer s.nonceLock.UnlockAddr(args.From)
 	}
 
+	// Quorum
+	batcher, err := setPrivacyMarkerBatchNonce(ctx, s.b, &args)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	// /Quorum
+
 	// Set some sanity defaults and terminate on failure
 	if err := args.setDefaults(ctx, s.b); err != nil {
 		return common.Hash{}, err
@@ -467,6 +474,18 @@ func (s *PrivateAccountAPI) SendTransaction(ctx context.Context, args SendTxArgs
 	}
 
 	// Quorum
+	if batcher != nil && signed.IsPrivate() {
+		account := accounts.Account{Address: args.From}
+		wallet, err := s.am.Find(account)
+		if err != nil {
+			return common.Hash{}, err
+		}
+		authorization, err := wallet.SignTextWithPassphrase(account, passwd, types.PrivacyMarkerBatchAuthorization(s.b.ChainConfig().ChainID, signed))
+		if err != nil {
+			return common.Hash{}, err
+		}
+		return batcher.Add(ctx, signed, authorization, &args.PrivateTxArgs)
+	}
 	if signed.IsPrivate() && s.b.IsPrivacyMarkerTransactionCreationEnabled() {
 		// Look up the wallet containing the requested signer
 		account := accounts.Account{Address: args.From}
@@ -873,6 +892,8 @@ func (s *PublicBlockChainAPI) GetStorageAt(ctx context.Context, address common.A
 
 // CallArgs represents the arguments for a call.
 type CallArgs struct {
+	PrivateTxArgs // Quorum
+
 	From       *common.Address   `json:"from"`
 	To         *common.Address   `json:"to"`
 	Gas        *hexutil.Uint64   `json:"gas"`
@@ -882,6 +903,12 @@ type CallArgs struct {
 	AccessList *types.AccessList `json:"accessList"`
 }
 
+// Quorum
+// IsPrivate returns true if the call simulates a private transaction
+func (args *CallArgs) IsPrivate() bool {
+	return args.PrivateFor != nil
+}
+
 // ToMessage converts CallArgs to the Message type used by the core evm
 func (args *CallArgs) ToMessage(globalGasCap uint64) types.Message {
 	// Set sender address or use zero address if none specified.
@@ -979,6 +1006,25 @@ func (diff *StateOverride) Apply(state *state.StateDB) error {
 // Before returning the result, we need to inspect the EVM and
 // perform verification check
 func DoCall(ctx context.Context, b Backend, args CallArgs, blockNrOrHash rpc.BlockNumberOrHash, overrides *StateOverride, vmCfg vm.Config, timeout time.Duration, globalGasCap uint64) (*core.ExecutionResult, error) {
+	// Quorum
+	var (
+		privateTx *types.Transaction
+		metadata  *engine.ExtraMetadata
+	)
+	if args.IsPrivate() {
+		var err error
+		privateTx, metadata, err = simulatePrivateCall(ctx, b, args, globalGasCap)
+		if err != nil {
+			return nil, err
+		}
+	}
+	// End Quorum
+	return doCall(ctx, b, args, blockNrOrHash, overrides, vmCfg, timeout, globalGasCap, privateTx, metadata)
+}
+
+// doCall executes the call, as the private transaction privateTx with the given privacy metadata if
+// privateTx is set
+func doCall(ctx context.Context, b Backend, args CallArgs, blockNrOrHash rpc.BlockNumberOrHash, overrides *StateOverride, vmCfg vm.Config, timeout time.Duration, globalGasCap uint64, privateTx *types.Transaction, metadata *engine.ExtraMetadata) (*core.ExecutionResult, error) {
 	defer func(start time.Time) { log.Debug("Executing EVM call finished", "runtime", time.Since(start)) }(time.Now())
 
 	state, header, err := b.StateAndHeaderByNumberOrHash(ctx, blockNrOrHash)
@@ -1002,10 +1048,20 @@ func DoCall(ctx context.Context, b Backend, args CallArgs, blockNrOrHash rpc.Blo
 
 	// Get a new instance of the EVM.
 	msg := args.ToMessage(globalGasCap)
+	// Quorum
+	if privateTx != nil {
+		msg = msg.WithSimulatedPrivacyMetadata(metadata)
+	}
+	// End Quorum
 	evm, vmError, err := b.GetEVM(ctx, msg, state, header, nil)
 	if err != nil {
 		return nil, err
 	}
+	// Quorum
+	if privateTx != nil {
+		evm.SetCurrentTX(privateTx)
+	}
+	// End Quorum
 	// Wait for the context to be done and cancel the evm. Even if the
 	// EVM has finished, cancelling may be done (repeatedly)
 	go func() {
@@ -1030,6 +1086,45 @@ func DoCall(ctx context.Context, b Backend, args CallArgs, blockNrOrHash rpc.Blo
 	return result, nil
 }
 
+// Quorum
+// simulatePrivateCall validates the privacy parameters of a call simulating a private transaction, and
+// returns the private transaction and the privacy metadata the private transaction manager would return
+// for it. The metadata is computed as when the transaction is sent, by a simulated execution finding the
+// affected contracts, so that the call runs the privacy enhancements checks of the block processing.
+func simulatePrivateCall(ctx context.Context, b Backend, args CallArgs, globalGasCap uint64) (*types.Transaction, *engine.ExtraMetadata, error) {
+	if args.Value != nil && args.Value.ToInt().Sign() != 0 {
+		return nil, nil, core.ErrEtherValueUnsupported
+	}
+	if err := args.SetDefaultPrivateFrom(ctx, b); err != nil {
+		return nil, nil, err
+	}
+	// the affected contracts are found with the highest gas allowance, as when the transaction is sent
+	args.Gas = nil
+	msg := args.ToMessage(globalGasCap)
+	if err := validatePrivateTxArgs(ctx, b, msg.To(), msg.Data(), &args.PrivateTxArgs); err != nil {
+		return nil, nil, err
+	}
+
+	var tx *types.Transaction
+	if msg.To() == nil {
+		tx = types.NewContractCreation(msg.Nonce(), msg.Value(), msg.Gas(), msg.GasPrice(), msg.Data())
+	} else {
+		tx = types.NewTransaction(msg.Nonce(), *msg.To(), msg.Value(), msg.Gas(), msg.GasPrice(), msg.Data())
+	}
+	tx.SetPrivate()
+
+	affectedCATxHashes, merkleRoot, err := simulateExecutionForPE(ctx, b, msg.From(), tx, &args.PrivateTxArgs)
+	if err != nil {
+		return nil, nil, err
+	}
+	return tx, &engine.ExtraMetadata{
+		ACHashes:            affectedCATxHashes,
+		ACMerkleRoot:        merkleRoot,
+		PrivacyFlag:         args.PrivacyFlag,
+		MandatoryRecipients: args.MandatoryRecipients,
+	}, nil
+}
+
 func newRevertError(result *core.ExecutionResult) *revertError {
 	reason, errUnpack := abi.UnpackRevert(result.Revert())
 	err := errors.New("execution reverted")
@@ -1145,11 +1240,26 @@ func DoEstimateGas(ctx context.Context, b Backend, args CallArgs, blockNrOrHash
 	}
 	cap = hi
 
+	// Quorum
+	// the privacy metadata of a private transaction does not depend on its gas allowance, so the
+	// simulation finding the affected contracts is run once rather than for every allowance tried
+	var (
+		privateTx *types.Transaction
+		metadata  *engine.ExtraMetadata
+	)
+	if args.IsPrivate() {
+		var err error
+		if privateTx, metadata, err = simulatePrivateCall(ctx, b, args, gasCap); err != nil {
+			return 0, err
+		}
+	}
+	// End Quorum
+
 	// Create a helper to check if a gas allowance results in an executable transaction
 	executable := func(gas uint64) (bool, *core.ExecutionResult, error) {
 		args.Gas = (*hexutil.Uint64)(&gas)
 
-		result, err := DoCall(ctx, b, args, blockNrOrHash, nil, vm.Config{}, 0, gasCap)
+		result, err := doCall(ctx, b, args, blockNrOrHash, nil, vm.Config{}, 0, gasCap, privateTx, metadata)
 		if err != nil {
 			if errors.Is(err, core.ErrIntrinsicGas) {
 				return true, nil, nil // Special case, raise gas limit
@@ -1201,6 +1311,26 @@ func DoEstimateGas(ctx context.Context, b Backend, args CallArgs, blockNrOrHash
 	//still run
 	//This makes the return value a potential over-estimate of gas, rather than the exact cost to run right now
 
+	//if the call simulates a private transaction, the PTM hash has been accounted for in the execution,
+	//and it is sent in a privacy marker transaction if they are enabled, whose gas is added
+	if args.IsPrivate() {
+		if !b.IsPrivacyMarkerTransactionCreationEnabled() {
+			return hexutil.Uint64(hi), nil
+		}
+		var accessList types.AccessList
+		if args.AccessList != nil {
+			accessList = *args.AccessList
+		}
+		pmtGas, err := privacyMarkerTransactionGas(b, common.Hex2Bytes(common.MaxPrivateIntrinsicDataHex), accessList)
+		if err != nil {
+			return 0, err
+		}
+		if math.MaxUint64-hi < pmtGas {
+			return 0, fmt.Errorf("privacy marker transaction gas addition exceeds allowance")
+		}
+		return hexutil.Uint64(hi + pmtGas), nil
+	}
+
 	//if the transaction has a value then it cannot be private, so we can skip this check
 	if args.Value != nil && args.Value.ToInt().Cmp(big.NewInt(0)) == 0 {
 		currentBlockHeight := b.CurrentHeader().Number
@@ -1658,6 +1788,14 @@ func (s *PublicTransactionPoolAPI) GetRawTransactionByBlockHashAndIndex(ctx cont
 func (s *PublicTransactionPoolAPI) GetTransactionCount(ctx context.Context, address common.Address, blockNrOrHash rpc.BlockNumberOrHash) (*hexutil.Uint64, error) {
 	// Ask transaction pool for the nonce which includes pending transactions
 	if blockNr, ok := blockNrOrHash.Number(); ok && blockNr == rpc.PendingBlockNumber {
+		// Quorum - include the batched private transactions, which are not in the transaction pool
+		if batcher := s.b.PrivacyMarkerBatcher(); batcher != nil {
+			nonce, err := batcher.Nonce(ctx, address)
+			if err != nil {
+				return nil, err
+			}
+			return (*hexutil.Uint64)(&nonce), nil
+		}
 		nonce, err := s.b.GetPoolNonce(ctx, address)
 		if err != nil {
 			return nil, err
@@ -1783,6 +1921,9 @@ func getTransactionReceiptCommonCode(tx *types.Transaction, blockHash common.Has
 	if len(receipt.RevertReason) > 0 {
 		fields["revertReason"] = hexutil.Encode(receipt.RevertReason)
 	}
+	if receipt.PrivacyVerificationFailure != nil {
+		fields["privacyVerificationFailure"] = receipt.PrivacyVerificationFailure
+	}
 	// End Quorum
 
 	// Assign receipt status or post state.
@@ -1803,7 +1944,8 @@ func getTransactionReceiptCommonCode(tx *types.Transaction, blockHash common.Has
 
 // Quorum
 // GetPrivateTransactionByHash accepts the hash for a privacy marker transaction,
-// but returns the associated private transaction
+// but returns the associated private transaction. It also accepts the hash of a private
+// transaction of a batch carried by a privacy marker transaction.
 func (s *PublicTransactionPoolAPI) GetPrivateTransactionByHash(ctx context.Context, hash common.Hash) (*RPCTransaction, error) {
 	if !private.IsQuorumPrivacyEnabled() {
 		return nil, fmt.Errorf("PrivateTransactionManager is not enabled")
@@ -1814,14 +1956,14 @@ func (s *PublicTransactionPoolAPI) GetPrivateTransactionByHash(ctx context.Conte
 	}
 
 	// first need the privacy marker transaction
-	pmt, blockHash, blockNumber, index, err := s.b.GetTransaction(ctx, hash)
+	pmt, batchedTxHash, blockHash, blockNumber, index, err := s.getPrivacyMarkerTransaction(ctx, hash)
 	if err != nil {
 		return nil, err
 	}
 
 	// now retrieve the private transaction
 	if pmt != nil {
-		tx, managedParties, _, err := private.FetchPrivateTransaction(pmt.Data())
+		tx, managedParties, err := fetchPrivateTransaction(pmt, batchedTxHash)
 		if err != nil {
 			return nil, err
 		}
@@ -1836,10 +1978,11 @@ func (s *PublicTransactionPoolAPI) GetPrivateTransactionByHash(ctx context.Conte
 
 // Quorum
 // GetPrivateTransactionReceipt accepts the hash for a privacy marker transaction,
-// but returns the receipt of the associated private transaction
+// but returns the receipt of the associated private transaction. It also accepts the hash
+// of a private transaction of a batch carried by a privacy marker transaction.
 func (s *PublicTransactionPoolAPI) GetPrivateTransactionReceipt(ctx context.Context, hash common.Hash) (map[string]interface{}, error) {
 	// first need the privacy marker transaction
-	pmt, blockHash, blockNumber, index, err := s.b.GetTransaction(ctx, hash)
+	pmt, batchedTxHash, blockHash, blockNumber, index, err := s.getPrivacyMarkerTransaction(ctx, hash)
 	if err != nil {
 		return nil, err
 	}
@@ -1849,7 +1992,7 @@ func (s *PublicTransactionPoolAPI) GetPrivateTransactionReceipt(ctx context.Cont
 	}
 
 	// now retrieve the private transaction
-	tx, _, _, err := private.FetchPrivateTransaction(pmt.Data())
+	tx, _, err := fetchPrivateTransaction(pmt, batchedTxHash)
 	if err != nil {
 		return nil, err
 	}
@@ -1874,6 +2017,18 @@ func (s *PublicTransactionPoolAPI) GetPrivateTransactionReceipt(ctx context.Cont
 		return nil, err
 	}
 	receipt := pmtReceipt.PSReceipts[psm.ID]
+	if receipt != nil && batchedTxHash != (common.Hash{}) {
+		// the receipt of the private transaction among the receipts of the batch
+		batchReceipt := receipt
+		receipt = nil
+		for _, r := range batchReceipt.BatchReceipts {
+			if r.TxHash == batchedTxHash {
+				receipt = r
+				break
+			}
+		}
+		hash = batchedTxHash
+	}
 	if receipt == nil {
 		return nil, errors.New("could not find receipt for private transaction")
 	}
@@ -1881,6 +2036,107 @@ func (s *PublicTransactionPoolAPI) GetPrivateTransactionReceipt(ctx context.Cont
 	return getTransactionReceiptCommonCode(tx, blockHash, blockNumber, hash, index, receipt)
 }
 
+// Quorum
+// getPrivacyMarkerTransaction returns the privacy marker transaction with the given hash, or the privacy
+// marker transaction carrying the batched private transaction with the given hash, in which case the hash
+// is also returned as the hash of the batched private transaction
+func (s *PublicTransactionPoolAPI) getPrivacyMarkerTransaction(ctx context.Context, hash common.Hash) (pmt *types.Transaction, batchedTxHash common.Hash, blockHash common.Hash, blockNumber uint64, index uint64, err error) {
+	pmt, blockHash, blockNumber, index, err = s.b.GetTransaction(ctx, hash)
+	if err != nil || pmt != nil {
+		return
+	}
+	pmtHash, ok := rawdb.ReadPrivateBatchTxLookup(s.b.ChainDb(), hash)
+	if !ok {
+		return
+	}
+	pmt, blockHash, blockNumber, index, err = s.b.GetTransaction(ctx, pmtHash)
+	return pmt, hash, blockHash, blockNumber, index, err
+}
+
+// Quorum
+// fetchPrivateTransaction retrieves the private transaction associated with the privacy marker transaction,
+// or the private transaction with the given hash of the batch carried by the privacy marker transaction
+func fetchPrivateTransaction(pmt *types.Transaction, batchedTxHash common.Hash) (*types.Transaction, []string, error) {
+	if batchedTxHash == (common.Hash{}) {
+		tx, managedParties, _, err := private.FetchPrivateTransaction(pmt.Data())
+		return tx, managedParties, err
+	}
+	txs, managedParties, _, err := private.FetchPrivateTransactions(pmt.Data())
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, tx := range txs {
+		if tx.Hash() == batchedTxHash {
+			return tx, managedParties, nil
+		}
+	}
+	return nil, nil, nil
+}
+
+// Quorum
+// GetPrivateTransactionBatchReceipts accepts the hash for a privacy marker transaction carrying a batch
+// of private transactions, and returns the receipts of the private transactions of the batch, in order
+func (s *PublicTransactionPoolAPI) GetPrivateTransactionBatchReceipts(ctx context.Context, hash common.Hash) ([]map[string]interface{}, error) {
+	// first need the privacy marker transaction
+	pmt, blockHash, blockNumber, index, err := s.b.GetTransaction(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+	if pmt == nil {
+		// Transaction unknown, return as such
+		return nil, errors.New("privacy marker transaction not found")
+	}
+	if !pmt.IsPrivacyMarkerBatch() {
+		return nil, errors.New("not a privacy marker transaction batch")
+	}
+
+	// now retrieve the private transactions
+	txs, _, _, err := private.FetchPrivateTransactions(pmt.Data())
+	if err != nil {
+		return nil, err
+	}
+	// Transactions not found, or not a participant in the private transactions, return as such
+	if txs == nil {
+		return nil, errors.New("private transactions not found for this participant")
+	}
+
+	// get receipt for the privacy marker transaction
+	receipts, err := s.b.GetReceipts(ctx, blockHash)
+	if err != nil {
+		return nil, err
+	}
+	if len(receipts) <= int(index) {
+		return nil, errors.New("could not find receipt for private transactions")
+	}
+	psm, err := s.b.PSMR().ResolveForUserContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	receipt := receipts[index].PSReceipts[psm.ID]
+	if receipt == nil {
+		return nil, errors.New("could not find receipt for private transactions")
+	}
+
+	// the private state has a receipt for each private transaction of the batch it is a party to
+	txsByHash := make(map[common.Hash]*types.Transaction, len(txs))
+	for _, tx := range txs {
+		txsByHash[tx.Hash()] = tx
+	}
+	fields := make([]map[string]interface{}, 0, len(receipt.BatchReceipts))
+	for _, batchReceipt := range receipt.BatchReceipts {
+		tx, ok := txsByHash[batchReceipt.TxHash]
+		if !ok {
+			return nil, fmt.Errorf("could not find private transaction %s of the batch", batchReceipt.TxHash.Hex())
+		}
+		receiptFields, err := getTransactionReceiptCommonCode(tx, blockHash, blockNumber, batchReceipt.TxHash, index, batchReceipt)
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, receiptFields)
+	}
+	return fields, nil
+}
+
 // Quorum: if signing a private TX, set with tx.SetPrivate() before calling this method.
 // sign is a helper function that signs a transaction with the private key of the given address.
 func (s *PublicTransactionPoolAPI) sign(addr common.Address, tx *types.Transaction) (*types.Transaction, error) {
@@ -2114,37 +2370,8 @@ func SubmitTransaction(ctx context.Context, b Backend, tx *types.Transaction, pr
 		return common.Hash{}, err
 	}
 	// Quorum
-	// Need to do authorization check for Ethereum Account being used in signing.
-	// We only care about private transactions (or the private transaction relating to a privacy marker)
-	if token, ok := b.SupportsMultitenancy(ctx); ok {
-		tx := tx
-		// If we are sending a Privacy Marker Transaction, then get the private txn details
-		if tx.IsPrivacyMarker() {
-			tx, _, _, err = private.FetchPrivateTransaction(tx.Data())
-			if err != nil {
-				return common.Hash{}, err
-			}
-		}
-		innerFrom, err := types.Sender(signer, tx)
-		if err != nil {
-			return common.Hash{}, err
-		}
-
-		if tx.IsPrivate() {
-			psm, err := b.PSMR().ResolveForUserContext(ctx)
-			if err != nil {
-				return common.Hash{}, err
-			}
-			eoaSecAttr := (&multitenancy.PrivateStateSecurityAttribute{}).WithPSI(psm.ID).WithSelfEOAIf(isRaw, innerFrom)
-			psm, err = b.PSMR().ResolveForManagedParty(privateFrom)
-			if err != nil {
-				return common.Hash{}, err
-			}
-			privateFromSecAttr := (&multitenancy.PrivateStateSecurityAttribute{}).WithPSI(psm.ID).WithSelfEOAIf(isRaw, innerFrom)
-			if isAuthorized, _ := multitenancy.IsAuthorized(token, eoaSecAttr, privateFromSecAttr); !isAuthorized {
-				return common.Hash{}, multitenancy.ErrNotAuthorized
-			}
-		}
+	if err := authorizePrivateTransaction(ctx, b, tx, signer, privateFrom, isRaw); err != nil {
+		return common.Hash{}, err
 	}
 	if !b.UnprotectedAllowed() && !tx.Protected() {
 		// Ensure only eip155 signed transactions are submitted if EIP155Required is set.
@@ -2165,6 +2392,45 @@ func SubmitTransaction(ctx context.Context, b Backend, tx *types.Transaction, pr
 	return tx.Hash(), nil
 }
 
+// Quorum
+// authorizePrivateTransaction does the authorization check for the Ethereum Account being used in signing.
+// We only care about private transactions (or the private transaction relating to a privacy marker)
+func authorizePrivateTransaction(ctx context.Context, b Backend, tx *types.Transaction, signer types.Signer, privateFrom string, isRaw bool) error {
+	token, ok := b.SupportsMultitenancy(ctx)
+	if !ok {
+		return nil
+	}
+	// If we are sending a Privacy Marker Transaction, then get the private txn details
+	if tx.IsPrivacyMarker() {
+		var err error
+		tx, _, _, err = private.FetchPrivateTransaction(tx.Data())
+		if err != nil {
+			return err
+		}
+	}
+	innerFrom, err := types.Sender(signer, tx)
+	if err != nil {
+		return err
+	}
+
+	if tx.IsPrivate() {
+		psm, err := b.PSMR().ResolveForUserContext(ctx)
+		if err != nil {
+			return err
+		}
+		eoaSecAttr := (&multitenancy.PrivateStateSecurityAttribute{}).WithPSI(psm.ID).WithSelfEOAIf(isRaw, innerFrom)
+		psm, err = b.PSMR().ResolveForManagedParty(privateFrom)
+		if err != nil {
+			return err
+		}
+		privateFromSecAttr := (&multitenancy.PrivateStateSecurityAttribute{}).WithPSI(psm.ID).WithSelfEOAIf(isRaw, innerFrom)
+		if isAuthorized, _ := multitenancy.IsAuthorized(token, eoaSecAttr, privateFromSecAttr); !isAuthorized {
+			return multitenancy.ErrNotAuthorized
+		}
+	}
+	return nil
+}
+
 // runSimulation runs a simulation of the given transaction.
 // It returns the EVM instance upon completion
 func runSimulation(ctx context.Context, b Backend, from common.Address, tx *types.Transaction) (*vm.EVM, []byte, error) {
@@ -2247,6 +2513,13 @@ func (s *PublicTransactionPoolAPI) SendTransaction(ctx context.Context, args Sen
 		defer s.nonceLock.UnlockAddr(args.From)
 	}
 
+	// Quorum
+	batcher, err := setPrivacyMarkerBatchNonce(ctx, s.b, &args)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	// /Quorum
+
 	// Set some sanity defaults and terminate on failure
 	if err := args.setDefaults(ctx, s.b); err != nil {
 		return common.Hash{}, err
@@ -2281,6 +2554,13 @@ func (s *PublicTransactionPoolAPI) SendTransaction(ctx context.Context, args Sen
 		return common.Hash{}, err
 	}
 	// Quorum
+	if batcher != nil && signed.IsPrivate() {
+		authorization, err := wallet.SignText(account, types.PrivacyMarkerBatchAuthorization(s.b.ChainConfig().ChainID, signed))
+		if err != nil {
+			return common.Hash{}, err
+		}
+		return batcher.Add(ctx, signed, authorization, &args.PrivateTxArgs)
+	}
 	if signed.IsPrivate() && s.b.IsPrivacyMarkerTransactionCreationEnabled() {
 		pmt, err := createPrivacyMarkerTransaction(s.b, signed, &args.PrivateTxArgs)
 		if err != nil {
@@ -2648,6 +2928,67 @@ func (api *PublicDebugAPI) PrintBlock(ctx context.Context, number uint64) (strin
 	return spew.Sdump(block), nil
 }
 
+// Quorum
+
+// PrivacyVerificationFailureResult is the failure of the privacy enhancements checks of a private
+// transaction, which is the inner private transaction of PrivacyMarkerTransactionHash if any
+type PrivacyVerificationFailureResult struct {
+	TransactionHash              common.Hash                       `json:"transactionHash"`
+	PrivacyMarkerTransactionHash *common.Hash                      `json:"privacyMarkerTransactionHash,omitempty"`
+	Failure                      *types.PrivacyVerificationFailure `json:"failure"`
+}
+
+// GetPrivacyVerificationFailures returns the private transactions of the block which failed the privacy
+// enhancements checks on the private state of the caller, with the reasons of the failures
+func (api *PublicDebugAPI) GetPrivacyVerificationFailures(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) ([]*PrivacyVerificationFailureResult, error) {
+	block, err := api.b.BlockByNumberOrHash(ctx, blockNrOrHash)
+	if err != nil {
+		return nil, err
+	}
+	if block == nil {
+		return nil, errors.New("block not found")
+	}
+	receipts, err := api.b.GetReceipts(ctx, block.Hash())
+	if err != nil {
+		return nil, err
+	}
+	if len(receipts) != len(block.Transactions()) {
+		return nil, errors.New("could not find receipts for the block")
+	}
+	psm, err := api.b.PSMR().ResolveForUserContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	failures := make([]*PrivacyVerificationFailureResult, 0)
+	for i, tx := range block.Transactions() {
+		receipt := receipts[i]
+		if !tx.IsPrivacyMarker() {
+			if receipt.PrivacyVerificationFailure != nil {
+				failures = append(failures, &PrivacyVerificationFailureResult{TransactionHash: tx.Hash(), Failure: receipt.PrivacyVerificationFailure})
+			}
+			continue
+		}
+		// the receipts of the inner private transactions of a privacy marker transaction
+		privateReceipt := receipt.PSReceipts[psm.ID]
+		if privateReceipt == nil {
+			continue
+		}
+		pmtHash := tx.Hash()
+		for _, innerReceipt := range append([]*types.Receipt{privateReceipt}, privateReceipt.BatchReceipts...) {
+			if innerReceipt.PrivacyVerificationFailure != nil {
+				failures = append(failures, &PrivacyVerificationFailureResult{
+					TransactionHash:              innerReceipt.TxHash,
+					PrivacyMarkerTransactionHash: &pmtHash,
+					Failure:                      innerReceipt.PrivacyVerificationFailure,
+				})
+			}
+		}
+	}
+	return failures, nil
+}
+
+// End Quorum
+
 // PrivateDebugAPI is the collection of Ethereum APIs exposed over the private
 // debugging endpoint.
 type PrivateDebugAPI struct {
@@ -2950,57 +3291,59 @@ func checkAndHandlePrivateTransaction(ctx context.Context, b Backend, tx *types.
 		return
 	}
 
-	if err = privateTxArgs.PrivacyFlag.Validate(); err != nil {
+	if err = validatePrivateTxArgs(ctx, b, tx.To(), tx.Data(), privateTxArgs); err != nil {
 		return
 	}
 
+	if len(tx.Data()) > 0 {
+		replaceDataWithHash = true
+		hash, err = handlePrivateTransaction(ctx, b, tx, privateTxArgs, from, txnType)
+	}
+
+	return
+}
+
+// Quorum
+// validatePrivateTxArgs validates the privacy parameters of a private transaction, or of a call simulating it
+func validatePrivateTxArgs(ctx context.Context, b Backend, to *common.Address, data []byte, privateTxArgs *PrivateTxArgs) error {
+	if err := privateTxArgs.PrivacyFlag.Validate(); err != nil {
+		return err
+	}
+
 	if !b.ChainConfig().IsPrivacyEnhancementsEnabled(b.CurrentBlock().Number()) && privateTxArgs.PrivacyFlag.IsNotStandardPrivate() {
-		err = fmt.Errorf("PrivacyEnhancements are disabled. Can only accept transactions with PrivacyFlag=0(StandardPrivate).")
-		return
+		return fmt.Errorf("PrivacyEnhancements are disabled. Can only accept transactions with PrivacyFlag=0(StandardPrivate).")
 	}
 
 	if engine.PrivacyFlagMandatoryRecipients == privateTxArgs.PrivacyFlag && len(privateTxArgs.MandatoryRecipients) == 0 {
-		err = fmt.Errorf("missing mandatory recipients data. if no mandatory recipients required consider using PrivacyFlag=1(PartyProtection)")
-		return
+		return fmt.Errorf("missing mandatory recipients data. if no mandatory recipients required consider using PrivacyFlag=1(PartyProtection)")
 	}
 
 	if engine.PrivacyFlagMandatoryRecipients != privateTxArgs.PrivacyFlag && len(privateTxArgs.MandatoryRecipients) > 0 {
-		err = fmt.Errorf("privacy metadata invalid. mandatory recipients are only applicable for PrivacyFlag=2(MandatoryRecipients)")
-		return
+		return fmt.Errorf("privacy metadata invalid. mandatory recipients are only applicable for PrivacyFlag=2(MandatoryRecipients)")
 	}
 
 	// validate that PrivateFrom is one of the addresses of the private state resolved from the user context
 	if b.ChainConfig().IsMPS {
-		var psm *mps.PrivateStateMetadata
-		psm, err = b.PSMR().ResolveForUserContext(ctx)
+		psm, err := b.PSMR().ResolveForUserContext(ctx)
 		if err != nil {
-			return
+			return err
 		}
 		if psm.NotIncludeAny(privateTxArgs.PrivateFrom) {
-			err = fmt.Errorf("The PrivateFrom (%s) address does not match the specified private state (%s) ", privateTxArgs.PrivateFrom, psm.ID)
-			return
+			return fmt.Errorf("The PrivateFrom (%s) address does not match the specified private state (%s) ", privateTxArgs.PrivateFrom, psm.ID)
 		}
 	}
 
-	if len(tx.Data()) > 0 {
-		// check private contract exists on the node initiating the transaction
-		if tx.To() != nil && privateTxArgs.PrivacyFlag.IsNotStandardPrivate() {
-			state, _, lerr := b.StateAndHeaderByNumber(ctx, rpc.BlockNumber(b.CurrentBlock().Number().Uint64()))
-			if lerr != nil && state == nil {
-				err = fmt.Errorf("state not found")
-				return
-			}
-			if state.GetCode(*tx.To()) == nil {
-				err = fmt.Errorf("contract not found. cannot transact")
-				return
-			}
+	// check private contract exists on the node initiating the transaction
+	if len(data) > 0 && to != nil && privateTxArgs.PrivacyFlag.IsNotStandardPrivate() {
+		state, _, err := b.StateAndHeaderByNumber(ctx, rpc.BlockNumber(b.CurrentBlock().Number().Uint64()))
+		if err != nil && state == nil {
+			return fmt.Errorf("state not found")
+		}
+		if state.GetCode(*to) == nil {
+			return fmt.Errorf("contract not found. cannot transact")
 		}
-
-		replaceDataWithHash = true
-		hash, err = handlePrivateTransaction(ctx, b, tx, privateTxArgs, from, txnType)
 	}
-
-	return
+	return nil
 }
 
 // Quorum
@@ -3110,6 +3453,28 @@ func handleNormalPrivateTransaction(ctx context.Context, b Backend, tx *types.Tr
 	return
 }
 
+// (Quorum) setPrivacyMarkerBatchNonce sets the nonce of the transaction if not given, taking into account
+// the batched private transactions of the sender which are not in the transaction pool, so that public and
+// private transactions of the sender do not collide. It returns the batcher of privacy marker transactions
+// if the private transaction is to be batched.
+func setPrivacyMarkerBatchNonce(ctx context.Context, b Backend, args *SendTxArgs) (*PrivacyMarkerBatcher, error) {
+	batcher := b.PrivacyMarkerBatcher()
+	if batcher == nil || !b.IsPrivacyMarkerTransactionCreationEnabled() {
+		return nil, nil
+	}
+	if args.Nonce == nil {
+		nonce, err := batcher.Nonce(ctx, args.From)
+		if err != nil {
+			return nil, err
+		}
+		args.Nonce = (*hexutil.Uint64)(&nonce)
+	}
+	if !args.IsPrivate() {
+		return nil, nil
+	}
+	return batcher, nil
+}
+
 // (Quorum) createPrivacyMarkerTransaction creates a new privacy marker transaction (PMT) with the given signed privateTx.
 // The private tx is sent only to the privateFor recipients. The resulting PMT's 'to' is the privacy precompile address and its 'data' is the
 // privacy manager hash for the private tx.
@@ -3127,18 +3492,32 @@ func createPrivacyMarkerTransaction(b Backend, privateTx *types.Transaction, pri
 		return nil, err
 	}
 
-	currentBlockHeight := b.CurrentHeader().Number
-	istanbul := b.ChainConfig().IsIstanbul(currentBlockHeight)
-	intrinsicGas, err := core.IntrinsicGas(ptmHash.Bytes(), privateTx.AccessList(), false, true, istanbul)
+	gas, err := privacyMarkerTransactionGas(b, ptmHash.Bytes(), privateTx.AccessList())
 	if err != nil {
 		return nil, err
 	}
 
-	pmt := types.NewTransaction(privateTx.Nonce(), common.QuorumPrivacyPrecompileContractAddress(), privateTx.Value(), intrinsicGas, privateTx.GasPrice(), ptmHash.Bytes())
+	pmt := types.NewTransaction(privateTx.Nonce(), common.QuorumPrivacyPrecompileContractAddress(), privateTx.Value(), gas, privateTx.GasPrice(), ptmHash.Bytes())
 
 	return pmt, nil
 }
 
+// (Quorum) privacyMarkerTransactionGas returns the gas of a privacy marker transaction with the given data,
+// which is its intrinsic gas and the gas required by the privacy precompile
+func privacyMarkerTransactionGas(b Backend, data []byte, accessList types.AccessList) (uint64, error) {
+	currentBlockHeight := b.CurrentHeader().Number
+	istanbul := b.ChainConfig().IsIstanbul(currentBlockHeight)
+	intrinsicGas, err := core.IntrinsicGas(data, accessList, false, true, istanbul)
+	if err != nil {
+		return 0, err
+	}
+	precompileGas := vm.QuorumPrecompiledContracts[common.QuorumPrivacyPrecompileContractAddress()].RequiredGas(data)
+	if math.MaxUint64-intrinsicGas < precompileGas {
+		return 0, core.ErrGasUintOverflow
+	}
+	return intrinsicGas + precompileGas, nil
+}
+
 // Quorum
 // simulateExecutionForPE simulates execution of a private transaction for enhanced privacy
 //
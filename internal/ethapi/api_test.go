@@ -183,6 +183,58 @@ func TestDoEstimateGas_whenNoValueTx_Istanbul(t *testing.T) {
 	assert.Equal(hexutil.Uint64(22024), estimation, "estimation for a public or private tx")
 }
 
+func TestDoEstimateGas_whenPrivateCall(t *testing.T) {
+	assert := assert.New(t)
+	args := callTxArgs
+	args.PrivateFrom = arbitraryPrivateFrom
+	args.PrivateFor = arbitraryPrivateFor
+	args.PrivacyFlag = engine.PrivacyFlagPartyProtection
+	stbBackend := &StubBackend{IstanbulBlock: big.NewInt(0), CurrentHeadNumber: big.NewInt(10)}
+
+	estimation, err := DoEstimateGas(arbitraryCtx, stbBackend, args, rpc.BlockNumberOrHashWithNumber(10), math.MaxInt64)
+
+	assert.NoError(err, "gas estimation")
+	// the private tx carries its 64 byte privacy manager hash
+	assert.Equal(hexutil.Uint64(22024), estimation, "estimation for a private tx")
+	assert.Equal(1, stbBackend.simulations, "expected the affected contracts to be found once")
+}
+
+func TestDoEstimateGas_whenPrivateCallAndPrivacyMarkerTransactionCreationEnabled(t *testing.T) {
+	assert := assert.New(t)
+	args := callTxArgs
+	args.PrivateFrom = arbitraryPrivateFrom
+	args.PrivateFor = arbitraryPrivateFor
+	stbBackend := &StubBackend{IstanbulBlock: big.NewInt(0), CurrentHeadNumber: big.NewInt(10), isPrivacyMarkerTransactionCreationEnabled: true}
+
+	estimation, err := DoEstimateGas(arbitraryCtx, stbBackend, args, rpc.BlockNumberOrHashWithNumber(10), math.MaxInt64)
+
+	assert.NoError(err, "gas estimation")
+	// the private tx and the privacy marker transaction both carry its 64 byte privacy manager hash
+	assert.Equal(hexutil.Uint64(22024+22024), estimation, "estimation for a private tx sent in a privacy marker transaction")
+}
+
+func TestSimulatePrivateCall_whenValueTransfer(t *testing.T) {
+	assert := assert.New(t)
+	args := callTxArgs
+	args.Value = (*hexutil.Big)(big.NewInt(1))
+	args.PrivateFor = []string{"arbitrary party 1"}
+
+	_, _, err := simulatePrivateCall(arbitraryCtx, &StubBackend{}, args, math.MaxInt64)
+
+	assert.Equal(core.ErrEtherValueUnsupported, err)
+}
+
+func TestSimulatePrivateCall_whenMandatoryRecipientsDataInvalid(t *testing.T) {
+	assert := assert.New(t)
+	args := callTxArgs
+	args.PrivateFor = []string{"arbitrary party 1"}
+	args.PrivacyFlag = engine.PrivacyFlagMandatoryRecipients
+
+	_, _, err := simulatePrivateCall(arbitraryCtx, &StubBackend{}, args, math.MaxInt64)
+
+	assert.EqualError(err, "missing mandatory recipients data. if no mandatory recipients required consider using PrivacyFlag=1(PartyProtection)")
+}
+
 func TestSimulateExecution_whenStandardPrivateCreation(t *testing.T) {
 	assert := assert.New(t)
 	privateTxArgs.PrivacyFlag = engine.PrivacyFlagStandardPrivate
@@ -684,6 +736,91 @@ func TestGetContractPrivacyMetadataWhenMandatoryRecipients(t *testing.T) {
 	assert.Equal(arbitraryMandatoryFor, privacyMetadata.MandatoryRecipients)
 }
 
+func TestPrivateContracts(t *testing.T) {
+	assert := assert.New(t)
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	psi := types.PrivateStateIdentifier("psi1")
+	mockpsmr := mps.NewMockPrivateStateMetadataResolver(mockCtrl)
+	mockpsmr.EXPECT().ResolveForUserContext(gomock.Any()).Return(mps.NewPrivateStateMetadata(psi, "psi1", "", mps.Resident, []string{"A"}), nil).AnyTimes()
+	mockTM := private.NewMockPrivateTransactionManager(mockCtrl)
+	saved := private.P
+	defer func() {
+		private.P = saved
+	}()
+	private.P = mockTM
+
+	db := rawdb.NewMemoryDatabase()
+	canonicalHash, staleHash := common.HexToHash("0x1"), common.HexToHash("0x2")
+	rawdb.WriteCanonicalHash(db, canonicalHash, 1)
+	rawdb.WritePrivateContractEntry(db, psi, &rawdb.PrivateContractEntry{
+		Address:        arbitrarySimpleStorageContractAddress,
+		BlockNumber:    1,
+		BlockHash:      canonicalHash,
+		TxHash:         common.HexToHash("0x11"),
+		CreationTxHash: arbitrarySimpleStorageContractEncryptedPayloadHash,
+		PrivacyFlag:    engine.PrivacyFlagStandardPrivate,
+	})
+	rawdb.WritePrivateContractEntry(db, psi, &rawdb.PrivateContractEntry{
+		Address:             arbitraryMandatoryRecipientsSimpleStorageContractAddress,
+		BlockNumber:         1,
+		BlockHash:           canonicalHash,
+		TxHash:              common.HexToHash("0x12"),
+		PrivacyMarkerTxHash: common.HexToHash("0x13"),
+		CreationTxHash:      arbitraryMandatoryRecipientsContractEncryptedPayloadHash,
+		PrivacyFlag:         engine.PrivacyFlagMandatoryRecipients,
+	})
+	rawdb.WritePrivateContractEntry(db, psi, &rawdb.PrivateContractEntry{
+		Address:     arbitraryTo,
+		BlockNumber: 1,
+		BlockHash:   staleHash,
+	})
+	mockTM.EXPECT().Receive(arbitrarySimpleStorageContractEncryptedPayloadHash).Return("", []string{"A", "B"}, nil, nil, nil)
+	mockTM.EXPECT().Receive(arbitraryMandatoryRecipientsContractEncryptedPayloadHash).Return("", []string{"A"}, nil, nil, nil)
+	mockTM.EXPECT().GetMandatory(arbitraryMandatoryRecipientsContractEncryptedPayloadHash).Return(arbitraryMandatoryFor, nil)
+
+	api := NewPublicBlockChainAPI(&privateContractsStubBackend{
+		MPSStubBackend: MPSStubBackend{StubBackend: StubBackend{CurrentHeadNumber: big.NewInt(10)}, psmr: mockpsmr},
+		db:             db,
+	})
+	page, err := api.PrivateContracts(arbitraryCtx, "", 0, rpc.LatestBlockNumber)
+
+	assert.NoError(err)
+	assert.Nil(page.Next)
+	if assert.Len(page.Contracts, 2) {
+		contracts := make(map[common.Address]*PrivateContract)
+		for _, c := range page.Contracts {
+			contracts[c.Address] = c
+		}
+		standardPrivate := contracts[arbitrarySimpleStorageContractAddress]
+		assert.Equal([]string{"A"}, standardPrivate.ManagedParties)
+		assert.Equal(arbitrarySimpleStorageContractEncryptedPayloadHash.ToBase64(), standardPrivate.CreationTxHash)
+		assert.Nil(standardPrivate.PrivacyMarkerTransactionHash)
+		assert.Empty(standardPrivate.MandatoryRecipients)
+
+		mandatoryRecipients := contracts[arbitraryMandatoryRecipientsSimpleStorageContractAddress]
+		assert.Equal(common.HexToHash("0x13"), *mandatoryRecipients.PrivacyMarkerTransactionHash)
+		assert.Equal(arbitraryMandatoryFor, mandatoryRecipients.MandatoryRecipients)
+	}
+}
+
+func TestPrivateContracts_whenPrivateStateOfAnotherTenant(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockpsmr := mps.NewMockPrivateStateMetadataResolver(mockCtrl)
+	mockpsmr.EXPECT().ResolveForUserContext(gomock.Any()).Return(mps.NewPrivateStateMetadata("psi1", "psi1", "", mps.Resident, []string{"A"}), nil)
+
+	api := NewPublicBlockChainAPI(&privateContractsStubBackend{
+		MPSStubBackend: MPSStubBackend{StubBackend: StubBackend{CurrentHeadNumber: big.NewInt(10), multitenancySupported: true}, psmr: mockpsmr},
+		db:             rawdb.NewMemoryDatabase(),
+	})
+	_, err := api.PrivateContracts(arbitraryCtx, "psi2", 0, rpc.LatestBlockNumber)
+
+	assert.ErrorIs(t, err, multitenancy.ErrNotAuthorized)
+}
+
 func TestSubmitPrivateTransaction(t *testing.T) {
 	assert := assert.New(t)
 
@@ -890,8 +1027,83 @@ func createKeystore(t *testing.T) (*keystore.KeyStore, accounts.Account, account
 	return keystore, fromAcct, toAcct
 }
 
+func TestPrivacyMarkerBatcher_BatchesByRecipients(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal(newBatchKey("A", []string{"B", "C"}), newBatchKey("A", []string{"C", "B"}))
+	assert.NotEqual(newBatchKey("A", []string{"B", "C"}), newBatchKey("A", []string{"B"}))
+	assert.NotEqual(newBatchKey("A", []string{"B"}), newBatchKey("D", []string{"B"}))
+}
+
+func TestPrivacyMarkerBatcher_Nonce_ResyncsWithTransactionPool(t *testing.T) {
+	assert := assert.New(t)
+	from := common.HexToAddress("0x1")
+	pmt := types.NewTransaction(0, common.QuorumPrivacyPrecompileContractAddress(), nil, 0, nil, nil)
+	b := &StubBackend{poolNonce: 5, poolTxs: map[common.Hash]*types.Transaction{pmt.Hash(): pmt}}
+	batcher := NewPrivacyMarkerBatcher(b, common.Address{}, 10, time.Minute)
+	batcher.nonces[from] = &batchedNonces{
+		waiting: map[uint64]struct{}{7: {}},
+		pmts:    map[common.Hash]uint64{pmt.Hash(): 7},
+	}
+
+	nonce, err := batcher.Nonce(context.Background(), from)
+	assert.NoError(err)
+	assert.Equal(uint64(8), nonce)
+
+	// the PMT left the pool and the private transaction waiting for its batch failed
+	delete(b.poolTxs, pmt.Hash())
+	delete(batcher.nonces[from].waiting, 7)
+
+	nonce, err = batcher.Nonce(context.Background(), from)
+	assert.NoError(err)
+	assert.Equal(uint64(5), nonce, "the nonce of the pool is used again")
+	assert.NotContains(batcher.nonces, from)
+}
+
+func TestPrivacyMarkerBatcher_Submit_FailsBeforeFork(t *testing.T) {
+	assert := assert.New(t)
+	key, _ := crypto.GenerateKey()
+	tx := types.NewTransaction(3, common.Address{}, big.NewInt(0), 0, big.NewInt(0), nil)
+	tx.SetPrivate()
+	signed, err := types.SignTx(tx, types.QuorumPrivateTxSigner{}, key)
+	assert.NoError(err)
+	from := signed.From()
+
+	b := &StubBackend{CurrentHeadNumber: big.NewInt(1)}
+	batcher := NewPrivacyMarkerBatcher(b, common.Address{}, 10, time.Minute)
+	batcher.nonces[from] = &batchedNonces{waiting: map[uint64]struct{}{3: {}}, pmts: make(map[common.Hash]uint64)}
+	batched := &batchedPrivateTransaction{tx: signed, privateFor: []string{"B"}, result: make(chan batchResult, 1)}
+
+	batcher.submit("A", []*batchedPrivateTransaction{batched})
+
+	result := <-batched.result
+	assert.ErrorIs(result.err, core.ErrPrivacyMarkerBatchNotEnabled)
+	assert.NotContains(batcher.nonces, from, "the nonce of the failed private transaction is available again")
+}
+
+func TestPrivacyMarkerBatcher_Add_RejectsAuthorizationNotSignedBySender(t *testing.T) {
+	assert := assert.New(t)
+	key, _ := crypto.GenerateKey()
+	otherKey, _ := crypto.GenerateKey()
+	tx := types.NewTransaction(3, common.Address{}, big.NewInt(0), 0, big.NewInt(0), nil)
+	tx.SetPrivate()
+	signed, err := types.SignTx(tx, types.QuorumPrivateTxSigner{}, key)
+	assert.NoError(err)
+	authorization, err := crypto.Sign(accounts.TextHash(types.PrivacyMarkerBatchAuthorization(params.QuorumTestChainConfig.ChainID, signed)), otherKey)
+	assert.NoError(err)
+
+	b := &StubBackend{CurrentHeadNumber: big.NewInt(1)}
+	batcher := NewPrivacyMarkerBatcher(b, common.Address{}, 10, time.Minute)
+
+	_, err = batcher.Add(context.Background(), signed, authorization, &PrivateTxArgs{PrivateFor: []string{"B"}})
+
+	assert.ErrorIs(err, errPrivacyMarkerBatchNotSigned)
+	assert.Empty(batcher.pending)
+}
+
 type StubBackend struct {
 	getEVMCalled                              bool
+	simulations                               int
 	sendTxCalled                              bool
 	txThatWasSent                             *types.Transaction
 	mockAccountExtraDataStateGetter           *vm.MockAccountExtraDataStateGetter
@@ -900,6 +1112,7 @@ type StubBackend struct {
 	accountManager                            *accounts.Manager
 	ks                                        *keystore.KeyStore
 	poolNonce                                 uint64
+	poolTxs                                   map[common.Hash]*types.Transaction
 	allowUnprotectedTxs                       bool
 
 	IstanbulBlock     *big.Int
@@ -1033,6 +1246,8 @@ func (sb *StubBackend) BlockByNumberOrHash(ctx context.Context, blockNrOrHash rp
 }
 
 func (sb *StubBackend) StateAndHeaderByNumber(ctx context.Context, blockNr rpc.BlockNumber) (vm.MinimalApiState, *types.Header, error) {
+	// only the simulated executions finding the affected contracts use the state of the current block
+	sb.simulations++
 	return &StubMinimalApiState{}, nil, nil
 }
 
@@ -1075,7 +1290,7 @@ func (sb *StubBackend) GetPoolTransactions() (types.Transactions, error) {
 }
 
 func (sb *StubBackend) GetPoolTransaction(txHash common.Hash) *types.Transaction {
-	panic("implement me")
+	return sb.poolTxs[txHash]
 }
 
 func (sb *StubBackend) GetPoolNonce(ctx context.Context, addr common.Address) (uint64, error) {
@@ -1139,10 +1354,27 @@ func (sb *MPSStubBackend) PSMR() mps.PrivateStateMetadataResolver {
 	return sb.psmr
 }
 
+type privateContractsStubBackend struct {
+	MPSStubBackend
+	db ethdb.Database
+}
+
+func (sb *privateContractsStubBackend) ChainDb() ethdb.Database {
+	return sb.db
+}
+
+func (sb *privateContractsStubBackend) SupportsMultitenancy(rpcCtx context.Context) (*proto.PreAuthenticatedAuthenticationToken, bool) {
+	return nil, sb.multitenancySupported
+}
+
 func (sb *StubBackend) IsPrivacyMarkerTransactionCreationEnabled() bool {
 	return sb.isPrivacyMarkerTransactionCreationEnabled
 }
 
+func (sb *StubBackend) PrivacyMarkerBatcher() *PrivacyMarkerBatcher {
+	return nil
+}
+
 type StubMinimalApiState struct {
 }
 
@@ -99,6 +99,8 @@ type Backend interface {
 	SupportsMultitenancy(rpcCtx context.Context) (*proto.PreAuthenticatedAuthenticationToken, bool)
 	// IsPrivacyMarkerTransactionCreationEnabled returns true if privacy marker transactions are enabled and should be created
 	IsPrivacyMarkerTransactionCreationEnabled() bool
+	// PrivacyMarkerBatcher returns the batcher of private transactions into privacy marker transactions, nil if disabled
+	PrivacyMarkerBatcher() *PrivacyMarkerBatcher
 }
 
 func GetAPIs(apiBackend Backend) []rpc.API {
@@ -143,6 +145,15 @@ func GetAPIs(apiBackend Backend) []rpc.API {
 			Version:   "1.0",
 			Service:   NewPrivateAccountProxyAPI(apiBackend, nonceLock),
 			Public:    false,
+		}, {
+			Namespace: "priv",
+			Version:   "1.0",
+			Service:   NewPublicPrivacyGroupAPI(apiBackend, nonceLock),
+			Public:    true,
+		}, {
+			Namespace: "priv",
+			Version:   "1.0",
+			Service:   NewPrivatePrivacyGroupAPI(apiBackend),
 		},
 	}
 }
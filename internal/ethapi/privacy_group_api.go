@@ -0,0 +1,286 @@
+package ethapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/multitenancy"
+	"github.com/ethereum/go-ethereum/private"
+	"github.com/ethereum/go-ethereum/private/engine"
+)
+
+var (
+	errPrivacyGroupIdRequired    = errors.New("privacyGroupId must be specified")
+	errPrivacyGroupAndPrivateFor = errors.New("privacyGroupId and privateFor are mutually exclusive")
+)
+
+// privacyGroups resolves the privacy groups of the private transaction manager visible to the
+// private state of the caller, for the services of the priv namespace
+type privacyGroups struct {
+	b Backend
+}
+
+// PublicPrivacyGroupAPI exposes the privacy groups of the private transaction manager in the priv
+// namespace, so that the same API can be used for Besu and GoQuorum nodes
+type PublicPrivacyGroupAPI struct {
+	privacyGroups
+	txPool *PublicTransactionPoolAPI
+}
+
+// NewPublicPrivacyGroupAPI creates a new RPC service with methods to find privacy groups and to
+// send private transactions to their members
+func NewPublicPrivacyGroupAPI(b Backend, nonceLock *AddrLocker) *PublicPrivacyGroupAPI {
+	return &PublicPrivacyGroupAPI{privacyGroups{b}, NewPublicTransactionPoolAPI(b, nonceLock)}
+}
+
+// PrivatePrivacyGroupAPI exposes the management of the privacy groups of the private transaction
+// manager in the priv namespace. It is not public, as creating and deleting privacy groups acts on
+// behalf of the keys of the private transaction manager.
+type PrivatePrivacyGroupAPI struct {
+	privacyGroups
+}
+
+// NewPrivatePrivacyGroupAPI creates a new RPC service with methods to manage privacy groups
+func NewPrivatePrivacyGroupAPI(b Backend) *PrivatePrivacyGroupAPI {
+	return &PrivatePrivacyGroupAPI{privacyGroups{b}}
+}
+
+// CreatePrivacyGroupArgs represents the arguments to create a privacy group
+type CreatePrivacyGroupArgs struct {
+	// Addresses are the public keys of the members of the privacy group
+	Addresses []string `json:"addresses"`
+	// From is the public key of the creator of the privacy group. Empty value means the
+	// Private Transaction Manager will use its first key, or the first key of the private
+	// state if multiple private states are enabled.
+	From        string `json:"from"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// PrivacyGroupSendTxArgs represents the arguments to sign and submit a private transaction
+// for the members of a privacy group
+type PrivacyGroupSendTxArgs struct {
+	SendTxArgs
+	PrivacyGroupId string `json:"privacyGroupId"`
+}
+
+// PrivacyGroupSendRawTxArgs represents the arguments to submit a signed private transaction
+// for the members of a privacy group
+type PrivacyGroupSendRawTxArgs struct {
+	SendRawTxArgs
+	PrivacyGroupId string `json:"privacyGroupId"`
+}
+
+// CreatePrivacyGroup creates a privacy group of type PANTHEON and returns its id
+func (s *PrivatePrivacyGroupAPI) CreatePrivacyGroup(ctx context.Context, args CreatePrivacyGroupArgs) (string, error) {
+	pgm, err := privacyGroupManager()
+	if err != nil {
+		return "", err
+	}
+	from, err := s.resolveFrom(ctx, args.From)
+	if err != nil {
+		return "", err
+	}
+	group, err := pgm.CreatePrivacyGroup(from, args.Addresses, args.Name, args.Description)
+	if err != nil {
+		return "", err
+	}
+	return group.PrivacyGroupId, nil
+}
+
+// DeletePrivacyGroup deletes the privacy group of type PANTHEON with the given id and returns the id
+func (s *PrivatePrivacyGroupAPI) DeletePrivacyGroup(ctx context.Context, privacyGroupId string, from *string) (string, error) {
+	pgm, err := privacyGroupManager()
+	if err != nil {
+		return "", err
+	}
+	if _, err := s.retrievePrivacyGroup(ctx, pgm, privacyGroupId); err != nil {
+		return "", err
+	}
+	var deleteFrom string
+	if from != nil {
+		deleteFrom = *from
+	}
+	if deleteFrom, err = s.resolveFrom(ctx, deleteFrom); err != nil {
+		return "", err
+	}
+	return pgm.DeletePrivacyGroup(deleteFrom, privacyGroupId)
+}
+
+// FindPrivacyGroup returns the privacy groups which have exactly the given members
+func (s *PublicPrivacyGroupAPI) FindPrivacyGroup(ctx context.Context, addresses []string) ([]engine.PrivacyGroup, error) {
+	pgm, err := privacyGroupManager()
+	if err != nil {
+		return nil, err
+	}
+	groups, err := pgm.FindPrivacyGroup(addresses)
+	if err != nil {
+		return nil, err
+	}
+	return s.visiblePrivacyGroups(ctx, groups)
+}
+
+// GetPrivacyGroupById returns the privacy group with the given id
+func (s *PublicPrivacyGroupAPI) GetPrivacyGroupById(ctx context.Context, privacyGroupId string) (*engine.PrivacyGroup, error) {
+	pgm, err := privacyGroupManager()
+	if err != nil {
+		return nil, err
+	}
+	return s.retrievePrivacyGroup(ctx, pgm, privacyGroupId)
+}
+
+// ListPrivacyGroups returns the privacy groups of the given type, which is either PANTHEON or LEGACY
+func (s *PublicPrivacyGroupAPI) ListPrivacyGroups(ctx context.Context, groupType string) ([]engine.PrivacyGroup, error) {
+	pgm, err := privacyGroupManager()
+	if err != nil {
+		return nil, err
+	}
+	groupType = strings.ToUpper(groupType)
+	if groupType != engine.PrivacyGroupPantheon && groupType != engine.PrivacyGroupLegacy {
+		return nil, fmt.Errorf("privacy group type must be %s or %s", engine.PrivacyGroupPantheon, engine.PrivacyGroupLegacy)
+	}
+	groups, err := pgm.PrivacyGroups(groupType)
+	if err != nil {
+		return nil, err
+	}
+	return s.visiblePrivacyGroups(ctx, groups)
+}
+
+// SendTransaction creates a private transaction for the members of the given privacy group,
+// signs it and submits it to the transaction pool
+func (s *PublicPrivacyGroupAPI) SendTransaction(ctx context.Context, args PrivacyGroupSendTxArgs) (common.Hash, error) {
+	if err := s.setPrivateFor(ctx, &args.PrivateTxArgs, args.PrivacyGroupId); err != nil {
+		return common.Hash{}, err
+	}
+	return s.txPool.SendTransaction(ctx, args.SendTxArgs)
+}
+
+// SendRawTransaction submits a signed private transaction for the members of the given
+// privacy group to the transaction pool
+func (s *PublicPrivacyGroupAPI) SendRawTransaction(ctx context.Context, encodedTx hexutil.Bytes, args PrivacyGroupSendRawTxArgs) (common.Hash, error) {
+	if err := s.setPrivateFor(ctx, &args.PrivateTxArgs, args.PrivacyGroupId); err != nil {
+		return common.Hash{}, err
+	}
+	return s.txPool.SendRawPrivateTransaction(ctx, encodedTx, args.SendRawTxArgs)
+}
+
+// setPrivateFor addresses the private transaction to the members of the privacy group, other than
+// the sender. If multiple private states are enabled the sender defaults to the first key of the
+// private state which is a member of the privacy group.
+func (s *PublicPrivacyGroupAPI) setPrivateFor(ctx context.Context, args *PrivateTxArgs, privacyGroupId string) error {
+	if len(privacyGroupId) == 0 {
+		return errPrivacyGroupIdRequired
+	}
+	if args.PrivateFor != nil {
+		return errPrivacyGroupAndPrivateFor
+	}
+	pgm, err := privacyGroupManager()
+	if err != nil {
+		return err
+	}
+	group, err := s.retrievePrivacyGroup(ctx, pgm, privacyGroupId)
+	if err != nil {
+		return err
+	}
+	if len(args.PrivateFrom) == 0 && s.b.ChainConfig().IsMPS {
+		psm, err := s.b.PSMR().ResolveForUserContext(ctx)
+		if err != nil {
+			return err
+		}
+		for _, address := range psm.Addresses {
+			if containsString(group.Members, address) {
+				args.PrivateFrom = address
+				break
+			}
+		}
+	}
+	if len(args.PrivateFrom) > 0 && !containsString(group.Members, args.PrivateFrom) {
+		return fmt.Errorf("privateFrom %s is not a member of privacy group %s", args.PrivateFrom, privacyGroupId)
+	}
+	privateFor := make([]string, 0, len(group.Members))
+	for _, member := range group.Members {
+		if member != args.PrivateFrom {
+			privateFor = append(privateFor, member)
+		}
+	}
+	args.PrivateFor = privateFor
+	return nil
+}
+
+// retrievePrivacyGroup returns the privacy group if it is visible to the private state of the caller
+func (s *privacyGroups) retrievePrivacyGroup(ctx context.Context, pgm private.PrivacyGroupManager, privacyGroupId string) (*engine.PrivacyGroup, error) {
+	group, err := pgm.RetrievePrivacyGroup(privacyGroupId)
+	if err != nil {
+		return nil, err
+	}
+	visible, err := s.visiblePrivacyGroups(ctx, []engine.PrivacyGroup{*group})
+	if err != nil {
+		return nil, err
+	}
+	if len(visible) == 0 {
+		return nil, multitenancy.ErrNotAuthorized
+	}
+	return group, nil
+}
+
+// visiblePrivacyGroups filters out the privacy groups which have no member in the private state
+// of the caller, if multiple private states are enabled
+func (s *privacyGroups) visiblePrivacyGroups(ctx context.Context, groups []engine.PrivacyGroup) ([]engine.PrivacyGroup, error) {
+	if !s.b.ChainConfig().IsMPS {
+		return groups, nil
+	}
+	psm, err := s.b.PSMR().ResolveForUserContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	visible := make([]engine.PrivacyGroup, 0, len(groups))
+	for _, group := range groups {
+		if !s.b.PSMR().NotIncludeAny(psm, group.Members...) {
+			visible = append(visible, group)
+		}
+	}
+	return visible, nil
+}
+
+// resolveFrom defaults the key acting on a privacy group to the first key of the private state of
+// the caller, and makes sure that the key belongs to it, if multiple private states are enabled
+func (s *privacyGroups) resolveFrom(ctx context.Context, from string) (string, error) {
+	if !s.b.ChainConfig().IsMPS {
+		return from, nil
+	}
+	psm, err := s.b.PSMR().ResolveForUserContext(ctx)
+	if err != nil {
+		return "", err
+	}
+	if len(from) == 0 {
+		return psm.Addresses[0], nil
+	}
+	if s.b.PSMR().NotIncludeAny(psm, from) {
+		return "", multitenancy.ErrNotAuthorized
+	}
+	return from, nil
+}
+
+func privacyGroupManager() (private.PrivacyGroupManager, error) {
+	pgm, ok := private.P.(private.PrivacyGroupManager)
+	if !ok {
+		if !private.IsQuorumPrivacyEnabled() {
+			return nil, fmt.Errorf("PrivateTransactionManager is not enabled")
+		}
+		return nil, engine.ErrPrivateTxManagerNotSupported
+	}
+	return pgm, nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,194 @@
+package ethapi
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/mps"
+	"github.com/ethereum/go-ethereum/multitenancy"
+	"github.com/ethereum/go-ethereum/private"
+	"github.com/ethereum/go-ethereum/private/engine"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubPrivacyGroupManager is a private transaction manager managing privacy groups in memory,
+// recording the key acting on them
+type stubPrivacyGroupManager struct {
+	StubPrivateTransactionManager
+	groups map[string]engine.PrivacyGroup
+	from   string
+}
+
+func (pgm *stubPrivacyGroupManager) CreatePrivacyGroup(from string, members []string, name, description string) (*engine.PrivacyGroup, error) {
+	pgm.from = from
+	group := engine.PrivacyGroup{Type: engine.PrivacyGroupPantheon, Name: name, Description: description, PrivacyGroupId: "new", Members: members}
+	pgm.groups[group.PrivacyGroupId] = group
+	return &group, nil
+}
+
+func (pgm *stubPrivacyGroupManager) DeletePrivacyGroup(from string, privacyGroupId string) (string, error) {
+	pgm.from = from
+	delete(pgm.groups, privacyGroupId)
+	return privacyGroupId, nil
+}
+
+func (pgm *stubPrivacyGroupManager) RetrievePrivacyGroup(privacyGroupId string) (*engine.PrivacyGroup, error) {
+	group, ok := pgm.groups[privacyGroupId]
+	if !ok {
+		return nil, engine.ErrPrivateTxManagerNotSupported
+	}
+	return &group, nil
+}
+
+func (pgm *stubPrivacyGroupManager) FindPrivacyGroup(members []string) ([]engine.PrivacyGroup, error) {
+	return pgm.PrivacyGroups(engine.PrivacyGroupPantheon)
+}
+
+func (pgm *stubPrivacyGroupManager) PrivacyGroups(groupType string) ([]engine.PrivacyGroup, error) {
+	groups := make([]engine.PrivacyGroup, 0, len(pgm.groups))
+	for _, id := range []string{"AC", "D"} {
+		if group, ok := pgm.groups[id]; ok && group.Type == groupType {
+			groups = append(groups, group)
+		}
+	}
+	return groups, nil
+}
+
+// stubPrivacyGroupPSMR resolves the private state of the caller to the same private state
+type stubPrivacyGroupPSMR struct {
+	mps.PrivateStateMetadataResolver
+	psm *mps.PrivateStateMetadata
+}
+
+func (psmr *stubPrivacyGroupPSMR) ResolveForUserContext(ctx context.Context) (*mps.PrivateStateMetadata, error) {
+	return psmr.psm, nil
+}
+
+func (psmr *stubPrivacyGroupPSMR) NotIncludeAny(psm *mps.PrivateStateMetadata, managedParties ...string) bool {
+	return psm.NotIncludeAny(managedParties...)
+}
+
+func setupPrivacyGroupManager(t *testing.T) *stubPrivacyGroupManager {
+	pgm := &stubPrivacyGroupManager{groups: map[string]engine.PrivacyGroup{
+		"AC": {Type: engine.PrivacyGroupPantheon, PrivacyGroupId: "AC", Members: []string{"A", "C"}},
+		"D":  {Type: engine.PrivacyGroupPantheon, PrivacyGroupId: "D", Members: []string{"D"}},
+	}}
+	saved := private.P
+	t.Cleanup(func() {
+		private.P = saved
+	})
+	private.P = pgm
+	return pgm
+}
+
+func newMPSPrivacyGroupBackend() *MPSStubBackend {
+	return &MPSStubBackend{psmr: &stubPrivacyGroupPSMR{psm: mps.NewPrivateStateMetadata("psi1", "psi1", "", mps.Resident, []string{"A", "B"})}}
+}
+
+func TestPrivatePrivacyGroupAPI_CreatePrivacyGroup_whenMPS(t *testing.T) {
+	pgm := setupPrivacyGroupManager(t)
+	api := NewPrivatePrivacyGroupAPI(newMPSPrivacyGroupBackend())
+
+	// the creator defaults to the first key of the private state of the caller
+	id, err := api.CreatePrivacyGroup(arbitraryCtx, CreatePrivacyGroupArgs{Addresses: []string{"A", "C"}})
+	require.NoError(t, err)
+	assert.Equal(t, "new", id)
+	assert.Equal(t, "A", pgm.from)
+
+	_, err = api.CreatePrivacyGroup(arbitraryCtx, CreatePrivacyGroupArgs{Addresses: []string{"B", "C"}, From: "B"})
+	require.NoError(t, err)
+	assert.Equal(t, "B", pgm.from)
+
+	// a key of another private state cannot be used
+	_, err = api.CreatePrivacyGroup(arbitraryCtx, CreatePrivacyGroupArgs{Addresses: []string{"C", "D"}, From: "C"})
+	assert.Equal(t, multitenancy.ErrNotAuthorized, err)
+}
+
+func TestPrivatePrivacyGroupAPI_CreatePrivacyGroup_whenNotMPS(t *testing.T) {
+	pgm := setupPrivacyGroupManager(t)
+	api := NewPrivatePrivacyGroupAPI(&StubBackend{})
+
+	// the creator is left to the private transaction manager
+	_, err := api.CreatePrivacyGroup(arbitraryCtx, CreatePrivacyGroupArgs{Addresses: []string{"A", "C"}})
+	require.NoError(t, err)
+	assert.Equal(t, "", pgm.from)
+}
+
+func TestPrivatePrivacyGroupAPI_DeletePrivacyGroup_whenMPS(t *testing.T) {
+	pgm := setupPrivacyGroupManager(t)
+	api := NewPrivatePrivacyGroupAPI(newMPSPrivacyGroupBackend())
+
+	// a privacy group with no member in the private state of the caller cannot be deleted
+	_, err := api.DeletePrivacyGroup(arbitraryCtx, "D", nil)
+	assert.Equal(t, multitenancy.ErrNotAuthorized, err)
+	assert.Contains(t, pgm.groups, "D")
+
+	id, err := api.DeletePrivacyGroup(arbitraryCtx, "AC", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "AC", id)
+	assert.Equal(t, "A", pgm.from)
+	assert.NotContains(t, pgm.groups, "AC")
+}
+
+func TestPublicPrivacyGroupAPI_visibility_whenMPS(t *testing.T) {
+	setupPrivacyGroupManager(t)
+	api := NewPublicPrivacyGroupAPI(newMPSPrivacyGroupBackend(), nil)
+
+	groups, err := api.ListPrivacyGroups(arbitraryCtx, "pantheon")
+	require.NoError(t, err)
+	require.Len(t, groups, 1)
+	assert.Equal(t, "AC", groups[0].PrivacyGroupId)
+
+	groups, err = api.FindPrivacyGroup(arbitraryCtx, []string{"A", "C"})
+	require.NoError(t, err)
+	require.Len(t, groups, 1)
+	assert.Equal(t, "AC", groups[0].PrivacyGroupId)
+
+	group, err := api.GetPrivacyGroupById(arbitraryCtx, "AC")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"A", "C"}, group.Members)
+
+	_, err = api.GetPrivacyGroupById(arbitraryCtx, "D")
+	assert.Equal(t, multitenancy.ErrNotAuthorized, err)
+
+	_, err = api.ListPrivacyGroups(arbitraryCtx, "unknown")
+	assert.Error(t, err)
+}
+
+func TestPublicPrivacyGroupAPI_setPrivateFor_whenMPS(t *testing.T) {
+	setupPrivacyGroupManager(t)
+	api := NewPublicPrivacyGroupAPI(newMPSPrivacyGroupBackend(), nil)
+
+	// the sender defaults to the key of the private state which is a member of the group
+	args := PrivateTxArgs{}
+	require.NoError(t, api.setPrivateFor(arbitraryCtx, &args, "AC"))
+	assert.Equal(t, "A", args.PrivateFrom)
+	assert.Equal(t, []string{"C"}, args.PrivateFor)
+
+	args = PrivateTxArgs{PrivateFrom: "B"}
+	assert.EqualError(t, api.setPrivateFor(arbitraryCtx, &args, "AC"), "privateFrom B is not a member of privacy group AC")
+
+	args = PrivateTxArgs{}
+	assert.Equal(t, multitenancy.ErrNotAuthorized, api.setPrivateFor(arbitraryCtx, &args, "D"))
+
+	args = PrivateTxArgs{PrivateFor: []string{"C"}}
+	assert.Equal(t, errPrivacyGroupAndPrivateFor, api.setPrivateFor(arbitraryCtx, &args, "AC"))
+
+	assert.Equal(t, errPrivacyGroupIdRequired, api.setPrivateFor(arbitraryCtx, &PrivateTxArgs{}, ""))
+}
+
+func TestPublicPrivacyGroupAPI_setPrivateFor_whenNotMPS(t *testing.T) {
+	setupPrivacyGroupManager(t)
+	api := NewPublicPrivacyGroupAPI(&StubBackend{}, nil)
+
+	// without a sender the private transaction manager sends from its default key
+	args := PrivateTxArgs{}
+	require.NoError(t, api.setPrivateFor(arbitraryCtx, &args, "AC"))
+	assert.Equal(t, "", args.PrivateFrom)
+	assert.Equal(t, []string{"A", "C"}, args.PrivateFor)
+
+	args = PrivateTxArgs{PrivateFrom: "A"}
+	require.NoError(t, api.setPrivateFor(arbitraryCtx, &args, "AC"))
+	assert.Equal(t, []string{"C"}, args.PrivateFor)
+}
@@ -0,0 +1,330 @@
+package ethapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/private"
+	"github.com/ethereum/go-ethereum/private/engine"
+)
+
+var (
+	errPrivacyMarkerBatcherStopped = errors.New("privacy marker transaction batcher stopped")
+	errPrivacyMarkerBatchNonceUsed = errors.New("nonce already used by a batched private transaction")
+	errPrivacyMarkerBatchNotSigned = errors.New("private transaction batch authorization not signed by the sender")
+)
+
+// PrivacyMarkerBatcher bundles the private transactions submitted to the node into privacy marker
+// transactions (PMT) carrying a batch of private transactions, signed by a single account of the
+// node. The private transactions with the same privateFrom and the same recipients are batched
+// together and sent, as a single payload, to their recipients, so that no recipient receives a
+// private transaction it is not party to. A batch is submitted when it reaches the configured
+// size, or when the configured interval elapses.
+//
+// The senders sign the authorization of the batch to carry their private transactions, so that every
+// node can check that the nonces consumed by the PMT are consumed on behalf of their owners.
+//
+// The private transactions of a batch are not submitted to the transaction pool, so the batcher
+// tracks the nonces of their senders until the PMT carrying them leaves the transaction pool,
+// either mined or dropped.
+type PrivacyMarkerBatcher struct {
+	b        Backend
+	account  common.Address
+	size     int
+	interval time.Duration
+
+	mu      sync.Mutex
+	pending map[batchKey][]*batchedPrivateTransaction // pending private transactions by privateFrom and recipients
+	nonces  map[common.Address]*batchedNonces         // nonces of the batched private transactions by sender
+
+	submitMu sync.Mutex // serializes the submission of the PMTs, which share the nonces of the account
+	quit     chan struct{}
+	wg       sync.WaitGroup
+}
+
+// batchKey identifies the private transactions which can be sent in the same payload
+type batchKey struct {
+	privateFrom string
+	privateFor  string // the sorted recipients, comma separated
+}
+
+func newBatchKey(privateFrom string, privateFor []string) batchKey {
+	recipients := make([]string, len(privateFor))
+	copy(recipients, privateFor)
+	sort.Strings(recipients)
+	return batchKey{privateFrom: privateFrom, privateFor: strings.Join(recipients, ",")}
+}
+
+// batchedNonces are the nonces of the private transactions of a sender which are not in the
+// transaction pool
+type batchedNonces struct {
+	waiting map[uint64]struct{}    // nonces of the private transactions waiting for their batch
+	pmts    map[common.Hash]uint64 // next nonce of the sender after each submitted PMT carrying its private transactions
+}
+
+type batchedPrivateTransaction struct {
+	tx         *types.Transaction
+	entry      types.PrivacyMarkerBatchEntry
+	privateFor []string
+	result     chan batchResult
+}
+
+type batchResult struct {
+	pmtHash common.Hash
+	err     error
+}
+
+// NewPrivacyMarkerBatcher creates a batcher which submits the PMTs signed by the given account
+func NewPrivacyMarkerBatcher(b Backend, account common.Address, size int, interval time.Duration) *PrivacyMarkerBatcher {
+	return &PrivacyMarkerBatcher{
+		b:        b,
+		account:  account,
+		size:     size,
+		interval: interval,
+		pending:  make(map[batchKey][]*batchedPrivateTransaction),
+		nonces:   make(map[common.Address]*batchedNonces),
+		quit:     make(chan struct{}),
+	}
+}
+
+// Start starts submitting the pending batches at the configured interval
+func (p *PrivacyMarkerBatcher) Start() {
+	p.wg.Add(1)
+	go p.loop()
+}
+
+// Stop stops the batcher. The private transactions waiting for their batch are failed.
+func (p *PrivacyMarkerBatcher) Stop() {
+	close(p.quit)
+	p.wg.Wait()
+}
+
+func (p *PrivacyMarkerBatcher) loop() {
+	defer p.wg.Done()
+
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			p.mu.Lock()
+			pending := p.pending
+			p.pending = make(map[batchKey][]*batchedPrivateTransaction)
+			p.mu.Unlock()
+			for key, batch := range pending {
+				p.submit(key.privateFrom, batch)
+			}
+		case <-p.quit:
+			return
+		}
+	}
+}
+
+// Nonce returns the next nonce of the sender, taking into account its private transactions which
+// are batched and not mined yet. It is used for the public transactions of the sender as well, so
+// they do not reuse the nonces of its batched private transactions.
+func (p *PrivacyMarkerBatcher) Nonce(ctx context.Context, from common.Address) (uint64, error) {
+	nonce, err := p.b.GetPoolNonce(ctx, from)
+	if err != nil {
+		return 0, err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.nextNonce(from, nonce), nil
+}
+
+// nextNonce resyncs the batched nonces of the sender with the nonce of the transaction pool and
+// returns the next nonce of the sender. The PMTs which left the transaction pool are forgotten: if
+// mined, the nonce of the pool includes their private transactions, otherwise their nonces are
+// available again. The caller must hold the lock.
+func (p *PrivacyMarkerBatcher) nextNonce(from common.Address, poolNonce uint64) uint64 {
+	nonces, ok := p.nonces[from]
+	if !ok {
+		return poolNonce
+	}
+	next := poolNonce
+	for nonce := range nonces.waiting {
+		if nonce+1 > next {
+			next = nonce + 1
+		}
+	}
+	for pmtHash, pmtNext := range nonces.pmts {
+		if p.b.GetPoolTransaction(pmtHash) == nil {
+			delete(nonces.pmts, pmtHash)
+			continue
+		}
+		if pmtNext > next {
+			next = pmtNext
+		}
+	}
+	if len(nonces.waiting) == 0 && len(nonces.pmts) == 0 {
+		delete(p.nonces, from)
+	}
+	return next
+}
+
+// Add adds the signed private transaction to the batch of its privateFrom and recipients, and
+// blocks until the PMT carrying the batch is submitted. It returns the hash of the PMT. The
+// authorization is the signature by the sender of the types.PrivacyMarkerBatchAuthorization of the
+// private transaction.
+func (p *PrivacyMarkerBatcher) Add(ctx context.Context, tx *types.Transaction, authorization []byte, privateTxArgs *PrivateTxArgs) (common.Hash, error) {
+	entry := types.NewPrivacyMarkerBatchEntry(tx, authorization)
+	if !entry.Authorized(p.b.ChainConfig().ChainID) {
+		return common.Hash{}, errPrivacyMarkerBatchNotSigned
+	}
+	if err := authorizePrivateTransaction(ctx, p.b, tx, types.QuorumPrivateTxSigner{}, privateTxArgs.PrivateFrom, false); err != nil {
+		return common.Hash{}, err
+	}
+	poolNonce, err := p.b.GetPoolNonce(ctx, tx.From())
+	if err != nil {
+		return common.Hash{}, err
+	}
+	batched := &batchedPrivateTransaction{
+		tx:         tx,
+		entry:      entry,
+		privateFor: privateTxArgs.PrivateFor,
+		result:     make(chan batchResult, 1),
+	}
+
+	p.mu.Lock()
+	p.nextNonce(tx.From(), poolNonce)
+	if tx.Nonce() < poolNonce {
+		p.mu.Unlock()
+		return common.Hash{}, core.ErrNonceTooLow
+	}
+	nonces, ok := p.nonces[tx.From()]
+	if !ok {
+		nonces = &batchedNonces{waiting: make(map[uint64]struct{}), pmts: make(map[common.Hash]uint64)}
+		p.nonces[tx.From()] = nonces
+	}
+	if _, ok := nonces.waiting[tx.Nonce()]; ok {
+		p.mu.Unlock()
+		return common.Hash{}, errPrivacyMarkerBatchNonceUsed
+	}
+	nonces.waiting[tx.Nonce()] = struct{}{}
+	key := newBatchKey(privateTxArgs.PrivateFrom, privateTxArgs.PrivateFor)
+	p.pending[key] = append(p.pending[key], batched)
+	var full []*batchedPrivateTransaction
+	if len(p.pending[key]) >= p.size {
+		full = p.pending[key]
+		delete(p.pending, key)
+	}
+	p.mu.Unlock()
+
+	if full != nil {
+		go p.submit(key.privateFrom, full)
+	}
+
+	select {
+	case result := <-batched.result:
+		return result.pmtHash, result.err
+	case <-ctx.Done():
+		return common.Hash{}, ctx.Err()
+	case <-p.quit:
+		return common.Hash{}, errPrivacyMarkerBatcherStopped
+	}
+}
+
+// submit sends the private transactions of the batch to the private transaction manager and
+// submits the PMT carrying the batch, then notifies the result to the senders
+func (p *PrivacyMarkerBatcher) submit(privateFrom string, batch []*batchedPrivateTransaction) {
+	pmtHash, err := p.submitBatch(privateFrom, batch)
+	if err != nil {
+		log.Warn("Failed to submit privacy marker transaction batch", "privateFrom", privateFrom, "size", len(batch), "err", err)
+	}
+	// the private transactions of the batch are no longer waiting: they are either carried by the
+	// PMT until it leaves the transaction pool, or their nonces are available again
+	p.mu.Lock()
+	for _, batched := range batch {
+		from, nonce := batched.tx.From(), batched.tx.Nonce()
+		nonces, ok := p.nonces[from]
+		if !ok {
+			continue
+		}
+		delete(nonces.waiting, nonce)
+		if err == nil && nonce+1 > nonces.pmts[pmtHash] {
+			nonces.pmts[pmtHash] = nonce + 1
+		}
+		if len(nonces.waiting) == 0 && len(nonces.pmts) == 0 {
+			delete(p.nonces, from)
+		}
+	}
+	p.mu.Unlock()
+	for _, batched := range batch {
+		batched.result <- batchResult{pmtHash, err}
+	}
+}
+
+func (p *PrivacyMarkerBatcher) submitBatch(privateFrom string, batch []*batchedPrivateTransaction) (common.Hash, error) {
+	currentBlockHeight := p.b.CurrentHeader().Number
+	if next := new(big.Int).Add(currentBlockHeight, common.Big1); !p.b.ChainConfig().IsPrivacyMarkerBatchEnabled(next) {
+		return common.Hash{}, core.ErrPrivacyMarkerBatchNotEnabled
+	}
+	var (
+		txs     = make([]*types.Transaction, len(batch))
+		entries = make([]types.PrivacyMarkerBatchEntry, len(batch))
+		// the private transactions of the batch have the same recipients
+		recipients = batch[0].privateFor
+		gasPrice   = new(big.Int)
+	)
+	for i, batched := range batch {
+		txs[i] = batched.tx
+		entries[i] = batched.entry
+		if batched.tx.GasPrice().Cmp(gasPrice) > 0 {
+			gasPrice = batched.tx.GasPrice()
+		}
+	}
+
+	payload, err := json.Marshal(txs)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	_, _, ptmHash, err := private.P.Send(payload, privateFrom, recipients, &engine.ExtraMetadata{})
+	if err != nil {
+		return common.Hash{}, err
+	}
+	data, err := types.NewPrivacyMarkerBatchData(ptmHash, entries)
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	intrinsicGas, err := core.IntrinsicGas(data, nil, false, true, p.b.ChainConfig().IsIstanbul(currentBlockHeight))
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	account := accounts.Account{Address: p.account}
+	wallet, err := p.b.AccountManager().Find(account)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	var chainID *big.Int // PMT is public so will have different chainID used in signing compared to the internal txs
+	if config := p.b.ChainConfig(); config.IsEIP155(currentBlockHeight) {
+		chainID = config.ChainID
+	}
+
+	p.submitMu.Lock()
+	defer p.submitMu.Unlock()
+	ctx := context.Background()
+	nonce, err := p.b.GetPoolNonce(ctx, p.account)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	pmt := types.NewTransaction(nonce, common.QuorumPrivacyPrecompileContractAddress(), new(big.Int), intrinsicGas, gasPrice, data)
+	signed, err := wallet.SignTx(account, pmt, chainID)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return SubmitTransaction(ctx, p.b, signed, privateFrom, false)
+}
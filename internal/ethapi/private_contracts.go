@@ -0,0 +1,140 @@
+package ethapi
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/mps"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/multitenancy"
+	"github.com/ethereum/go-ethereum/private"
+	"github.com/ethereum/go-ethereum/private/engine"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// privateContractsPageSize is the number of private contracts read for a page of eth_privateContracts,
+// which holds all the private contracts of its last block
+const privateContractsPageSize = 100
+
+// PrivateContract is a private contract created in a private state
+type PrivateContract struct {
+	Address                      common.Address         `json:"address"`
+	CreationTxHash               string                 `json:"creationTxHash"`
+	TransactionHash              common.Hash            `json:"transactionHash"`
+	PrivacyMarkerTransactionHash *common.Hash           `json:"privacyMarkerTransactionHash,omitempty"`
+	BlockNumber                  hexutil.Uint64         `json:"blockNumber"`
+	BlockHash                    common.Hash            `json:"blockHash"`
+	PrivacyFlag                  engine.PrivacyFlagType `json:"privacyFlag"`
+	ManagedParties               []string               `json:"managedParties"`
+	MandatoryRecipients          []string               `json:"mandatoryFor,omitempty"`
+}
+
+// PrivateContractsPage is a page of the private contracts created in a range of blocks. Next is
+// the first block of the next page, if the range is not exhausted.
+type PrivateContractsPage struct {
+	Contracts []*PrivateContract `json:"contracts"`
+	Next      *hexutil.Uint64    `json:"next,omitempty"`
+}
+
+// PrivateContracts returns the private contracts created in the private state from block fromBlock
+// to block toBlock, in the order of their creation. The contracts are returned by pages, the next
+// page is retrieved with the fromBlock set to the next block of the page. The contracts of the blocks
+// imported before the index was introduced are only returned once indexed by
+// 'geth privatestate index-contracts'.
+func (s *PublicBlockChainAPI) PrivateContracts(ctx context.Context, psi types.PrivateStateIdentifier, fromBlock, toBlock rpc.BlockNumber) (*PrivateContractsPage, error) {
+	psm, err := s.resolvePrivateState(ctx, psi)
+	if err != nil {
+		return nil, err
+	}
+	head := s.b.CurrentHeader().Number.Uint64()
+	from, to := resolveBlockNumber(fromBlock, head), resolveBlockNumber(toBlock, head)
+	if from > to {
+		return nil, errors.New("fromBlock is after toBlock")
+	}
+
+	db := s.b.ChainDb()
+	page := &PrivateContractsPage{Contracts: make([]*PrivateContract, 0)}
+	entries := rawdb.ReadPrivateContractEntries(db, psm.ID, from, to, privateContractsPageSize)
+	for _, entry := range entries {
+		// the contracts of the blocks which are no longer canonical are ignored
+		if rawdb.ReadCanonicalHash(db, entry.BlockNumber) != entry.BlockHash {
+			continue
+		}
+		contract, err := newPrivateContract(s.b, psm, entry)
+		if err != nil {
+			return nil, err
+		}
+		page.Contracts = append(page.Contracts, contract)
+	}
+	if len(entries) >= privateContractsPageSize {
+		if last := entries[len(entries)-1].BlockNumber; last < to {
+			next := hexutil.Uint64(last + 1)
+			page.Next = &next
+		}
+	}
+	return page, nil
+}
+
+// resolvePrivateState returns the private state with the given identifier, which defaults to the
+// private state of the caller and must be the private state of the caller if multitenancy is enabled
+func (s *PublicBlockChainAPI) resolvePrivateState(ctx context.Context, psi types.PrivateStateIdentifier) (*mps.PrivateStateMetadata, error) {
+	if len(psi) == 0 {
+		return s.b.PSMR().ResolveForUserContext(ctx)
+	}
+	if _, ok := s.b.SupportsMultitenancy(ctx); ok {
+		psm, err := s.b.PSMR().ResolveForUserContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if psm.ID != psi {
+			return nil, multitenancy.ErrNotAuthorized
+		}
+		return psm, nil
+	}
+	return s.b.PSMR().ResolveForUserContext(rpc.WithPrivateStateIdentifier(ctx, psi))
+}
+
+func resolveBlockNumber(number rpc.BlockNumber, head uint64) uint64 {
+	if number < 0 || uint64(number) > head {
+		return head
+	}
+	return uint64(number)
+}
+
+// newPrivateContract returns the private contract recorded by the entry, with its managed parties
+// and its mandatory recipients retrieved from the private transaction manager
+func newPrivateContract(b Backend, psm *mps.PrivateStateMetadata, entry *rawdb.PrivateContractEntry) (*PrivateContract, error) {
+	contract := &PrivateContract{
+		Address:         entry.Address,
+		CreationTxHash:  entry.CreationTxHash.ToBase64(),
+		TransactionHash: entry.TxHash,
+		BlockNumber:     hexutil.Uint64(entry.BlockNumber),
+		BlockHash:       entry.BlockHash,
+		PrivacyFlag:     entry.PrivacyFlag,
+		ManagedParties:  make([]string, 0),
+	}
+	if entry.PrivacyMarkerTxHash != (common.Hash{}) {
+		pmtHash := entry.PrivacyMarkerTxHash
+		contract.PrivacyMarkerTransactionHash = &pmtHash
+	}
+	if common.EmptyEncryptedPayloadHash(entry.CreationTxHash) {
+		return contract, nil
+	}
+	_, managedParties, _, _, err := private.P.Receive(entry.CreationTxHash)
+	if err != nil {
+		return nil, err
+	}
+	if b.ChainConfig().IsMPS {
+		managedParties = psm.FilterAddresses(managedParties...)
+	}
+	contract.ManagedParties = append(contract.ManagedParties, managedParties...)
+	if entry.PrivacyFlag == engine.PrivacyFlagMandatoryRecipients {
+		if contract.MandatoryRecipients, err = private.P.GetMandatory(entry.CreationTxHash); err != nil {
+			return nil, err
+		}
+	}
+	return contract, nil
+}
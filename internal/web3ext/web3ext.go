@@ -41,6 +41,7 @@ var Modules = map[string]string{
 	"quorumExtension":  Extension_JS,
 	"plugin_account":   Account_Plugin_Js,
 	"qlight":           QLight_JS,
+	"priv":             Priv_JS,
 }
 
 const ChequebookJs = `
@@ -231,6 +232,31 @@ web3._extend({
 			name: 'stopWS',
 			call: 'admin_stopWS'
 		}),
+		new web3._extend.Method({
+			name: 'ptmEndpoints',
+			call: 'admin_ptmEndpoints'
+		}),
+		new web3._extend.Method({
+			name: 'missingPrivatePayloads',
+			call: 'admin_missingPrivatePayloads',
+			params: 2,
+			inputFormatter: [null, null]
+		}),
+		new web3._extend.Method({
+			name: 'missingPrivatePayloadsStatus',
+			call: 'admin_missingPrivatePayloadsStatus'
+		}),
+		new web3._extend.Method({
+			name: 'resendPrivatePayloads',
+			call: 'admin_resendPrivatePayloads',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'refreshPrivateStates',
+			call: 'admin_refreshPrivateStates',
+			params: 1,
+			inputFormatter: [null]
+		}),
 	],
 	properties: [
 		new web3._extend.Property({
@@ -278,6 +304,12 @@ web3._extend({
 			call: 'debug_getBlockRlp',
 			params: 1
 		}),
+		new web3._extend.Method({
+			name: 'getPrivacyVerificationFailures',
+			call: 'debug_getPrivacyVerificationFailures',
+			params: 1,
+			inputFormatter: [web3._extend.formatters.inputBlockNumberFormatter]
+		}),
 		new web3._extend.Method({
 			name: 'testSignCliqueBlock',
 			call: 'debug_testSignCliqueBlock',
@@ -488,6 +520,12 @@ web3._extend({
 			params: 3,
 			inputFormatter: [null, null, null]
 		}),
+		new web3._extend.Method({
+			name: 'traceBlockAllStates',
+			call: 'debug_traceBlockAllStates',
+			params: 2,
+			inputFormatter: [null, null]
+		}),
 		new web3._extend.Method({
 			name: 'preimage',
 			call: 'debug_preimage',
@@ -504,6 +542,18 @@ web3._extend({
 			call: 'debug_storageRangeAt',
 			params: 5,
 		}),
+		new web3._extend.Method({
+			name: 'publicStateDiff',
+			call: 'debug_publicStateDiff',
+			params: 2,
+			inputFormatter: [null, null],
+		}),
+		new web3._extend.Method({
+			name: 'privateStateDiff',
+			call: 'debug_privateStateDiff',
+			params: 3,
+			inputFormatter: [null, null, null],
+		}),
 		new web3._extend.Method({
 			name: 'getModifiedAccountsByNumber',
 			call: 'debug_getModifiedAccountsByNumber',
@@ -552,6 +602,12 @@ web3._extend({
 			call: 'eth_getContractPrivacyMetadata',
 			params: 1
 		}),
+		new web3._extend.Method({
+			name: 'privateContracts',
+			call: 'eth_privateContracts',
+			params: 3,
+			inputFormatter: [null, web3._extend.formatters.inputBlockNumberFormatter, web3._extend.formatters.inputBlockNumberFormatter]
+		}),
 		new web3._extend.Method({
 			name: 'chainId',
 			call: 'eth_chainId',
@@ -683,6 +739,11 @@ web3._extend({
             call: 'eth_getPrivateTransactionReceipt',
             params: 1,
             outputFormatter: web3._extend.formatters.outputTransactionReceiptFormatter
+        }),
+        new web3._extend.Method({
+            name: 'getPrivateTransactionBatchReceipts',
+            call: 'eth_getPrivateTransactionBatchReceipts',
+            params: 1
         }),
 		// END-QUORUM
 	],
@@ -1269,12 +1330,24 @@ web3._extend({
 			params: 4,
 			inputFormatter: [web3._extend.formatters.inputAddressFormatter, null, web3._extend.formatters.inputAddressFormatter, web3._extend.formatters.inputTransactionFormatter]
 		}),
+		new web3._extend.Method({
+			name: 'extendContractWithDependencies',
+			call: 'quorumExtension_extendContractWithDependencies',
+			params: 5,
+			inputFormatter: [web3._extend.formatters.inputAddressFormatter, null, web3._extend.formatters.inputAddressFormatter, web3._extend.formatters.inputTransactionFormatter, null]
+		}),
 		new web3._extend.Method({
 			name: 'cancelExtension',
 			call: 'quorumExtension_cancelExtension',
 			params: 2,
 			inputFormatter: [web3._extend.formatters.inputAddressFormatter, web3._extend.formatters.inputTransactionFormatter]
 		}),
+		new web3._extend.Method({
+			name: 'previewExtension',
+			call: 'quorumExtension_previewExtension',
+			params: 1,
+			inputFormatter: [web3._extend.formatters.inputAddressFormatter]
+		}),
 		new web3._extend.Method({
 			name: 'addBalance',
 			call: 'les_addBalance',
@@ -1325,6 +1398,57 @@ web3._extend({
 });
 `
 
+const Priv_JS = `
+web3._extend({
+	property: 'priv',
+	methods:
+	[
+		new web3._extend.Method({
+			name: 'createPrivacyGroup',
+			call: 'priv_createPrivacyGroup',
+			params: 1,
+			inputFormatter: [null]
+		}),
+		new web3._extend.Method({
+			name: 'deletePrivacyGroup',
+			call: 'priv_deletePrivacyGroup',
+			params: 2,
+			inputFormatter: [null, null]
+		}),
+		new web3._extend.Method({
+			name: 'findPrivacyGroup',
+			call: 'priv_findPrivacyGroup',
+			params: 1,
+			inputFormatter: [null]
+		}),
+		new web3._extend.Method({
+			name: 'getPrivacyGroupById',
+			call: 'priv_getPrivacyGroupById',
+			params: 1,
+			inputFormatter: [null]
+		}),
+		new web3._extend.Method({
+			name: 'listPrivacyGroups',
+			call: 'priv_listPrivacyGroups',
+			params: 1,
+			inputFormatter: [null]
+		}),
+		new web3._extend.Method({
+			name: 'sendTransaction',
+			call: 'priv_sendTransaction',
+			params: 1,
+			inputFormatter: [null]
+		}),
+		new web3._extend.Method({
+			name: 'sendRawTransaction',
+			call: 'priv_sendRawTransaction',
+			params: 2,
+			inputFormatter: [null, null]
+		}),
+	]
+});
+`
+
 const Account_Plugin_Js = `
 web3._extend({
 	property: 'plugin_account',
@@ -349,6 +349,10 @@ func (b *LesApiBackend) IsPrivacyMarkerTransactionCreationEnabled() bool {
 	return b.eth.config.QuorumChainConfig.PrivacyMarkerEnabled()
 }
 
+func (b *LesApiBackend) PrivacyMarkerBatcher() *ethapi.PrivacyMarkerBatcher {
+	return nil
+}
+
 func (b *LesApiBackend) CallTimeOut() time.Duration {
 	return b.eth.config.EVMCallTimeOut
 }
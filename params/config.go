@@ -248,21 +248,21 @@ var (
 	//
 	// This configuration is intentionally not using keyed fields to force anyone
 	// adding flags to the config to also have to set these fields.
-	AllEthashProtocolChanges = &ChainConfig{big.NewInt(1337), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, nil, new(EthashConfig), nil, nil, nil, nil, nil, false, 32, 35, big.NewInt(0), big.NewInt(0), nil, nil, false, nil, nil}
+	AllEthashProtocolChanges = &ChainConfig{big.NewInt(1337), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, nil, new(EthashConfig), nil, nil, nil, nil, nil, false, 32, 35, big.NewInt(0), big.NewInt(0), nil, nil, false, nil, nil, nil, nil, nil}
 
 	// AllCliqueProtocolChanges contains every protocol change (EIPs) introduced
 	// and accepted by the Ethereum core developers into the Clique consensus.
 	//
 	// This configuration is intentionally not using keyed fields to force anyone
 	// adding flags to the config to also have to set these fields.
-	AllCliqueProtocolChanges = &ChainConfig{big.NewInt(10), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, nil, nil, &CliqueConfig{Period: 0, Epoch: 30000}, nil, nil, nil, nil, false, 32, 32, big.NewInt(0), big.NewInt(0), nil, nil, false, nil, nil}
+	AllCliqueProtocolChanges = &ChainConfig{big.NewInt(10), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, nil, nil, &CliqueConfig{Period: 0, Epoch: 30000}, nil, nil, nil, nil, false, 32, 32, big.NewInt(0), big.NewInt(0), nil, nil, false, nil, nil, nil, nil, nil}
 
 	// Quorum chainID should 10
-	TestChainConfig = &ChainConfig{big.NewInt(10), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, nil, new(EthashConfig), nil, nil, nil, nil, nil, false, 32, 32, big.NewInt(0), big.NewInt(0), nil, nil, false, nil, nil}
+	TestChainConfig = &ChainConfig{big.NewInt(10), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, nil, new(EthashConfig), nil, nil, nil, nil, nil, false, 32, 32, big.NewInt(0), big.NewInt(0), nil, nil, false, nil, nil, nil, nil, nil}
 	TestRules       = TestChainConfig.Rules(new(big.Int))
 
-	QuorumTestChainConfig    = &ChainConfig{big.NewInt(10), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, nil, nil, nil, new(EthashConfig), nil, nil, nil, nil, nil, true, 64, 32, big.NewInt(0), big.NewInt(0), nil, big.NewInt(0), false, nil, nil}
-	QuorumMPSTestChainConfig = &ChainConfig{big.NewInt(10), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, nil, nil, nil, new(EthashConfig), nil, nil, nil, nil, nil, true, 64, 32, big.NewInt(0), big.NewInt(0), nil, big.NewInt(0), true, nil, nil}
+	QuorumTestChainConfig    = &ChainConfig{big.NewInt(10), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, nil, nil, nil, new(EthashConfig), nil, nil, nil, nil, nil, true, 64, 32, big.NewInt(0), big.NewInt(0), nil, big.NewInt(0), false, nil, nil, nil, nil, nil}
+	QuorumMPSTestChainConfig = &ChainConfig{big.NewInt(10), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, nil, nil, nil, new(EthashConfig), nil, nil, nil, nil, nil, true, 64, 32, big.NewInt(0), big.NewInt(0), nil, big.NewInt(0), true, nil, nil, nil, nil, nil}
 )
 
 // TrustedCheckpoint represents a set of post-processed trie roots (CHT and
@@ -369,9 +369,12 @@ type ChainConfig struct {
 	// to track multiple changes to maxCodeSize
 	MaxCodeSizeConfig        []MaxCodeConfigStruct `json:"maxCodeSizeConfig,omitempty"`
 	PrivacyEnhancementsBlock *big.Int              `json:"privacyEnhancementsBlock,omitempty"`
-	IsMPS                    bool                  `json:"isMPS"`                            // multiple private states flag
-	PrivacyPrecompileBlock   *big.Int              `json:"privacyPrecompileBlock,omitempty"` // Switch block to enable privacy precompiled contract to process privacy marker transactions
-	EnableGasPriceBlock      *big.Int              `json:"enableGasPriceBlock,omitempty"`    // Switch block to enable usage of gas price
+	IsMPS                    bool                  `json:"isMPS"`                             // multiple private states flag
+	PrivacyPrecompileBlock   *big.Int              `json:"privacyPrecompileBlock,omitempty"`  // Switch block to enable privacy precompiled contract to process privacy marker transactions
+	EnableGasPriceBlock      *big.Int              `json:"enableGasPriceBlock,omitempty"`     // Switch block to enable usage of gas price
+	ShanghaiBlock            *big.Int              `json:"shanghaiBlock,omitempty"`           // Switch block to enable the Shanghai opcodes PUSH0 and BASEFEE (nil = no fork, 0 = already activated)
+	CancunBlock              *big.Int              `json:"cancunBlock,omitempty"`             // Switch block to enable the Cancun opcodes TLOAD, TSTORE and MCOPY, implies Shanghai (nil = no fork, 0 = already activated)
+	PrivacyMarkerBatchBlock  *big.Int              `json:"privacyMarkerBatchBlock,omitempty"` // Switch block to enable privacy marker transactions carrying a batch of private transactions
 
 	// End of Quorum specific configs
 }
@@ -471,6 +474,9 @@ type Transition struct {
 	BeneficiaryMode              *string               `json:"beneficiaryMode,omitempty"`              // Mode for setting the beneficiary, either: list, besu, validators (beneficiary list is the list of validators)
 	MiningBeneficiary            *common.Address       `json:"miningBeneficiary,omitempty"`            // Wallet address that benefits at every new block (besu mode)
 	MaxRequestTimeoutSeconds     *uint64               `json:"maxRequestTimeoutSeconds,omitempty"`     // The max a timeout should be for a round change
+	ShanghaiEnabled              *bool                 `json:"shanghaiEnabled,omitempty"`              // enable the Shanghai opcodes PUSH0 and BASEFEE
+	CancunEnabled                *bool                 `json:"cancunEnabled,omitempty"`                // enable the Cancun opcodes TLOAD, TSTORE and MCOPY, implies Shanghai
+	PrivacyMarkerBatchEnabled    *bool                 `json:"privacyMarkerBatchEnabled,omitempty"`    // enable privacy marker transactions carrying a batch of private transactions
 }
 
 // String implements the fmt.Stringer interface.
@@ -486,7 +492,7 @@ func (c *ChainConfig) String() string {
 	default:
 		engine = "unknown"
 	}
-	return fmt.Sprintf("{ChainID: %v Homestead: %v DAO: %v DAOSupport: %v EIP150: %v EIP155: %v EIP158: %v Byzantium: %v IsQuorum: %v Constantinople: %v TransactionSizeLimit: %v MaxCodeSize: %v Petersburg: %v Istanbul: %v, Muir Glacier: %v, Berlin: %v  Catalyst: %v YOLO v3: %v PrivacyEnhancements: %v PrivacyPrecompile: %v EnableGasPriceBlock: %v Engine: %v}",
+	return fmt.Sprintf("{ChainID: %v Homestead: %v DAO: %v DAOSupport: %v EIP150: %v EIP155: %v EIP158: %v Byzantium: %v IsQuorum: %v Constantinople: %v TransactionSizeLimit: %v MaxCodeSize: %v Petersburg: %v Istanbul: %v, Muir Glacier: %v, Berlin: %v  Catalyst: %v YOLO v3: %v PrivacyEnhancements: %v PrivacyPrecompile: %v EnableGasPriceBlock: %v Shanghai: %v Cancun: %v PrivacyMarkerBatch: %v Engine: %v}",
 		c.ChainID,
 		c.HomesteadBlock,
 		c.DAOForkBlock,
@@ -508,6 +514,9 @@ func (c *ChainConfig) String() string {
 		c.PrivacyEnhancementsBlock, //Quorum
 		c.PrivacyPrecompileBlock,   //Quorum
 		c.EnableGasPriceBlock,      //Quorum
+		c.ShanghaiBlock,            //Quorum
+		c.CancunBlock,              //Quorum
+		c.PrivacyMarkerBatchBlock,  //Quorum
 		engine,
 	)
 }
@@ -786,6 +795,23 @@ func (c *ChainConfig) CheckTransitionsData() error {
 		}
 		prevBlock = transition.Block
 	}
+	return c.checkShanghaiTransitions()
+}
+
+// Quorum
+//
+// checkShanghaiTransitions validates that the transitions enabling Shanghai or Cancun
+// happen once Berlin is enabled.
+func (c *ChainConfig) checkShanghaiTransitions() error {
+	for _, transition := range c.Transitions {
+		if transition.Block == nil {
+			continue
+		}
+		enabled := (transition.ShanghaiEnabled != nil && *transition.ShanghaiEnabled) || (transition.CancunEnabled != nil && *transition.CancunEnabled)
+		if enabled && !c.IsBerlin(transition.Block) {
+			return ErrShanghaiBeforeBerlin
+		}
+	}
 	return nil
 }
 
@@ -921,6 +947,15 @@ func isTransitionsConfigCompatible(c1, c2 *ChainConfig, head *big.Int) (error, *
 		if isSameBlock || c1.Transitions[i].MinerGasLimit != c2.Transitions[i].MinerGasLimit {
 			return ErrTransitionIncompatible("TransactionSizeLimit"), head, head
 		}
+		if isSameBlock || !configBoolEqual(c1.Transitions[i].ShanghaiEnabled, c2.Transitions[i].ShanghaiEnabled) {
+			return ErrTransitionIncompatible("ShanghaiEnabled"), head, head
+		}
+		if isSameBlock || !configBoolEqual(c1.Transitions[i].CancunEnabled, c2.Transitions[i].CancunEnabled) {
+			return ErrTransitionIncompatible("CancunEnabled"), head, head
+		}
+		if isSameBlock || !configBoolEqual(c1.Transitions[i].PrivacyMarkerBatchEnabled, c2.Transitions[i].PrivacyMarkerBatchEnabled) {
+			return ErrTransitionIncompatible("PrivacyMarkerBatchEnabled"), head, head
+		}
 	}
 
 	return nil, big.NewInt(0), big.NewInt(0)
@@ -986,6 +1021,62 @@ func (c *ChainConfig) IsGasPriceEnabled(num *big.Int) bool {
 	return isForked(c.EnableGasPriceBlock, num) || isGasEnabled
 }
 
+// Quorum
+//
+// IsShanghai returns whether num represents a block number after the ShanghaiBlock,
+// or after a transition enabling Shanghai. Cancun implies Shanghai, and both
+// require Berlin as their jump tables build on its access list gas functions.
+func (c *ChainConfig) IsShanghai(num *big.Int) bool {
+	if !c.IsBerlin(num) {
+		return false
+	}
+	isShanghaiEnabled := false
+	c.GetTransitionValue(num, func(transition Transition) {
+		if transition.ShanghaiEnabled != nil {
+			isShanghaiEnabled = *transition.ShanghaiEnabled
+		}
+	})
+
+	return isForked(c.ShanghaiBlock, num) || isShanghaiEnabled || c.IsCancun(num)
+}
+
+// Quorum
+//
+// IsCancun returns whether num represents a block number after the CancunBlock,
+// or after a transition enabling Cancun. Cancun requires Berlin.
+func (c *ChainConfig) IsCancun(num *big.Int) bool {
+	if !c.IsBerlin(num) {
+		return false
+	}
+	isCancunEnabled := false
+	c.GetTransitionValue(num, func(transition Transition) {
+		if transition.CancunEnabled != nil {
+			isCancunEnabled = *transition.CancunEnabled
+		}
+	})
+
+	return isForked(c.CancunBlock, num) || isCancunEnabled
+}
+
+// Quorum
+//
+// IsPrivacyMarkerBatchEnabled returns whether num represents a block number after the PrivacyMarkerBatchBlock,
+// or after a transition enabling privacy marker transactions carrying a batch of private transactions.
+// Batches are only processed by the privacy precompile, so they also require it.
+func (c *ChainConfig) IsPrivacyMarkerBatchEnabled(num *big.Int) bool {
+	if !c.IsPrivacyPrecompileEnabled(num) {
+		return false
+	}
+	isPrivacyMarkerBatchEnabled := false
+	c.GetTransitionValue(num, func(transition Transition) {
+		if transition.PrivacyMarkerBatchEnabled != nil {
+			isPrivacyMarkerBatchEnabled = *transition.PrivacyMarkerBatchEnabled
+		}
+	})
+
+	return isForked(c.PrivacyMarkerBatchBlock, num) || isPrivacyMarkerBatchEnabled
+}
+
 // CheckCompatible checks whether scheduled fork transitions have been imported
 // with a mismatching chain configuration.
 func (c *ChainConfig) CheckCompatible(newcfg *ChainConfig, height uint64, isQuorumEIP155Activated bool) *ConfigCompatError {
@@ -1041,6 +1132,8 @@ func (c *ChainConfig) CheckConfigForkOrder() error {
 		{name: "istanbulBlock", block: c.IstanbulBlock},
 		{name: "muirGlacierBlock", block: c.MuirGlacierBlock, optional: true},
 		{name: "berlinBlock", block: c.BerlinBlock},
+		{name: "shanghaiBlock", block: c.ShanghaiBlock, optional: true}, // Quorum
+		{name: "cancunBlock", block: c.CancunBlock, optional: true},     // Quorum
 	} {
 		if lastFork.name != "" {
 			// Next one must be higher number
@@ -1060,7 +1153,8 @@ func (c *ChainConfig) CheckConfigForkOrder() error {
 			lastFork = cur
 		}
 	}
-	return nil
+	// Quorum: Shanghai and Cancun may also be enabled by transitions
+	return c.checkShanghaiTransitions()
 }
 
 func (c *ChainConfig) checkCompatible(newcfg *ChainConfig, head *big.Int, isQuorumEIP155Activated bool) *ConfigCompatError {
@@ -1134,6 +1228,15 @@ func (c *ChainConfig) checkCompatible(newcfg *ChainConfig, head *big.Int, isQuor
 	if isForkIncompatible(c.PrivacyPrecompileBlock, newcfg.PrivacyPrecompileBlock, head) {
 		return newCompatError("Privacy Precompile fork block", c.PrivacyPrecompileBlock, newcfg.PrivacyPrecompileBlock)
 	}
+	if isForkIncompatible(c.ShanghaiBlock, newcfg.ShanghaiBlock, head) {
+		return newCompatError("Shanghai fork block", c.ShanghaiBlock, newcfg.ShanghaiBlock)
+	}
+	if isForkIncompatible(c.CancunBlock, newcfg.CancunBlock, head) {
+		return newCompatError("Cancun fork block", c.CancunBlock, newcfg.CancunBlock)
+	}
+	if isForkIncompatible(c.PrivacyMarkerBatchBlock, newcfg.PrivacyMarkerBatchBlock, head) {
+		return newCompatError("Privacy Marker Batch fork block", c.PrivacyMarkerBatchBlock, newcfg.PrivacyMarkerBatchBlock)
+	}
 	return nil
 }
 
@@ -1151,6 +1254,16 @@ func isForked(s, head *big.Int) bool {
 	return s.Cmp(head) <= 0
 }
 
+// Quorum
+//
+// configBoolEqual returns whether both transition flags are unset, or set to the same value.
+func configBoolEqual(x, y *bool) bool {
+	if x == nil || y == nil {
+		return x == y
+	}
+	return *x == *y
+}
+
 func configNumEqual(x, y *big.Int) bool {
 	if x == nil {
 		return y == nil
@@ -1206,6 +1319,8 @@ type Rules struct {
 	IsPrivacyEnhancementsEnabled bool
 	IsPrivacyPrecompile          bool
 	IsGasPriceEnabled            bool
+	IsShanghai, IsCancun         bool
+	IsPrivacyMarkerBatch         bool
 }
 
 // Rules ensures c's ChainID is not nil.
@@ -1230,5 +1345,8 @@ func (c *ChainConfig) Rules(num *big.Int) Rules {
 		IsPrivacyEnhancementsEnabled: c.IsPrivacyEnhancementsEnabled(num),
 		IsPrivacyPrecompile:          c.IsPrivacyPrecompileEnabled(num),
 		IsGasPriceEnabled:            c.IsGasPriceEnabled(num),
+		IsShanghai:                   c.IsShanghai(num),
+		IsCancun:                     c.IsCancun(num),
+		IsPrivacyMarkerBatch:         c.IsPrivacyMarkerBatchEnabled(num),
 	}
 }
@@ -332,10 +332,10 @@ func TestCheckTransitionsData(t *testing.T) {
 	var ibftTransitionsConfig, qbftTransitionsConfig, invalidTransition, invalidBlockOrder []Transition
 	var emptyBlockPeriodSeconds uint64 = 10
 
-	tranI0 := Transition{big.NewInt(0), IBFT, 30000, 5, nil, 10, 50, common.Address{}, nil, "", nil, nil, nil, nil, 0, nil, 0, nil, nil, nil, nil}
-	tranQ5 := Transition{big.NewInt(5), QBFT, 30000, 5, &emptyBlockPeriodSeconds, 10, 50, common.Address{}, nil, "", nil, nil, nil, nil, 0, nil, 0, nil, nil, nil, nil}
-	tranI10 := Transition{big.NewInt(10), IBFT, 30000, 5, nil, 10, 50, common.Address{}, nil, "", nil, nil, nil, nil, 0, nil, 0, nil, nil, nil, nil}
-	tranQ8 := Transition{big.NewInt(8), QBFT, 30000, 5, &emptyBlockPeriodSeconds, 10, 50, common.Address{}, nil, "", nil, nil, nil, nil, 0, nil, 0, nil, nil, nil, nil}
+	tranI0 := Transition{big.NewInt(0), IBFT, 30000, 5, nil, 10, 50, common.Address{}, nil, "", nil, nil, nil, nil, 0, nil, 0, nil, nil, nil, nil, nil, nil, nil}
+	tranQ5 := Transition{big.NewInt(5), QBFT, 30000, 5, &emptyBlockPeriodSeconds, 10, 50, common.Address{}, nil, "", nil, nil, nil, nil, 0, nil, 0, nil, nil, nil, nil, nil, nil, nil}
+	tranI10 := Transition{big.NewInt(10), IBFT, 30000, 5, nil, 10, 50, common.Address{}, nil, "", nil, nil, nil, nil, 0, nil, 0, nil, nil, nil, nil, nil, nil, nil}
+	tranQ8 := Transition{big.NewInt(8), QBFT, 30000, 5, &emptyBlockPeriodSeconds, 10, 50, common.Address{}, nil, "", nil, nil, nil, nil, 0, nil, 0, nil, nil, nil, nil, nil, nil, nil}
 
 	ibftTransitionsConfig = append(ibftTransitionsConfig, tranI0, tranI10)
 	qbftTransitionsConfig = append(qbftTransitionsConfig, tranQ5, tranQ8)
@@ -395,7 +395,7 @@ func TestCheckTransitionsData(t *testing.T) {
 			wantErr: ErrBlockOrder,
 		},
 		{
-			stored:  &ChainConfig{Transitions: []Transition{{nil, IBFT, 30000, 5, &emptyBlockPeriodSeconds, 10, 50, common.Address{}, nil, "", nil, nil, nil, nil, 0, nil, 0, nil, nil, nil, nil}}},
+			stored:  &ChainConfig{Transitions: []Transition{{nil, IBFT, 30000, 5, &emptyBlockPeriodSeconds, 10, 50, common.Address{}, nil, "", nil, nil, nil, nil, 0, nil, 0, nil, nil, nil, nil, nil, nil, nil}}},
 			wantErr: ErrBlockNumberMissing,
 		},
 		{
@@ -426,6 +426,18 @@ func TestCheckTransitionsData(t *testing.T) {
 			stored:  &ChainConfig{Transitions: []Transition{{Block: big.NewInt(0)}}},
 			wantErr: nil,
 		},
+		{
+			stored:  &ChainConfig{Transitions: []Transition{{Block: big.NewInt(5), CancunEnabled: newPBool(true)}}},
+			wantErr: ErrShanghaiBeforeBerlin,
+		},
+		{
+			stored:  &ChainConfig{BerlinBlock: big.NewInt(10), Transitions: []Transition{{Block: big.NewInt(5), ShanghaiEnabled: newPBool(true)}}},
+			wantErr: ErrShanghaiBeforeBerlin,
+		},
+		{
+			stored:  &ChainConfig{BerlinBlock: big.NewInt(0), Transitions: []Transition{{Block: big.NewInt(5), ShanghaiEnabled: newPBool(true)}}},
+			wantErr: nil,
+		},
 	}
 
 	for _, test := range tests {
@@ -619,6 +631,64 @@ func TestIsGasPriceEnabled(t *testing.T) {
 	}
 }
 
+func TestIsShanghaiAndCancun(t *testing.T) {
+	type test struct {
+		config      *ChainConfig
+		blockNumber int64
+		shanghai    bool
+		cancun      bool
+	}
+
+	config1, config2 := *TestChainConfig, *TestChainConfig
+	config1.ShanghaiBlock = big.NewInt(11)
+	config1.CancunBlock = big.NewInt(21)
+
+	config2.Transitions = []Transition{
+		{Block: big.NewInt(11), ShanghaiEnabled: newPBool(true)},
+		{Block: big.NewInt(21), CancunEnabled: newPBool(true)},
+	}
+
+	tests := []test{
+		{MainnetChainConfig, 0, false, false},
+		{&config1, 10, false, false},
+		{&config1, 11, true, false},
+		{&config1, 21, true, true},
+		{&config2, 10, false, false},
+		{&config2, 11, true, false},
+		{&config2, 21, true, true},
+		{&config2, 22, true, true},
+	}
+
+	for _, test := range tests {
+		rules := test.config.Rules(big.NewInt(test.blockNumber))
+		if rules.IsShanghai != test.shanghai || rules.IsCancun != test.cancun {
+			t.Errorf("error mismatch on %v:\nexpected: shanghai %v cancun %v\nreceived: shanghai %v cancun %v\n", test.blockNumber, test.shanghai, test.cancun, rules.IsShanghai, rules.IsCancun)
+		}
+	}
+
+	// Cancun alone implies Shanghai
+	config3 := *TestChainConfig
+	config3.Transitions = []Transition{{Block: big.NewInt(5), CancunEnabled: newPBool(true)}}
+	if !config3.IsShanghai(big.NewInt(5)) {
+		t.Errorf("expected Shanghai enabled along with Cancun")
+	}
+
+	// Shanghai and Cancun require Berlin, even when the configuration is not validated
+	config4 := config3
+	config4.BerlinBlock = nil
+	config4.ShanghaiBlock = big.NewInt(0)
+	if config4.IsShanghai(big.NewInt(5)) || config4.IsCancun(big.NewInt(5)) {
+		t.Errorf("expected Shanghai and Cancun disabled without Berlin")
+	}
+	if err := config4.CheckConfigForkOrder(); err == nil {
+		t.Errorf("expected a fork ordering error for Shanghai without Berlin")
+	}
+	config4.ShanghaiBlock = nil
+	if err := config4.CheckConfigForkOrder(); err != ErrShanghaiBeforeBerlin {
+		t.Errorf("error mismatch:\nexpected: %v\nreceived: %v", ErrShanghaiBeforeBerlin, err)
+	}
+}
+
 func newPBool(b bool) *bool {
 	return &b
 }
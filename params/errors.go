@@ -17,6 +17,7 @@ var (
 	ErrMissingValidatorSelectionMode   = errors.New("validator selection mode is missing, should specify `contract` when using validatorcontractaddress")
 	ErrTransactionSizeLimit            = errors.New("genesis transaction size limit must be between 32 and 128")
 	ErrBeneficiaryMode                 = errors.New("beneficiary mode is not valid")
+	ErrShanghaiBeforeBerlin            = errors.New("shanghai and cancun transitions require berlin to be enabled")
 )
 
 func ErrTransitionIncompatible(field string) error {
@@ -3,17 +3,58 @@ package cache
 import (
 	"time"
 
+	"github.com/ethereum/go-ethereum/ethdb/leveldb"
 	"github.com/ethereum/go-ethereum/private/engine"
-	gocache "github.com/patrickmn/go-cache"
 )
 
 const (
 	DefaultExpiration = 5 * time.Minute
 	CleanupInterval   = 5 * time.Minute
+
+	// DefaultSize is the default maximum number of items held in memory
+	DefaultSize = 10000
+
+	// DefaultDiskExpiration is the default time after which an item is dropped from disk
+	DefaultDiskExpiration = 7 * 24 * time.Hour
 )
 
-func NewDefaultCache() *gocache.Cache {
-	return gocache.New(DefaultExpiration, CleanupInterval)
+// Cache holds the items retrieved from a private transaction manager, keyed by the hex
+// encoded payload hash.
+type Cache interface {
+	Get(key string) (interface{}, bool)
+	Set(key string, value interface{})
+	Delete(key string)
+	// Close releases the resources of the cache, i.e. closes its disk tier
+	Close() error
+}
+
+// Config is the configuration of the private payload cache
+type Config struct {
+	Size       int           // Maximum number of items held in memory, DefaultSize if zero
+	Expiration time.Duration // Time after which an item is dropped from memory, DefaultExpiration if zero
+	Dir        string        // Directory of the leveldb database holding the payloads on disk, disabled if empty
+
+	DiskExpiration time.Duration // Time after which an item is dropped from disk, DefaultDiskExpiration if zero
+}
+
+// New creates a cache from the given configuration, opening the disk tier if it is enabled. The
+// cache must be closed once it is no longer used.
+func New(cfg Config) (Cache, error) {
+	if cfg.Dir == "" {
+		return newLRUCache(cfg.Size, cfg.Expiration, nil), nil
+	}
+	db, err := leveldb.New(cfg.Dir, 16, 16, "ptm/cache/disk/db/", false)
+	if err != nil {
+		return nil, err
+	}
+	disk := newDiskCache(db, cfg.DiskExpiration)
+	disk.startPruning()
+	return newLRUCache(cfg.Size, cfg.Expiration, disk), nil
+}
+
+// NewDefaultCache creates an in-memory cache with the default size and expiration
+func NewDefaultCache() Cache {
+	return newLRUCache(DefaultSize, DefaultExpiration, nil)
 }
 
 type PrivateCacheItem struct {
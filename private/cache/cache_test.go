@@ -0,0 +1,127 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethdb/memorydb"
+	"github.com/ethereum/go-ethereum/private/engine"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLRUCache_whenFull(t *testing.T) {
+	c := newLRUCache(2, time.Minute, nil)
+
+	c.Set("a", PrivateCacheItem{Payload: []byte("a")})
+	c.Set("b", PrivateCacheItem{Payload: []byte("b")})
+	_, found := c.Get("a") // a is now the most recently used
+	require.True(t, found)
+	c.Set("c", PrivateCacheItem{Payload: []byte("c")})
+
+	_, found = c.Get("b")
+	assert.False(t, found, "expected least recently used item to be evicted")
+	item, found := c.Get("a")
+	require.True(t, found)
+	assert.Equal(t, []byte("a"), item.(PrivateCacheItem).Payload)
+	_, found = c.Get("c")
+	assert.True(t, found)
+}
+
+func TestLRUCache_whenExpired(t *testing.T) {
+	c := newLRUCache(2, time.Millisecond, nil)
+
+	c.Set("a", PrivateCacheItem{Payload: []byte("a")})
+	time.Sleep(5 * time.Millisecond)
+
+	_, found := c.Get("a")
+	assert.False(t, found)
+}
+
+func TestLRUCache_Delete(t *testing.T) {
+	disk := newDiskCache(memorydb.New(), time.Minute)
+	c := newLRUCache(2, time.Minute, disk)
+
+	c.Set("a", PrivateCacheItem{Payload: []byte("a")})
+	c.Delete("a")
+
+	_, found := c.Get("a")
+	assert.False(t, found)
+	_, found = disk.get("a")
+	assert.False(t, found)
+}
+
+func TestLRUCache_whenDiskTierEnabled(t *testing.T) {
+	db := memorydb.New()
+	acHashes := make(common.EncryptedPayloadHashes)
+	acHashes.Add(common.BytesToEncryptedPayloadHash([]byte("affected")))
+	expected := PrivateCacheItem{
+		Payload: []byte("payload"),
+		Extra: engine.ExtraMetadata{
+			ACHashes:       acHashes,
+			ACMerkleRoot:   common.HexToHash("0x01"),
+			PrivacyFlag:    engine.PrivacyFlagStateValidation,
+			ManagedParties: []string{"AAA"},
+			Sender:         "AAA",
+		},
+	}
+	newLRUCache(2, time.Minute, newDiskCache(db, time.Minute)).Set("a", expected)
+
+	// a new cache on the same database, as it would be after a restart
+	c := newLRUCache(2, time.Minute, newDiskCache(db, time.Minute))
+	item, found := c.Get("a")
+
+	require.True(t, found)
+	assert.Equal(t, expected, item)
+}
+
+func TestLRUCache_whenDiskTierEnabledAndNotPrivateCacheItem(t *testing.T) {
+	db := memorydb.New()
+	newLRUCache(2, time.Minute, newDiskCache(db, time.Minute)).Set("a", "not a private cache item")
+
+	_, found := newLRUCache(2, time.Minute, newDiskCache(db, time.Minute)).Get("a")
+
+	assert.False(t, found, "expected only private cache items to be written to disk")
+}
+
+func TestLRUCache_whenDiskItemExpired(t *testing.T) {
+	db := memorydb.New()
+	newLRUCache(2, time.Minute, newDiskCache(db, time.Nanosecond)).Set("a", PrivateCacheItem{Payload: []byte("a")})
+	newLRUCache(2, time.Minute, newDiskCache(db, time.Hour)).Set("b", PrivateCacheItem{Payload: []byte("b")})
+	require.NoError(t, db.Put(diskKey("c"), []byte("not json")))
+	time.Sleep(time.Second) // expiry is stored in seconds
+
+	c := newLRUCache(2, time.Minute, newDiskCache(db, time.Hour))
+	_, found := c.Get("a")
+	assert.False(t, found, "expected expired item not to be returned")
+
+	c.disk.prune()
+
+	has, err := db.Has(diskKey("a"))
+	require.NoError(t, err)
+	assert.False(t, has, "expected expired item to be pruned")
+	has, err = db.Has(diskKey("c"))
+	require.NoError(t, err)
+	assert.False(t, has, "expected undecodable item to be pruned")
+	has, err = db.Has(diskKey("b"))
+	require.NoError(t, err)
+	assert.True(t, has, "expected unexpired item to be kept")
+}
+
+func TestNew_whenDiskTierEnabled(t *testing.T) {
+	dir := t.TempDir()
+	c, err := New(Config{Dir: dir})
+	require.NoError(t, err)
+	c.Set("a", PrivateCacheItem{Payload: []byte("a")})
+	require.NoError(t, c.Close())
+
+	// the database is released on close, so that it can be opened again
+	c, err = New(Config{Dir: dir})
+	require.NoError(t, err)
+	defer c.Close()
+	c.(*lruCache).lru.Purge()
+	item, found := c.Get("a")
+	require.True(t, found)
+	assert.Equal(t, []byte("a"), item.(PrivateCacheItem).Payload)
+}
@@ -0,0 +1,139 @@
+package cache
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/private/engine"
+)
+
+var diskKeyPrefix = []byte("ptm-cache-")
+
+// diskPruneInterval is the interval between removals of the expired items from disk
+const diskPruneInterval = time.Hour
+
+// diskEntry is a PrivateCacheItem as stored on disk, with the time it expires at
+type diskEntry struct {
+	PrivateCacheItem
+	Expires int64 // unix time in seconds
+}
+
+// diskCache persists PrivateCacheItem values in a dedicated key-value store. Items are
+// stored as JSON, which is how ExtraMetadata is already exchanged with the transaction
+// manager, and unencrypted, so the store must be protected like the transaction manager's
+// own. Items expire after a fixed time, so that the store does not grow indefinitely: the
+// expired items are removed when the store is opened and periodically afterwards.
+type diskCache struct {
+	db         ethdb.KeyValueStore
+	expiration time.Duration
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+func newDiskCache(db ethdb.KeyValueStore, expiration time.Duration) *diskCache {
+	if expiration <= 0 {
+		expiration = DefaultDiskExpiration
+	}
+	return &diskCache{db: db, expiration: expiration}
+}
+
+// startPruning removes the expired items now and then every diskPruneInterval, until the
+// store is closed
+func (d *diskCache) startPruning() {
+	d.quit = make(chan struct{})
+	d.prune()
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+		ticker := time.NewTicker(diskPruneInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				d.prune()
+			case <-d.quit:
+				return
+			}
+		}
+	}()
+}
+
+func (d *diskCache) get(key string) (PrivateCacheItem, bool) {
+	data, err := d.db.Get(diskKey(key))
+	if err != nil {
+		return PrivateCacheItem{}, false
+	}
+	entry, ok := decodeDiskEntry(data)
+	if !ok || d.expired(entry) {
+		return PrivateCacheItem{}, false
+	}
+	return entry.PrivateCacheItem, true
+}
+
+func (d *diskCache) put(key string, item PrivateCacheItem) error {
+	data, err := json.Marshal(&diskEntry{PrivateCacheItem: item, Expires: time.Now().Add(d.expiration).Unix()})
+	if err != nil {
+		return err
+	}
+	return d.db.Put(diskKey(key), data)
+}
+
+func (d *diskCache) delete(key string) error {
+	return d.db.Delete(diskKey(key))
+}
+
+// prune removes the expired items, and the ones which cannot be decoded
+func (d *diskCache) prune() {
+	it := d.db.NewIterator(diskKeyPrefix, nil)
+	defer it.Release()
+
+	batch := d.db.NewBatch()
+	pruned := 0
+	for it.Next() {
+		if entry, ok := decodeDiskEntry(it.Value()); ok && !d.expired(entry) {
+			continue
+		}
+		if err := batch.Delete(common.CopyBytes(it.Key())); err != nil {
+			log.Warn("Failed to prune the private payload disk cache", "err", err)
+			return
+		}
+		pruned++
+	}
+	if err := batch.Write(); err != nil {
+		log.Warn("Failed to prune the private payload disk cache", "err", err)
+		return
+	}
+	if pruned > 0 {
+		log.Debug("Pruned expired private payloads from the disk cache", "count", pruned)
+	}
+}
+
+func (d *diskCache) close() error {
+	if d.quit != nil {
+		close(d.quit)
+		d.wg.Wait()
+	}
+	return d.db.Close()
+}
+
+func (d *diskCache) expired(entry diskEntry) bool {
+	return time.Now().Unix() >= entry.Expires
+}
+
+func decodeDiskEntry(data []byte) (diskEntry, bool) {
+	// the affected contract hashes are decoded into an existing map
+	entry := diskEntry{PrivateCacheItem: PrivateCacheItem{Extra: engine.ExtraMetadata{ACHashes: make(common.EncryptedPayloadHashes)}}}
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return entry, false
+	}
+	return entry, true
+}
+
+func diskKey(key string) []byte {
+	return append(append([]byte{}, diskKeyPrefix...), key...)
+}
@@ -0,0 +1,122 @@
+package cache
+
+import (
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/hashicorp/golang-lru/simplelru"
+)
+
+var (
+	hitMeter      = metrics.NewRegisteredMeter("ptm/cache/hits", nil)
+	missMeter     = metrics.NewRegisteredMeter("ptm/cache/misses", nil)
+	evictionMeter = metrics.NewRegisteredMeter("ptm/cache/evictions", nil)
+	diskHitMeter  = metrics.NewRegisteredMeter("ptm/cache/disk/hits", nil)
+	sizeGauge     = metrics.NewRegisteredGauge("ptm/cache/size", nil)
+)
+
+type lruEntry struct {
+	value   interface{}
+	expires time.Time
+}
+
+// lruCache is a Cache bounded by the number of items it holds, evicting the least recently
+// used item when it is full. Items also expire after a fixed time, so that they do not stay
+// in memory indefinitely on a quiet node.
+//
+// If a disk tier is configured, PrivateCacheItem values are also written to disk and items
+// missing from memory are looked up on disk, so that decrypted payloads survive restarts.
+type lruCache struct {
+	lru        *simplelru.LRU
+	expiration time.Duration
+	disk       *diskCache // nil if the disk tier is disabled
+	lock       sync.Mutex
+}
+
+func newLRUCache(size int, expiration time.Duration, disk *diskCache) *lruCache {
+	if size <= 0 {
+		size = DefaultSize
+	}
+	if expiration <= 0 {
+		expiration = DefaultExpiration
+	}
+	lru, _ := simplelru.NewLRU(size, nil) // error only on non-positive size
+	return &lruCache{
+		lru:        lru,
+		expiration: expiration,
+		disk:       disk,
+	}
+}
+
+// The disk tier is accessed without holding the lock, so that a slow disk does not block the
+// lookups of the items held in memory.
+func (c *lruCache) Get(key string) (interface{}, bool) {
+	c.lock.Lock()
+	if v, ok := c.lru.Get(key); ok {
+		entry := v.(*lruEntry)
+		if time.Now().Before(entry.expires) {
+			c.lock.Unlock()
+			hitMeter.Mark(1)
+			return entry.value, true
+		}
+		c.lru.Remove(key)
+	}
+	c.lock.Unlock()
+
+	if c.disk != nil {
+		if item, ok := c.disk.get(key); ok {
+			diskHitMeter.Mark(1)
+			hitMeter.Mark(1)
+			c.lock.Lock()
+			c.add(key, item)
+			c.lock.Unlock()
+			return item, true
+		}
+	}
+	missMeter.Mark(1)
+	return nil, false
+}
+
+func (c *lruCache) Set(key string, value interface{}) {
+	c.lock.Lock()
+	c.add(key, value)
+	c.lock.Unlock()
+
+	if c.disk != nil {
+		if item, ok := value.(PrivateCacheItem); ok {
+			if err := c.disk.put(key, item); err != nil {
+				log.Warn("Failed to write private payload to the disk cache", "key", key, "err", err)
+			}
+		}
+	}
+}
+
+func (c *lruCache) Delete(key string) {
+	c.lock.Lock()
+	c.lru.Remove(key)
+	sizeGauge.Update(int64(c.lru.Len()))
+	c.lock.Unlock()
+
+	if c.disk != nil {
+		if err := c.disk.delete(key); err != nil {
+			log.Warn("Failed to delete private payload from the disk cache", "key", key, "err", err)
+		}
+	}
+}
+
+// Close closes the disk tier, if any
+func (c *lruCache) Close() error {
+	if c.disk != nil {
+		return c.disk.close()
+	}
+	return nil
+}
+
+func (c *lruCache) add(key string, value interface{}) {
+	if evicted := c.lru.Add(key, &lruEntry{value: value, expires: time.Now().Add(c.expiration)}); evicted {
+		evictionMeter.Mark(1)
+	}
+	sizeGauge.Update(int64(c.lru.Len()))
+}
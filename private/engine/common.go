@@ -18,6 +18,7 @@ const (
 var (
 	ErrPrivateTxManagerNotinUse                          = errors.New("private transaction manager is not in use")
 	ErrPrivateTxManagerNotReady                          = errors.New("private transaction manager is not ready")
+	ErrPrivateTxManagerUnreachable                       = errors.New("private transaction manager is unreachable")
 	ErrPrivateTxManagerNotSupported                      = errors.New("private transaction manager does not support this operation")
 	ErrPrivateTxManagerDoesNotSupportPrivacyEnhancements = errors.New("private transaction manager does not support privacy enhancements")
 	ErrPrivateTxManagerDoesNotSupportMandatoryRecipients = errors.New("private transaction manager does not support mandatory recipients")
@@ -58,6 +59,8 @@ type QuorumPayloadExtra struct {
 type Client struct {
 	HttpClient *http.Client
 	BaseURL    string
+	Retry      *RetryPolicy    // nil if calls are not retried
+	Breaker    *CircuitBreaker // nil if calls never fail fast
 }
 
 func (c *Client) FullPath(path string) string {
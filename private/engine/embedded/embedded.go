@@ -0,0 +1,672 @@
+// Package embedded implements an in-process private transaction manager intended for
+// development and tests. It keeps encrypted payloads in a local database and uses NaCl
+// box keys in the same way as Tessera, so that several nodes running in one process, or on
+// the same host, can exchange private transactions without a Tessera node each. It is
+// enabled in geth with the --ptm.embedded.* flags.
+package embedded
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/private/engine"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// DefaultResidentGroup is the resident group of the keys which are not part of any configured group
+const DefaultResidentGroup = "private"
+
+var payloadPrefix = []byte("ptm-payload-") // payloadPrefix + hash -> RLP encoded encodedPayload
+
+// Config holds the keys managed by an embedded private transaction manager
+type Config struct {
+	// Keys managed by the transaction manager, the first one is used when no sender is given
+	Keys []*KeyPair
+	// ResidentGroups partitions the keys into private states when running with multiple private states
+	ResidentGroups []ResidentGroup
+}
+
+// ResidentGroup is a named group of managed keys sharing a private state
+type ResidentGroup struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Members     []string `json:"members"` // base64 encoded public keys
+}
+
+// configFile is the JSON format of the file read by LoadConfig
+type configFile struct {
+	Keys []struct {
+		PublicKey  string `json:"publicKey"`
+		PrivateKey string `json:"privateKey"`
+	} `json:"keys"`
+	ResidentGroups []ResidentGroup `json:"residentGroups"`
+}
+
+// LoadConfig reads the keys and the resident groups from a JSON file such as
+//
+//	{
+//	  "keys": [{"publicKey": "<base64>", "privateKey": "<base64>"}],
+//	  "residentGroups": [{"name": "tenantA", "members": ["<base64>"]}]
+//	}
+func LoadConfig(path string) (Config, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, err
+	}
+	var file configFile
+	if err := json.Unmarshal(raw, &file); err != nil {
+		return Config{}, fmt.Errorf("unable to parse %s: %v", path, err)
+	}
+	cfg := Config{ResidentGroups: file.ResidentGroups}
+	for _, key := range file.Keys {
+		keyPair, err := NewKeyPair(key.PublicKey, key.PrivateKey)
+		if err != nil {
+			return Config{}, err
+		}
+		cfg.Keys = append(cfg.Keys, keyPair)
+	}
+	return cfg, nil
+}
+
+type embeddedPrivateTxManager struct {
+	features   *engine.FeatureSet
+	db         ethdb.KeyValueStore
+	network    *Network
+	keys       map[string]*KeyPair // by base64 public key
+	defaultKey *KeyPair
+	groups     []engine.PrivacyGroup
+}
+
+func Is(ptm interface{}) bool {
+	_, ok := ptm.(*embeddedPrivateTxManager)
+	return ok
+}
+
+// New creates an embedded private transaction manager storing its payloads in db. Payloads
+// for keys managed by other transaction managers are delivered through the network, which
+// may be nil when all parties are managed by this transaction manager.
+func New(cfg Config, db ethdb.KeyValueStore, network *Network) (*embeddedPrivateTxManager, error) {
+	if len(cfg.Keys) == 0 {
+		return nil, errors.New("at least one key must be configured")
+	}
+	t := &embeddedPrivateTxManager{
+		features:   engine.NewFeatureSet(engine.PrivacyEnhancements, engine.MultiplePrivateStates, engine.MandatoryRecipients),
+		db:         db,
+		network:    network,
+		keys:       make(map[string]*KeyPair),
+		defaultKey: cfg.Keys[0],
+	}
+	for _, key := range cfg.Keys {
+		t.keys[key.PublicKey()] = key
+	}
+	groups, err := residentGroups(cfg.Keys, cfg.ResidentGroups)
+	if err != nil {
+		return nil, err
+	}
+	t.groups = groups
+	if network != nil {
+		if err := network.join(t); err != nil {
+			return nil, err
+		}
+	}
+	return t, nil
+}
+
+// residentGroups validates the configured groups and assigns the remaining keys to the default group
+func residentGroups(keys []*KeyPair, configured []ResidentGroup) ([]engine.PrivacyGroup, error) {
+	assigned := make(map[string]string)
+	groups := make([]engine.PrivacyGroup, 0, len(configured)+1)
+	defaultIdx := -1
+	for _, group := range configured {
+		for _, member := range group.Members {
+			if existing, ok := assigned[member]; ok {
+				return nil, fmt.Errorf("key %s is part of resident groups %s and %s", member, existing, group.Name)
+			}
+			assigned[member] = group.Name
+		}
+		if group.Name == DefaultResidentGroup {
+			defaultIdx = len(groups)
+		}
+		groups = append(groups, newResidentGroup(group.Name, group.Description, group.Members))
+	}
+	for member := range assigned {
+		if !containsKey(keys, member) {
+			return nil, fmt.Errorf("resident group member %s is not a managed key", member)
+		}
+	}
+	var unassigned []string
+	for _, key := range keys {
+		if _, ok := assigned[key.PublicKey()]; !ok {
+			unassigned = append(unassigned, key.PublicKey())
+		}
+	}
+	if len(unassigned) > 0 {
+		if defaultIdx < 0 {
+			groups = append(groups, newResidentGroup(DefaultResidentGroup, "default resident group", nil))
+			defaultIdx = len(groups) - 1
+		}
+		groups[defaultIdx].Members = append(groups[defaultIdx].Members, unassigned...)
+	}
+	return groups, nil
+}
+
+func newResidentGroup(name, description string, members []string) engine.PrivacyGroup {
+	return engine.PrivacyGroup{
+		Type:           engine.PrivacyGroupResident,
+		Name:           name,
+		PrivacyGroupId: base64.StdEncoding.EncodeToString([]byte(name)),
+		Description:    description,
+		Members:        append([]string{}, members...),
+	}
+}
+
+func containsKey(keys []*KeyPair, key string) bool {
+	for _, k := range keys {
+		if k.PublicKey() == key {
+			return true
+		}
+	}
+	return false
+}
+
+func (t *embeddedPrivateTxManager) Send(data []byte, from string, to []string, extra *engine.ExtraMetadata) (string, []string, common.EncryptedPayloadHash, error) {
+	sender, err := t.senderKey(from)
+	if err != nil {
+		return "", nil, common.EncryptedPayloadHash{}, err
+	}
+	payload, masterKey, err := sealPayload(data, sender)
+	if err != nil {
+		return "", nil, common.EncryptedPayloadHash{}, err
+	}
+	recipients, err := recipientKeys(sender, to)
+	if err != nil {
+		return "", nil, common.EncryptedPayloadHash{}, err
+	}
+	if err := payload.setRecipients(masterKey, sender, recipients); err != nil {
+		return "", nil, common.EncryptedPayloadHash{}, err
+	}
+	if err := t.setExtra(payload, sender.PublicKey(), to, extra); err != nil {
+		return "", nil, common.EncryptedPayloadHash{}, err
+	}
+	hash := payload.hash()
+	if err := t.distribute(hash, payload); err != nil {
+		return "", nil, common.EncryptedPayloadHash{}, err
+	}
+	return sender.PublicKey(), t.managedParties(payload), hash, nil
+}
+
+func (t *embeddedPrivateTxManager) StoreRaw(data []byte, from string) (common.EncryptedPayloadHash, error) {
+	sender, err := t.senderKey(from)
+	if err != nil {
+		return common.EncryptedPayloadHash{}, err
+	}
+	payload, masterKey, err := sealPayload(data, sender)
+	if err != nil {
+		return common.EncryptedPayloadHash{}, err
+	}
+	if err := payload.setRecipients(masterKey, sender, []*[keyLength]byte{sender.Public}); err != nil {
+		return common.EncryptedPayloadHash{}, err
+	}
+	payload.Raw = true
+	hash := payload.hash()
+	if err := t.store(hash, payload); err != nil {
+		return common.EncryptedPayloadHash{}, err
+	}
+	return hash, nil
+}
+
+// SendSignedTx distributes a payload previously stored with StoreRaw. The cipher text is
+// unchanged so the payload keeps the hash returned by StoreRaw.
+func (t *embeddedPrivateTxManager) SendSignedTx(hash common.EncryptedPayloadHash, to []string, extra *engine.ExtraMetadata) (string, []string, []byte, error) {
+	payload, err := t.load(hash)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	if payload == nil {
+		return "", nil, nil, fmt.Errorf("raw transaction %s not found", hash.ToBase64())
+	}
+	sender, isSender := findKey(t.keys, payload.SenderKey)
+	if !isSender {
+		return "", nil, nil, fmt.Errorf("raw transaction %s was not stored by a managed key", hash.ToBase64())
+	}
+	masterKey, err := payload.masterKey(t.keys)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	recipients, err := recipientKeys(sender, to)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	if err := payload.setRecipients(masterKey, sender, recipients); err != nil {
+		return "", nil, nil, err
+	}
+	if err := t.setExtra(payload, sender.PublicKey(), to, extra); err != nil {
+		return "", nil, nil, err
+	}
+	payload.Raw = false
+	if err := t.distribute(hash, payload); err != nil {
+		return "", nil, nil, err
+	}
+	return sender.PublicKey(), t.managedParties(payload), hash.Bytes(), nil
+}
+
+func (t *embeddedPrivateTxManager) Receive(hash common.EncryptedPayloadHash) (string, []string, []byte, *engine.ExtraMetadata, error) {
+	return t.receive(hash, false)
+}
+
+// retrieve raw will not return information about medata.
+// Related to SendSignedTx
+func (t *embeddedPrivateTxManager) ReceiveRaw(hash common.EncryptedPayloadHash) ([]byte, string, *engine.ExtraMetadata, error) {
+	sender, _, data, extra, err := t.receive(hash, true)
+	return data, sender, extra, err
+}
+
+func (t *embeddedPrivateTxManager) receive(hash common.EncryptedPayloadHash, isRaw bool) (string, []string, []byte, *engine.ExtraMetadata, error) {
+	if common.EmptyEncryptedPayloadHash(hash) {
+		return "", nil, nil, nil, nil
+	}
+	payload, err := t.load(hash)
+	if err != nil {
+		return "", nil, nil, nil, err
+	}
+	if payload == nil || (payload.Raw && !isRaw) {
+		return "", nil, nil, nil, nil
+	}
+	data, err := payload.open(t.keys)
+	if err != nil {
+		return "", nil, nil, nil, err
+	}
+	sender := base64.StdEncoding.EncodeToString(payload.SenderKey)
+	managedParties := t.managedParties(payload)
+	extra := &engine.ExtraMetadata{
+		ManagedParties: managedParties,
+		Sender:         sender,
+	}
+	if !isRaw {
+		extra.ACHashes = make(common.EncryptedPayloadHashes, len(payload.AffectedContractTxs))
+		for _, acHash := range payload.AffectedContractTxs {
+			extra.ACHashes.Add(common.BytesToEncryptedPayloadHash(acHash))
+		}
+		extra.ACMerkleRoot = common.BytesToHash(payload.ExecHash)
+		extra.PrivacyFlag = engine.PrivacyFlagType(payload.PrivacyFlag)
+	}
+	return sender, managedParties, data, extra, nil
+}
+
+func (t *embeddedPrivateTxManager) IsSender(hash common.EncryptedPayloadHash) (bool, error) {
+	payload, err := t.loadExisting(hash)
+	if err != nil {
+		return false, err
+	}
+	_, isSender := findKey(t.keys, payload.SenderKey)
+	return isSender, nil
+}
+
+func (t *embeddedPrivateTxManager) GetParticipants(hash common.EncryptedPayloadHash) ([]string, error) {
+	payload, err := t.loadExisting(hash)
+	if err != nil {
+		return nil, err
+	}
+	participants := make([]string, len(payload.RecipientKeys))
+	for i, key := range payload.RecipientKeys {
+		participants[i] = base64.StdEncoding.EncodeToString(key)
+	}
+	return participants, nil
+}
+
+func (t *embeddedPrivateTxManager) GetMandatory(hash common.EncryptedPayloadHash) ([]string, error) {
+	payload, err := t.loadExisting(hash)
+	if err != nil {
+		return nil, err
+	}
+	return payload.MandatoryRecipients, nil
+}
+
+type encryptPayloadResponse struct {
+	SenderKey       []byte   `json:"senderKey"`
+	CipherText      []byte   `json:"cipherText"`
+	CipherTextNonce []byte   `json:"cipherTextNonce"`
+	RecipientBoxes  []string `json:"recipientBoxes"`
+	RecipientNonce  []byte   `json:"recipientNonce"`
+	RecipientKeys   []string `json:"recipientKeys"`
+}
+
+// encryptedEnvelope is what EncryptPayload encrypts, so that the privacy metadata is returned
+// by DecryptPayload, as the decrypt request only carries the cipher text and the recipient boxes
+type encryptedEnvelope struct {
+	Data                []byte
+	AffectedContractTxs [][]byte
+	ExecHash            []byte
+	PrivacyFlag         uint64
+	MandatoryRecipients []string
+}
+
+// EncryptPayload encrypts the payload without storing or distributing it, the result has the
+// same format as Tessera's /encodedpayload/create response. The privacy metadata is validated
+// as with Send and encrypted along with the payload.
+func (t *embeddedPrivateTxManager) EncryptPayload(data []byte, from string, to []string, extra *engine.ExtraMetadata) ([]byte, error) {
+	sender, err := t.senderKey(from)
+	if err != nil {
+		return nil, err
+	}
+	masterKey, err := newMasterKey()
+	if err != nil {
+		return nil, err
+	}
+	recipients, err := recipientKeys(sender, to)
+	if err != nil {
+		return nil, err
+	}
+	payload := &encodedPayload{SenderKey: sender.Public[:]}
+	if err := payload.setRecipients(masterKey, sender, recipients); err != nil {
+		return nil, err
+	}
+	if err := t.setExtra(payload, sender.PublicKey(), to, extra); err != nil {
+		return nil, err
+	}
+	envelope, err := rlp.EncodeToBytes(&encryptedEnvelope{
+		Data:                data,
+		AffectedContractTxs: payload.AffectedContractTxs,
+		ExecHash:            payload.ExecHash,
+		PrivacyFlag:         payload.PrivacyFlag,
+		MandatoryRecipients: payload.MandatoryRecipients,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := payload.seal(envelope, masterKey); err != nil {
+		return nil, err
+	}
+	response := &encryptPayloadResponse{
+		SenderKey:       payload.SenderKey,
+		CipherText:      payload.CipherText,
+		CipherTextNonce: payload.CipherTextNonce,
+		RecipientNonce:  payload.RecipientNonce,
+	}
+	for i := range payload.RecipientKeys {
+		response.RecipientBoxes = append(response.RecipientBoxes, base64.StdEncoding.EncodeToString(payload.RecipientBoxes[i]))
+		response.RecipientKeys = append(response.RecipientKeys, base64.StdEncoding.EncodeToString(payload.RecipientKeys[i]))
+	}
+	return json.Marshal(response)
+}
+
+func (t *embeddedPrivateTxManager) DecryptPayload(request common.DecryptRequest) ([]byte, *engine.ExtraMetadata, error) {
+	if len(request.RecipientBoxes) != len(request.RecipientKeys) {
+		return nil, nil, errors.New("number of recipient boxes and recipient keys do not match")
+	}
+	payload := &encodedPayload{
+		SenderKey:       request.SenderKey,
+		CipherText:      request.CipherText,
+		CipherTextNonce: request.CipherTextNonce,
+		RecipientNonce:  request.RecipientNonce,
+	}
+	for i := range request.RecipientKeys {
+		recipientBox, err := base64.StdEncoding.DecodeString(request.RecipientBoxes[i])
+		if err != nil {
+			return nil, nil, fmt.Errorf("unable to decode recipient box: %v", err)
+		}
+		recipientKey, err := base64.StdEncoding.DecodeString(request.RecipientKeys[i])
+		if err != nil {
+			return nil, nil, fmt.Errorf("unable to decode recipient key: %v", err)
+		}
+		payload.RecipientBoxes = append(payload.RecipientBoxes, recipientBox)
+		payload.RecipientKeys = append(payload.RecipientKeys, recipientKey)
+	}
+	opened, err := payload.open(t.keys)
+	if err != nil {
+		return nil, nil, err
+	}
+	var envelope encryptedEnvelope
+	if err := rlp.DecodeBytes(opened, &envelope); err != nil {
+		return nil, nil, fmt.Errorf("unable to decode encrypted payload: %v", err)
+	}
+	extra := &engine.ExtraMetadata{
+		ACHashes:            make(common.EncryptedPayloadHashes, len(envelope.AffectedContractTxs)),
+		ACMerkleRoot:        common.BytesToHash(envelope.ExecHash),
+		PrivacyFlag:         engine.PrivacyFlagType(envelope.PrivacyFlag),
+		MandatoryRecipients: envelope.MandatoryRecipients,
+	}
+	for _, acHash := range envelope.AffectedContractTxs {
+		extra.ACHashes.Add(common.BytesToEncryptedPayloadHash(acHash))
+	}
+	return envelope.Data, extra, nil
+}
+
+func (t *embeddedPrivateTxManager) Groups() ([]engine.PrivacyGroup, error) {
+	return t.groups, nil
+}
+
+func (t *embeddedPrivateTxManager) Name() string {
+	return "Embedded"
+}
+
+func (t *embeddedPrivateTxManager) HasFeature(f engine.PrivateTransactionManagerFeature) bool {
+	return t.features.HasFeature(f)
+}
+
+func (t *embeddedPrivateTxManager) senderKey(from string) (*KeyPair, error) {
+	if from == "" {
+		return t.defaultKey, nil
+	}
+	key, ok := t.keys[from]
+	if !ok {
+		return nil, fmt.Errorf("sender %s is not a managed key", from)
+	}
+	return key, nil
+}
+
+// setExtra validates the privacy metadata in the same way as Tessera and records it in the
+// payload, whose recipients must already be set
+func (t *embeddedPrivateTxManager) setExtra(payload *encodedPayload, from string, to []string, extra *engine.ExtraMetadata) error {
+	if extra == nil {
+		return nil
+	}
+	if err := extra.PrivacyFlag.Validate(); err != nil {
+		return err
+	}
+	if extra.PrivacyFlag == engine.PrivacyFlagMandatoryRecipients {
+		if len(extra.MandatoryRecipients) == 0 {
+			return errors.New("missing mandatory recipients data")
+		}
+		for _, mandatory := range extra.MandatoryRecipients {
+			if mandatory != from && !containsString(to, mandatory) {
+				return fmt.Errorf("mandatory recipient %s is not included in the participant list", mandatory)
+			}
+		}
+	} else if len(extra.MandatoryRecipients) > 0 {
+		return errors.New("mandatory recipients are only applicable for privacy flag MandatoryRecipients")
+	}
+	if extra.PrivacyFlag == engine.PrivacyFlagStateValidation && common.EmptyHash(extra.ACMerkleRoot) {
+		return errors.New("execution hash is required for privacy flag StateValidation")
+	}
+	payload.AffectedContractTxs = make([][]byte, 0, len(extra.ACHashes))
+	for acHash := range extra.ACHashes {
+		payload.AffectedContractTxs = append(payload.AffectedContractTxs, acHash.Bytes())
+	}
+	if !common.EmptyHash(extra.ACMerkleRoot) {
+		payload.ExecHash = extra.ACMerkleRoot.Bytes()
+	}
+	payload.PrivacyFlag = uint64(extra.PrivacyFlag)
+	payload.MandatoryRecipients = extra.MandatoryRecipients
+	return t.checkAffectedContracts(payload)
+}
+
+// checkAffectedContracts enforces the privacy of the contracts affected by a payload with
+// enhanced privacy in the same way as Tessera: the affected contract transactions must be
+// known with the same privacy flag and the sender must be one of their participants. With
+// StateValidation the payload must also have the same participants as each of them.
+func (t *embeddedPrivateTxManager) checkAffectedContracts(payload *encodedPayload) error {
+	privacyFlag := engine.PrivacyFlagType(payload.PrivacyFlag)
+	if !privacyFlag.IsNotStandardPrivate() {
+		return nil
+	}
+	for _, acHash := range payload.AffectedContractTxs {
+		hash := common.BytesToEncryptedPayloadHash(acHash)
+		affected, err := t.load(hash)
+		if err != nil {
+			return err
+		}
+		if affected == nil {
+			return fmt.Errorf("affected contract transaction %s not found", hash.ToBase64())
+		}
+		if engine.PrivacyFlagType(affected.PrivacyFlag) != privacyFlag {
+			return fmt.Errorf("privacy flag of affected contract transaction %s does not match", hash.ToBase64())
+		}
+		if !affected.isParticipant(payload.SenderKey) {
+			return fmt.Errorf("sender %s is not a participant of affected contract transaction %s", base64.StdEncoding.EncodeToString(payload.SenderKey), hash.ToBase64())
+		}
+		if privacyFlag == engine.PrivacyFlagStateValidation && !sameKeys(affected.participants(), payload.participants()) {
+			return fmt.Errorf("participants do not match the ones of affected contract transaction %s", hash.ToBase64())
+		}
+	}
+	return nil
+}
+
+// distribute stores the payload and delivers it to the transaction managers owning the other
+// recipients, either in this process or in the process of a peer of the network
+func (t *embeddedPrivateTxManager) distribute(hash common.EncryptedPayloadHash, payload *encodedPayload) error {
+	deliveries := make(map[*embeddedPrivateTxManager][][]byte)
+	pushes := make(map[string][][]byte)
+	for _, recipient := range payload.RecipientKeys {
+		if _, ok := findKey(t.keys, recipient); ok {
+			continue
+		}
+		b64 := base64.StdEncoding.EncodeToString(recipient)
+		if t.network == nil {
+			return fmt.Errorf("recipient %s is not known to the network", b64)
+		}
+		if owner, ok := t.network.owner(b64); ok {
+			deliveries[owner] = append(deliveries[owner], recipient)
+		} else if peer, ok := t.network.peer(b64); ok {
+			pushes[peer] = append(pushes[peer], recipient)
+		} else {
+			return fmt.Errorf("recipient %s is not known to the network", b64)
+		}
+	}
+	if err := t.store(hash, payload); err != nil {
+		return err
+	}
+	for owner, recipients := range deliveries {
+		if err := owner.accept(hash, payload.forRecipients(recipients)); err != nil {
+			return fmt.Errorf("unable to deliver payload %s: %v", hash.ToBase64(), err)
+		}
+	}
+	for peer, recipients := range pushes {
+		if err := t.network.push(peer, payload.forRecipients(recipients)); err != nil {
+			return fmt.Errorf("unable to deliver payload %s: %v", hash.ToBase64(), err)
+		}
+	}
+	return nil
+}
+
+// accept stores a payload delivered by the transaction manager of its sender. As with Tessera,
+// a payload violating the privacy of the contracts it affects is ignored.
+func (t *embeddedPrivateTxManager) accept(hash common.EncryptedPayloadHash, payload *encodedPayload) error {
+	if err := t.checkAffectedContracts(payload); err != nil {
+		log.Warn("Ignoring private payload violating the privacy of affected contracts", "hash", hash.ToBase64(), "err", err)
+		return nil
+	}
+	return t.store(hash, payload)
+}
+
+// managedParties returns the managed keys which are either the sender or a recipient of the payload
+func (t *embeddedPrivateTxManager) managedParties(payload *encodedPayload) []string {
+	var managed []string
+	if key, ok := findKey(t.keys, payload.SenderKey); ok {
+		managed = append(managed, key.PublicKey())
+	}
+	for _, recipient := range payload.RecipientKeys {
+		if key, ok := findKey(t.keys, recipient); ok && !containsString(managed, key.PublicKey()) {
+			managed = append(managed, key.PublicKey())
+		}
+	}
+	return managed
+}
+
+func (t *embeddedPrivateTxManager) store(hash common.EncryptedPayloadHash, payload *encodedPayload) error {
+	enc, err := rlp.EncodeToBytes(payload)
+	if err != nil {
+		return err
+	}
+	return t.db.Put(payloadKey(hash), enc)
+}
+
+// load returns nil if the payload is not found
+func (t *embeddedPrivateTxManager) load(hash common.EncryptedPayloadHash) (*encodedPayload, error) {
+	key := payloadKey(hash)
+	if ok, _ := t.db.Has(key); !ok {
+		return nil, nil
+	}
+	enc, err := t.db.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	payload := new(encodedPayload)
+	if err := rlp.DecodeBytes(enc, payload); err != nil {
+		return nil, fmt.Errorf("unable to decode payload %s: %v", hash.ToBase64(), err)
+	}
+	return payload, nil
+}
+
+func (t *embeddedPrivateTxManager) loadExisting(hash common.EncryptedPayloadHash) (*encodedPayload, error) {
+	payload, err := t.load(hash)
+	if err != nil {
+		return nil, err
+	}
+	if payload == nil {
+		return nil, fmt.Errorf("transaction %s not found", hash.ToBase64())
+	}
+	return payload, nil
+}
+
+func payloadKey(hash common.EncryptedPayloadHash) []byte {
+	return append(append([]byte{}, payloadPrefix...), hash.Bytes()...)
+}
+
+func recipientKeys(sender *KeyPair, to []string) ([]*[keyLength]byte, error) {
+	recipients := []*[keyLength]byte{sender.Public}
+	seen := map[string]bool{sender.PublicKey(): true}
+	for _, recipient := range to {
+		if seen[recipient] {
+			continue
+		}
+		key, err := decodeKey(recipient)
+		if err != nil {
+			return nil, fmt.Errorf("invalid recipient %s: %v", recipient, err)
+		}
+		seen[recipient] = true
+		recipients = append(recipients, key)
+	}
+	return recipients, nil
+}
+
+// sameKeys returns true if both lists hold the same keys, in any order
+func sameKeys(a, b [][]byte) bool {
+	set := make(map[string]bool, len(a))
+	for _, key := range a {
+		set[string(key)] = true
+	}
+	other := make(map[string]bool, len(b))
+	for _, key := range b {
+		if !set[string(key)] {
+			return false
+		}
+		other[string(key)] = true
+	}
+	return len(set) == len(other)
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,362 @@
+package embedded
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethdb/memorydb"
+	"github.com/ethereum/go-ethereum/private/engine"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var arbitraryPayload = []byte("arbitrary private payload")
+
+func newTestNode(t *testing.T, network *Network, keyCount int) (*embeddedPrivateTxManager, []string) {
+	keys := make([]*KeyPair, keyCount)
+	publicKeys := make([]string, keyCount)
+	for i := range keys {
+		key, err := GenerateKeyPair()
+		require.NoError(t, err)
+		keys[i] = key
+		publicKeys[i] = key.PublicKey()
+	}
+	ptm, err := New(Config{Keys: keys}, memorydb.New(), network)
+	require.NoError(t, err)
+	return ptm, publicKeys
+}
+
+func TestSend_whenTypical(t *testing.T) {
+	network := NewNetwork()
+	alice, aliceKeys := newTestNode(t, network, 1)
+	bob, bobKeys := newTestNode(t, network, 1)
+	charlie, _ := newTestNode(t, network, 1)
+
+	sender, managedParties, hash, err := alice.Send(arbitraryPayload, "", bobKeys, &engine.ExtraMetadata{})
+	require.NoError(t, err)
+	assert.Equal(t, aliceKeys[0], sender)
+	assert.Equal(t, aliceKeys, managedParties)
+
+	sender, managedParties, data, extra, err := bob.Receive(hash)
+	require.NoError(t, err)
+	assert.Equal(t, aliceKeys[0], sender)
+	assert.Equal(t, bobKeys, managedParties)
+	assert.Equal(t, arbitraryPayload, data)
+	assert.Equal(t, engine.PrivacyFlagStandardPrivate, extra.PrivacyFlag)
+
+	_, _, data, _, err = alice.Receive(hash)
+	require.NoError(t, err)
+	assert.Equal(t, arbitraryPayload, data)
+
+	_, _, data, extra, err = charlie.Receive(hash)
+	require.NoError(t, err)
+	assert.Nil(t, data, "expected payload not to be delivered to a non-participant")
+	assert.Nil(t, extra)
+}
+
+func TestSend_whenRecipientUnknown(t *testing.T) {
+	alice, _ := newTestNode(t, NewNetwork(), 1)
+	unknown, err := GenerateKeyPair()
+	require.NoError(t, err)
+
+	_, _, _, err = alice.Send(arbitraryPayload, "", []string{unknown.PublicKey()}, &engine.ExtraMetadata{})
+
+	assert.EqualError(t, err, "recipient "+unknown.PublicKey()+" is not known to the network")
+}
+
+func TestSend_whenMandatoryRecipientNotParticipant(t *testing.T) {
+	network := NewNetwork()
+	alice, _ := newTestNode(t, network, 1)
+	_, bobKeys := newTestNode(t, network, 1)
+	_, charlieKeys := newTestNode(t, network, 1)
+
+	_, _, _, err := alice.Send(arbitraryPayload, "", bobKeys, &engine.ExtraMetadata{
+		PrivacyFlag:         engine.PrivacyFlagMandatoryRecipients,
+		MandatoryRecipients: charlieKeys,
+	})
+
+	assert.EqualError(t, err, "mandatory recipient "+charlieKeys[0]+" is not included in the participant list")
+}
+
+func TestSend_whenPrivacyFlagDoesNotMatchAffectedContract(t *testing.T) {
+	network := NewNetwork()
+	alice, _ := newTestNode(t, network, 1)
+	_, bobKeys := newTestNode(t, network, 1)
+	_, _, creationHash, err := alice.Send(arbitraryPayload, "", bobKeys, &engine.ExtraMetadata{PrivacyFlag: engine.PrivacyFlagPartyProtection})
+	require.NoError(t, err)
+	acHashes := make(common.EncryptedPayloadHashes)
+	acHashes.Add(creationHash)
+
+	_, _, _, err = alice.Send(arbitraryPayload, "", bobKeys, &engine.ExtraMetadata{
+		ACHashes:     acHashes,
+		ACMerkleRoot: common.HexToHash("0x01"),
+		PrivacyFlag:  engine.PrivacyFlagStateValidation,
+	})
+
+	assert.EqualError(t, err, "privacy flag of affected contract transaction "+creationHash.ToBase64()+" does not match")
+}
+
+func TestSend_whenPrivacyEnhancements(t *testing.T) {
+	network := NewNetwork()
+	alice, _ := newTestNode(t, network, 1)
+	bob, bobKeys := newTestNode(t, network, 1)
+	_, _, creationHash, err := alice.Send(arbitraryPayload, "", bobKeys, &engine.ExtraMetadata{PrivacyFlag: engine.PrivacyFlagPartyProtection})
+	require.NoError(t, err)
+	acHashes := make(common.EncryptedPayloadHashes)
+	acHashes.Add(creationHash)
+	merkleRoot := common.HexToHash("0x01")
+
+	_, _, hash, err := alice.Send(arbitraryPayload, "", bobKeys, &engine.ExtraMetadata{
+		ACHashes:     acHashes,
+		ACMerkleRoot: merkleRoot,
+		PrivacyFlag:  engine.PrivacyFlagPartyProtection,
+	})
+	require.NoError(t, err)
+
+	_, _, _, extra, err := bob.Receive(hash)
+	require.NoError(t, err)
+	assert.Equal(t, engine.PrivacyFlagPartyProtection, extra.PrivacyFlag)
+	assert.Equal(t, merkleRoot, extra.ACMerkleRoot)
+	assert.Equal(t, acHashes, extra.ACHashes)
+}
+
+func TestSendSignedTx_keepsStoreRawHash(t *testing.T) {
+	network := NewNetwork()
+	alice, aliceKeys := newTestNode(t, network, 1)
+	bob, bobKeys := newTestNode(t, network, 1)
+
+	hash, err := alice.StoreRaw(arbitraryPayload, aliceKeys[0])
+	require.NoError(t, err)
+
+	data, sender, _, err := alice.ReceiveRaw(hash)
+	require.NoError(t, err)
+	assert.Equal(t, arbitraryPayload, data)
+	assert.Equal(t, aliceKeys[0], sender)
+	_, _, data, _, err = alice.Receive(hash)
+	require.NoError(t, err)
+	assert.Nil(t, data, "expected raw payload not to be returned before it is sent")
+
+	_, _, sentHash, err := alice.SendSignedTx(hash, bobKeys, &engine.ExtraMetadata{
+		PrivacyFlag:         engine.PrivacyFlagMandatoryRecipients,
+		MandatoryRecipients: bobKeys,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, hash.Bytes(), sentHash)
+
+	_, _, data, _, err = bob.Receive(hash)
+	require.NoError(t, err)
+	assert.Equal(t, arbitraryPayload, data)
+
+	isSender, err := alice.IsSender(hash)
+	require.NoError(t, err)
+	assert.True(t, isSender)
+	isSender, err = bob.IsSender(hash)
+	require.NoError(t, err)
+	assert.False(t, isSender)
+
+	participants, err := alice.GetParticipants(hash)
+	require.NoError(t, err)
+	assert.Equal(t, append(aliceKeys, bobKeys...), participants)
+	mandatory, err := bob.GetMandatory(hash)
+	require.NoError(t, err)
+	assert.Equal(t, bobKeys, mandatory)
+}
+
+func TestEncryptDecryptPayload(t *testing.T) {
+	network := NewNetwork()
+	alice, _ := newTestNode(t, network, 1)
+	bob, bobKeys := newTestNode(t, network, 1)
+	charlie, _ := newTestNode(t, network, 1)
+
+	encrypted, err := alice.EncryptPayload(arbitraryPayload, "", bobKeys, &engine.ExtraMetadata{})
+	require.NoError(t, err)
+	var request common.DecryptRequest
+	require.NoError(t, json.Unmarshal(encrypted, &request))
+
+	data, _, err := bob.DecryptPayload(request)
+	require.NoError(t, err)
+	assert.Equal(t, arbitraryPayload, data)
+
+	_, _, err = charlie.DecryptPayload(request)
+	assert.Equal(t, errNotARecipient, err)
+}
+
+func TestSend_whenRecipientsManagedBySameNode(t *testing.T) {
+	network := NewNetwork()
+	alice, aliceKeys := newTestNode(t, network, 1)
+	bob, bobKeys := newTestNode(t, network, 2)
+
+	_, _, hash, err := alice.Send(arbitraryPayload, aliceKeys[0], bobKeys, &engine.ExtraMetadata{})
+	require.NoError(t, err)
+
+	_, managedParties, data, _, err := bob.Receive(hash)
+	require.NoError(t, err)
+	assert.Equal(t, arbitraryPayload, data)
+	assert.ElementsMatch(t, bobKeys, managedParties)
+}
+
+func TestGroups_whenResidentGroupsConfigured(t *testing.T) {
+	keys := make([]*KeyPair, 3)
+	for i := range keys {
+		key, err := GenerateKeyPair()
+		require.NoError(t, err)
+		keys[i] = key
+	}
+	ptm, err := New(Config{
+		Keys: keys,
+		ResidentGroups: []ResidentGroup{
+			{Name: "tenantA", Members: []string{keys[0].PublicKey(), keys[1].PublicKey()}},
+		},
+	}, memorydb.New(), nil)
+	require.NoError(t, err)
+
+	groups, err := ptm.Groups()
+	require.NoError(t, err)
+	require.Len(t, groups, 2)
+	assert.Equal(t, engine.PrivacyGroupResident, groups[0].Type)
+	assert.Equal(t, base64.StdEncoding.EncodeToString([]byte("tenantA")), groups[0].PrivacyGroupId)
+	assert.Equal(t, DefaultResidentGroup, groups[1].Name)
+	assert.Equal(t, []string{keys[2].PublicKey()}, groups[1].Members)
+	assert.True(t, ptm.HasFeature(engine.MultiplePrivateStates))
+}
+
+func TestNew_whenKeyAlreadyInNetwork(t *testing.T) {
+	network := NewNetwork()
+	key, err := GenerateKeyPair()
+	require.NoError(t, err)
+	_, err = New(Config{Keys: []*KeyPair{key}}, memorydb.New(), network)
+	require.NoError(t, err)
+
+	_, err = New(Config{Keys: []*KeyPair{key}}, memorydb.New(), network)
+
+	assert.EqualError(t, err, "key "+key.PublicKey()+" is already managed by another transaction manager in the network")
+}
+
+func TestNewKeyPair(t *testing.T) {
+	key, err := GenerateKeyPair()
+	require.NoError(t, err)
+	other, err := GenerateKeyPair()
+	require.NoError(t, err)
+
+	restored, err := NewKeyPair(key.PublicKey(), key.PrivateKey())
+	require.NoError(t, err)
+	assert.Equal(t, key, restored)
+
+	_, err = NewKeyPair(other.PublicKey(), key.PrivateKey())
+	assert.EqualError(t, err, "public key "+other.PublicKey()+" does not belong to the private key")
+}
+
+func TestSend_whenSenderNotParticipantOfAffectedContract(t *testing.T) {
+	network := NewNetwork()
+	alice, aliceKeys := newTestNode(t, network, 2)
+	_, bobKeys := newTestNode(t, network, 1)
+	_, _, creationHash, err := alice.Send(arbitraryPayload, aliceKeys[0], bobKeys, &engine.ExtraMetadata{PrivacyFlag: engine.PrivacyFlagPartyProtection})
+	require.NoError(t, err)
+	acHashes := make(common.EncryptedPayloadHashes)
+	acHashes.Add(creationHash)
+
+	_, _, _, err = alice.Send(arbitraryPayload, aliceKeys[1], bobKeys, &engine.ExtraMetadata{
+		ACHashes:    acHashes,
+		PrivacyFlag: engine.PrivacyFlagPartyProtection,
+	})
+
+	assert.EqualError(t, err, "sender "+aliceKeys[1]+" is not a participant of affected contract transaction "+creationHash.ToBase64())
+}
+
+func TestSend_whenStateValidationParticipantsDiffer(t *testing.T) {
+	network := NewNetwork()
+	alice, aliceKeys := newTestNode(t, network, 1)
+	bob, bobKeys := newTestNode(t, network, 1)
+	_, charlieKeys := newTestNode(t, network, 1)
+	_, _, creationHash, err := alice.Send(arbitraryPayload, "", bobKeys, &engine.ExtraMetadata{
+		ACMerkleRoot: common.HexToHash("0x01"),
+		PrivacyFlag:  engine.PrivacyFlagStateValidation,
+	})
+	require.NoError(t, err)
+	acHashes := make(common.EncryptedPayloadHashes)
+	acHashes.Add(creationHash)
+	extra := &engine.ExtraMetadata{
+		ACHashes:     acHashes,
+		ACMerkleRoot: common.HexToHash("0x02"),
+		PrivacyFlag:  engine.PrivacyFlagStateValidation,
+	}
+
+	_, _, _, err = alice.Send(arbitraryPayload, "", append(bobKeys, charlieKeys...), extra)
+	assert.EqualError(t, err, "participants do not match the ones of affected contract transaction "+creationHash.ToBase64())
+
+	// the recipient knows the participants of the affected contract as well
+	_, _, _, err = bob.Send(arbitraryPayload, "", nil, extra)
+	assert.EqualError(t, err, "participants do not match the ones of affected contract transaction "+creationHash.ToBase64())
+	_, _, _, err = bob.Send(arbitraryPayload, "", aliceKeys, extra)
+	assert.NoError(t, err)
+}
+
+func TestEncryptDecryptPayload_whenExtraMetadata(t *testing.T) {
+	network := NewNetwork()
+	alice, _ := newTestNode(t, network, 1)
+	bob, bobKeys := newTestNode(t, network, 1)
+	_, _, creationHash, err := alice.Send(arbitraryPayload, "", bobKeys, &engine.ExtraMetadata{PrivacyFlag: engine.PrivacyFlagPartyProtection})
+	require.NoError(t, err)
+	acHashes := make(common.EncryptedPayloadHashes)
+	acHashes.Add(creationHash)
+	merkleRoot := common.HexToHash("0x01")
+
+	encrypted, err := alice.EncryptPayload(arbitraryPayload, "", bobKeys, &engine.ExtraMetadata{
+		ACHashes:     acHashes,
+		ACMerkleRoot: merkleRoot,
+		PrivacyFlag:  engine.PrivacyFlagPartyProtection,
+	})
+	require.NoError(t, err)
+	var request common.DecryptRequest
+	require.NoError(t, json.Unmarshal(encrypted, &request))
+
+	data, extra, err := bob.DecryptPayload(request)
+	require.NoError(t, err)
+	assert.Equal(t, arbitraryPayload, data)
+	assert.Equal(t, engine.PrivacyFlagPartyProtection, extra.PrivacyFlag)
+	assert.Equal(t, merkleRoot, extra.ACMerkleRoot)
+	assert.Equal(t, acHashes, extra.ACHashes)
+}
+
+func TestSend_whenRecipientManagedByPeer(t *testing.T) {
+	network, peerNetwork := NewNetwork(), NewNetwork()
+	alice, aliceKeys := newTestNode(t, network, 1)
+	bob, bobKeys := newTestNode(t, peerNetwork, 1)
+	addr, err := peerNetwork.Listen("127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { peerNetwork.Close() })
+	network.AddPeers("http://" + addr.String())
+
+	_, _, hash, err := alice.Send(arbitraryPayload, "", bobKeys, &engine.ExtraMetadata{})
+	require.NoError(t, err)
+
+	sender, managedParties, data, _, err := bob.Receive(hash)
+	require.NoError(t, err)
+	assert.Equal(t, aliceKeys[0], sender)
+	assert.Equal(t, bobKeys, managedParties)
+	assert.Equal(t, arbitraryPayload, data)
+}
+
+func TestListen_whenNotLoopback(t *testing.T) {
+	_, err := NewNetwork().Listen("0.0.0.0:0")
+
+	assert.EqualError(t, err, "listen address 0.0.0.0:0 is not a loopback address")
+}
+
+func TestLoadConfig(t *testing.T) {
+	key, err := GenerateKeyPair()
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "keys.json")
+	content := `{"keys": [{"publicKey": "` + key.PublicKey() + `", "privateKey": "` + key.PrivateKey() + `"}],
+		"residentGroups": [{"name": "tenantA", "members": ["` + key.PublicKey() + `"]}]}`
+	require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+
+	cfg, err := LoadConfig(path)
+	require.NoError(t, err)
+	assert.Equal(t, []*KeyPair{key}, cfg.Keys)
+	assert.Equal(t, []ResidentGroup{{Name: "tenantA", Members: []string{key.PublicKey()}}}, cfg.ResidentGroups)
+}
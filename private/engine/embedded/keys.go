@@ -0,0 +1,72 @@
+package embedded
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+
+	"golang.org/x/crypto/curve25519"
+	"golang.org/x/crypto/nacl/box"
+)
+
+const keyLength = 32
+
+// KeyPair is a NaCl box key pair identifying a party, in the same way as a Tessera key pair
+type KeyPair struct {
+	Public  *[keyLength]byte
+	Private *[keyLength]byte
+}
+
+// GenerateKeyPair creates a new random key pair
+func GenerateKeyPair() (*KeyPair, error) {
+	public, private, err := box.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	return &KeyPair{Public: public, Private: private}, nil
+}
+
+// NewKeyPair restores a key pair from its base64 encoded public and private keys
+func NewKeyPair(b64Public, b64Private string) (*KeyPair, error) {
+	public, err := decodeKey(b64Public)
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key: %v", err)
+	}
+	private, err := decodeKey(b64Private)
+	if err != nil {
+		return nil, fmt.Errorf("invalid private key: %v", err)
+	}
+	derived, err := curve25519.X25519(private[:], curve25519.Basepoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid private key: %v", err)
+	}
+	if !bytes.Equal(derived, public[:]) {
+		return nil, fmt.Errorf("public key %s does not belong to the private key", b64Public)
+	}
+	return &KeyPair{Public: public, Private: private}, nil
+}
+
+// PublicKey returns the base64 encoded public key, which is how parties are addressed
+// in privateFrom/privateFor
+func (k *KeyPair) PublicKey() string {
+	return base64.StdEncoding.EncodeToString(k.Public[:])
+}
+
+// PrivateKey returns the base64 encoded private key, so that the key pair can be restored with NewKeyPair
+func (k *KeyPair) PrivateKey() string {
+	return base64.StdEncoding.EncodeToString(k.Private[:])
+}
+
+func decodeKey(b64 string) (*[keyLength]byte, error) {
+	raw, err := base64.StdEncoding.DecodeString(b64)
+	if err != nil {
+		return nil, err
+	}
+	if len(raw) != keyLength {
+		return nil, fmt.Errorf("expected %d bytes but got %d", keyLength, len(raw))
+	}
+	var key [keyLength]byte
+	copy(key[:], raw)
+	return &key, nil
+}
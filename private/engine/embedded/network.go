@@ -0,0 +1,243 @@
+package embedded
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+const (
+	keysPath = "/keys"
+	pushPath = "/push"
+
+	peerTimeout     = 10 * time.Second
+	maxPayloadBytes = 64 * 1024 * 1024
+)
+
+// Network connects the embedded private transaction managers running in the same process,
+// delivering private payloads to the transaction manager which owns the recipient key.
+// Networks of other processes on the same host, e.g. the ones of several geth nodes on
+// localhost, are reached through their peer URLs once they listen on a loopback address.
+type Network struct {
+	members map[string]*embeddedPrivateTxManager // by base64 public key
+	peers   []string                             // URLs of the networks of other processes
+	remote  map[string]string                    // peer URL by base64 public key, learnt from the peers
+	lock    sync.RWMutex
+
+	client *http.Client
+	server *http.Server
+}
+
+// NewNetwork creates an empty network, transaction managers join it when they are created
+func NewNetwork() *Network {
+	return &Network{
+		members: make(map[string]*embeddedPrivateTxManager),
+		remote:  make(map[string]string),
+		client:  &http.Client{Timeout: peerTimeout},
+	}
+}
+
+// AddPeers adds the URLs of the networks of other processes, which are asked for the keys
+// they manage when a payload is sent to a key which is not known yet
+func (n *Network) AddPeers(urls ...string) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	for _, url := range urls {
+		n.peers = append(n.peers, strings.TrimSuffix(url, "/"))
+	}
+}
+
+// Listen accepts the payloads delivered by the networks of other processes on addr, which
+// must be a loopback address as payloads are exchanged without authentication. It returns
+// the address actually listened on, which differs from addr when its port is 0.
+func (n *Network) Listen(addr string) (net.Addr, error) {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+	if ip := net.ParseIP(host); host != "localhost" && (ip == nil || !ip.IsLoopback()) {
+		return nil, fmt.Errorf("listen address %s is not a loopback address", addr)
+	}
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc(keysPath, n.handleKeys)
+	mux.HandleFunc(pushPath, n.handlePush)
+	server := &http.Server{Handler: mux, ReadHeaderTimeout: peerTimeout}
+
+	n.lock.Lock()
+	if n.server != nil {
+		n.lock.Unlock()
+		listener.Close()
+		return nil, errors.New("network is already listening")
+	}
+	n.server = server
+	n.lock.Unlock()
+
+	go func() {
+		if err := server.Serve(listener); err != nil && err != http.ErrServerClosed {
+			log.Error("Embedded private transaction manager stopped accepting payloads", "err", err)
+		}
+	}()
+	return listener.Addr(), nil
+}
+
+// Close stops accepting the payloads delivered by the networks of other processes
+func (n *Network) Close() error {
+	n.lock.Lock()
+	server := n.server
+	n.server = nil
+	n.lock.Unlock()
+
+	if server == nil {
+		return nil
+	}
+	return server.Close()
+}
+
+func (n *Network) join(ptm *embeddedPrivateTxManager) error {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	for key := range ptm.keys {
+		if _, exists := n.members[key]; exists {
+			return fmt.Errorf("key %s is already managed by another transaction manager in the network", key)
+		}
+	}
+	for key := range ptm.keys {
+		n.members[key] = ptm
+	}
+	return nil
+}
+
+func (n *Network) owner(key string) (*embeddedPrivateTxManager, bool) {
+	n.lock.RLock()
+	defer n.lock.RUnlock()
+	ptm, ok := n.members[key]
+	return ptm, ok
+}
+
+// peer returns the URL of the network of another process which manages the key, asking
+// the peers for their keys if it is not known yet
+func (n *Network) peer(key string) (string, bool) {
+	n.lock.RLock()
+	url, ok := n.remote[key]
+	peers := n.peers
+	n.lock.RUnlock()
+	if ok {
+		return url, true
+	}
+	for _, peer := range peers {
+		keys, err := n.fetchKeys(peer)
+		if err != nil {
+			log.Debug("Unable to fetch the keys of embedded private transaction manager peer", "peer", peer, "err", err)
+			continue
+		}
+		n.lock.Lock()
+		for _, k := range keys {
+			n.remote[k] = peer
+		}
+		n.lock.Unlock()
+	}
+	n.lock.RLock()
+	defer n.lock.RUnlock()
+	url, ok = n.remote[key]
+	return url, ok
+}
+
+func (n *Network) fetchKeys(peer string) ([]string, error) {
+	res, err := n.client.Get(peer + keysPath)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", res.Status)
+	}
+	var keys []string
+	if err := json.NewDecoder(res.Body).Decode(&keys); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// push delivers the payload to the network of another process
+func (n *Network) push(peer string, payload *encodedPayload) error {
+	enc, err := rlp.EncodeToBytes(payload)
+	if err != nil {
+		return err
+	}
+	res, err := n.client.Post(peer+pushPath, "application/octet-stream", bytes.NewReader(enc))
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		return fmt.Errorf("peer %s rejected the payload with status %s: %s", peer, res.Status, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+func (n *Network) handleKeys(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	n.lock.RLock()
+	keys := make([]string, 0, len(n.members))
+	for key := range n.members {
+		keys = append(keys, key)
+	}
+	n.lock.RUnlock()
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(keys)
+}
+
+// handlePush stores a payload delivered by the network of another process with the transaction
+// managers owning its recipients. The hash is derived from the payload rather than trusted.
+func (n *Network) handlePush(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	enc, err := io.ReadAll(io.LimitReader(r.Body, maxPayloadBytes))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	payload := new(encodedPayload)
+	if err := rlp.DecodeBytes(enc, payload); err != nil {
+		http.Error(w, fmt.Sprintf("unable to decode payload: %v", err), http.StatusBadRequest)
+		return
+	}
+	deliveries := make(map[*embeddedPrivateTxManager][][]byte)
+	for _, recipient := range payload.RecipientKeys {
+		if owner, ok := n.owner(base64.StdEncoding.EncodeToString(recipient)); ok {
+			deliveries[owner] = append(deliveries[owner], recipient)
+		}
+	}
+	if len(deliveries) == 0 {
+		http.Error(w, "none of the recipients is managed by this network", http.StatusNotFound)
+		return
+	}
+	hash := payload.hash()
+	for owner, recipients := range deliveries {
+		if err := owner.accept(hash, payload.forRecipients(recipients)); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+}
@@ -0,0 +1,190 @@
+package embedded
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"io"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/private/engine"
+	"golang.org/x/crypto/nacl/box"
+	"golang.org/x/crypto/nacl/secretbox"
+	"golang.org/x/crypto/sha3"
+)
+
+const nonceLength = 24
+
+var errNotARecipient = errors.New("none of the managed keys is a recipient of the payload")
+
+// encodedPayload is the encrypted form of a private payload, modelled on Tessera's EncodedPayload.
+// The payload is encrypted once with a random master key, and the master key is then boxed
+// for each recipient. The hash of the cipher text identifies the payload.
+type encodedPayload struct {
+	SenderKey           []byte
+	CipherText          []byte
+	CipherTextNonce     []byte
+	RecipientBoxes      [][]byte
+	RecipientNonce      []byte
+	RecipientKeys       [][]byte
+	AffectedContractTxs [][]byte
+	ExecHash            []byte
+	PrivacyFlag         uint64
+	MandatoryRecipients []string
+	// Participants are the recipients of a payload with enhanced privacy, which are kept in
+	// the copies delivered to each recipient so that the affected contracts can be checked
+	Participants [][]byte
+	// Raw is set for payloads stored with StoreRaw which have not been sent yet
+	Raw bool
+}
+
+// sealPayload encrypts data with a new master key, returning the payload without any recipients
+func sealPayload(data []byte, sender *KeyPair) (*encodedPayload, *[keyLength]byte, error) {
+	masterKey, err := newMasterKey()
+	if err != nil {
+		return nil, nil, err
+	}
+	payload := &encodedPayload{SenderKey: sender.Public[:]}
+	if err := payload.seal(data, masterKey); err != nil {
+		return nil, nil, err
+	}
+	return payload, masterKey, nil
+}
+
+func newMasterKey() (*[keyLength]byte, error) {
+	var masterKey [keyLength]byte
+	if _, err := io.ReadFull(rand.Reader, masterKey[:]); err != nil {
+		return nil, err
+	}
+	return &masterKey, nil
+}
+
+// seal encrypts data with the master key, replacing any existing cipher text
+func (p *encodedPayload) seal(data []byte, masterKey *[keyLength]byte) error {
+	nonce, err := newNonce()
+	if err != nil {
+		return err
+	}
+	p.CipherText = secretbox.Seal(nil, data, nonce, masterKey)
+	p.CipherTextNonce = nonce[:]
+	return nil
+}
+
+func (p *encodedPayload) hash() common.EncryptedPayloadHash {
+	return common.EncryptedPayloadHash(sha3.Sum512(p.CipherText))
+}
+
+// setRecipients boxes the master key for each of the recipients, replacing any existing recipients
+func (p *encodedPayload) setRecipients(masterKey *[keyLength]byte, sender *KeyPair, recipients []*[keyLength]byte) error {
+	nonce, err := newNonce()
+	if err != nil {
+		return err
+	}
+	p.RecipientNonce = nonce[:]
+	p.RecipientKeys = make([][]byte, len(recipients))
+	p.RecipientBoxes = make([][]byte, len(recipients))
+	for i, recipient := range recipients {
+		p.RecipientKeys[i] = recipient[:]
+		p.RecipientBoxes[i] = box.Seal(nil, masterKey[:], nonce, recipient, sender.Private)
+	}
+	return nil
+}
+
+// masterKey opens the first recipient box that can be opened with one of the given keys,
+// either because the key is the recipient or because it is the sender
+func (p *encodedPayload) masterKey(keys map[string]*KeyPair) (*[keyLength]byte, error) {
+	var nonce [nonceLength]byte
+	copy(nonce[:], p.RecipientNonce)
+	sender, isSender := findKey(keys, p.SenderKey)
+	for i, recipientKey := range p.RecipientKeys {
+		var peer [keyLength]byte
+		var private *[keyLength]byte
+		if recipient, ok := findKey(keys, recipientKey); ok {
+			copy(peer[:], p.SenderKey)
+			private = recipient.Private
+		} else if isSender {
+			copy(peer[:], recipientKey)
+			private = sender.Private
+		} else {
+			continue
+		}
+		opened, ok := box.Open(nil, p.RecipientBoxes[i], &nonce, &peer, private)
+		if !ok || len(opened) != keyLength {
+			continue
+		}
+		var masterKey [keyLength]byte
+		copy(masterKey[:], opened)
+		return &masterKey, nil
+	}
+	return nil, errNotARecipient
+}
+
+// open decrypts the payload with one of the given keys
+func (p *encodedPayload) open(keys map[string]*KeyPair) ([]byte, error) {
+	masterKey, err := p.masterKey(keys)
+	if err != nil {
+		return nil, err
+	}
+	var nonce [nonceLength]byte
+	copy(nonce[:], p.CipherTextNonce)
+	data, ok := secretbox.Open(nil, p.CipherText, &nonce, masterKey)
+	if !ok {
+		return nil, errors.New("unable to decrypt payload")
+	}
+	return data, nil
+}
+
+// forRecipients returns a copy of the payload which only carries the boxes of the given
+// recipients, which is what gets delivered to the transaction manager owning them
+func (p *encodedPayload) forRecipients(recipients [][]byte) *encodedPayload {
+	cpy := *p
+	if engine.PrivacyFlagType(p.PrivacyFlag).IsNotStandardPrivate() {
+		cpy.Participants = p.participants()
+	}
+	cpy.RecipientKeys = nil
+	cpy.RecipientBoxes = nil
+	for i, key := range p.RecipientKeys {
+		for _, recipient := range recipients {
+			if bytes.Equal(key, recipient) {
+				cpy.RecipientKeys = append(cpy.RecipientKeys, key)
+				cpy.RecipientBoxes = append(cpy.RecipientBoxes, p.RecipientBoxes[i])
+				break
+			}
+		}
+	}
+	return &cpy
+}
+
+// participants returns the keys of the recipients of the payload, including the sender
+func (p *encodedPayload) participants() [][]byte {
+	if len(p.Participants) > 0 {
+		return p.Participants
+	}
+	return p.RecipientKeys
+}
+
+func (p *encodedPayload) isParticipant(key []byte) bool {
+	if bytes.Equal(p.SenderKey, key) {
+		return true
+	}
+	for _, participant := range p.participants() {
+		if bytes.Equal(participant, key) {
+			return true
+		}
+	}
+	return false
+}
+
+func newNonce() (*[nonceLength]byte, error) {
+	var nonce [nonceLength]byte
+	if _, err := io.ReadFull(rand.Reader, nonce[:]); err != nil {
+		return nil, err
+	}
+	return &nonce, nil
+}
+
+func findKey(keys map[string]*KeyPair, public []byte) (*KeyPair, bool) {
+	key, ok := keys[base64.StdEncoding.EncodeToString(public)]
+	return key, ok
+}
@@ -11,7 +11,6 @@ import (
 	"github.com/ethereum/go-ethereum/private/cache"
 	"github.com/ethereum/go-ethereum/private/engine"
 	"github.com/ethereum/go-ethereum/rpc"
-	gocache "github.com/patrickmn/go-cache"
 )
 
 type RPCClientCaller interface {
@@ -20,7 +19,7 @@ type RPCClientCaller interface {
 
 type CachingProxyTxManager struct {
 	features  *engine.FeatureSet
-	cache     *gocache.Cache
+	cache     cache.Cache
 	rpcClient RPCClientCaller
 }
 
@@ -38,10 +37,15 @@ func Is(ptm interface{}) bool {
 func New() *CachingProxyTxManager {
 	return &CachingProxyTxManager{
 		features: engine.NewFeatureSet(engine.PrivacyEnhancements),
-		cache:    gocache.New(cache.DefaultExpiration, cache.CleanupInterval),
+		cache:    cache.NewDefaultCache(),
 	}
 }
 
+// SetCache replaces the cache of private transaction data received from the qlight server node
+func (t *CachingProxyTxManager) SetCache(c cache.Cache) {
+	t.cache = c
+}
+
 func (t *CachingProxyTxManager) SetRPCClient(client *rpc.Client) {
 	t.rpcClient = client
 }
@@ -136,7 +140,7 @@ func (t *CachingProxyTxManager) CheckAndAddEmptyToCache(hash common.EncryptedPay
 
 	t.cache.Set(cacheKey, CPItem{
 		IsEmpty: true,
-	}, gocache.DefaultExpiration)
+	})
 }
 
 type CachablePrivateTransactionData struct {
@@ -161,7 +165,7 @@ func (t *CachingProxyTxManager) Cache(privateTxData *CachablePrivateTransactionD
 			Extra:   *privateTxData.QuorumPrivateTxData.ExtraMetaData,
 		},
 		IsSender: privateTxData.QuorumPrivateTxData.IsSender,
-	}, gocache.DefaultExpiration)
+	})
 
 	return nil
 }
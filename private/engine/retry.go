@@ -0,0 +1,214 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+var (
+	// ErrPrivateTxManagerCircuitOpen is returned without calling the private transaction manager
+	// while the circuit breaker is open. It wraps ErrPrivateTxManagerUnreachable.
+	ErrPrivateTxManagerCircuitOpen = fmt.Errorf("%w: circuit breaker is open", ErrPrivateTxManagerUnreachable)
+
+	retryMeter          = metrics.NewRegisteredMeter("ptm/http/retries", nil)
+	circuitOpenMeter    = metrics.NewRegisteredMeter("ptm/http/circuitbreaker/opened", nil)
+	circuitRejectMeter  = metrics.NewRegisteredMeter("ptm/http/circuitbreaker/rejected", nil)
+	circuitBreakerGauge = metrics.NewRegisteredGauge("ptm/http/circuitbreaker/open", nil)
+)
+
+// RetryPolicy decides whether and when a failed call to the private transaction manager
+// is attempted again
+type RetryPolicy struct {
+	MaxAttempts          int           // Maximum number of attempts, including the first one
+	InitialBackoff       time.Duration // Wait before the first retry, doubled for each further retry
+	MaxBackoff           time.Duration // Upper bound of the wait between retries
+	RetryableStatusCodes []int         // Response status codes which are retried
+}
+
+func (p *RetryPolicy) maxAttempts() int {
+	if p == nil || p.MaxAttempts < 1 {
+		return 1
+	}
+	return p.MaxAttempts
+}
+
+// backoff returns the wait after the given (1-based) failed attempt
+func (p *RetryPolicy) backoff(attempt int) time.Duration {
+	wait := p.InitialBackoff
+	for i := 1; i < attempt && (p.MaxBackoff <= 0 || wait < p.MaxBackoff); i++ {
+		wait *= 2
+	}
+	if p.MaxBackoff > 0 && wait > p.MaxBackoff {
+		wait = p.MaxBackoff
+	}
+	return wait
+}
+
+func (p *RetryPolicy) isRetryableStatus(statusCode int) bool {
+	if p == nil {
+		return false
+	}
+	for _, code := range p.RetryableStatusCodes {
+		if code == statusCode {
+			return true
+		}
+	}
+	return false
+}
+
+// CircuitBreaker fails calls to the private transaction manager fast after a number of
+// consecutive failures, i.e. calls which could not be completed or got a 502, 503 or 504 response.
+// Other responses, including a 500, show that the private transaction manager is up and close the
+// breaker. Once the cooldown has passed a single call is let through; the breaker closes again if it
+// succeeds and stays open for another cooldown if it fails.
+type CircuitBreaker struct {
+	threshold int
+	cooldown  time.Duration
+
+	failures  int
+	openUntil time.Time
+	trial     bool // a call is let through to probe the private transaction manager
+	mu        sync.Mutex
+}
+
+// NewCircuitBreaker creates a circuit breaker which opens after threshold consecutive failures.
+// It returns nil, which never opens, if threshold is not positive.
+func NewCircuitBreaker(threshold int, cooldown time.Duration) *CircuitBreaker {
+	if threshold <= 0 {
+		return nil
+	}
+	return &CircuitBreaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+	}
+}
+
+// Allow returns ErrPrivateTxManagerCircuitOpen if the call must not be made
+func (b *CircuitBreaker) Allow() error {
+	if b == nil {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.failures < b.threshold {
+		return nil
+	}
+	if time.Now().Before(b.openUntil) || b.trial {
+		circuitRejectMeter.Mark(1)
+		return ErrPrivateTxManagerCircuitOpen
+	}
+	b.trial = true
+	return nil
+}
+
+func (b *CircuitBreaker) success() {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.failures >= b.threshold {
+		log.Info("Private transaction manager is reachable again, closing circuit breaker")
+		circuitBreakerGauge.Update(0)
+	}
+	b.failures = 0
+	b.trial = false
+}
+
+func (b *CircuitBreaker) failure() {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures++
+	b.trial = false
+	if b.failures >= b.threshold {
+		if b.failures == b.threshold {
+			log.Warn("Private transaction manager keeps failing, opening circuit breaker", "failures", b.failures, "cooldown", b.cooldown)
+			circuitOpenMeter.Mark(1)
+			circuitBreakerGauge.Update(1)
+		}
+		b.openUntil = time.Now().Add(b.cooldown)
+	}
+}
+
+// Do sends the request built by newRequest, retrying it according to the retry policy of the
+// client. A call which is not idempotent is only retried if the request could not be sent at
+// all, as the private transaction manager may otherwise have acted on it already.
+//
+// The response of the last attempt is returned, even if its status code is retryable.
+func (c *Client) Do(newRequest func() (*http.Request, error), idempotent bool) (*http.Response, error) {
+	for attempt := 1; ; attempt++ {
+		req, err := newRequest()
+		if err != nil {
+			return nil, err
+		}
+		if err := c.Breaker.Allow(); err != nil {
+			return nil, err
+		}
+		res, err := c.HttpClient.Do(req)
+		if err != nil || isUnavailableStatus(res.StatusCode) {
+			c.Breaker.failure()
+		} else {
+			c.Breaker.success()
+		}
+		var retryable bool
+		if err != nil {
+			retryable = idempotent || IsNotSent(err)
+		} else {
+			retryable = idempotent && c.Retry.isRetryableStatus(res.StatusCode)
+		}
+		if !retryable || attempt >= c.Retry.maxAttempts() {
+			return res, err
+		}
+		if res != nil {
+			_, _ = io.Copy(io.Discard, res.Body)
+			res.Body.Close()
+		}
+		wait := c.Retry.backoff(attempt)
+		log.Warn("Retrying call to private transaction manager", "url", req.URL, "attempt", attempt, "wait", wait, "err", err)
+		retryMeter.Mark(1)
+		time.Sleep(wait)
+	}
+}
+
+// isUnavailableStatus reports whether the status code means that the private transaction
+// manager, rather than the request, is at fault
+func isUnavailableStatus(statusCode int) bool {
+	switch statusCode {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// IsUnreachable reports whether the error means that the private transaction manager did not
+// answer the call. An error status code is an answer, so the manager is up.
+func IsUnreachable(err error) bool {
+	if errors.Is(err, ErrPrivateTxManagerUnreachable) {
+		return true
+	}
+	var urlErr *url.Error
+	return errors.As(err, &urlErr)
+}
+
+// IsNotSent reports whether the error means that no connection was made, or that the call
+// was failed fast by the circuit breaker, so that the private transaction manager cannot
+// have received the request
+func IsNotSent(err error) bool {
+	if errors.Is(err, ErrPrivateTxManagerCircuitOpen) {
+		return true
+	}
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
@@ -0,0 +1,154 @@
+package engine
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testRetryPolicy = &RetryPolicy{
+	MaxAttempts:          3,
+	InitialBackoff:       time.Millisecond,
+	MaxBackoff:           2 * time.Millisecond,
+	RetryableStatusCodes: []int{http.StatusServiceUnavailable},
+}
+
+// startFlakyServer responds with 503 to the first failures requests and with 200 afterwards
+func startFlakyServer(failures int32) (*httptest.Server, *int32) {
+	return startFlakyServerWithStatus(failures, http.StatusServiceUnavailable)
+}
+
+// startFlakyServerWithStatus responds with statusCode to the first failures requests and with 200 afterwards
+func startFlakyServerWithStatus(failures int32, statusCode int) (*httptest.Server, *int32) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if atomic.AddInt32(&calls, 1) <= failures {
+			w.WriteHeader(statusCode)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	return server, &calls
+}
+
+func newGetRequest(url string) func() (*http.Request, error) {
+	return func() (*http.Request, error) {
+		return http.NewRequest("GET", url, nil)
+	}
+}
+
+func TestClientDo_whenIdempotent(t *testing.T) {
+	server, calls := startFlakyServer(2)
+	defer server.Close()
+	client := &Client{HttpClient: server.Client(), BaseURL: server.URL, Retry: testRetryPolicy}
+
+	res, err := client.Do(newGetRequest(server.URL), true)
+
+	require.NoError(t, err)
+	defer res.Body.Close()
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, int32(3), atomic.LoadInt32(calls))
+}
+
+func TestClientDo_whenAttemptsExhausted(t *testing.T) {
+	server, calls := startFlakyServer(5)
+	defer server.Close()
+	client := &Client{HttpClient: server.Client(), BaseURL: server.URL, Retry: testRetryPolicy}
+
+	res, err := client.Do(newGetRequest(server.URL), true)
+
+	require.NoError(t, err)
+	defer res.Body.Close()
+	assert.Equal(t, http.StatusServiceUnavailable, res.StatusCode)
+	assert.Equal(t, int32(3), atomic.LoadInt32(calls))
+}
+
+func TestClientDo_whenNotIdempotent(t *testing.T) {
+	server, calls := startFlakyServer(2)
+	defer server.Close()
+	client := &Client{HttpClient: server.Client(), BaseURL: server.URL, Retry: testRetryPolicy}
+
+	res, err := client.Do(newGetRequest(server.URL), false)
+
+	require.NoError(t, err)
+	defer res.Body.Close()
+	assert.Equal(t, http.StatusServiceUnavailable, res.StatusCode)
+	assert.Equal(t, int32(1), atomic.LoadInt32(calls), "expected a request which has been received not to be retried")
+}
+
+func TestClientDo_whenNotIdempotentAndNotSent(t *testing.T) {
+	server, _ := startFlakyServer(0)
+	url := server.URL
+	server.Close()
+	client := &Client{HttpClient: &http.Client{}, BaseURL: url, Retry: testRetryPolicy}
+	var attempts int
+
+	_, err := client.Do(func() (*http.Request, error) {
+		attempts++
+		return http.NewRequest("POST", url, nil)
+	}, false)
+
+	assert.Error(t, err)
+	assert.Equal(t, 3, attempts, "expected a request which could not be sent to be retried")
+	assert.True(t, IsUnreachable(err))
+}
+
+func TestIsUnreachable_whenAnswered(t *testing.T) {
+	assert.False(t, IsUnreachable(fmt.Errorf("non-200 status code: %d", http.StatusInternalServerError)))
+	assert.True(t, IsUnreachable(ErrPrivateTxManagerCircuitOpen))
+}
+
+func TestClientDo_whenCircuitBreakerOpen(t *testing.T) {
+	server, calls := startFlakyServer(2)
+	defer server.Close()
+	client := &Client{HttpClient: server.Client(), BaseURL: server.URL, Breaker: NewCircuitBreaker(2, 20*time.Millisecond)}
+
+	for i := 0; i < 2; i++ {
+		res, err := client.Do(newGetRequest(server.URL), true)
+		require.NoError(t, err)
+		res.Body.Close()
+	}
+	_, err := client.Do(newGetRequest(server.URL), true)
+
+	assert.ErrorIs(t, err, ErrPrivateTxManagerCircuitOpen)
+	assert.ErrorIs(t, err, ErrPrivateTxManagerUnreachable)
+	assert.Equal(t, int32(2), atomic.LoadInt32(calls), "expected the call to fail fast")
+
+	time.Sleep(30 * time.Millisecond)
+	res, err := client.Do(newGetRequest(server.URL), true)
+
+	require.NoError(t, err, "expected a trial call after the cooldown")
+	defer res.Body.Close()
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Nil(t, client.Breaker.Allow(), "expected the circuit breaker to be closed again")
+}
+
+func TestClientDo_whenInternalServerError(t *testing.T) {
+	server, calls := startFlakyServerWithStatus(2, http.StatusInternalServerError)
+	defer server.Close()
+	policy := *testRetryPolicy
+	policy.RetryableStatusCodes = []int{http.StatusInternalServerError}
+	client := &Client{HttpClient: server.Client(), BaseURL: server.URL, Retry: &policy, Breaker: NewCircuitBreaker(1, time.Minute)}
+
+	res, err := client.Do(newGetRequest(server.URL), true)
+
+	require.NoError(t, err, "expected a 500 not to open the circuit breaker")
+	defer res.Body.Close()
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, int32(3), atomic.LoadInt32(calls))
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := &RetryPolicy{InitialBackoff: 100 * time.Millisecond, MaxBackoff: 300 * time.Millisecond}
+
+	assert.Equal(t, 100*time.Millisecond, policy.backoff(1))
+	assert.Equal(t, 200*time.Millisecond, policy.backoff(2))
+	assert.Equal(t, 300*time.Millisecond, policy.backoff(3))
+	assert.Equal(t, 300*time.Millisecond, policy.backoff(10))
+}
@@ -79,6 +79,46 @@ type sendSignedTxResponse struct {
 	SenderKey string `json:"senderKey"`
 }
 
+// request object for /createPrivacyGroup API
+type createPrivacyGroupRequest struct {
+	// base64-encoded public keys of the members
+	Addresses []string `json:"addresses"`
+
+	// base64-encoded
+	From string `json:"from,omitempty"`
+
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// request object for /deletePrivacyGroup API
+type deletePrivacyGroupRequest struct {
+	PrivacyGroupId string `json:"privacyGroupId"`
+
+	// base64-encoded
+	From string `json:"from,omitempty"`
+}
+
+// request object for /retrievePrivacyGroup API
+type retrievePrivacyGroupRequest struct {
+	PrivacyGroupId string `json:"privacyGroupId"`
+}
+
+// request object for /findPrivacyGroup API
+type findPrivacyGroupRequest struct {
+	// base64-encoded public keys of the members
+	Addresses []string `json:"addresses"`
+}
+
+// request object for the /resend API of the P2P server
+type resendRequest struct {
+	// ALL to resend all the payloads for the public key
+	Type string `json:"type"`
+
+	// base64-encoded
+	PublicKey string `json:"publicKey"`
+}
+
 type encryptPayloadResponse struct {
 	SenderKey       []byte   `json:"senderKey"`
 	CipherText      []byte   `json:"cipherText"`
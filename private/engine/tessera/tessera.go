@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -18,13 +19,17 @@ import (
 	"github.com/ethereum/go-ethereum/params"
 	"github.com/ethereum/go-ethereum/private/cache"
 	"github.com/ethereum/go-ethereum/private/engine"
-	gocache "github.com/patrickmn/go-cache"
 )
 
+// peerHttpClient is used for the requests made to the P2P server of other Tessera nodes when
+// this node's Tessera is reached through an IPC socket, as the IPC client cannot reach them
+var peerHttpClient = &http.Client{Timeout: 30 * time.Second}
+
 type tesseraPrivateTxManager struct {
 	features *engine.FeatureSet
 	client   *engine.Client
-	cache    *gocache.Cache
+	cache    cache.Cache
+	failover bool
 }
 
 func Is(ptm interface{}) bool {
@@ -40,10 +45,41 @@ func New(client *engine.Client, version []byte) *tesseraPrivateTxManager {
 	return &tesseraPrivateTxManager{
 		features: engine.NewFeatureSet(tesseraVersionFeatures(ptmVersion)...),
 		client:   client,
-		cache:    gocache.New(cache.DefaultExpiration, cache.CleanupInterval),
+		cache:    cache.NewDefaultCache(),
 	}
 }
 
+// SetCache replaces the cache of retrieved payloads, e.g. to share one cache between failover endpoints
+func (t *tesseraPrivateTxManager) SetCache(c cache.Cache) {
+	t.cache = c
+}
+
+// SetFailover marks this Tessera as one of several failover endpoints. A Tessera that cannot be reached
+// is then reported with engine.ErrPrivateTxManagerUnreachable instead of terminating the node, so that
+// the caller can retry against another endpoint.
+func (t *tesseraPrivateTxManager) SetFailover(failover bool) {
+	t.failover = failover
+}
+
+// requests to these paths store a payload in Tessera, so they are only retried if they were not sent at all
+var nonIdempotentPaths = map[string]bool{
+	"/send":               true,
+	"/storeraw":           true,
+	"/sendsignedtx":       true,
+	"/createPrivacyGroup": true,
+	"/deletePrivacyGroup": true,
+}
+
+// the privacy group APIs are not versioned, their Content-type/Accept is application/json
+var privacyGroupPaths = map[string]bool{
+	"/createPrivacyGroup":   true,
+	"/deletePrivacyGroup":   true,
+	"/findPrivacyGroup":     true,
+	"/retrievePrivacyGroup": true,
+	"/groups/legacy":        true,
+	"/groups/pantheon":      true,
+}
+
 func (t *tesseraPrivateTxManager) submitJSON(method, path string, request interface{}, response interface{}) (int, error) {
 	apiVersion := ""
 	if t.features.HasFeature(engine.MultiTenancy) {
@@ -56,13 +92,20 @@ func (t *tesseraPrivateTxManager) submitJSON(method, path string, request interf
 		// for the groups API the Content-type/Accept is application/json
 		apiVersion = ""
 	}
-	req, err := newOptionalJSONRequest(method, t.client.FullPath(path), request, apiVersion)
-	if err != nil {
-		return -1, fmt.Errorf("unable to build json request for (method:%s,path:%s). Cause: %v", method, path, err)
+	if privacyGroupPaths[path] {
+		apiVersion = ""
+	}
+	var buildErr error
+	res, err := t.client.Do(func() (*http.Request, error) {
+		req, err := newOptionalJSONRequest(method, t.client.FullPath(path), request, apiVersion)
+		buildErr = err
+		return req, err
+	}, method == "GET" || !nonIdempotentPaths[path])
+	if buildErr != nil {
+		return -1, fmt.Errorf("unable to build json request for (method:%s,path:%s). Cause: %v", method, path, buildErr)
 	}
-	res, err := t.client.HttpClient.Do(req)
 	if err != nil {
-		return -1, fmt.Errorf("unable to submit request (method:%s,path:%s). Cause: %v", method, path, err)
+		return -1, fmt.Errorf("unable to submit request (method:%s,path:%s). Cause: %w", method, path, err)
 	}
 	defer res.Body.Close()
 	if res.StatusCode != http.StatusOK && res.StatusCode != http.StatusCreated {
@@ -114,7 +157,7 @@ func (t *tesseraPrivateTxManager) Send(data []byte, from string, to []string, ex
 			ManagedParties: response.ManagedParties,
 			Sender:         response.SenderKey,
 		},
-	}, gocache.DefaultExpiration)
+	})
 
 	return response.SenderKey, response.ManagedParties, eph, nil
 }
@@ -165,22 +208,22 @@ func (t *tesseraPrivateTxManager) StoreRaw(data []byte, from string) (common.Enc
 	t.cache.Set(cacheKeyTemp, cache.PrivateCacheItem{
 		Payload: data,
 		Extra:   extra,
-	}, gocache.DefaultExpiration)
+	})
 
 	return eph, nil
 }
 
 // allow new quorum to send raw transactions when connected to an old tessera
 func (c *tesseraPrivateTxManager) sendSignedPayloadOctetStream(signedPayload []byte, b64To []string) (string, []string, []byte, error) {
-	buf := bytes.NewBuffer(signedPayload)
-	req, err := http.NewRequest("POST", c.client.FullPath("/sendsignedtx"), buf)
-	if err != nil {
-		return "", nil, nil, err
-	}
-
-	req.Header.Set("c11n-to", strings.Join(b64To, ","))
-	req.Header.Set("Content-Type", "application/octet-stream")
-	res, err := c.client.HttpClient.Do(req)
+	res, err := c.client.Do(func() (*http.Request, error) {
+		req, err := http.NewRequest("POST", c.client.FullPath("/sendsignedtx"), bytes.NewBuffer(signedPayload))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("c11n-to", strings.Join(b64To, ","))
+		req.Header.Set("Content-Type", "application/octet-stream")
+		return req, nil
+	}, false)
 	if err != nil {
 		return "", nil, nil, err
 	}
@@ -254,7 +297,7 @@ func (t *tesseraPrivateTxManager) SendSignedTx(data common.EncryptedPayloadHash,
 					ManagedParties: response.ManagedParties,
 					Sender:         response.SenderKey,
 				},
-			}, gocache.DefaultExpiration)
+			})
 			t.cache.Delete(cacheKeyTemp)
 		}
 	}
@@ -292,24 +335,23 @@ func (t *tesseraPrivateTxManager) receive(data common.EncryptedPayloadHash, isRa
 
 	uri := fmt.Sprintf("/transaction/%s?isRaw=%v", url.PathEscape(data.ToBase64()), isRaw)
 
-	var statusCode int
-	var err error
+	// retries are made by the client according to the configured retry policy. Receive is on the
+	// block processing path, so a single Tessera which cannot be reached, including while the circuit
+	// breaker is open, still stops the node rather than letting the private state diverge.
 	response := new(receiveResponse)
-
-	for i := 0; i < 5; i++ {
-		statusCode, err = t.submitJSON("GET", uri, nil, response)
-		if err != nil && statusCode != http.StatusNotFound {
-			log.Warn("Failed to fetch data from tessera", "retry", i, "uri", uri, "statuscode", statusCode, "err", err)
-			time.Sleep(1 * time.Second)
-			continue
-		}
-		break
-	}
+	statusCode, err := t.submitJSON("GET", uri, nil, response)
 
 	if statusCode == http.StatusNotFound {
 		log.Debug("data not found in tessera", "uri", uri, "statuscode", statusCode, "err", err)
 		return "", nil, nil, nil, nil
 	} else if err != nil {
+		if t.failover {
+			log.Warn("Failed to fetch data from tessera", "uri", uri, "statuscode", statusCode, "err", err)
+			if errors.Is(err, engine.ErrPrivateTxManagerUnreachable) {
+				return "", nil, nil, nil, err
+			}
+			return "", nil, nil, nil, fmt.Errorf("%w: %v", engine.ErrPrivateTxManagerUnreachable, err)
+		}
 		log.Error("Failed to fetch data from tessera", "uri", uri, "statuscode", statusCode, "err", err)
 		os.Exit(112)
 	}
@@ -341,7 +383,7 @@ func (t *tesseraPrivateTxManager) receive(data common.EncryptedPayloadHash, isRa
 	t.cache.Set(cacheKey, cache.PrivateCacheItem{
 		Payload: response.Payload,
 		Extra:   extra,
-	}, gocache.DefaultExpiration)
+	})
 
 	return response.SenderKey, response.ManagedParties, response.Payload, &extra, nil
 }
@@ -380,12 +422,9 @@ func (t *tesseraPrivateTxManager) DecryptPayload(payload common.DecryptRequest)
 
 func (t *tesseraPrivateTxManager) IsSender(txHash common.EncryptedPayloadHash) (bool, error) {
 	requestUrl := "/transaction/" + url.PathEscape(txHash.ToBase64()) + "/isSender"
-	req, err := http.NewRequest("GET", t.client.FullPath(requestUrl), nil)
-	if err != nil {
-		return false, err
-	}
-
-	res, err := t.client.HttpClient.Do(req)
+	res, err := t.client.Do(func() (*http.Request, error) {
+		return http.NewRequest("GET", t.client.FullPath(requestUrl), nil)
+	}, true)
 
 	if res != nil {
 		defer res.Body.Close()
@@ -410,12 +449,9 @@ func (t *tesseraPrivateTxManager) IsSender(txHash common.EncryptedPayloadHash) (
 
 func (t *tesseraPrivateTxManager) GetParticipants(txHash common.EncryptedPayloadHash) ([]string, error) {
 	requestUrl := "/transaction/" + url.PathEscape(txHash.ToBase64()) + "/participants"
-	req, err := http.NewRequest("GET", t.client.FullPath(requestUrl), nil)
-	if err != nil {
-		return nil, err
-	}
-
-	res, err := t.client.HttpClient.Do(req)
+	res, err := t.client.Do(func() (*http.Request, error) {
+		return http.NewRequest("GET", t.client.FullPath(requestUrl), nil)
+	}, true)
 
 	if res != nil {
 		defer res.Body.Close()
@@ -442,12 +478,9 @@ func (t *tesseraPrivateTxManager) GetParticipants(txHash common.EncryptedPayload
 
 func (t *tesseraPrivateTxManager) GetMandatory(txHash common.EncryptedPayloadHash) ([]string, error) {
 	requestUrl := "/transaction/" + url.PathEscape(txHash.ToBase64()) + "/mandatory"
-	req, err := http.NewRequest("GET", t.client.FullPath(requestUrl), nil)
-	if err != nil {
-		return nil, err
-	}
-
-	res, err := t.client.HttpClient.Do(req)
+	res, err := t.client.Do(func() (*http.Request, error) {
+		return http.NewRequest("GET", t.client.FullPath(requestUrl), nil)
+	}, true)
 
 	if res != nil {
 		defer res.Body.Close()
@@ -480,6 +513,98 @@ func (t *tesseraPrivateTxManager) Groups() ([]engine.PrivacyGroup, error) {
 	return response, nil
 }
 
+func (t *tesseraPrivateTxManager) CreatePrivacyGroup(from string, members []string, name, description string) (*engine.PrivacyGroup, error) {
+	response := new(engine.PrivacyGroup)
+	if _, err := t.submitJSON("POST", "/createPrivacyGroup", &createPrivacyGroupRequest{
+		Addresses:   members,
+		From:        from,
+		Name:        name,
+		Description: description,
+	}, response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (t *tesseraPrivateTxManager) DeletePrivacyGroup(from string, privacyGroupId string) (string, error) {
+	var response string
+	if _, err := t.submitJSON("POST", "/deletePrivacyGroup", &deletePrivacyGroupRequest{
+		PrivacyGroupId: privacyGroupId,
+		From:           from,
+	}, &response); err != nil {
+		return "", err
+	}
+	return response, nil
+}
+
+func (t *tesseraPrivateTxManager) RetrievePrivacyGroup(privacyGroupId string) (*engine.PrivacyGroup, error) {
+	response := new(engine.PrivacyGroup)
+	if _, err := t.submitJSON("POST", "/retrievePrivacyGroup", &retrievePrivacyGroupRequest{
+		PrivacyGroupId: privacyGroupId,
+	}, response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (t *tesseraPrivateTxManager) FindPrivacyGroup(members []string) ([]engine.PrivacyGroup, error) {
+	response := make([]engine.PrivacyGroup, 0)
+	if _, err := t.submitJSON("POST", "/findPrivacyGroup", &findPrivacyGroupRequest{
+		Addresses: members,
+	}, &response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// PrivacyGroups returns the privacy groups of the given type, which is either
+// engine.PrivacyGroupLegacy or engine.PrivacyGroupPantheon
+func (t *tesseraPrivateTxManager) PrivacyGroups(groupType string) ([]engine.PrivacyGroup, error) {
+	var path string
+	switch groupType {
+	case engine.PrivacyGroupLegacy:
+		path = "/groups/legacy"
+	case engine.PrivacyGroupPantheon:
+		path = "/groups/pantheon"
+	default:
+		return nil, fmt.Errorf("unsupported privacy group type: %s", groupType)
+	}
+	response := make([]engine.PrivacyGroup, 0)
+	if _, err := t.submitJSON("GET", path, nil, &response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// RequestResend asks the Tessera node whose P2P server is at peerURL to send again all the
+// payloads it holds for the given public key of this node. The peer pushes the payloads
+// to this node's Tessera asynchronously.
+func (t *tesseraPrivateTxManager) RequestResend(peerURL string, publicKey string) error {
+	body, err := json.Marshal(&resendRequest{
+		Type:      "ALL",
+		PublicKey: publicKey,
+	})
+	if err != nil {
+		return err
+	}
+	// the client configured for this node's Tessera carries the TLS settings, e.g. the client
+	// certificate, which the P2P servers of the Tessera network expect
+	httpClient := t.client.HttpClient
+	if strings.HasPrefix(t.client.BaseURL, "http+unix://") {
+		httpClient = peerHttpClient
+	}
+	res, err := httpClient.Post(strings.TrimSuffix(peerURL, "/")+"/resend", "application/json", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("unable to request resend from %s. Cause: %w", peerURL, err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		out, _ := io.ReadAll(res.Body)
+		return fmt.Errorf("%d status: %s", res.StatusCode, string(out))
+	}
+	return nil
+}
+
 func (t *tesseraPrivateTxManager) Name() string {
 	return "Tessera"
 }
@@ -10,6 +10,7 @@ import (
 	"os"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/private/engine"
@@ -549,6 +550,34 @@ func TestReceive_whenHavingPayloadButNoPrivateExtraMetadata(t *testing.T) {
 	assert.True(common.EmptyHash(actualExtra.ACMerkleRoot), "returned merkle root")
 }
 
+func TestReceive_whenFailoverAndCircuitBreakerOpen(t *testing.T) {
+	assert := testifyassert.New(t)
+	server := httptest.NewServer(http.HandlerFunc(func(response http.ResponseWriter, _ *http.Request) {
+		response.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+	client := &engine.Client{
+		HttpClient: &http.Client{},
+		BaseURL:    server.URL,
+		Breaker:    engine.NewCircuitBreaker(1, time.Minute),
+	}
+	res, err := client.Do(func() (*http.Request, error) {
+		return http.NewRequest("GET", server.URL+"/upcheck", nil)
+	}, true)
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	res.Body.Close()
+
+	failoverTessera := New(client, []byte("2.0.0"))
+	failoverTessera.SetFailover(true)
+
+	_, _, data, _, err := failoverTessera.Receive(arbitraryHash)
+
+	assert.ErrorIs(err, engine.ErrPrivateTxManagerCircuitOpen, "expected the error to be returned to the failover manager")
+	assert.Nil(data)
+}
+
 func TestSendSignedTx_whenTypical(t *testing.T) {
 	assert := testifyassert.New(t)
 
@@ -660,3 +689,126 @@ func TestGetMandatory_notFound(t *testing.T) {
 
 	assert.Error(err, "Non-200 status code")
 }
+
+func TestPrivacyGroups_whenTypical(t *testing.T) {
+	assert := testifyassert.New(t)
+	pantheonGroup := engine.PrivacyGroup{
+		Type:           engine.PrivacyGroupPantheon,
+		Name:           "P1",
+		PrivacyGroupId: "P1",
+		Description:    "Pantheon Group 1",
+		From:           "AAA",
+		Members:        []string{"AAA", "BBB"},
+	}
+	var actualCreateRequest createPrivacyGroupRequest
+	var actualDeleteRequest deletePrivacyGroupRequest
+	mux := http.NewServeMux()
+	mux.HandleFunc("/createPrivacyGroup", func(response http.ResponseWriter, request *http.Request) {
+		assert.Equal("application/json", request.Header.Get("Content-type"))
+		_ = json.NewDecoder(request.Body).Decode(&actualCreateRequest)
+		data, _ := json.Marshal(&pantheonGroup)
+		response.Write(data)
+	})
+	mux.HandleFunc("/retrievePrivacyGroup", func(response http.ResponseWriter, request *http.Request) {
+		data, _ := json.Marshal(&pantheonGroup)
+		response.Write(data)
+	})
+	mux.HandleFunc("/findPrivacyGroup", func(response http.ResponseWriter, request *http.Request) {
+		data, _ := json.Marshal([]engine.PrivacyGroup{pantheonGroup})
+		response.Write(data)
+	})
+	mux.HandleFunc("/deletePrivacyGroup", func(response http.ResponseWriter, request *http.Request) {
+		_ = json.NewDecoder(request.Body).Decode(&actualDeleteRequest)
+		response.Write([]byte(`"P1"`))
+	})
+	mux.HandleFunc("/groups/pantheon", func(response http.ResponseWriter, request *http.Request) {
+		data, _ := json.Marshal([]engine.PrivacyGroup{pantheonGroup})
+		response.Write(data)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	// privacy group APIs are not versioned, even if multi-tenancy is supported
+	ptm := New(&engine.Client{
+		HttpClient: &http.Client{},
+		BaseURL:    server.URL,
+	}, []byte("2.1.0"))
+
+	created, err := ptm.CreatePrivacyGroup("AAA", []string{"AAA", "BBB"}, "P1", "Pantheon Group 1")
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	assert.Equal(&pantheonGroup, created)
+	assert.Equal(createPrivacyGroupRequest{Addresses: []string{"AAA", "BBB"}, From: "AAA", Name: "P1", Description: "Pantheon Group 1"}, actualCreateRequest)
+
+	retrieved, err := ptm.RetrievePrivacyGroup("P1")
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	assert.Equal(&pantheonGroup, retrieved)
+
+	found, err := ptm.FindPrivacyGroup([]string{"AAA", "BBB"})
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	assert.Equal([]engine.PrivacyGroup{pantheonGroup}, found)
+
+	listed, err := ptm.PrivacyGroups(engine.PrivacyGroupPantheon)
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	assert.Equal([]engine.PrivacyGroup{pantheonGroup}, listed)
+
+	deleted, err := ptm.DeletePrivacyGroup("AAA", "P1")
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	assert.Equal("P1", deleted)
+	assert.Equal(deletePrivacyGroupRequest{PrivacyGroupId: "P1", From: "AAA"}, actualDeleteRequest)
+}
+
+func TestPrivacyGroups_whenUnsupportedType(t *testing.T) {
+	assert := testifyassert.New(t)
+
+	_, err := testObject.PrivacyGroups(engine.PrivacyGroupResident)
+
+	assert.Error(err, "resident groups are returned by Groups")
+}
+
+func TestRequestResend_whenTypical(t *testing.T) {
+	assert := testifyassert.New(t)
+	var actualRequest resendRequest
+	peer := httptest.NewServer(http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+		assert.Equal("/resend", request.URL.Path)
+		_ = json.NewDecoder(request.Body).Decode(&actualRequest)
+	}))
+	defer peer.Close()
+
+	err := testObject.RequestResend(peer.URL+"/", "AAA")
+
+	assert.NoError(err)
+	assert.Equal(resendRequest{Type: "ALL", PublicKey: "AAA"}, actualRequest)
+}
+
+func TestRequestResend_whenTLS(t *testing.T) {
+	assert := testifyassert.New(t)
+	peer := httptest.NewTLSServer(http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {}))
+	defer peer.Close()
+	// the client of this node's Tessera trusts the certificate of the peer
+	ptm := New(&engine.Client{HttpClient: peer.Client(), BaseURL: testServer.URL}, []byte("2.0.0"))
+
+	err := ptm.RequestResend(peer.URL, "AAA")
+
+	assert.NoError(err, "expected the TLS configuration of the client to be used")
+}
+
+func TestRequestResend_whenPeerFails(t *testing.T) {
+	assert := testifyassert.New(t)
+	peer := httptest.NewServer(http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+		response.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer peer.Close()
+
+	err := testObject.RequestResend(peer.URL, "AAA")
+
+	assert.Error(err)
+}
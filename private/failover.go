@@ -0,0 +1,467 @@
+package private
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	http2 "github.com/ethereum/go-ethereum/common/http"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/private/cache"
+	"github.com/ethereum/go-ethereum/private/engine"
+)
+
+var (
+	healthyEndpointsGauge = metrics.NewRegisteredGauge("ptm/endpoints/healthy", nil)
+	failoverMeter         = metrics.NewRegisteredMeter("ptm/endpoints/failover", nil)
+	unreachableMeter      = metrics.NewRegisteredMeter("ptm/endpoints/unreachable", nil)
+)
+
+// HasEndpointStatus is implemented by private transaction managers which are backed by
+// more than one private transaction manager endpoint
+type HasEndpointStatus interface {
+	EndpointStatus() []EndpointStatus
+}
+
+// EndpointStatus is the health of a single private transaction manager endpoint
+type EndpointStatus struct {
+	Url         string    `json:"url"`
+	Name        string    `json:"name,omitempty"`
+	Healthy     bool      `json:"healthy"`
+	Active      bool      `json:"active"`
+	LastChecked time.Time `json:"lastChecked"`
+	LastError   string    `json:"lastError,omitempty"`
+}
+
+// implemented by private transaction managers which can report an unreachable endpoint
+// instead of terminating the node
+type failoverAware interface {
+	SetFailover(failover bool)
+}
+
+type ptmEndpoint struct {
+	url         string
+	client      *engine.Client
+	ptm         PrivateTransactionManager // nil until the endpoint has been reachable once
+	healthy     bool
+	lastChecked time.Time
+	lastError   error
+	upGauge     metrics.Gauge
+}
+
+// failoverPrivateTxManager routes requests to one of several private transaction managers
+// which share the same key set. All requests go to the active endpoint until it becomes
+// unreachable, at which point the next healthy endpoint takes over.
+type failoverPrivateTxManager struct {
+	endpoints []*ptmEndpoint
+	active    int
+	mu        sync.RWMutex
+	cache     cache.Cache // shared by the endpoints, as they serve the same payloads
+
+	interval  time.Duration
+	quit      chan struct{}
+	closeOnce sync.Once
+	wg        sync.WaitGroup
+}
+
+func newFailoverPrivateTxManager(cfg http2.Config, payloadCache cache.Cache) (*failoverPrivateTxManager, error) {
+	urls := append([]string{cfg.HttpUrl}, cfg.HttpFailoverUrls...)
+	f := &failoverPrivateTxManager{
+		cache:     payloadCache,
+		endpoints: make([]*ptmEndpoint, 0, len(urls)),
+		active:    -1,
+		interval:  time.Duration(cfg.UpcheckInterval) * time.Second,
+		quit:      make(chan struct{}),
+	}
+	if f.interval <= 0 {
+		f.interval = time.Duration(http2.DefaultConfig.UpcheckInterval) * time.Second
+	}
+	for i, url := range urls {
+		endpointCfg := cfg
+		endpointCfg.SetHttpUrl(url)
+		// a failing call fails over to the next endpoint right away instead of being retried
+		// against the same one, which would hold up the failover for the whole retry backoff
+		endpointCfg.SetRetryMaxAttempts(1)
+		client, err := http2.CreateClient(endpointCfg)
+		if err != nil {
+			return nil, fmt.Errorf("unable to create connection to private tx manager %s due to: %s", url, err)
+		}
+		f.endpoints = append(f.endpoints, &ptmEndpoint{
+			url:     url,
+			client:  client,
+			upGauge: metrics.GetOrRegisterGauge(fmt.Sprintf("ptm/endpoints/%d/up", i), nil),
+		})
+	}
+	f.checkEndpoints()
+	if f.active < 0 {
+		return nil, fmt.Errorf("none of the %d private tx manager endpoints is ready", len(f.endpoints))
+	}
+	f.wg.Add(1)
+	go f.loop()
+	return f, nil
+}
+
+// loop periodically probes /upcheck on all endpoints until Close is called
+func (f *failoverPrivateTxManager) loop() {
+	defer f.wg.Done()
+	ticker := time.NewTicker(f.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			f.checkEndpoints()
+		case <-f.quit:
+			return
+		}
+	}
+}
+
+// Close stops the health checks. It can be called more than once.
+func (f *failoverPrivateTxManager) Close() {
+	f.closeOnce.Do(func() { close(f.quit) })
+	f.wg.Wait()
+}
+
+// checkEndpoints probes all endpoints, lazily selecting the client implementation of an
+// endpoint the first time it is reachable, and moves the active endpoint off an unhealthy one
+func (f *failoverPrivateTxManager) checkEndpoints() {
+	for _, ep := range f.endpoints {
+		err := upcheck(ep.client)
+		var ptm PrivateTransactionManager
+		if err == nil && f.endpointPTM(ep) == nil {
+			if ptm, err = selectPrivateTxManager(ep.client); err == nil {
+				if fa, ok := ptm.(failoverAware); ok {
+					fa.SetFailover(true)
+				}
+				setCache(ptm, f.cache)
+			}
+		}
+		f.mu.Lock()
+		if ptm != nil {
+			ep.ptm = ptm
+		}
+		f.setHealth(ep, err)
+		f.mu.Unlock()
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	healthy := 0
+	for _, ep := range f.endpoints {
+		if ep.healthy {
+			healthy++
+		}
+	}
+	healthyEndpointsGauge.Update(int64(healthy))
+	if f.active >= 0 && f.endpoints[f.active].healthy {
+		return
+	}
+	for i, ep := range f.endpoints {
+		if ep.healthy {
+			f.switchTo(i)
+			return
+		}
+	}
+}
+
+// setHealth records the result of a probe or a call, the caller must hold the write lock
+func (f *failoverPrivateTxManager) setHealth(ep *ptmEndpoint, err error) {
+	ep.lastChecked = time.Now()
+	ep.lastError = err
+	ep.healthy = err == nil && ep.ptm != nil
+	if ep.healthy {
+		ep.upGauge.Update(1)
+	} else {
+		ep.upGauge.Update(0)
+		unreachableMeter.Mark(1)
+	}
+}
+
+// switchTo makes the endpoint at index i the active one, the caller must hold the write lock
+func (f *failoverPrivateTxManager) switchTo(i int) {
+	if f.active == i {
+		return
+	}
+	if f.active >= 0 {
+		log.Warn("Failing over to another private tx manager", "from", f.endpoints[f.active].url, "to", f.endpoints[i].url)
+		failoverMeter.Mark(1)
+	} else {
+		log.Info("Using private tx manager", "url", f.endpoints[i].url)
+	}
+	f.active = i
+}
+
+func (f *failoverPrivateTxManager) endpointPTM(ep *ptmEndpoint) PrivateTransactionManager {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return ep.ptm
+}
+
+// candidates returns the endpoints in the order they should be tried: the active endpoint,
+// then the other healthy endpoints, then the unhealthy endpoints that have been reachable before
+func (f *failoverPrivateTxManager) candidates() []int {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	order := make([]int, 0, len(f.endpoints))
+	if f.active >= 0 {
+		order = append(order, f.active)
+	}
+	for i, ep := range f.endpoints {
+		if i != f.active && ep.healthy {
+			order = append(order, i)
+		}
+	}
+	for i, ep := range f.endpoints {
+		if i != f.active && !ep.healthy && ep.ptm != nil {
+			order = append(order, i)
+		}
+	}
+	return order
+}
+
+// call invokes op on the active endpoint, failing over to the other endpoints when the
+// active one turns out to be unreachable. Errors returned by a reachable endpoint are
+// passed back to the caller as they are. A call which is not idempotent only fails over if
+// the request could not be sent at all, as an endpoint which became unreachable after
+// receiving it may have acted on it already, e.g. distributed a private transaction.
+func (f *failoverPrivateTxManager) call(op func(ptm PrivateTransactionManager) error, idempotent bool) error {
+	var lastErr error = engine.ErrPrivateTxManagerNotReady
+	for _, i := range f.candidates() {
+		ep := f.endpoints[i]
+		err := op(f.endpointPTM(ep))
+		if err == nil {
+			f.mu.Lock()
+			if !ep.healthy {
+				f.setHealth(ep, nil)
+			}
+			f.switchTo(i)
+			f.mu.Unlock()
+			return nil
+		}
+		if !engine.IsUnreachable(err) {
+			return err
+		}
+		log.Warn("Private tx manager endpoint is unreachable", "url", ep.url, "err", err)
+		f.mu.Lock()
+		f.setHealth(ep, err)
+		f.mu.Unlock()
+		if !idempotent && !engine.IsNotSent(err) {
+			return err
+		}
+		lastErr = err
+	}
+	return lastErr
+}
+
+// receive behaves like call but, as with a single Tessera, terminates the node when none of
+// the endpoints can be reached, since block processing cannot continue without the payload
+func (f *failoverPrivateTxManager) receive(op func(ptm PrivateTransactionManager) error) error {
+	err := f.call(op, true)
+	if errors.Is(err, engine.ErrPrivateTxManagerUnreachable) {
+		log.Error("Failed to fetch data from all private tx manager endpoints", "err", err)
+		os.Exit(112)
+	}
+	return err
+}
+
+func (f *failoverPrivateTxManager) Send(data []byte, from string, to []string, extra *engine.ExtraMetadata) (sender string, managedParties []string, hash common.EncryptedPayloadHash, err error) {
+	err = f.call(func(ptm PrivateTransactionManager) (err error) {
+		sender, managedParties, hash, err = ptm.Send(data, from, to, extra)
+		return err
+	}, false)
+	return
+}
+
+func (f *failoverPrivateTxManager) StoreRaw(data []byte, from string) (hash common.EncryptedPayloadHash, err error) {
+	err = f.call(func(ptm PrivateTransactionManager) (err error) {
+		hash, err = ptm.StoreRaw(data, from)
+		return err
+	}, false)
+	return
+}
+
+func (f *failoverPrivateTxManager) SendSignedTx(data common.EncryptedPayloadHash, to []string, extra *engine.ExtraMetadata) (sender string, managedParties []string, hash []byte, err error) {
+	err = f.call(func(ptm PrivateTransactionManager) (err error) {
+		sender, managedParties, hash, err = ptm.SendSignedTx(data, to, extra)
+		return err
+	}, false)
+	return
+}
+
+func (f *failoverPrivateTxManager) Receive(data common.EncryptedPayloadHash) (sender string, managedParties []string, payload []byte, extra *engine.ExtraMetadata, err error) {
+	err = f.receive(func(ptm PrivateTransactionManager) (err error) {
+		sender, managedParties, payload, extra, err = ptm.Receive(data)
+		return err
+	})
+	return
+}
+
+func (f *failoverPrivateTxManager) ReceiveRaw(data common.EncryptedPayloadHash) (payload []byte, sender string, extra *engine.ExtraMetadata, err error) {
+	err = f.receive(func(ptm PrivateTransactionManager) (err error) {
+		payload, sender, extra, err = ptm.ReceiveRaw(data)
+		return err
+	})
+	return
+}
+
+func (f *failoverPrivateTxManager) IsSender(txHash common.EncryptedPayloadHash) (isSender bool, err error) {
+	err = f.call(func(ptm PrivateTransactionManager) (err error) {
+		isSender, err = ptm.IsSender(txHash)
+		return err
+	}, true)
+	return
+}
+
+func (f *failoverPrivateTxManager) GetParticipants(txHash common.EncryptedPayloadHash) (participants []string, err error) {
+	err = f.call(func(ptm PrivateTransactionManager) (err error) {
+		participants, err = ptm.GetParticipants(txHash)
+		return err
+	}, true)
+	return
+}
+
+func (f *failoverPrivateTxManager) GetMandatory(txHash common.EncryptedPayloadHash) (mandatory []string, err error) {
+	err = f.call(func(ptm PrivateTransactionManager) (err error) {
+		mandatory, err = ptm.GetMandatory(txHash)
+		return err
+	}, true)
+	return
+}
+
+func (f *failoverPrivateTxManager) EncryptPayload(data []byte, from string, to []string, extra *engine.ExtraMetadata) (encrypted []byte, err error) {
+	err = f.call(func(ptm PrivateTransactionManager) (err error) {
+		encrypted, err = ptm.EncryptPayload(data, from, to, extra)
+		return err
+	}, true)
+	return
+}
+
+func (f *failoverPrivateTxManager) DecryptPayload(payload common.DecryptRequest) (decrypted []byte, extra *engine.ExtraMetadata, err error) {
+	err = f.call(func(ptm PrivateTransactionManager) (err error) {
+		decrypted, extra, err = ptm.DecryptPayload(payload)
+		return err
+	}, true)
+	return
+}
+
+func (f *failoverPrivateTxManager) Groups() (groups []engine.PrivacyGroup, err error) {
+	err = f.call(func(ptm PrivateTransactionManager) (err error) {
+		groups, err = ptm.Groups()
+		return err
+	}, true)
+	return
+}
+
+// Name returns the name of the active endpoint
+func (f *failoverPrivateTxManager) Name() string {
+	return f.activePTM().Name()
+}
+
+// HasFeature is answered by the active endpoint; all endpoints are expected to run the same version
+func (f *failoverPrivateTxManager) HasFeature(feature engine.PrivateTransactionManagerFeature) bool {
+	return f.activePTM().HasFeature(feature)
+}
+
+func (f *failoverPrivateTxManager) activePTM() PrivateTransactionManager {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.endpoints[f.active].ptm
+}
+
+func (f *failoverPrivateTxManager) EndpointStatus() []EndpointStatus {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	status := make([]EndpointStatus, len(f.endpoints))
+	for i, ep := range f.endpoints {
+		status[i] = EndpointStatus{
+			Url:         ep.url,
+			Healthy:     ep.healthy,
+			Active:      i == f.active,
+			LastChecked: ep.lastChecked,
+		}
+		if ep.ptm != nil {
+			status[i].Name = ep.ptm.Name()
+		}
+		if ep.lastError != nil {
+			status[i].LastError = ep.lastError.Error()
+		}
+	}
+	return status
+}
+
+func upcheck(client *engine.Client) error {
+	res, err := client.Get("/upcheck")
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return engine.ErrPrivateTxManagerNotReady
+	}
+	return nil
+}
+
+func (f *failoverPrivateTxManager) CreatePrivacyGroup(from string, members []string, name, description string) (group *engine.PrivacyGroup, err error) {
+	err = f.callPrivacyGroupManager(func(pgm PrivacyGroupManager) (err error) {
+		group, err = pgm.CreatePrivacyGroup(from, members, name, description)
+		return err
+	}, false)
+	return
+}
+
+func (f *failoverPrivateTxManager) DeletePrivacyGroup(from string, privacyGroupId string) (deleted string, err error) {
+	err = f.callPrivacyGroupManager(func(pgm PrivacyGroupManager) (err error) {
+		deleted, err = pgm.DeletePrivacyGroup(from, privacyGroupId)
+		return err
+	}, false)
+	return
+}
+
+func (f *failoverPrivateTxManager) RetrievePrivacyGroup(privacyGroupId string) (group *engine.PrivacyGroup, err error) {
+	err = f.callPrivacyGroupManager(func(pgm PrivacyGroupManager) (err error) {
+		group, err = pgm.RetrievePrivacyGroup(privacyGroupId)
+		return err
+	}, true)
+	return
+}
+
+func (f *failoverPrivateTxManager) FindPrivacyGroup(members []string) (groups []engine.PrivacyGroup, err error) {
+	err = f.callPrivacyGroupManager(func(pgm PrivacyGroupManager) (err error) {
+		groups, err = pgm.FindPrivacyGroup(members)
+		return err
+	}, true)
+	return
+}
+
+func (f *failoverPrivateTxManager) PrivacyGroups(groupType string) (groups []engine.PrivacyGroup, err error) {
+	err = f.callPrivacyGroupManager(func(pgm PrivacyGroupManager) (err error) {
+		groups, err = pgm.PrivacyGroups(groupType)
+		return err
+	}, true)
+	return
+}
+
+func (f *failoverPrivateTxManager) callPrivacyGroupManager(op func(pgm PrivacyGroupManager) error, idempotent bool) error {
+	return f.call(func(ptm PrivateTransactionManager) error {
+		pgm, ok := ptm.(PrivacyGroupManager)
+		if !ok {
+			return engine.ErrPrivateTxManagerNotSupported
+		}
+		return op(pgm)
+	}, idempotent)
+}
+
+func (f *failoverPrivateTxManager) RequestResend(peerURL string, publicKey string) error {
+	return f.call(func(ptm PrivateTransactionManager) error {
+		resender, ok := ptm.(PayloadResender)
+		if !ok {
+			return engine.ErrPrivateTxManagerNotSupported
+		}
+		return resender.RequestResend(peerURL, publicKey)
+	}, true)
+}
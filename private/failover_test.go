@@ -0,0 +1,257 @@
+package private
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	http2 "github.com/ethereum/go-ethereum/common/http"
+	"github.com/ethereum/go-ethereum/private/cache"
+	"github.com/ethereum/go-ethereum/private/engine"
+	"github.com/ethereum/go-ethereum/private/engine/tessera"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func startTesseraHTTPServer(isSender bool) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/upcheck", MockEmptySuccessHandler)
+	mux.HandleFunc("/version", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("21.1.0"))
+	})
+	mux.HandleFunc("/transaction/", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(fmt.Sprintf("%v", isSender)))
+	})
+	return httptest.NewServer(mux)
+}
+
+// startTesseraSendServer is a Tessera which counts the /send requests it receives. If dropSend
+// is set, it drops the connection of a /send request after receiving it and is down from then on.
+func startTesseraSendServer(dropSend bool) (*httptest.Server, *int32) {
+	var sends, down int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/upcheck", func(w http.ResponseWriter, _ *http.Request) {
+		if atomic.LoadInt32(&down) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.HandleFunc("/version", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("21.1.0"))
+	})
+	mux.HandleFunc("/send", func(w http.ResponseWriter, _ *http.Request) {
+		atomic.AddInt32(&sends, 1)
+		if dropSend {
+			atomic.StoreInt32(&down, 1)
+			conn, _, _ := w.(http.Hijacker).Hijack()
+			conn.Close()
+			return
+		}
+		hash := common.BytesToEncryptedPayloadHash([]byte("arbitrary"))
+		_, _ = w.Write([]byte(fmt.Sprintf(`{"key":"%s"}`, hash.ToBase64())))
+	})
+	return httptest.NewServer(mux), &sends
+}
+
+func newFailoverConfig(urls ...string) http2.Config {
+	cfg := http2.DefaultConfig
+	cfg.SetHttpUrl(urls[0])
+	cfg.SetHttpFailoverUrls(urls[1:])
+	return cfg
+}
+
+func TestNewPrivateTxManager_whenFailoverUrlsConfigured(t *testing.T) {
+	primary := startTesseraHTTPServer(true)
+	defer primary.Close()
+	secondary := startTesseraHTTPServer(false)
+	defer secondary.Close()
+
+	p, err := NewPrivateTxManager(newFailoverConfig(primary.URL, secondary.URL))
+	require.NoError(t, err)
+	defer p.(*failoverPrivateTxManager).Close()
+
+	assert.IsType(t, &failoverPrivateTxManager{}, p)
+	assert.Equal(t, "Tessera", p.Name())
+	status := p.(HasEndpointStatus).EndpointStatus()
+	require.Len(t, status, 2)
+	assert.True(t, status[0].Active)
+	assert.True(t, status[0].Healthy)
+	assert.False(t, status[1].Active)
+	assert.True(t, status[1].Healthy)
+}
+
+func TestFailoverPrivateTxManager_whenActiveEndpointGoesDown(t *testing.T) {
+	primary := startTesseraHTTPServer(true)
+	secondary := startTesseraHTTPServer(false)
+	defer secondary.Close()
+
+	f, err := newFailoverPrivateTxManager(newFailoverConfig(primary.URL, secondary.URL), cache.NewDefaultCache())
+	require.NoError(t, err)
+	defer f.Close()
+
+	txHash := common.BytesToEncryptedPayloadHash([]byte("arbitrary"))
+	isSender, err := f.IsSender(txHash)
+	require.NoError(t, err)
+	assert.True(t, isSender, "expected primary endpoint to be used")
+
+	primary.Close()
+
+	isSender, err = f.IsSender(txHash)
+	require.NoError(t, err)
+	assert.False(t, isSender, "expected secondary endpoint to be used")
+
+	status := f.EndpointStatus()
+	assert.False(t, status[0].Healthy)
+	assert.False(t, status[0].Active)
+	assert.NotEmpty(t, status[0].LastError)
+	assert.True(t, status[1].Healthy)
+	assert.True(t, status[1].Active)
+}
+
+func TestFailoverPrivateTxManager_whenActiveEndpointAnswersWithError(t *testing.T) {
+	var upchecks int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/upcheck", func(w http.ResponseWriter, _ *http.Request) {
+		atomic.AddInt32(&upchecks, 1)
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.HandleFunc("/version", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("21.1.0"))
+	})
+	mux.HandleFunc("/transaction/", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	primary := httptest.NewServer(mux)
+	defer primary.Close()
+	secondary := startTesseraHTTPServer(false)
+	defer secondary.Close()
+
+	f, err := newFailoverPrivateTxManager(newFailoverConfig(primary.URL, secondary.URL), cache.NewDefaultCache())
+	require.NoError(t, err)
+	defer f.Close()
+	upchecksBefore := atomic.LoadInt32(&upchecks)
+
+	_, err = f.IsSender(common.BytesToEncryptedPayloadHash([]byte("arbitrary")))
+
+	assert.Error(t, err)
+	assert.Equal(t, upchecksBefore, atomic.LoadInt32(&upchecks), "expected no upcheck as the endpoint answered")
+	status := f.EndpointStatus()
+	assert.True(t, status[0].Active)
+	assert.True(t, status[0].Healthy)
+}
+
+func TestNewFailoverPrivateTxManager_whenRetriesConfigured(t *testing.T) {
+	primary := startTesseraHTTPServer(true)
+	defer primary.Close()
+	secondary := startTesseraHTTPServer(false)
+	defer secondary.Close()
+	cfg := newFailoverConfig(primary.URL, secondary.URL)
+	cfg.SetRetryMaxAttempts(5)
+
+	f, err := newFailoverPrivateTxManager(cfg, cache.NewDefaultCache())
+	require.NoError(t, err)
+	defer f.Close()
+
+	for _, endpoint := range f.endpoints {
+		assert.Equal(t, 1, endpoint.client.Retry.MaxAttempts, "expected calls to fail over instead of being retried against %s", endpoint.url)
+	}
+}
+
+func TestFailoverPrivateTxManager_whenSecondaryEndpointNotReadyAtStartup(t *testing.T) {
+	primary := startTesseraHTTPServer(true)
+	defer primary.Close()
+	secondary := startTesseraHTTPServer(false)
+	secondary.Close()
+
+	f, err := newFailoverPrivateTxManager(newFailoverConfig(primary.URL, secondary.URL), cache.NewDefaultCache())
+	require.NoError(t, err)
+	defer f.Close()
+
+	status := f.EndpointStatus()
+	assert.True(t, status[0].Active)
+	assert.False(t, status[1].Healthy)
+	assert.Empty(t, status[1].Name)
+	assert.True(t, tessera.Is(f.activePTM()))
+}
+
+func TestFailoverPrivateTxManager_whenNoEndpointReady(t *testing.T) {
+	primary := startTesseraHTTPServer(true)
+	primary.Close()
+	secondary := startTesseraHTTPServer(false)
+	secondary.Close()
+
+	_, err := newFailoverPrivateTxManager(newFailoverConfig(primary.URL, secondary.URL), cache.NewDefaultCache())
+
+	assert.EqualError(t, err, "none of the 2 private tx manager endpoints is ready")
+}
+
+func TestConnectionLifecycle_whenFailoverConfigured(t *testing.T) {
+	saved := P
+	defer func() {
+		P = saved
+	}()
+	primary := startTesseraHTTPServer(true)
+	defer primary.Close()
+	secondary := startTesseraHTTPServer(false)
+	defer secondary.Close()
+	cfg := newFailoverConfig(primary.URL, secondary.URL)
+
+	require.NoError(t, InitialiseConnection(cfg, false))
+	previous := P.(*failoverPrivateTxManager)
+	require.NoError(t, InitialiseConnection(cfg, false))
+	current := P.(*failoverPrivateTxManager)
+
+	assertClosed(t, previous, "expected the health checks of the previous connection to be stopped")
+	assert.NoError(t, (&ConnectionLifecycle{}).Stop())
+	assertClosed(t, current, "expected the health checks to be stopped with the node")
+}
+
+func assertClosed(t *testing.T, f *failoverPrivateTxManager, msg string) {
+	select {
+	case <-f.quit:
+	default:
+		t.Error(msg)
+	}
+}
+
+func TestFailoverPrivateTxManager_whenSendNotSentToActiveEndpoint(t *testing.T) {
+	primary, primarySends := startTesseraSendServer(false)
+	secondary, secondarySends := startTesseraSendServer(false)
+	defer secondary.Close()
+
+	f, err := newFailoverPrivateTxManager(newFailoverConfig(primary.URL, secondary.URL), cache.NewDefaultCache())
+	require.NoError(t, err)
+	defer f.Close()
+
+	primary.Close()
+	// so that the send is not attempted on a connection made before the endpoint went down
+	f.endpoints[0].client.HttpClient.CloseIdleConnections()
+
+	_, _, _, err = f.Send([]byte("arbitrary payload"), "", nil, &engine.ExtraMetadata{})
+	require.NoError(t, err)
+	assert.Equal(t, int32(0), atomic.LoadInt32(primarySends))
+	assert.Equal(t, int32(1), atomic.LoadInt32(secondarySends), "expected send to fail over as it could not reach the primary endpoint")
+}
+
+func TestFailoverPrivateTxManager_whenSendDroppedByActiveEndpoint(t *testing.T) {
+	primary, primarySends := startTesseraSendServer(true)
+	defer primary.Close()
+	secondary, secondarySends := startTesseraSendServer(false)
+	defer secondary.Close()
+
+	f, err := newFailoverPrivateTxManager(newFailoverConfig(primary.URL, secondary.URL), cache.NewDefaultCache())
+	require.NoError(t, err)
+	defer f.Close()
+
+	_, _, _, err = f.Send([]byte("arbitrary payload"), "", nil, &engine.ExtraMetadata{})
+	assert.Error(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(primarySends))
+	assert.Equal(t, int32(0), atomic.LoadInt32(secondarySends), "expected send not to be sent again as the primary endpoint may have distributed it")
+
+	status := f.EndpointStatus()
+	assert.False(t, status[0].Healthy, "expected primary endpoint to be marked unhealthy")
+}
@@ -3,27 +3,53 @@ package private
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"os"
+	"sync"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	http2 "github.com/ethereum/go-ethereum/common/http"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/ethdb/leveldb"
+	"github.com/ethereum/go-ethereum/ethdb/memorydb"
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/private/cache"
 	"github.com/ethereum/go-ethereum/private/engine"
 	"github.com/ethereum/go-ethereum/private/engine/constellation"
+	"github.com/ethereum/go-ethereum/private/engine/embedded"
 	"github.com/ethereum/go-ethereum/private/engine/notinuse"
 	"github.com/ethereum/go-ethereum/private/engine/qlightptm"
 	"github.com/ethereum/go-ethereum/private/engine/tessera"
 	"github.com/ethereum/go-ethereum/rpc"
 )
 
+var (
+	// ErrPrivateTransactionBatch is returned when a single private transaction is retrieved for
+	// a privacy marker transaction carrying a batch of private transactions
+	ErrPrivateTransactionBatch = errors.New("privacy marker transaction carries a batch of private transactions")
+)
+
 var (
 	// global variable to be accessed by other packages
 	// singleton gateway to interact with private transaction manager
 	P                PrivateTransactionManager
 	isPrivacyEnabled = false
+
+	// cache of the payloads retrieved by P, closed when the node stops
+	payloadCache   cache.Cache
+	payloadCacheMu sync.Mutex
+
+	// transport and database of P when it is an embedded transaction manager, closed when the node stops
+	embeddedResources   []io.Closer
+	embeddedResourcesMu sync.Mutex
+
+	// P when it fails over between endpoints, whose health checks are stopped when the node stops
+	failoverPTM   *failoverPrivateTxManager
+	failoverPTMMu sync.Mutex
 )
 
 type HasRPCClient interface {
@@ -55,6 +81,67 @@ type PrivateTransactionManager interface {
 	Groups() ([]engine.PrivacyGroup, error)
 }
 
+// PrivacyGroupManager is implemented by private transaction managers which can manage
+// privacy groups of type engine.PrivacyGroupPantheon and list engine.PrivacyGroupLegacy ones,
+// in addition to the resident groups returned by Groups
+type PrivacyGroupManager interface {
+	CreatePrivacyGroup(from string, members []string, name, description string) (*engine.PrivacyGroup, error)
+	// Returns the id of the deleted privacy group
+	DeletePrivacyGroup(from string, privacyGroupId string) (string, error)
+	RetrievePrivacyGroup(privacyGroupId string) (*engine.PrivacyGroup, error)
+	// Returns the privacy groups which have exactly the given members
+	FindPrivacyGroup(members []string) ([]engine.PrivacyGroup, error)
+	PrivacyGroups(groupType string) ([]engine.PrivacyGroup, error)
+}
+
+// PayloadResender is implemented by private transaction managers which can ask another
+// private transaction manager to send again the payloads it holds for a managed party,
+// e.g. to recover the payloads lost when restoring a database from a backup
+type PayloadResender interface {
+	RequestResend(peerURL string, publicKey string) error
+}
+
+// ManagedParties returns the public keys managed by the private transaction manager,
+// i.e. the members of its resident groups
+func ManagedParties(ptm PrivateTransactionManager) ([]string, error) {
+	groups, err := ptm.Groups()
+	if err != nil {
+		return nil, err
+	}
+	var parties []string
+	for _, group := range groups {
+		if group.Type == engine.PrivacyGroupResident {
+			parties = append(parties, group.Members...)
+		}
+	}
+	if len(parties) == 0 {
+		return nil, fmt.Errorf("private transaction manager has no resident groups")
+	}
+	return parties, nil
+}
+
+// RequestResendFromPeers asks the private transaction manager of each peer to send again the
+// payloads it holds for the parties managed by ptm
+func RequestResendFromPeers(ptm PrivateTransactionManager, peerURLs []string) error {
+	resender, ok := ptm.(PayloadResender)
+	if !ok {
+		return engine.ErrPrivateTxManagerNotSupported
+	}
+	parties, err := ManagedParties(ptm)
+	if err != nil {
+		return err
+	}
+	for _, peerURL := range peerURLs {
+		for _, party := range parties {
+			if err := resender.RequestResend(peerURL, party); err != nil {
+				return err
+			}
+			log.Info("Requested resend of private payloads", "peer", peerURL, "party", party)
+		}
+	}
+	return nil
+}
+
 // This loads any config specified via the legacy environment variable
 func GetLegacyEnvironmentConfig() (http2.Config, error) {
 	return FromEnvironmentOrNil("PRIVATE_CONFIG")
@@ -71,9 +158,16 @@ func FromEnvironmentOrNil(name string) (http2.Config, error) {
 }
 
 func InitialiseConnection(cfg http2.Config, isLightClient bool) error {
+	// the health checks of a previous connection would keep probing its endpoints
+	closeFailover()
 	var err error
 	if isLightClient {
+		var c cache.Cache
+		if c, err = newPayloadCache(cfg); err != nil {
+			return err
+		}
 		P, err = NewQLightTxManager()
+		setCache(P, c)
 		return err
 	}
 	P, err = NewPrivateTxManager(cfg)
@@ -89,26 +183,187 @@ func NewQLightTxManager() (PrivateTransactionManager, error) {
 	return qlightptm.New(), nil
 }
 
+// NewEmbeddedTxManager creates an in-process private transaction manager for development and tests,
+// keeping its payloads in db and exchanging them with the other members of the network
+func NewEmbeddedTxManager(cfg embedded.Config, db ethdb.KeyValueStore, network *embedded.Network) (PrivateTransactionManager, error) {
+	ptm, err := embedded.New(cfg, db, network)
+	if err != nil {
+		return nil, err
+	}
+	isPrivacyEnabled = true
+	return ptm, nil
+}
+
 func NewPrivateTxManager(cfg http2.Config) (PrivateTransactionManager, error) {
 	if cfg.ConnectionType == http2.NoConnection {
 		log.Info("Running with private transaction manager disabled - quorum private transactions will not be supported")
 		return &notinuse.PrivateTransactionManager{}, nil
 	}
+	if cfg.ConnectionType == http2.EmbeddedConnection {
+		return newEmbeddedPrivateTxManager(cfg)
+	}
+
+	payloadCache, err := newPayloadCache(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create private payload cache due to: %s", err)
+	}
+
+	if len(cfg.HttpFailoverUrls) > 0 {
+		ptm, err := newFailoverPrivateTxManager(cfg, payloadCache)
+		if err != nil {
+			closePayloadCache()
+			return nil, fmt.Errorf("unable to connect to private tx manager due to: %s", err)
+		}
+		failoverPTMMu.Lock()
+		failoverPTM = ptm
+		failoverPTMMu.Unlock()
+		isPrivacyEnabled = true
+		return ptm, nil
+	}
 
 	client, err := http2.CreateClient(cfg)
 	if err != nil {
+		closePayloadCache()
 		return nil, fmt.Errorf("unable to create connection to private tx manager due to: %s", err)
 	}
 
 	ptm, err := selectPrivateTxManager(client)
 	if err != nil {
+		closePayloadCache()
 		return nil, fmt.Errorf("unable to connect to private tx manager due to: %s", err)
 	}
+	setCache(ptm, payloadCache)
 
 	isPrivacyEnabled = true
 	return ptm, nil
 }
 
+// newEmbeddedPrivateTxManager creates the embedded transaction manager configured with the
+// --ptm.embedded.* flags. Its transport and database replace the ones of a previous connection,
+// which are closed so that they can be opened again.
+func newEmbeddedPrivateTxManager(cfg http2.Config) (PrivateTransactionManager, error) {
+	closeEmbeddedResources()
+	embeddedCfg, err := embedded.LoadConfig(cfg.EmbeddedKeys)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load keys of embedded private tx manager due to: %s", err)
+	}
+	var db ethdb.KeyValueStore = memorydb.New()
+	if cfg.EmbeddedDir != "" {
+		if db, err = leveldb.New(cfg.EmbeddedDir, 16, 16, "ptm/embedded/db/", false); err != nil {
+			return nil, fmt.Errorf("unable to open database of embedded private tx manager due to: %s", err)
+		}
+	}
+	network := embedded.NewNetwork()
+	network.AddPeers(cfg.EmbeddedPeers...)
+	ptm, err := NewEmbeddedTxManager(embeddedCfg, db, network)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("unable to create embedded private tx manager due to: %s", err)
+	}
+	if cfg.EmbeddedListen != "" {
+		addr, err := network.Listen(cfg.EmbeddedListen)
+		if err != nil {
+			db.Close()
+			return nil, fmt.Errorf("unable to accept payloads for embedded private tx manager due to: %s", err)
+		}
+		log.Info("Embedded private tx manager accepting payloads from other nodes", "url", "http://"+addr.String())
+	}
+	embeddedResourcesMu.Lock()
+	embeddedResources = []io.Closer{network, db}
+	embeddedResourcesMu.Unlock()
+	log.Info("Running with embedded private tx manager", "keys", len(embeddedCfg.Keys), "peers", len(cfg.EmbeddedPeers))
+	return ptm, nil
+}
+
+// closeEmbeddedResources closes the transport and then the database of the embedded transaction manager, if any
+func closeEmbeddedResources() error {
+	embeddedResourcesMu.Lock()
+	defer embeddedResourcesMu.Unlock()
+
+	var firstErr error
+	for _, resource := range embeddedResources {
+		if err := resource.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	embeddedResources = nil
+	return firstErr
+}
+
+// implemented by private transaction managers which cache the payloads they retrieve
+type cacheAware interface {
+	SetCache(c cache.Cache)
+}
+
+func setCache(ptm PrivateTransactionManager, c cache.Cache) {
+	if ca, ok := ptm.(cacheAware); ok && c != nil {
+		ca.SetCache(c)
+	}
+}
+
+// newPayloadCache creates the cache of retrieved payloads, which is shared by all
+// endpoints when failover is configured. It replaces the cache of a previous connection,
+// which is closed so that its disk tier can be opened again.
+func newPayloadCache(cfg http2.Config) (cache.Cache, error) {
+	closePayloadCache()
+	c, err := cache.New(cache.Config{
+		Size:           cfg.CacheSize,
+		Expiration:     time.Duration(cfg.CacheExpiration) * time.Second,
+		Dir:            cfg.CacheDir,
+		DiskExpiration: time.Duration(cfg.CacheDiskExpiration) * time.Second,
+	})
+	if err != nil {
+		return nil, err
+	}
+	payloadCacheMu.Lock()
+	payloadCache = c
+	payloadCacheMu.Unlock()
+	return c, nil
+}
+
+// closePayloadCache closes the cache of retrieved payloads, if any
+func closePayloadCache() error {
+	payloadCacheMu.Lock()
+	defer payloadCacheMu.Unlock()
+
+	if payloadCache == nil {
+		return nil
+	}
+	err := payloadCache.Close()
+	payloadCache = nil
+	return err
+}
+
+// closeFailover stops the health checks of the failover transaction manager, if any
+func closeFailover() {
+	failoverPTMMu.Lock()
+	defer failoverPTMMu.Unlock()
+
+	if failoverPTM != nil {
+		failoverPTM.Close()
+		failoverPTM = nil
+	}
+}
+
+// ConnectionLifecycle is registered with the node to stop the health checks of a failover
+// transaction manager, and close the cache of retrieved payloads and the resources of an
+// embedded transaction manager, when the node stops, after the services which use the
+// private transaction manager
+type ConnectionLifecycle struct{}
+
+func (l *ConnectionLifecycle) Start() error {
+	return nil
+}
+
+func (l *ConnectionLifecycle) Stop() error {
+	closeFailover()
+	embeddedErr := closeEmbeddedResources()
+	if err := closePayloadCache(); err != nil {
+		return err
+	}
+	return embeddedErr
+}
+
 // First call /upcheck to make sure the private tx manager is up
 // Then call /version to decide which private tx manager client implementation to be used
 func selectPrivateTxManager(client *engine.Client) (PrivateTransactionManager, error) {
@@ -156,6 +411,9 @@ func FetchPrivateTransactionWithPTM(data []byte, ptm PrivateTransactionManager)
 	if txData == nil {
 		return nil, nil, nil, nil
 	}
+	if isPrivateTransactionBatch(txData) {
+		return nil, nil, nil, ErrPrivateTransactionBatch
+	}
 
 	var tx types.Transaction
 	err = json.NewDecoder(bytes.NewReader(txData)).Decode(&tx)
@@ -166,3 +424,41 @@ func FetchPrivateTransactionWithPTM(data []byte, ptm PrivateTransactionManager)
 
 	return &tx, managedParties, metadata, nil
 }
+
+// Retrieve the private transactions that are associated with a privacy marker transaction: its
+// private transaction, or the private transactions of the batch it carries, in order
+func FetchPrivateTransactions(data []byte) ([]*types.Transaction, []string, *engine.ExtraMetadata, error) {
+	return FetchPrivateTransactionsWithPTM(data, P)
+}
+
+func FetchPrivateTransactionsWithPTM(data []byte, ptm PrivateTransactionManager) ([]*types.Transaction, []string, *engine.ExtraMetadata, error) {
+	_, managedParties, txData, metadata, err := ptm.Receive(common.BytesToEncryptedPayloadHash(data))
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if txData == nil {
+		return nil, nil, nil, nil
+	}
+
+	var txs []*types.Transaction
+	if isPrivateTransactionBatch(txData) {
+		err = json.Unmarshal(txData, &txs)
+	} else {
+		var tx types.Transaction
+		err = json.Unmarshal(txData, &tx)
+		txs = []*types.Transaction{&tx}
+	}
+	if err != nil {
+		log.Trace("failed to deserialize private transactions", "err", err)
+		return nil, nil, nil, err
+	}
+
+	return txs, managedParties, metadata, nil
+}
+
+// isPrivateTransactionBatch returns true if the payload is the JSON array of the private
+// transactions of a batch, rather than a single private transaction
+func isPrivateTransactionBatch(txData []byte) bool {
+	trimmed := bytes.TrimLeft(txData, " \t\r\n")
+	return len(trimmed) > 0 && trimmed[0] == '['
+}
@@ -12,7 +12,9 @@ import (
 	"testing"
 
 	http2 "github.com/ethereum/go-ethereum/common/http"
+	"github.com/ethereum/go-ethereum/private/cache"
 	"github.com/ethereum/go-ethereum/private/engine/constellation"
+	"github.com/ethereum/go-ethereum/private/engine/embedded"
 	"github.com/ethereum/go-ethereum/private/engine/tessera"
 	"github.com/stretchr/testify/assert"
 )
@@ -104,3 +106,60 @@ func startUnixSocketHTTPServer(t *testing.T, handlers map[string]http.HandlerFun
 	t.Log("Unix Socket HTTP server started")
 	return &testServer, tmpFile
 }
+
+func TestNewPrivateTxManager_whenUnreachableWithCacheDir(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+	dir := t.TempDir()
+	cfg := http2.DefaultConfig
+	cfg.SetHttpUrl(server.URL)
+	cfg.SetCacheDir(dir)
+	cfg.SetRetryMaxAttempts(1)
+
+	_, err := NewPrivateTxManager(cfg)
+	assert.Error(t, err)
+
+	// the disk tier of the payload cache has been released
+	c, err := cache.New(cache.Config{Dir: dir})
+	assert.NoError(t, err)
+	assert.NoError(t, c.Close())
+}
+
+func TestInitialiseConnection_whenInitialisedTwiceWithCacheDir(t *testing.T) {
+	saved := P
+	defer func() {
+		P = saved
+	}()
+	cfg := http2.DefaultConfig
+	cfg.SetCacheDir(t.TempDir())
+
+	assert.NoError(t, InitialiseConnection(cfg, true))
+	assert.NoError(t, InitialiseConnection(cfg, true), "expected the disk tier of the previous connection to be released")
+	assert.NoError(t, (&ConnectionLifecycle{}).Stop())
+}
+
+func TestInitialiseConnection_whenEmbeddedInitialisedTwice(t *testing.T) {
+	saved := P
+	defer func() {
+		P = saved
+	}()
+	key, err := embedded.GenerateKeyPair()
+	assert.NoError(t, err)
+	keysFile := filepath.Join(t.TempDir(), "keys.json")
+	keys := `{"keys": [{"publicKey": "` + key.PublicKey() + `", "privateKey": "` + key.PrivateKey() + `"}]}`
+	assert.NoError(t, os.WriteFile(keysFile, []byte(keys), 0600))
+	cfg := http2.DefaultConfig
+	cfg.SetEmbeddedKeys(keysFile)
+	cfg.SetEmbeddedDir(t.TempDir())
+	cfg.SetEmbeddedListen("127.0.0.1:0")
+	assert.NoError(t, cfg.Validate())
+
+	assert.NoError(t, InitialiseConnection(cfg, false))
+	assert.NoError(t, InitialiseConnection(cfg, false), "expected the database of the previous connection to be released")
+	assert.True(t, embedded.Is(P))
+	assert.True(t, IsQuorumPrivacyEnabled())
+	groups, err := P.Groups()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{key.PublicKey()}, groups[0].Members)
+	assert.NoError(t, (&ConnectionLifecycle{}).Stop())
+}
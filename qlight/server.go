@@ -31,6 +31,7 @@ func (p *privateBlockDataResolverImpl) PrepareBlockPrivateData(block *types.Bloc
 		return nil, err
 	}
 	for _, tx := range block.Transactions() {
+		txs := []*types.Transaction{tx}
 		if tx.IsPrivacyMarker() {
 			ptd, err := p.fetchPrivateData(tx.Data(), psm)
 			if err != nil {
@@ -40,13 +41,17 @@ func (p *privateBlockDataResolverImpl) PrepareBlockPrivateData(block *types.Bloc
 				pvtTxs = append(pvtTxs, *ptd)
 			}
 
-			innerTx, _, _, _ := private.FetchPrivateTransactionWithPTM(tx.Data(), p.ptm)
-			if innerTx != nil {
-				tx = innerTx
+			// the privacy marker transaction may carry a batch of private transactions
+			innerTxs, _, _, _ := private.FetchPrivateTransactionsWithPTM(tx.Data(), p.ptm)
+			if innerTxs != nil {
+				txs = innerTxs
 			}
 		}
 
-		if tx.IsPrivate() {
+		for _, tx := range txs {
+			if !tx.IsPrivate() {
+				continue
+			}
 			ptd, err := p.fetchPrivateData(tx.Data(), psm)
 			if err != nil {
 				return nil, err
@@ -53,6 +53,9 @@ var (
 	vmTestDir          = filepath.Join(baseDir, "VMTests")
 	rlpTestDir         = filepath.Join(baseDir, "RLPTests")
 	difficultyTestDir  = filepath.Join(baseDir, "BasicTests")
+
+	// Quorum
+	quorumStateTestDir = filepath.Join(".", "quorum", "testdata", "GeneralStateTests")
 )
 
 func readJSON(reader io.Reader, value interface{}) error {
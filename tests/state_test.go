@@ -23,7 +23,15 @@ import (
 	"reflect"
 	"testing"
 
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/private"
+	"github.com/ethereum/go-ethereum/private/engine"
+	"github.com/ethereum/go-ethereum/private/engine/notinuse"
 )
 
 func TestState(t *testing.T) {
@@ -86,6 +94,93 @@ func TestState(t *testing.T) {
 	}
 }
 
+// Quorum: the state tests of the Quorum specific forks are executed as public transactions, and as
+// private transactions whose post-state is the private state
+func TestQuorumState(t *testing.T) {
+	st := new(testMatcher)
+	st.walk(t, quorumStateTestDir, func(t *testing.T, name string, test *StateTest) {
+		for _, subtest := range test.Subtests() {
+			subtest := subtest
+			key := fmt.Sprintf("%s/%d", subtest.Fork, subtest.Index)
+			name := name + "/" + key
+
+			t.Run(key+"/public", func(t *testing.T) {
+				withTrace(t, test.gasLimit(subtest), func(vmconfig vm.Config) error {
+					_, _, err := test.Run(subtest, vmconfig, false)
+					return st.checkFailure(t, name+"/public", err)
+				})
+			})
+			t.Run(key+"/private", func(t *testing.T) {
+				withTrace(t, test.gasLimit(subtest), func(vmconfig vm.Config) error {
+					err := runPrivateStateTest(test, subtest, vmconfig)
+					return st.checkFailure(t, name+"/private", err)
+				})
+			})
+		}
+	})
+}
+
+// runPrivateStateTest executes the subtest as a private transaction, in the private state
+// initialized with the pre-state of the test, and verifies the post-state of the private state
+func runPrivateStateTest(test *StateTest, subtest StateSubtest, vmconfig vm.Config) error {
+	forkConfig, eips, err := GetChainConfig(subtest.Fork)
+	if err != nil {
+		return UnsupportedForkError{subtest.Fork}
+	}
+	config := *forkConfig
+	config.IsQuorum = true
+	vmconfig.ExtraEips = eips
+	block := test.genesis(&config).ToBlock(nil)
+	_, publicState := MakePreState(rawdb.NewMemoryDatabase(), test.json.Pre, false)
+	_, privateState := MakePreState(rawdb.NewMemoryDatabase(), test.json.Pre, false)
+
+	post := test.json.Post[subtest.Fork][subtest.Index]
+	msg, err := test.json.Tx.toMessage(post)
+	if err != nil {
+		return err
+	}
+	// the transaction carries the hash of its payload, retrieved from the private transaction manager
+	payloadHash := common.BytesToEncryptedPayloadHash(crypto.Keccak512(msg.Data()))
+	saved := private.P
+	defer func() { private.P = saved }()
+	private.P = &stateTestPrivateTxManager{payloads: map[common.EncryptedPayloadHash][]byte{payloadHash: msg.Data()}}
+	privateMsg := privateMessage{types.NewMessage(msg.From(), msg.To(), msg.Nonce(), msg.Value(), msg.Gas(), msg.GasPrice(), payloadHash.Bytes(), msg.AccessList(), true)}
+
+	context := core.NewEVMBlockContext(block.Header(), nil, &test.json.Env.Coinbase)
+	context.GetHash = vmTestBlockHash
+	evm := vm.NewEVM(context, core.NewEVMTxContext(privateMsg), publicState, privateState, &config, vmconfig)
+
+	snapshot := privateState.Snapshot()
+	gaspool := new(core.GasPool)
+	gaspool.AddGas(block.GasLimit())
+	if _, err := core.ApplyMessage(evm, privateMsg, gaspool); err != nil {
+		privateState.RevertToSnapshot(snapshot)
+	}
+	if root := privateState.IntermediateRoot(config.IsEIP158(block.Number())); root != common.Hash(post.PrivateRoot) {
+		return fmt.Errorf("private post state root mismatch: got %x, want %x", root, post.PrivateRoot)
+	}
+	return nil
+}
+
+// privateMessage is the message of a private transaction
+type privateMessage struct {
+	types.Message
+}
+
+func (privateMessage) IsPrivate() bool {
+	return true
+}
+
+// stateTestPrivateTxManager returns the payloads of the private transactions of the state tests
+type stateTestPrivateTxManager struct {
+	notinuse.PrivateTransactionManager
+	payloads map[common.EncryptedPayloadHash][]byte
+}
+
+func (ptm *stateTestPrivateTxManager) Receive(hash common.EncryptedPayloadHash) (string, []string, []byte, *engine.ExtraMetadata, error) {
+	return "", nil, ptm.payloads[hash], nil, nil
+}
+
 // Transactions with gasLimit above this value will not get a VM trace on failure.
 const traceErrorLimit = 400000
 
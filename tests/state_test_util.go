@@ -72,6 +72,7 @@ type stPostState struct {
 		Gas   int `json:"gas"`
 		Value int `json:"value"`
 	}
+	PrivateRoot common.UnprefixedHash `json:"privateHash"` // Quorum: root of the private state when the transaction is executed as a private transaction
 }
 
 //go:generate gencodec -type stEnv -field-override stEnvMarshaling -out gen_stenv.go
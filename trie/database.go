@@ -311,6 +311,12 @@ func NewDatabaseWithConfig(diskdb ethdb.KeyValueStore, config *Config) *Database
 	return db
 }
 
+// Quorum
+// Preimages returns whether the preimages of the trie keys are recorded
+func (db *Database) Preimages() bool {
+	return db.preimages != nil
+}
+
 // DiskDB retrieves the persistent storage backing the trie database.
 func (db *Database) DiskDB() ethdb.KeyValueStore {
 	return db.diskdb